@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// AddMemberCmd represents the addMember command
+var AddMemberCmd = &cobra.Command{
+	Use:   "add-member [username] [group] [member]",
+	Short: "Add a user to a group the user belongs to",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		name := args[1]
+		member := args[2]
+
+		_, err := fs.AddMember(username, name, member)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Add %v to %v successfully.\n", member, name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(AddMemberCmd)
+}
@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/internal/script"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Statuses of the lines of a batch.
+const (
+	statusOK      = "ok"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// batchCommands are the commands a batch can run, the ones working on the virtual file system.
+var batchCommands = []*cobra.Command{
+	RegisterCmd,
+	DeleteUserCmd,
+	RenameUserCmd,
+	ListUsersCmd,
+	CreateFolderCmd,
+	DeleteFolderCmd,
+	ListFoldersCmd,
+	RenameFolderCmd,
+	CopyFolderCmd,
+	MoveFolderCmd,
+	ShareFolderCmd,
+	UnshareFolderCmd,
+	ListSharesCmd,
+	CreateGroupCmd,
+	AddMemberCmd,
+	RemoveMemberCmd,
+	ListGroupsCmd,
+	GrantRoleCmd,
+	RevokeRoleCmd,
+	SetPasswordCmd,
+	SetQuotaCmd,
+	UsageCmd,
+	CreateFileCmd,
+	DeleteFileCmd,
+	ListFilesCmd,
+	RenameFileCmd,
+	MoveFileCmd,
+	CopyFileCmd,
+	TagCmd,
+	UntagCmd,
+	TagsCmd,
+	ExportCmd,
+}
+
+type batchResult struct {
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type batchReport struct {
+	Results   []batchResult `json:"results"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"`
+	Atomic    bool          `json:"atomic"`
+	Committed bool          `json:"committed"`
+}
+
+// BatchCmd represents the batch command
+var BatchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Run the commands of a script, one per line, against a single loaded store",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		onError, _ := cmd.Flags().GetString("on-error")
+		output, _ := cmd.Flags().GetString("output")
+		atomic, _ := cmd.Flags().GetBool("atomic")
+
+		if onError != "stop" && onError != "continue" {
+			cmd.Printf("Error: unsupported --on-error: %s, use stop or continue\n", onError)
+			return
+		}
+		if output != "text" && output != "json" {
+			cmd.Printf("Error: unsupported --output: %s, use text or json\n", output)
+			return
+		}
+
+		lines, err := readScript(cmd, file)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		report, err := runBatch(lines, onError == "continue", atomic)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if output == "json" {
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			_ = encoder.Encode(report)
+			return
+		}
+
+		for _, result := range report.Results {
+			cmd.Printf("[%d] %s: %s\n", result.Line, result.Status, result.Command)
+			if result.Output != "" {
+				cmd.Print(result.Output)
+			} else if result.Error != "" {
+				cmd.Printf("Error: %v\n", result.Error)
+			}
+		}
+		if report.Atomic && !report.Committed {
+			cmd.Println("Roll back the batch, nothing was changed.")
+		}
+		cmd.Printf(
+			"Run %d lines: %d succeeded, %d failed, %d skipped.\n",
+			len(report.Results),
+			report.Succeeded,
+			report.Failed,
+			report.Skipped,
+		)
+	},
+}
+
+func readScript(cmd *cobra.Command, file string) ([]script.Line, error) {
+	if file == "-" {
+		return script.Parse(cmd.InOrStdin())
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the script: %w", err)
+	}
+	defer f.Close()
+
+	return script.Parse(f)
+}
+
+// runBatch is used to run lines against fs, up to the first failure unless keepGoing.
+// An atomic batch runs against a staged copy of the store, which replaces the store only when every line succeeded.
+func runBatch(lines []script.Line, keepGoing bool, atomic bool) (report *batchReport, err error) {
+	report = &batchReport{Atomic: atomic}
+
+	if atomic {
+		var staged vfs.VirtualFileSystem
+		var commit func() error
+		var discard func()
+		staged, commit, discard, err = stageStore()
+		if err != nil {
+			return nil, err
+		}
+		defer discard()
+
+		loaded := fs
+		fs = staged
+		defer func() {
+			fs = loaded
+		}()
+
+		defer func() {
+			if err == nil && report.Failed == 0 {
+				err = commit()
+				report.Committed = err == nil
+			}
+		}()
+	}
+
+	for _, line := range lines {
+		result := batchResult{Line: line.Number, Command: line.Text, Status: statusSkipped}
+		if report.Failed == 0 || keepGoing {
+			var lineErr error
+			result.Output, lineErr = runLine(line.Args)
+			result.Status = statusOK
+			if lineErr != nil {
+				result.Status = statusFailed
+				result.Error = lineErr.Error()
+			}
+		}
+
+		switch result.Status {
+		case statusOK:
+			report.Succeeded++
+		case statusFailed:
+			report.Failed++
+		default:
+			report.Skipped++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// runLine is used to run the command of args against fs, returning what it printed.
+// The commands report their failures by printing an error, which is returned too.
+func runLine(args []string) (output string, err error) {
+	var target *cobra.Command
+	for _, command := range batchCommands {
+		if command.Name() == args[0] || command.HasAlias(args[0]) {
+			target = command
+		}
+	}
+	if target == nil {
+		return "", fmt.Errorf("unknown command %q for batch", args[0])
+	}
+
+	// the flags keep the values of the previous lines, and the store can't change in the middle of a batch
+	target.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		_ = flag.Value.Set(flag.DefValue)
+		flag.Changed = false
+	})
+	inherited := make(map[string]string)
+	target.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		inherited[flag.Name] = flag.Value.String()
+	})
+
+	err = target.ParseFlags(args[1:])
+	target.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Value.String() != inherited[flag.Name] {
+			_ = flag.Value.Set(inherited[flag.Name])
+			err = fmt.Errorf("the --%s flag can't be changed in a batch", flag.Name)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	args = target.Flags().Args()
+
+	err = target.ValidateArgs(args)
+	if err != nil {
+		return "", err
+	}
+	err = target.ValidateRequiredFlags()
+	if err != nil {
+		return "", err
+	}
+	err = target.ValidateFlagGroups()
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	target.SetOut(buf)
+	target.SetErr(buf)
+	defer func() {
+		target.SetOut(nil)
+		target.SetErr(nil)
+	}()
+
+	target.Run(target, args)
+
+	for _, printed := range strings.Split(buf.String(), "\n") {
+		if message, failed := strings.CutPrefix(printed, "Error: "); failed {
+			return buf.String(), errors.New(message)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// stageStore is used to load a copy of the document store at Out and its groups. commit replaces the store with
+// the copy, and discard removes what is left of the copy.
+func stageStore() (staged vfs.VirtualFileSystem, commit func() error, discard func(), err error) {
+	if !utils.IsDocument(utils.CheckPathType(Out)) {
+		return nil, nil, nil, fmt.Errorf("atomic batches need a document store, the %s isn't one", Out)
+	}
+
+	copyPath := filepath.Join(filepath.Dir(Out), ".batch-"+filepath.Base(Out))
+	err = copyFile(Out, copyPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the groups are staged with the document
+	groupsPath, copyGroupsPath := store.GroupsPath(Out), store.GroupsPath(copyPath)
+	discard = func() {
+		_ = os.Remove(copyPath)
+		_ = os.Remove(copyGroupsPath)
+	}
+
+	err = copyFile(groupsPath, copyGroupsPath)
+	if err != nil {
+		discard()
+		return nil, nil, nil, err
+	}
+
+	staged, err = NewVFSWithJSON(copyPath)
+	if err != nil {
+		discard()
+		return nil, nil, nil, err
+	}
+	staged, err = authenticate(staged)
+	if err != nil {
+		discard()
+		return nil, nil, nil, err
+	}
+
+	commit = func() error {
+		// the groups go first and the original ones are kept aside until the document is in place too, so they're
+		// put back when it can't be
+		undo, done, err := swapGroups(copyGroupsPath, groupsPath)
+		if err != nil {
+			return err
+		}
+
+		// nothing was written, like a batch of lists, when a copy is missing
+		err = os.Rename(copyPath, Out)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.Join(err, undo())
+		}
+		done()
+
+		return nil
+	}
+
+	return staged, commit, discard, nil
+}
+
+// swapGroups is used to move the staged groups to path, keeping the original ones aside until done drops them,
+// while undo puts them back. Nothing is moved when staged is missing, as nothing was written to the groups.
+func swapGroups(staged, path string) (undo func() error, done func(), err error) {
+	undo, done = func() error { return nil }, func() {}
+	if _, err = os.Stat(staged); errors.Is(err, os.ErrNotExist) {
+		return undo, done, nil
+	}
+
+	backup := filepath.Join(filepath.Dir(path), ".batch-backup-"+filepath.Base(path))
+	err = os.Rename(path, backup)
+	if errors.Is(err, os.ErrNotExist) {
+		// there were no groups to put back
+		backup = ""
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to keep the groups aside: %w", err)
+	}
+
+	undo = func() error {
+		if backup == "" {
+			err := os.Remove(path)
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		return os.Rename(backup, path)
+	}
+	done = func() {
+		if backup != "" {
+			_ = os.Remove(backup)
+		}
+	}
+
+	err = os.Rename(staged, path)
+	if err != nil {
+		return nil, nil, errors.Join(err, undo())
+	}
+
+	return undo, done, nil
+}
+
+// copyFile is used to copy the file src to dst, a missing src is an empty store.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", src, err)
+	}
+
+	_, err = io.Copy(out, in)
+	err = errors.Join(err, out.Close())
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", src, err)
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(BatchCmd)
+
+	BatchCmd.Flags().StringP("file", "f", "-", "The script, one command per line, - for stdin")
+	BatchCmd.Flags().String("on-error", "stop", "What to do after a failing line (stop or continue)")
+	BatchCmd.Flags().String("output", "text", "Format of the results (text or json)")
+	BatchCmd.Flags().Bool("atomic", false, "Apply the whole batch or nothing, only for document stores")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CopyFileCmd represents the copyFile command
+var CopyFileCmd = &cobra.Command{
+	Use:   "copy-file [username] [foldername] [filename] [new-foldername] [new-filename]?",
+	Short: "Copy a file to a folder, under the same or a new name",
+	Args:  cobra.RangeArgs(4, 5),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		filename := args[2]
+		newFoldername := args[3]
+		newFilename := filename
+		if len(args) == 5 {
+			newFilename = args[4]
+		}
+
+		file, err := fs.CopyFile(username, foldername, filename, newFoldername, newFilename, conflictOf(cmd))
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if file == nil {
+			cmd.Printf("Skip %v, the %v has already existed in %v/%v.\n", filename, newFilename, username, newFoldername)
+			return
+		}
+
+		cmd.Printf(
+			"Copy %v/%v/%v to %v/%v/%v successfully.\n",
+			username,
+			foldername,
+			filename,
+			username,
+			newFoldername,
+			newFilename,
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(CopyFileCmd)
+
+	addConflictFlags(CopyFileCmd)
+}
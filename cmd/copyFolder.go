@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CopyFolderCmd represents the copyFolder command
+var CopyFolderCmd = &cobra.Command{
+	Use:   "copy-folder [username] [foldername] [new-foldername]? [--to-user username]",
+	Short: "Copy a folder with its files, to a new name or to another user",
+	Args:  cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		newUsername, newFoldername := folderTarget(cmd, args)
+
+		_, err := fs.CopyFolder(username, foldername, newUsername, newFoldername)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Copy %v/%v to %v/%v successfully.\n", username, foldername, newUsername, newFoldername)
+	},
+}
+
+// folderTarget is used to read where cmd copies or moves the folder of args, the same user and name by default.
+func folderTarget(cmd *cobra.Command, args []string) (newUsername, newFoldername string) {
+	newUsername, _ = cmd.Flags().GetString("to-user")
+	if newUsername == "" {
+		newUsername = args[0]
+	}
+
+	newFoldername = args[1]
+	if len(args) == 3 {
+		newFoldername = args[2]
+	}
+
+	return newUsername, newFoldername
+}
+
+func init() {
+	rootCmd.AddCommand(CopyFolderCmd)
+
+	CopyFolderCmd.Flags().String("to-user", "", "The user receiving the copy, the same user by default")
+}
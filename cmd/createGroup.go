@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CreateGroupCmd represents the createGroup command
+var CreateGroupCmd = &cobra.Command{
+	Use:   "create-group [username] [group]",
+	Short: "Create a group with the user as its first member, its folders are group:[group]/[foldername]",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		name := args[1]
+
+		_, err := fs.CreateGroup(username, name)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Create %v successfully.\n", name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(CreateGroupCmd)
+}
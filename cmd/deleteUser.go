@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// DeleteUserCmd represents the deleteUser command
+var DeleteUserCmd = &cobra.Command{
+	Use:   "delete-user [username] [--cascade]",
+	Short: "Delete a user, which must not have any folders unless --cascade deletes them too",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		cascade, _ := cmd.Flags().GetBool("cascade")
+
+		err := fs.DeleteUser(username, cascade)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Delete %v successfully.\n", username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(DeleteUserCmd)
+
+	DeleteUserCmd.Flags().Bool("cascade", false, "Delete the folders and files of the user too")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+// ExportCmd represents the export command
+var ExportCmd = &cobra.Command{
+	Use:   "export [username] [foldername]",
+	Short: "Write the folders and files of a user to a directory or an archive",
+	Args:  cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := ""
+		if len(args) > 1 {
+			foldername = args[1]
+		}
+		to, _ := cmd.Flags().GetString("to")
+
+		report, err := transfer.Export(fs, username, foldername, to)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Export %d folders and %d files to %v.\n", report.Folders, report.Files, to)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ExportCmd)
+
+	ExportCmd.Flags().String("to", "", "The output, a directory or a .tar, .tar.gz (or .tgz) or .zip archive")
+	_ = ExportCmd.MarkFlagRequired("to")
+}
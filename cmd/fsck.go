@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// FsckCmd represents the fsck command
+var FsckCmd = &cobra.Command{
+	Use:   "fsck",
+	Short: "Verify the integrity of the store and optionally repair it",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repair, _ := cmd.Flags().GetBool("repair")
+
+		problems, err := store.Fsck(Out, repair)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		repaired := 0
+		for _, problem := range problems {
+			if problem.Repaired {
+				repaired++
+			}
+			cmd.Println(problem.String())
+		}
+
+		if len(problems) == 0 {
+			cmd.Println("No problems found.")
+			return
+		}
+
+		cmd.Printf("Found %d problems, repaired %d.\n", len(problems), repaired)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(FsckCmd)
+
+	FsckCmd.Flags().Bool("repair", false, "Fix the problems that can be repaired safely")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// GrantRoleCmd represents the grantRole command
+var GrantRoleCmd = &cobra.Command{
+	Use:   "grant-role [username] [admin|member|readonly]",
+	Short: "Give a user a role, which only an admin acting with --as can do",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		role, err := model.ParseRole(args[1])
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		_, err = fs.GrantRole(username, role)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Grant %v to %v successfully.\n", role, username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(GrantRoleCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/internal/history"
+	vfsI "github.com/blackhorseya/iscool-assessment/internal/vfs"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+// LogCmd represents the log command
+var LogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "List the mutations recorded by a git-backed store",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		repository, err := openHistory()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		entries, err := repository.Log()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(entries) == 0 {
+			cmd.Println("Warning: There are no commits.")
+			return
+		}
+
+		// List commits with the following fields: [hash] [date] [author] [message]
+		for _, entry := range entries {
+			cmd.Printf(
+				"%s %s %s %s\n",
+				entry.Short(),
+				entry.When.Format("2006-01-02 15:04:05"),
+				entry.Author,
+				strings.TrimSpace(entry.Message),
+			)
+		}
+	},
+}
+
+// RevertCmd represents the revert command
+var RevertCmd = &cobra.Command{
+	Use:   "revert [commit]",
+	Short: "Undo the changes of a commit of a git-backed store",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		rev := args[0]
+
+		repository, err := openHistory()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		entry, err := repository.Revert(rev)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Revert %v as %v successfully.\n", rev, entry.Short())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(LogCmd)
+	rootCmd.AddCommand(RevertCmd)
+}
+
+// NewVFSWithGit is used to create a VirtualFileSystem on the JSON document of the git repository at path,
+// committing every mutation.
+func NewVFSWithGit(path string) (vfs.VirtualFileSystem, error) {
+	repository, err := history.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := NewVFSWithJSON(repository.Document())
+	if err != nil {
+		return nil, err
+	}
+
+	return vfsI.NewWithHistory(next, repository), nil
+}
+
+func openHistory() (*history.Repository, error) {
+	if utils.CheckPathType(Out) != "git" {
+		return nil, fmt.Errorf("the %s isn't a git-backed store", Out)
+	}
+
+	return history.Open(Out)
+}
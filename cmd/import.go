@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/transfer"
+	"github.com/spf13/cobra"
+)
+
+// ImportCmd represents the import command
+var ImportCmd = &cobra.Command{
+	Use:   "import [username] [local-dir]",
+	Short: "Recreate a local directory as folders and files of a user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		dir := args[1]
+		policy, _ := cmd.Flags().GetString("on-conflict")
+		mapping, _ := cmd.Flags().GetStringToString("map")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		report, err := transfer.Import(fs, username, dir, transfer.ImportOptions{
+			Policy:  policy,
+			Mapping: mapping,
+			DryRun:  dryRun,
+		})
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		verb := "Import"
+		if dryRun {
+			verb = "Would import"
+			for _, action := range report.Actions {
+				cmd.Println(action)
+			}
+		}
+		cmd.Printf(
+			"%s %d folders and %d files (overwritten: %d, skipped: %d, renamed: %d, sanitized: %d).\n",
+			verb,
+			report.Folders,
+			report.Files,
+			report.Overwritten,
+			report.Skipped,
+			report.Renamed,
+			report.Sanitized,
+		)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ImportCmd)
+
+	ImportCmd.Flags().String(
+		"on-conflict",
+		transfer.PolicySkip,
+		"What to do with existing folders and files (skip, overwrite or rename)",
+	)
+	ImportCmd.Flags().StringToString(
+		"map",
+		transfer.DefaultMapping,
+		"Replacements sanitizing invalid names, like ' =_,.=-'",
+	)
+	ImportCmd.Flags().Bool("dry-run", false, "Only report the changes without applying them")
+}
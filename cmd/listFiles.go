@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/spf13/cobra"
 )
 
@@ -11,7 +12,7 @@ const (
 
 // ListFilesCmd represents the listFiles command
 var ListFilesCmd = &cobra.Command{
-	Use:   "list-files [username] [foldername]",
+	Use:   "list-files [username] [foldername] [--tag tag]",
 	Short: "List all files in a folder",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
@@ -19,6 +20,7 @@ var ListFilesCmd = &cobra.Command{
 		foldername := args[1]
 		sortName, _ := cmd.Flags().GetString("sort-name")
 		sortCreated, _ := cmd.Flags().GetString("sort-created")
+		tag, _ := cmd.Flags().GetString("tag")
 
 		if sortName != "" && sortCreated != "" {
 			cmd.Println("Error: Cannot use both --sort-name and --sort-created flags together")
@@ -47,12 +49,23 @@ var ListFilesCmd = &cobra.Command{
 			order = sortCreated
 		}
 
-		files, err := fs.ListFiles(username, foldername, sortCriteria, order)
+		var files []*model.File
+		var err error
+		if tag != "" {
+			files, err = fs.ListTaggedFiles(username, foldername, tag, sortCriteria, order)
+		} else {
+			files, err = fs.ListFiles(username, foldername, sortCriteria, order)
+		}
 		if err != nil {
 			cmd.PrintErrf("Error: %v\n", err)
 			return
 		}
 
+		if tag != "" && len(files) == 0 {
+			cmd.Printf("Warning: The folder doesn't have any files tagged %s.\n", tag)
+			return
+		}
+
 		// Warning: The folder is empty.
 		if len(files) == 0 {
 			cmd.Println("Warning: The folder is empty.")
@@ -81,4 +94,5 @@ func init() {
 	// ListFilesCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	ListFilesCmd.Flags().String("sort-name", "", "Sort folders by name (asc or desc)")
 	ListFilesCmd.Flags().String("sort-created", "", "Sort folders by created time (asc or desc)")
+	ListFilesCmd.Flags().String("tag", "", "Only list the files tagged with this tag")
 }
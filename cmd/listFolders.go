@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
 	"github.com/spf13/cobra"
 )
 
@@ -8,13 +13,14 @@ const orderAsc = "asc"
 
 // ListFoldersCmd represents the listFolders command
 var ListFoldersCmd = &cobra.Command{
-	Use:   "list-folders [username]",
+	Use:   "list-folders [username] [--tag tag]",
 	Short: "List all folders",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		username := args[0]
 		sortName, _ := cmd.Flags().GetString("sort-name")
 		sortCreated, _ := cmd.Flags().GetString("sort-created")
+		tag, _ := cmd.Flags().GetString("tag")
 
 		if sortName != "" && sortCreated != "" {
 			cmd.Println("Error: Cannot use both --sort-name and --sort-created flags together")
@@ -43,14 +49,42 @@ var ListFoldersCmd = &cobra.Command{
 			order = sortCreated
 		}
 
-		folders, err := fs.ListFolders(username, sortCriteria, order)
+		var folders []*model.Folder
+		var err error
+		if tag != "" {
+			folders, err = fs.ListTaggedFolders(username, tag, sortCriteria, order)
+		} else {
+			folders, err = fs.ListFolders(username, sortCriteria, order)
+		}
 		if err != nil {
 			cmd.Printf("Error: %v\n", err)
 			return
 		}
 
+		if tag != "" && len(folders) == 0 {
+			cmd.Printf("Warning: The %s doesn't have any folders tagged %s.\n", username, tag)
+			return
+		}
+
+		// the folders of a group aren't shared, a token without the shares scope only lists the own ones, and
+		// the tags of the shared folders are their owners', so a list by tag leaves them out
+		var shared []*model.Share
+		if !strings.HasPrefix(username, model.GroupPrefix) && tag == "" {
+			shares, err := fs.ListShares(username)
+			if err != nil && !errors.Is(err, vfs.ErrPermissionDenied) {
+				cmd.Printf("Error: %v\n", err)
+				return
+			}
+
+			for _, share := range shares {
+				if share.Grantee == username {
+					shared = append(shared, share)
+				}
+			}
+		}
+
 		// Warning: The [username] doesn't have any folders.
-		if len(folders) == 0 {
+		if len(folders) == 0 && len(shared) == 0 {
 			cmd.Printf("Warning: The %s doesn't have any folders.\n", username)
 			return
 		}
@@ -61,6 +95,14 @@ var ListFoldersCmd = &cobra.Command{
 			createdAt := folder.CreatedAt.Format("2006-01-02 15:04:05")
 			cmd.Printf("%s %s %s %s\n", folder.Name, folder.Description, createdAt, username)
 		}
+
+		// Then the folders of the others shared with the [username]: [owner]/[foldername] [access]
+		if len(shared) > 0 {
+			cmd.Println("Shared with me:")
+			for _, share := range shared {
+				cmd.Printf("%s/%s %s\n", share.Owner, share.Folder, share.Access)
+			}
+		}
 	},
 }
 
@@ -78,4 +120,5 @@ func init() {
 	// ListFoldersCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	ListFoldersCmd.Flags().String("sort-name", "", "Sort folders by name (asc or desc)")
 	ListFoldersCmd.Flags().String("sort-created", "", "Sort folders by created time (asc or desc)")
+	ListFoldersCmd.Flags().String("tag", "", "Only list the folders tagged with this tag")
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ListGroupsCmd represents the listGroups command
+var ListGroupsCmd = &cobra.Command{
+	Use:   "list-groups [username]",
+	Short: "List the groups a user belongs to",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		groups, err := fs.ListGroups(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(groups) == 0 {
+			cmd.Printf("Warning: The %s doesn't belong to any groups.\n", username)
+			return
+		}
+
+		// List the groups in following formats: [group] [member,...] [created at]
+		for _, group := range groups {
+			createdAt := group.CreatedAt.Format("2006-01-02 15:04:05")
+			cmd.Printf("%s %s %s\n", group.Name, strings.Join(group.Members, ","), createdAt)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ListGroupsCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// ListSharesCmd represents the listShares command
+var ListSharesCmd = &cobra.Command{
+	Use:   "list-shares [username]",
+	Short: "List the folders a user has shared and the ones shared with the user",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		shares, err := fs.ListShares(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(shares) == 0 {
+			cmd.Printf("Warning: The %s doesn't have any shares.\n", username)
+			return
+		}
+
+		// List the shares in following formats: [owner]/[foldername] [grantee] [access]
+		for _, share := range shares {
+			cmd.Printf("%s/%s %s %s\n", share.Owner, share.Folder, share.Grantee, share.Access)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ListSharesCmd)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// ListUsersCmd represents the listUsers command
+var ListUsersCmd = &cobra.Command{
+	Use:   "list-users [--sort-name asc|desc] [--offset n] [--limit n]",
+	Short: "List the users, a page at a time with --offset and --limit",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		sortName, _ := cmd.Flags().GetString("sort-name")
+		offset, _ := cmd.Flags().GetInt("offset")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if sortName != orderAsc && sortName != "desc" {
+			cmd.Println("Error: Invalid value for --sort-name. Use 'asc' or 'desc'")
+			return
+		}
+
+		users, err := fs.ListUsers(sortName, offset, limit)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(users) == 0 {
+			cmd.Println("Warning: There aren't any users.")
+			return
+		}
+
+		// List the users in following formats: [username] [role]
+		for _, user := range users {
+			role := model.RoleMember
+			if user.Role != "" {
+				role = user.Role
+			}
+			cmd.Printf("%s %s\n", user.Username, role)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ListUsersCmd)
+
+	ListUsersCmd.Flags().String("sort-name", orderAsc, "Sort users by name (asc or desc)")
+	ListUsersCmd.Flags().Int("offset", 0, "The number of users to skip")
+	ListUsersCmd.Flags().Int("limit", 0, "The number of users to list, all of them by default")
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// LoginCmd represents the login command
+var LoginCmd = &cobra.Command{
+	Use:   "login [username] [--password password] [--ttl duration]",
+	Short: "Start a session of a user, who the next commands act as and whose username they can leave out",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		flag, _ := cmd.Flags().GetString("password")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+
+		password, err := readPassword(cmd, flag)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		expiresAt := time.Now().Add(ttl)
+		token, err := fs.Login(username, password, ttl)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		err = saveSession(&session{Username: username, Token: token, ExpiresAt: expiresAt})
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Login %v successfully, the session expires at %v.\n", username, expiresAt.Format(time.RFC3339))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(LoginCmd)
+
+	LoginCmd.Flags().String("password", "-", "The password, - to read it from the first line of stdin")
+	LoginCmd.Flags().Duration("ttl", time.Hour, "How long the session lasts")
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+// LogoutCmd represents the logout command
+var LogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "End the session to the store",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		current, err := loadSession()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if current == nil {
+			cmd.Println("Warning: Nobody is logged in.")
+			return
+		}
+
+		// a session which has already expired is only forgotten
+		err = fs.Logout(current.Username, current.Token)
+		if err != nil && !errors.Is(err, vfs.ErrUnauthenticated) {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		err = removeSession()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Logout %v successfully.\n", current.Username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(LogoutCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// MigrateCmd represents the migrate command
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the store to the current schema version",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		upgrades, err := store.Migrate(Out, dryRun)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		pending := 0
+		for _, upgrade := range upgrades {
+			if upgrade.From == upgrade.To {
+				continue
+			}
+
+			pending++
+			cmd.Printf("%s: version %d -> %d\n", upgrade.Path, upgrade.From, upgrade.To)
+		}
+
+		switch {
+		case pending == 0:
+			cmd.Printf("Already at schema version %d.\n", store.CurrentVersion)
+		case dryRun:
+			cmd.Printf("Would migrate %d documents (dry run).\n", pending)
+		default:
+			cmd.Printf("Migrate %d documents successfully.\n", pending)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(MigrateCmd)
+
+	MigrateCmd.Flags().Bool("dry-run", false, "Only report the migrations without writing them")
+}
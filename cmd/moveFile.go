@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// MoveFileCmd represents the moveFile command
+var MoveFileCmd = &cobra.Command{
+	Use:   "move-file [username] [foldername] [filename] [new-foldername]",
+	Short: "Move a file to another folder",
+	Args:  cobra.ExactArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		filename := args[2]
+		newFoldername := args[3]
+
+		file, err := fs.MoveFile(username, foldername, filename, newFoldername, conflictOf(cmd))
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if file == nil {
+			cmd.Printf("Skip %v, the %v has already existed in %v/%v.\n", filename, filename, username, newFoldername)
+			return
+		}
+
+		cmd.Printf("Move %v from %v/%v to %v/%v successfully.\n", filename, username, foldername, username, newFoldername)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(MoveFileCmd)
+
+	addConflictFlags(MoveFileCmd)
+}
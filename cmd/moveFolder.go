@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// MoveFolderCmd represents the moveFolder command
+var MoveFolderCmd = &cobra.Command{
+	Use:   "move-folder [username] [foldername] [new-foldername]? [--to-user username]",
+	Short: "Move a folder with its files, to a new name or to another user",
+	Args:  cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		newUsername, newFoldername := folderTarget(cmd, args)
+
+		_, err := fs.MoveFolder(username, foldername, newUsername, newFoldername)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Move %v/%v to %v/%v successfully.\n", username, foldername, newUsername, newFoldername)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(MoveFolderCmd)
+
+	MoveFolderCmd.Flags().String("to-user", "", "The user receiving the folder, the same user by default")
+}
@@ -1,22 +1,46 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/spf13/cobra"
 )
 
 // RegisterCmd represents the register command
 var RegisterCmd = &cobra.Command{
-	Use:   "register [username]",
+	Use:   "register [username] [--password password]",
 	Short: "register a new user",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		username := args[0]
+		flag, _ := cmd.Flags().GetString("password")
+
+		password, err := readPassword(cmd, flag)
+		if err != nil {
+			cmd.PrintErrf("Error: %v\n", err)
+			return
+		}
+
 		user, err := fs.RegisterUser(username)
 		if err != nil {
 			cmd.PrintErrf("Error: %v\n", err)
 			return
 		}
 
+		if password != "" {
+			// the new user sets its own password, and is deleted again when it can't, rather than kept without one
+			_, err = fs.As(username).SetPassword(username, password)
+			if err != nil {
+				deleteErr := fs.As(username).DeleteUser(username, false)
+				if deleteErr != nil {
+					err = errors.Join(err, fmt.Errorf("failed to delete %s without a password: %w", username, deleteErr))
+				}
+				cmd.PrintErrf("Error: %v\n", err)
+				return
+			}
+		}
+
 		cmd.Printf("Add %v successfully.\n", user.Username)
 	},
 }
@@ -33,4 +57,5 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// RegisterCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	RegisterCmd.Flags().String("password", "", "The password of the user to log in with, - to read it from stdin")
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/blackhorseya/iscool-assessment/internal/snapshot"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// RekeyCmd represents the rekey command
+var RekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt the store with a new key or passphrase",
+	Long: `Re-encrypt the store with a new key read from --new-key-file, $` + envNewKey +
+		` or derived from $` + envNewPassphrase + `.
+The store and its snapshots are read with the current key, use --decrypt to store them in plaintext instead.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		newKeyFile, _ := cmd.Flags().GetString("new-key-file")
+		decrypt, _ := cmd.Flags().GetBool("decrypt")
+
+		var next *store.Secret
+		if !decrypt {
+			var err error
+			next, err = loadSecret(newKeyFile, envNewKey, envNewPassphrase)
+			if err != nil {
+				cmd.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		if next == nil && !decrypt {
+			cmd.Printf("Error: %v\n", errors.New("provide a new key or passphrase, or use --decrypt"))
+			return
+		}
+
+		manager, err := snapshot.New(Out, "")
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		err = manager.Rekey(next)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if next == nil {
+			cmd.Printf("Decrypt %v successfully.\n", Out)
+			return
+		}
+
+		cmd.Printf("Rekey %v successfully.\n", Out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(RekeyCmd)
+
+	RekeyCmd.Flags().String("new-key-file", "", "File with the new encryption key")
+	RekeyCmd.Flags().Bool("decrypt", false, "Store the data in plaintext")
+}
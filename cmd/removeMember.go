@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RemoveMemberCmd represents the removeMember command
+var RemoveMemberCmd = &cobra.Command{
+	Use:   "remove-member [username] [group] [member]",
+	Short: "Remove a user from a group the user belongs to, the last member can't be removed",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		name := args[1]
+		member := args[2]
+
+		_, err := fs.RemoveMember(username, name, member)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Remove %v from %v successfully.\n", member, name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(RemoveMemberCmd)
+}
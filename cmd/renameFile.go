@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// RenameFileCmd represents the renameFile command
+var RenameFileCmd = &cobra.Command{
+	Use:   "rename-file [username] [foldername] [filename] [new-filename]",
+	Short: "Rename a file in its folder",
+	Args:  cobra.ExactArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		filename := args[2]
+		newFilename := args[3]
+
+		file, err := fs.RenameFile(username, foldername, filename, newFilename, conflictOf(cmd))
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+		if file == nil {
+			cmd.Printf("Skip %v, the %v has already existed.\n", filename, newFilename)
+			return
+		}
+
+		cmd.Printf("Rename %v to %v in %v/%v successfully.\n", filename, newFilename, username, foldername)
+	},
+}
+
+// conflictOf is used to read what cmd does with an existing destination file from its flags.
+func conflictOf(cmd *cobra.Command) model.Conflict {
+	if overwrite, _ := cmd.Flags().GetBool("overwrite"); overwrite {
+		return model.ConflictOverwrite
+	}
+	if noClobber, _ := cmd.Flags().GetBool("no-clobber"); noClobber {
+		return model.ConflictSkip
+	}
+
+	return model.ConflictFail
+}
+
+// addConflictFlags is used to add the flags choosing what cmd does with an existing destination file.
+func addConflictFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("overwrite", false, "Replace the destination file if it exists")
+	cmd.Flags().Bool("no-clobber", false, "Keep the destination file if it exists, without failing")
+	cmd.MarkFlagsMutuallyExclusive("overwrite", "no-clobber")
+}
+
+func init() {
+	rootCmd.AddCommand(RenameFileCmd)
+
+	addConflictFlags(RenameFileCmd)
+}
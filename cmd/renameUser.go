@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// RenameUserCmd represents the renameUser command
+var RenameUserCmd = &cobra.Command{
+	Use:   "rename-user [username] [new-username]",
+	Short: "Rename a user along with its folders, the folders shared with it and its groups",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		newUsername := args[1]
+
+		_, err := fs.RenameUser(username, newUsername)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Rename %v to %v successfully, its tokens and sessions were revoked.\n", username, newUsername)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(RenameUserCmd)
+}
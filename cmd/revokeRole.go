@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// RevokeRoleCmd represents the revokeRole command
+var RevokeRoleCmd = &cobra.Command{
+	Use:   "revoke-role [username] [admin|readonly]",
+	Short: "Take a role back from a user, who is a member again, which only an admin acting with --as can do",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		role, err := model.ParseRole(args[1])
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		_, err = fs.RevokeRole(username, role)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Revoke %v from %v successfully.\n", role, username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(RevokeRoleCmd)
+}
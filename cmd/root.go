@@ -3,15 +3,28 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
+	"github.com/blackhorseya/iscool-assessment/internal/store"
 	"github.com/blackhorseya/iscool-assessment/pkg/utils"
 	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
 	"github.com/spf13/cobra"
 )
 
 var Out string
+var Format string
+var As string
+var Token string
 var fs vfs.VirtualFileSystem
 
+// initErr is why fs couldn't be built for the command run, like a wrong key for an encrypted store.
+var initErr error
+
+// storelessCommands work on the store without fs, so that they still run when it can't be built, like fsck on a
+// document which doesn't load, by themselves or with their subcommands.
+var storelessCommands = []*cobra.Command{FsckCmd, MigrateCmd, RekeyCmd, ShardCmd, SnapshotCmd, LogCmd, RevertCmd}
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "iscool",
@@ -32,11 +45,26 @@ func Execute() {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentPreRunE = RequireInit
 
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&Out, "out", "out/vfs.json", "output file or directory")
+	rootCmd.PersistentFlags().StringVar(
+		&Format,
+		"format",
+		"",
+		"serialization format of the store ("+strings.Join(store.Formats(), ", ")+"), picked by extension by default",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&KeyFile,
+		"key-file",
+		"",
+		"file with the encryption key, otherwise read from $"+envKey+" or derived from $"+envPassphrase,
+	)
+	rootCmd.PersistentFlags().StringVar(&As, "as", "", "user acting for the usernames given, an admin for the others")
+	rootCmd.PersistentFlags().StringVar(&Token, "token", "", "API token to act with, otherwise read from $"+envToken)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -45,21 +73,74 @@ func init() {
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
-	err := initVFS()
+	fs, initErr = nil, nil
+	loggedIn, sessionErr = nil, nil
+
+	secret, err := loadSecret(KeyFile, envKey, envPassphrase)
 	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: Failed to init virtual filesystem: %v\n", err)
+		initErr = fmt.Errorf("failed to load encryption key: %w", err)
+		return
+	}
+	store.SetSecret(secret)
+
+	err = store.SetFormat(Format)
+	if err != nil {
+		initErr = err
+		return
+	}
+
+	err = initVFS()
+	if err != nil {
+		fs, initErr = nil, fmt.Errorf("failed to init virtual filesystem: %w", err)
 		return
 	}
 }
 
+// RequireInit is used to stop cmd, exiting with an error, when fs couldn't be built for it, unless it works on the
+// store without fs.
+func RequireInit(cmd *cobra.Command, args []string) error {
+	if initErr == nil {
+		return nil
+	}
+
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if slices.Contains(storelessCommands, parent) {
+			return nil
+		}
+	}
+
+	cmd.SilenceUsage = true
+	return initErr
+}
+
 func initVFS() (err error) {
 	pathType := utils.CheckPathType(Out)
 	switch {
-	case pathType == "json":
+	case utils.IsDocument(pathType):
 		fs, err = NewVFSWithJSON(Out)
 		if err != nil {
 			return err
 		}
+	case pathType == "sharded":
+		fs, err = NewVFSWithSharded(Out)
+		if err != nil {
+			return err
+		}
+	case pathType == "s3":
+		fs, err = NewVFSWithS3(Out)
+		if err != nil {
+			return err
+		}
+	case pathType == "redis":
+		fs, err = NewVFSWithRedis(Out)
+		if err != nil {
+			return err
+		}
+	case pathType == "git":
+		fs, err = NewVFSWithGit(Out)
+		if err != nil {
+			return err
+		}
 	case pathType == "folder":
 		fs, err = NewVFSWithSystem(Out)
 		if err != nil {
@@ -69,5 +150,10 @@ func initVFS() (err error) {
 		return fmt.Errorf("unsupported path type: %s", pathType)
 	}
 
+	fs, err = authenticate(fs)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
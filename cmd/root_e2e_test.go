@@ -2,10 +2,15 @@ package cmd_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/blackhorseya/iscool-assessment/cmd"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 )
@@ -525,3 +530,1562 @@ func TestDeleteFolderCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestShardCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.ShardCmd)
+
+	testCases := []struct {
+		name    string
+		src     string
+		dst     string
+		wantErr bool
+		wantMsg string
+		mock    func()
+	}{
+		{
+			name:    "shard a single-file store",
+			src:     "out/vfs.json",
+			dst:     "out/vfs.shards",
+			wantErr: false,
+			wantMsg: "Migrate 1 users from out/vfs.json to out/vfs.shards successfully.",
+			mock: func() {
+				_, _ = executeCommand(rootCmd, "register", "test")
+			},
+		},
+		{
+			name:    "shard into a store that already has the user",
+			src:     "out/vfs.json",
+			dst:     "out/vfs.shards",
+			wantErr: false,
+			wantMsg: "Error: the test has already existed",
+			mock: func() {
+				_, _ = executeCommand(rootCmd, "register", "test")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.mock != nil {
+				tc.mock()
+			}
+
+			output, err := executeCommand(rootCmd, "shard", tc.src, tc.dst)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, output, tc.wantMsg)
+		})
+	}
+
+	_ = os.RemoveAll("out/vfs.shards")
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestMigrateCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.MigrateCmd)
+
+	testCases := []struct {
+		name    string
+		dryRun  string
+		wantErr bool
+		wantMsg string
+	}{
+		{
+			name:    "dry run a legacy store",
+			dryRun:  "true",
+			wantErr: false,
+			wantMsg: "Would migrate 1 documents (dry run).",
+		},
+		{
+			name:    "migrate a legacy store",
+			dryRun:  "false",
+			wantErr: false,
+			wantMsg: "Migrate 1 documents successfully.",
+		},
+		{
+			name:    "migrate an up-to-date store",
+			dryRun:  "false",
+			wantErr: false,
+			wantMsg: "Already at schema version",
+		},
+	}
+
+	_ = os.MkdirAll("out", 0755)
+	_ = os.WriteFile("out/vfs.json", []byte(`{"test": {"username": "test", "folders": {}}}`), 0600)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = cmd.MigrateCmd.Flags().Set("dry-run", tc.dryRun)
+			output, err := executeCommand(rootCmd, "migrate")
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, output, tc.wantMsg)
+		})
+	}
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestFsckCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.FsckCmd)
+
+	testCases := []struct {
+		name    string
+		repair  string
+		wantErr bool
+		wantMsg string
+	}{
+		{
+			name:    "report problems",
+			repair:  "false",
+			wantErr: false,
+			wantMsg: "Found 1 problems, repaired 0.",
+		},
+		{
+			name:    "repair problems",
+			repair:  "true",
+			wantErr: false,
+			wantMsg: "Found 1 problems, repaired 1.",
+		},
+		{
+			name:    "repaired store is clean",
+			repair:  "false",
+			wantErr: false,
+			wantMsg: "No problems found.",
+		},
+	}
+
+	_ = os.MkdirAll("out", 0755)
+	_ = os.WriteFile("out/vfs.json", []byte(`{"test": {"username": "other", "folders": {}}}`), 0600)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_ = cmd.FsckCmd.Flags().Set("repair", tc.repair)
+			output, err := executeCommand(rootCmd, "fsck")
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, output, tc.wantMsg)
+		})
+	}
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestSnapshotCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.SnapshotCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+
+	output, err := executeCommand(rootCmd, "snapshot", "create", "--label", "empty")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "(users: 1, folders: 0, files: 0) successfully.")
+
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+
+	output, err = executeCommand(rootCmd, "snapshot", "create")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "(users: 1, folders: 1, files: 0) successfully.")
+
+	output, err = executeCommand(rootCmd, "snapshot", "list")
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "empty")
+
+	first := strings.Fields(lines[0])[0]
+	second := strings.Fields(lines[1])[0]
+
+	output, err = executeCommand(rootCmd, "snapshot", "diff", first, second)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "+ test/folder1")
+
+	output, err = executeCommand(rootCmd, "snapshot", "restore", first)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Restore snapshot "+first+" successfully.")
+
+	output, err = executeCommand(rootCmd, "snapshot", "restore", "missing")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the snapshot missing doesn't exist")
+
+	_ = os.RemoveAll("out/vfs.json.snapshots")
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestRekeyCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.RekeyCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+
+	output, err := executeCommand(rootCmd, "rekey")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: provide a new key or passphrase, or use --decrypt")
+
+	t.Setenv("ISCOOL_NEW_PASSPHRASE", "secret")
+	output, err = executeCommand(rootCmd, "rekey")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Rekey out/vfs.json successfully.")
+
+	data, _ := os.ReadFile("out/vfs.json")
+	assert.NotContains(t, string(data), `"username"`)
+
+	t.Setenv("ISCOOL_PASSPHRASE", "secret")
+	output, err = executeCommand(rootCmd, "rekey", "--decrypt")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Decrypt out/vfs.json successfully.")
+
+	data, _ = os.ReadFile("out/vfs.json")
+	assert.Contains(t, string(data), `"username": "test"`)
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestInitFailure(t *testing.T) {
+	rootCmd := &cobra.Command{PersistentPreRunE: cmd.RequireInit, SilenceErrors: true}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.RekeyCmd)
+	rootCmd.AddCommand(cmd.FsckCmd)
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	t.Setenv("ISCOOL_NEW_PASSPHRASE", "secret")
+	output, err := executeCommand(rootCmd, "rekey", "--decrypt=false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Rekey out/vfs.json successfully.")
+
+	// the commands needing the file system fail rather than run without it
+	output, err = executeCommand(rootCmd, "register", "other")
+	assert.ErrorContains(t, err, "failed to init virtual filesystem")
+	_, err = executeCommand(rootCmd, "list-folders", "test", "--sort-name", "asc", "--sort-created", "")
+	assert.ErrorContains(t, err, "failed to init virtual filesystem")
+
+	t.Setenv("ISCOOL_PASSPHRASE", "wrong")
+	_, err = executeCommand(rootCmd, "register", "other")
+	assert.ErrorContains(t, err, "failed to init virtual filesystem")
+
+	// the ones working on the store without it still run, reporting what they find
+	output, err = executeCommand(rootCmd, "fsck", "--repair=false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error:")
+
+	t.Setenv("ISCOOL_PASSPHRASE", "secret")
+	output, err = executeCommand(rootCmd, "register", "other")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Add other successfully.")
+}
+
+func TestLogAndRevertCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.LogCmd)
+	rootCmd.AddCommand(cmd.RevertCmd)
+
+	output, err := executeCommand(rootCmd, "log")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the out/vfs.json isn't a git-backed store")
+
+	cmd.Out = "out/vfs.git"
+	defer func() {
+		cmd.Out = "out/vfs.json"
+		_ = os.RemoveAll("out/vfs.git")
+	}()
+
+	output, err = executeCommand(rootCmd, "log")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Warning: There are no commits.")
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+
+	output, err = executeCommand(rootCmd, "log")
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "test: create-folder folder1")
+	assert.Contains(t, lines[1], "test: register")
+
+	hash := strings.Fields(lines[0])[0]
+	output, err = executeCommand(rootCmd, "revert", hash)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Revert "+hash+" as ")
+
+	output, err = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Create folder1 successfully.")
+
+	output, err = executeCommand(rootCmd, "log")
+	assert.NoError(t, err)
+	assert.Contains(t, output, `Revert "test: create-folder folder1"`)
+}
+
+func TestS3Store(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.RenameFolderCmd)
+
+	cmd.Out = buckettest.Start(t)
+	defer func() {
+		cmd.Out = "out/vfs.json"
+	}()
+
+	output, err := executeCommand(rootCmd, "register", "test")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Add test successfully.")
+
+	output, err = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Create folder1 successfully.")
+
+	output, err = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the folder1 has already existed")
+
+	output, err = executeCommand(rootCmd, "rename-folder", "test", "folder1", "folder2")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Rename folder1 to folder2 successfully.")
+}
+
+func TestDirectoryStore(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+
+	cmd.Out = filepath.Join(t.TempDir(), "vfs")
+	defer func() {
+		cmd.Out = "out/vfs.json"
+	}()
+
+	// the directory store keeps no passwords, so the user isn't kept without one
+	output, err := executeCommand(rootCmd, "register", "test", "--password", "secret")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: this isn't supported by the directory store")
+	_, err = os.Stat(filepath.Join(cmd.Out, "test"))
+	assert.True(t, os.IsNotExist(err), "the user without a password is deleted")
+
+	output, err = executeCommand(rootCmd, "register", "test", "--password", "")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Add test successfully.")
+}
+
+func TestRedisStore(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.RenameFolderCmd)
+
+	cmd.Out = "redis://" + miniredis.RunT(t).Addr()
+	defer func() {
+		cmd.Out = "out/vfs.json"
+	}()
+
+	output, err := executeCommand(rootCmd, "register", "test")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Add test successfully.")
+
+	output, err = executeCommand(rootCmd, "create-folder", "test", "folder1", "test description")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Create folder1 successfully.")
+
+	output, err = executeCommand(rootCmd, "rename-folder", "test", "folder1", "folder2")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Rename folder1 to folder2 successfully.")
+}
+
+func TestImportCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.ImportCmd)
+
+	dir := t.TempDir()
+	_ = os.MkdirAll(dir+"/Photos 2024", 0755)
+	_ = os.WriteFile(dir+"/Photos 2024/beach.jpg", []byte("content"), 0644)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+
+	output, err := executeCommand(rootCmd, "import", "test", dir, "--dry-run")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "create-file Photos_2024/beach_jpg (from Photos 2024/beach.jpg)")
+	assert.Contains(t, output, "Would import 1 folders and 1 files (overwritten: 0, skipped: 0, renamed: 0, sanitized: 2).")
+
+	output, err = executeCommand(rootCmd, "import", "test", dir, "--dry-run=false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Import 1 folders and 1 files")
+
+	output, err = executeCommand(rootCmd, "import", "test", dir, "--on-conflict", "rename", "--map", " =-,.=-")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Import 1 folders and 1 files (overwritten: 0, skipped: 0, renamed: 0, sanitized: 2).")
+
+	output, err = executeCommand(rootCmd, "import", "test", dir, "--on-conflict", "merge")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: unsupported conflict policy: merge")
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestExportCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.DeleteFolderCmd)
+	rootCmd.AddCommand(cmd.ExportCmd)
+	rootCmd.AddCommand(cmd.ImportCmd)
+
+	to := t.TempDir() + "/out.tar.gz"
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1", "description")
+	_, _ = executeCommand(rootCmd, "create-file", "test", "folder1", "file1", "description")
+
+	output, err := executeCommand(rootCmd, "export", "test", "--to", to)
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Export 1 folders and 1 files to "+to+".")
+
+	output, err = executeCommand(rootCmd, "export", "test", "folder2", "--to", t.TempDir())
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the folder2 doesn't exist")
+
+	_, _ = executeCommand(rootCmd, "delete-folder", "test", "folder1")
+
+	output, err = executeCommand(rootCmd, "import", "test", to, "--on-conflict", "skip", "--dry-run=false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Import 1 folders and 1 files (overwritten: 0, skipped: 0, renamed: 0, sanitized: 0).")
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestBatchCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.PersistentFlags().StringVar(&cmd.Out, "out", "out/vfs.json", "")
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.BatchCmd)
+
+	script := filepath.Join(t.TempDir(), "ops.txt")
+	_ = os.WriteFile(script, []byte(strings.Join([]string{
+		"# provisioning",
+		"register batcher",
+		`create-folder batcher folder1 "my folder"`,
+		"create-folder batcher folder1",
+		"create-file batcher folder1 file1",
+	}, "\n")), 0644)
+
+	output, err := executeCommand(rootCmd, "batch", "-f", script, "--on-error", "stop", "--output", "text")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "[2] ok: register batcher\nAdd batcher successfully.\n")
+	assert.Contains(t, output, "[4] failed: create-folder batcher folder1\nError: the folder1 has already existed\n")
+	assert.Contains(t, output, "[5] skipped: create-file batcher folder1 file1\n")
+	assert.Contains(t, output, "Run 4 lines: 2 succeeded, 1 failed, 1 skipped.")
+
+	rootCmd.SetIn(strings.NewReader("create-folder batcher folder1\ncreate-file batcher folder1 file1\nfsck\n"))
+	output, err = executeCommand(rootCmd, "batch", "-f", "-", "--on-error", "continue", "--output", "json")
+	assert.NoError(t, err)
+
+	var report struct {
+		Results []struct {
+			Line   int    `json:"line"`
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		} `json:"results"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(output), &report))
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, "the folder1 has already existed", report.Results[0].Error)
+	assert.Equal(t, `unknown command "fsck" for batch`, report.Results[2].Error)
+
+	rootCmd.SetIn(strings.NewReader("create-folder batcher folder2\ncreate-file batcher folder2 file1 --out other.json\n"))
+	output, err = executeCommand(rootCmd, "batch", "-f", "-", "--on-error", "stop", "--output", "text", "--atomic")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the --out flag can't be changed in a batch")
+	assert.Contains(t, output, "Roll back the batch, nothing was changed.")
+
+	rootCmd.SetIn(strings.NewReader("create-folder batcher folder2\ncreate-file batcher folder2 file1\n"))
+	output, err = executeCommand(rootCmd, "batch", "-f", "-", "--on-error", "stop", "--output", "text", "--atomic")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Run 2 lines: 2 succeeded, 0 failed, 0 skipped.")
+
+	rootCmd.SetIn(strings.NewReader("create-folder batcher folder2\n"))
+	output, err = executeCommand(rootCmd, "batch", "-f", "-", "--on-error", "stop", "--output", "text", "--atomic=false")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: the folder2 has already existed")
+
+	rootCmd.SetIn(strings.NewReader("create-folder batcher 'folder3\n"))
+	output, err = executeCommand(rootCmd, "batch")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: line 1: unclosed ' quote")
+
+	_ = os.Remove("out/vfs.json")
+}
+
+func TestRenameMoveAndCopyFileCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.RenameFileCmd)
+	rootCmd.AddCommand(cmd.MoveFileCmd)
+	rootCmd.AddCommand(cmd.CopyFileCmd)
+
+	seed := func() {
+		_, _ = executeCommand(rootCmd, "register", "test")
+		_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1")
+		_, _ = executeCommand(rootCmd, "create-folder", "test", "folder2")
+		_, _ = executeCommand(rootCmd, "create-file", "test", "folder1", "file1", "description")
+		_, _ = executeCommand(rootCmd, "create-file", "test", "folder2", "file1", "description")
+	}
+
+	testCases := []struct {
+		name    string
+		args    []string
+		wantErr bool
+		wantMsg string
+	}{
+		{
+			name:    "rename file",
+			args:    []string{"rename-file", "test", "folder1", "file1", "file2"},
+			wantMsg: "Rename file1 to file2 in test/folder1 successfully.",
+		},
+		{
+			name:    "rename missing file",
+			args:    []string{"rename-file", "test", "folder1", "missing", "file2"},
+			wantMsg: "Error: the missing doesn't exist",
+		},
+		{
+			name:    "move file to a folder holding it",
+			args:    []string{"move-file", "test", "folder1", "file1", "folder2"},
+			wantMsg: "Error: the file1 has already existed",
+		},
+		{
+			name:    "move file without clobbering",
+			args:    []string{"move-file", "test", "folder1", "file1", "folder2", "--no-clobber"},
+			wantMsg: "Skip file1, the file1 has already existed in test/folder2.",
+		},
+		{
+			name:    "move file overwriting",
+			args:    []string{"move-file", "test", "folder1", "file1", "folder2", "--overwrite"},
+			wantMsg: "Move file1 from test/folder1 to test/folder2 successfully.",
+		},
+		{
+			name:    "copy file under a new name",
+			args:    []string{"copy-file", "test", "folder1", "file1", "folder2", "file2"},
+			wantMsg: "Copy test/folder1/file1 to test/folder2/file2 successfully.",
+		},
+		{
+			name:    "copy file with conflicting flags",
+			args:    []string{"copy-file", "test", "folder1", "file1", "folder2", "--overwrite", "--no-clobber"},
+			wantErr: true,
+			wantMsg: "none of the others can be",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seed()
+			// the flags keep their values between the cases
+			for _, command := range []*cobra.Command{cmd.RenameFileCmd, cmd.MoveFileCmd, cmd.CopyFileCmd} {
+				for _, name := range []string{"overwrite", "no-clobber"} {
+					flag := command.Flags().Lookup(name)
+					_ = flag.Value.Set(flag.DefValue)
+					flag.Changed = false
+				}
+			}
+
+			output, err := executeCommand(rootCmd, tc.args...)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, output, tc.wantMsg)
+
+			// Clean up
+			_ = os.Remove("out/vfs.json")
+		})
+	}
+}
+
+func TestCopyAndMoveFolderCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.ListFilesCmd)
+	rootCmd.AddCommand(cmd.CopyFolderCmd)
+	rootCmd.AddCommand(cmd.MoveFolderCmd)
+
+	seed := func() {
+		_, _ = executeCommand(rootCmd, "register", "test")
+		_, _ = executeCommand(rootCmd, "register", "other")
+		_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1")
+		_, _ = executeCommand(rootCmd, "create-file", "test", "folder1", "file1", "description")
+	}
+
+	testCases := []struct {
+		name      string
+		args      []string
+		wantMsg   string
+		wantFiles []string
+	}{
+		{
+			name:      "copy folder to a new name",
+			args:      []string{"copy-folder", "test", "folder1", "folder2", "--to-user", ""},
+			wantMsg:   "Copy test/folder1 to test/folder2 successfully.",
+			wantFiles: []string{"list-files", "test", "folder2"},
+		},
+		{
+			name:    "copy folder onto itself",
+			args:    []string{"copy-folder", "test", "folder1", "--to-user", ""},
+			wantMsg: "Error: the folder1 has already existed",
+		},
+		{
+			name:      "move folder to another user",
+			args:      []string{"move-folder", "test", "folder1", "--to-user", "other"},
+			wantMsg:   "Move test/folder1 to other/folder1 successfully.",
+			wantFiles: []string{"list-files", "other", "folder1"},
+		},
+		{
+			name:    "move folder to a missing user",
+			args:    []string{"move-folder", "test", "folder1", "--to-user", "missing"},
+			wantMsg: "Error: the missing doesn't exist",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			seed()
+
+			output, err := executeCommand(rootCmd, tc.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, tc.wantMsg)
+
+			if tc.wantFiles != nil {
+				output, err = executeCommand(rootCmd, append(tc.wantFiles, "--sort-name", "asc", "--sort-created", "")...)
+				assert.NoError(t, err)
+				assert.Contains(t, output, "file1")
+			}
+
+			// Clean up
+			_ = os.Remove("out/vfs.json")
+		})
+	}
+}
+
+func TestShareFolderCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.ListFilesCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.ShareFolderCmd)
+	rootCmd.AddCommand(cmd.UnshareFolderCmd)
+	rootCmd.AddCommand(cmd.ListSharesCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "register", "other")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1")
+	_, _ = executeCommand(rootCmd, "create-file", "test", "folder1", "file1", "description")
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	// the steps run in order, each on the shares left by the previous ones
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "list files of a folder not shared",
+			args:    []string{"list-files", "other", "test/folder1", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "Error: permission denied: other can't read test/folder1",
+		},
+		{
+			name:    "share folder with an unsupported access",
+			args:    []string{"share-folder", "test", "folder1", "other", "--access", "admin"},
+			wantMsg: "Error: unsupported access: admin, use read or write",
+		},
+		{
+			name:    "share folder to read",
+			args:    []string{"share-folder", "test", "folder1", "other", "--access", "read"},
+			wantMsg: "Share test/folder1 with other to read successfully.",
+		},
+		{
+			name:    "list files of a folder shared to read",
+			args:    []string{"list-files", "other", "test/folder1", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "file1",
+		},
+		{
+			name:    "create file in a folder shared to read",
+			args:    []string{"create-file", "other", "test/folder1", "file2"},
+			wantMsg: "Error: permission denied: other can't write test/folder1",
+		},
+		{
+			name:    "list folders shared with me",
+			args:    []string{"list-folders", "other", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "Shared with me:\ntest/folder1 read",
+		},
+		{
+			name:    "list shares",
+			args:    []string{"list-shares", "test"},
+			wantMsg: "test/folder1 other read",
+		},
+		{
+			name:    "unshare folder",
+			args:    []string{"unshare-folder", "test", "folder1", "other"},
+			wantMsg: "Unshare test/folder1 with other successfully.",
+		},
+		{
+			name:    "unshare folder not shared",
+			args:    []string{"unshare-folder", "test", "folder1", "other"},
+			wantMsg: "Error: the folder1 isn't shared with other",
+		},
+		{
+			name:    "list shares after unshare",
+			args:    []string{"list-shares", "other"},
+			wantMsg: "Warning: The other doesn't have any shares.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestGroupCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.ListFilesCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.CreateGroupCmd)
+	rootCmd.AddCommand(cmd.AddMemberCmd)
+	rootCmd.AddCommand(cmd.RemoveMemberCmd)
+	rootCmd.AddCommand(cmd.ListGroupsCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "register", "other")
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+		_ = os.Remove("out/vfs.groups.json")
+	}()
+
+	// the steps run in order, each on the groups left by the previous ones
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "create group",
+			args:    []string{"create-group", "test", "team"},
+			wantMsg: "Create team successfully.",
+		},
+		{
+			name:    "create group existed",
+			args:    []string{"create-group", "other", "team"},
+			wantMsg: "Error: the team has already existed",
+		},
+		{
+			name:    "add member by a non member",
+			args:    []string{"add-member", "other", "team", "other"},
+			wantMsg: "Error: permission denied: other isn't a member of team",
+		},
+		{
+			name:    "add member",
+			args:    []string{"add-member", "test", "team", "other"},
+			wantMsg: "Add other to team successfully.",
+		},
+		{
+			name:    "create folder of the group",
+			args:    []string{"create-folder", "other", "group:team/folder1"},
+			wantMsg: "Create folder1 successfully.",
+		},
+		{
+			name:    "create file in a folder of the group",
+			args:    []string{"create-file", "test", "group:team/folder1", "file1"},
+			wantMsg: "Create file1 in test/group:team/folder1 successfully.",
+		},
+		{
+			name:    "list folders of the group",
+			args:    []string{"list-folders", "group:team", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1  ",
+		},
+		{
+			name:    "list groups",
+			args:    []string{"list-groups", "other"},
+			wantMsg: "team other,test ",
+		},
+		{
+			name:    "remove member",
+			args:    []string{"remove-member", "other", "team", "test"},
+			wantMsg: "Remove test from team successfully.",
+		},
+		{
+			name:    "list files by a removed member",
+			args:    []string{"list-files", "test", "group:team/folder1", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "Error: permission denied: test isn't a member of team",
+		},
+		{
+			name:    "remove the last member",
+			args:    []string{"remove-member", "other", "team", "other"},
+			wantMsg: "Error: the other is the last member of team",
+		},
+		{
+			name:    "list groups of a user without any",
+			args:    []string{"list-groups", "test"},
+			wantMsg: "Warning: The test doesn't belong to any groups.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestRoleCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.GrantRoleCmd)
+	rootCmd.AddCommand(cmd.RevokeRoleCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "root")
+	_, _ = executeCommand(rootCmd, "register", "test")
+	defer func() {
+		cmd.As = ""
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	// the steps run in order, each with the roles left by the previous ones and acting as the user as
+	steps := []struct {
+		name    string
+		as      string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "grant an unsupported role",
+			args:    []string{"grant-role", "root", "owner"},
+			wantMsg: "Error: unsupported role: owner, use admin, member or readonly",
+		},
+		{
+			name:    "grant admin without an actor",
+			args:    []string{"grant-role", "root", "admin"},
+			wantMsg: "Grant admin to root successfully.",
+		},
+		{
+			name:    "grant role as a member",
+			as:      "test",
+			args:    []string{"grant-role", "test", "admin"},
+			wantMsg: "Error: permission denied: only the admins change the roles, test isn't one",
+		},
+		{
+			name:    "act as another user as a member",
+			as:      "test",
+			args:    []string{"create-folder", "root", "folder1"},
+			wantMsg: "Error: permission denied: test can't act as root",
+		},
+		{
+			name:    "grant readonly as an admin",
+			as:      "root",
+			args:    []string{"grant-role", "test", "readonly"},
+			wantMsg: "Grant readonly to test successfully.",
+		},
+		{
+			name:    "grant role without an actor once there is an admin",
+			args:    []string{"grant-role", "test", "member"},
+			wantMsg: "Error: permission denied: only the admins change the roles, and no admin is acting",
+		},
+		{
+			name:    "grant admin without an actor once there is an admin",
+			args:    []string{"grant-role", "test", "admin"},
+			wantMsg: "Error: permission denied: only the admins change the roles, and no admin is acting",
+		},
+		{
+			name:    "create folder as a readonly user",
+			args:    []string{"create-folder", "test", "folder1"},
+			wantMsg: "Error: permission denied: test is readonly",
+		},
+		{
+			name:    "create folder for a readonly user as an admin",
+			as:      "root",
+			args:    []string{"create-folder", "test", "folder1"},
+			wantMsg: "Create folder1 successfully.",
+		},
+		{
+			name:    "list folders as a readonly user",
+			args:    []string{"list-folders", "test", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1",
+		},
+		{
+			name:    "revoke a role the user doesn't have",
+			as:      "root",
+			args:    []string{"revoke-role", "test", "admin"},
+			wantMsg: "Error: the test isn't admin",
+		},
+		{
+			name:    "revoke readonly",
+			as:      "root",
+			args:    []string{"revoke-role", "test", "readonly"},
+			wantMsg: "Revoke readonly from test successfully.",
+		},
+		{
+			name:    "create folder as a member again",
+			args:    []string{"create-folder", "test", "folder2"},
+			wantMsg: "Create folder2 successfully.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			cmd.As = step.as
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestUserLifecycleCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.DeleteUserCmd)
+	rootCmd.AddCommand(cmd.RenameUserCmd)
+	rootCmd.AddCommand(cmd.ListUsersCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "register", "other")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1")
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	// the steps run in order, each on the users left by the previous ones
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "list users",
+			args:    []string{"list-users", "--sort-name", "asc", "--offset", "0", "--limit", "0"},
+			wantMsg: "other member\ntest member\n",
+		},
+		{
+			name:    "list a page of users",
+			args:    []string{"list-users", "--sort-name", "desc", "--offset", "1", "--limit", "1"},
+			wantMsg: "other member\n",
+		},
+		{
+			name:    "list users with an invalid order",
+			args:    []string{"list-users", "--sort-name", "up"},
+			wantMsg: "Error: Invalid value for --sort-name. Use 'asc' or 'desc'",
+		},
+		{
+			name:    "rename user to an existing user",
+			args:    []string{"rename-user", "test", "other"},
+			wantMsg: "Error: the other has already existed",
+		},
+		{
+			name:    "rename user",
+			args:    []string{"rename-user", "test", "renamed"},
+			wantMsg: "Rename test to renamed successfully, its tokens and sessions were revoked.",
+		},
+		{
+			name:    "list folders of a renamed user",
+			args:    []string{"list-folders", "renamed", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1",
+		},
+		{
+			name:    "delete user with folders",
+			args:    []string{"delete-user", "renamed", "--cascade=false"},
+			wantMsg: "Error: the renamed still has folders, delete them first or cascade",
+		},
+		{
+			name:    "delete user with its folders",
+			args:    []string{"delete-user", "renamed", "--cascade"},
+			wantMsg: "Delete renamed successfully.",
+		},
+		{
+			name:    "delete user without folders",
+			args:    []string{"delete-user", "other", "--cascade=false"},
+			wantMsg: "Delete other successfully.",
+		},
+		{
+			name:    "list users of an empty store",
+			args:    []string{"list-users", "--sort-name", "asc", "--offset", "0", "--limit", "0"},
+			wantMsg: "Warning: There aren't any users.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestAuthCmd(t *testing.T) {
+	t.Setenv("ISCOOL_CONFIG_DIR", t.TempDir())
+
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.GrantRoleCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.LoginCmd)
+	rootCmd.AddCommand(cmd.LogoutCmd)
+	rootCmd.AddCommand(cmd.SettingsCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "other", "--password", "")
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+		_ = os.Remove("out/vfs.settings.json")
+	}()
+
+	// the steps run in order, each with the session and the settings left by the previous ones
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "register with a password",
+			args:    []string{"register", "test", "--password", "secret"},
+			wantMsg: "Add test successfully.",
+		},
+		{
+			name:    "grant admin before any session",
+			args:    []string{"grant-role", "test", "admin"},
+			wantMsg: "Grant admin to test successfully.",
+		},
+		{
+			name:    "leave out the username without a session",
+			args:    []string{"create-folder", "folder1"},
+			wantMsg: "Error: the username is missing, give it or login first",
+		},
+		{
+			name:    "login with a wrong password",
+			args:    []string{"login", "test", "--password", "wrong", "--ttl", "1h"},
+			wantMsg: "Error: unauthenticated: wrong password for test",
+		},
+		{
+			name:    "login a user without a password",
+			args:    []string{"login", "other", "--password", "secret", "--ttl", "1h"},
+			wantMsg: "Error: unauthenticated: other has no password",
+		},
+		{
+			name:    "login",
+			args:    []string{"login", "test", "--password", "secret", "--ttl", "1h"},
+			wantMsg: "Login test successfully, the session expires at",
+		},
+		{
+			name:    "leave out the username with a session",
+			args:    []string{"create-folder", "folder1"},
+			wantMsg: "Create folder1 successfully.",
+		},
+		{
+			name:    "list folders of the user logged in",
+			args:    []string{"list-folders", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1",
+		},
+		{
+			name:    "require auth",
+			args:    []string{"settings", "--require-auth=true"},
+			wantMsg: "require-auth: true",
+		},
+		{
+			name:    "change with a session while requiring auth",
+			args:    []string{"create-folder", "other", "folder2"},
+			wantMsg: "Create folder2 successfully.",
+		},
+		{
+			name:    "logout",
+			args:    []string{"logout"},
+			wantMsg: "Logout test successfully.",
+		},
+		{
+			name:    "logout without a session",
+			args:    []string{"logout"},
+			wantMsg: "Warning: Nobody is logged in.",
+		},
+		{
+			name:    "change without a session while requiring auth",
+			args:    []string{"create-folder", "test", "folder3"},
+			wantMsg: "Error: unauthenticated: create-folder needs the session of the acting user, login first",
+		},
+		{
+			name:    "list without a session while requiring auth",
+			args:    []string{"list-folders", "test", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1",
+		},
+		{
+			name:    "change the settings without a session",
+			args:    []string{"settings", "--require-auth=false"},
+			wantMsg: "Error: unauthenticated: the settings of a store requiring auth are changed by a logged in admin",
+		},
+		{
+			name:    "login again",
+			args:    []string{"login", "test", "--password", "secret", "--ttl", "1h"},
+			wantMsg: "Login test successfully",
+		},
+		{
+			name:    "stop requiring auth as a logged in admin",
+			args:    []string{"settings", "--require-auth=false"},
+			wantMsg: "require-auth: false",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestStoreAuthCmd(t *testing.T) {
+	t.Setenv("ISCOOL_CONFIG_DIR", t.TempDir())
+
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.GrantRoleCmd)
+	rootCmd.AddCommand(cmd.LoginCmd)
+	rootCmd.AddCommand(cmd.SettingsCmd)
+	rootCmd.AddCommand(cmd.FsckCmd)
+	rootCmd.AddCommand(cmd.MigrateCmd)
+	rootCmd.AddCommand(cmd.RekeyCmd)
+	rootCmd.AddCommand(cmd.SnapshotCmd)
+	rootCmd.AddCommand(cmd.RevertCmd)
+	rootCmd.AddCommand(cmd.ImportCmd)
+	rootCmd.AddCommand(cmd.ShardCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test", "--password", "secret")
+	_, _ = executeCommand(rootCmd, "grant-role", "test", "admin")
+	_, _ = executeCommand(rootCmd, "settings", "--require-auth=true")
+	_ = os.MkdirAll("out/import/photos", 0755)
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+		_ = os.Remove("out/vfs.settings.json")
+		_ = os.RemoveAll("out/import")
+	}()
+
+	// the steps run in order, on a store requiring auth
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "check without a session",
+			args:    []string{"fsck", "--repair=false"},
+			wantMsg: "No problems found.",
+		},
+		{
+			name:    "repair without a session",
+			args:    []string{"fsck", "--repair=true"},
+			wantMsg: "Error: unauthenticated: fsck changes the whole store, which needs the session of an admin",
+		},
+		{
+			name:    "migrate without a session",
+			args:    []string{"migrate", "--dry-run=false"},
+			wantMsg: "Error: unauthenticated: migrate changes the whole store",
+		},
+		{
+			name:    "preview a migration without a session",
+			args:    []string{"migrate", "--dry-run=true"},
+			wantMsg: "Already at schema version",
+		},
+		{
+			name:    "rekey without a session",
+			args:    []string{"rekey", "--decrypt"},
+			wantMsg: "Error: unauthenticated: rekey changes the whole store",
+		},
+		{
+			name:    "restore a snapshot without a session",
+			args:    []string{"snapshot", "restore", "20240101T000000Z"},
+			wantMsg: "Error: unauthenticated: restore changes the whole store",
+		},
+		{
+			name:    "revert without a session",
+			args:    []string{"revert", "HEAD"},
+			wantMsg: "Error: unauthenticated: revert changes the whole store",
+		},
+		{
+			name:    "import without a session",
+			args:    []string{"import", "test", "out/import", "--dry-run=false"},
+			wantMsg: "unauthenticated: create-folder needs the session of the acting user, login first",
+		},
+		{
+			name:    "shard into a store requiring auth",
+			args:    []string{"shard", "out/vfs.json", "out/vfs.json"},
+			wantMsg: "Error: unauthenticated: shard changes the whole store",
+		},
+		{
+			name:    "login",
+			args:    []string{"login", "test", "--password", "secret", "--ttl", "1h"},
+			wantMsg: "Login test successfully",
+		},
+		{
+			name:    "repair as a logged in admin",
+			args:    []string{"fsck", "--repair=true"},
+			wantMsg: "No problems found.",
+		},
+		{
+			name:    "stop requiring auth",
+			args:    []string{"settings", "--require-auth=false"},
+			wantMsg: "require-auth: false",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestTokenCmd(t *testing.T) {
+	t.Setenv("ISCOOL_CONFIG_DIR", t.TempDir())
+
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.TokenCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test", "--password", "")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "folder1")
+	defer func() {
+		cmd.Token = ""
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	output, err := executeCommand(rootCmd, "token", "create", "test", "--scope", "folders:read", "--expires", "1w")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: invalid --expires: 1w, use days like 30d or a duration like 12h")
+
+	output, err = executeCommand(rootCmd, "token", "create", "test", "--scope", "tokens:write", "--expires", "30d")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Error: unsupported scope: tokens:write")
+
+	output, err = executeCommand(rootCmd, "token", "create", "test", "--scope", "folders:read", "--expires", "30d")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "successfully, keep it since it isn't shown again:")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	token := lines[len(lines)-1]
+	id := strings.Split(token, ".")[1]
+
+	output, err = executeCommand(rootCmd, "token", "list", "test")
+	assert.NoError(t, err)
+	assert.Contains(t, output, id+" folders:read ")
+
+	// the steps run in order with the token, or without it when it's empty
+	steps := []struct {
+		name    string
+		token   string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "list folders with a token",
+			token:   token,
+			args:    []string{"list-folders", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "folder1",
+		},
+		{
+			name:    "create folder without the scope",
+			token:   token,
+			args:    []string{"create-folder", "folder2"},
+			wantMsg: "Error: permission denied: create-folder needs the folders:write scope",
+		},
+		{
+			name:    "list tokens with a token",
+			token:   token,
+			args:    []string{"token", "list"},
+			wantMsg: "Error: permission denied: token list can't be run with a token",
+		},
+		{
+			name:    "revoke a missing token",
+			args:    []string{"token", "revoke", "test", "missing"},
+			wantMsg: "Error: the token missing doesn't exist",
+		},
+		{
+			name:    "revoke token",
+			args:    []string{"token", "revoke", "test", id},
+			wantMsg: "Revoke token " + id + " successfully.",
+		},
+		{
+			name:    "list folders with a revoked token",
+			token:   token,
+			args:    []string{"list-folders", "test", "--sort-name", "asc", "--sort-created", ""},
+			wantMsg: "Error: unauthenticated: the token isn't valid",
+		},
+		{
+			name:    "list tokens of a user without any",
+			args:    []string{"token", "list", "test"},
+			wantMsg: "Warning: The test doesn't have any tokens.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			cmd.Token = step.token
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestQuotaCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.GrantRoleCmd)
+	rootCmd.AddCommand(cmd.SetQuotaCmd)
+	rootCmd.AddCommand(cmd.UsageCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "root")
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "grant-role", "root", "admin")
+	defer func() {
+		cmd.As = ""
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	// the steps run in order, acting as the user as
+	steps := []struct {
+		name    string
+		as      string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "set quota as a member",
+			as:      "test",
+			args:    []string{"set-quota", "test", "--max-folders", "1"},
+			wantMsg: "Error: permission denied: only the admins change the quotas, test isn't one",
+		},
+		{
+			name:    "set quota without an actor",
+			args:    []string{"set-quota", "test", "--max-folders", "100"},
+			wantMsg: "Error: permission denied: only the admins change the quotas, and no admin is acting",
+		},
+		{
+			name:    "set a negative quota",
+			as:      "root",
+			args:    []string{"set-quota", "test", "--max-folders", "-1"},
+			wantMsg: "Error: the limits of a quota can't be negative",
+		},
+		{
+			name:    "set quota as an admin",
+			as:      "root",
+			args:    []string{"set-quota", "test", "--max-folders", "1"},
+			wantMsg: "Set the quota of test successfully.",
+		},
+		{
+			name:    "set another limit keeping the others",
+			as:      "root",
+			args:    []string{"set-quota", "test", "--max-folders", "1", "--max-folder-files", "1"},
+			wantMsg: "Set the quota of test successfully.",
+		},
+		{
+			name:    "create folder within the quota",
+			args:    []string{"create-folder", "test", "folder1"},
+			wantMsg: "Create folder1 successfully.",
+		},
+		{
+			name:    "create folder beyond the quota",
+			args:    []string{"create-folder", "test", "folder2"},
+			wantMsg: "Error: quota exceeded: test can't have more than 1 folders",
+		},
+		{
+			name:    "create file within the quota",
+			args:    []string{"create-file", "test", "folder1", "file1"},
+			wantMsg: "Create file1 in test/folder1 successfully.",
+		},
+		{
+			name:    "create file beyond the quota",
+			args:    []string{"create-file", "test", "folder1", "file2"},
+			wantMsg: "Error: quota exceeded: test can't have more than 1 files in a folder",
+		},
+		{
+			name:    "usage of a user",
+			args:    []string{"usage", "test"},
+			wantMsg: "folders: 1/1\nfiles: 1/unlimited\nfiles per folder: 1/1 in folder1\nbytes: 0/unlimited\n",
+		},
+		{
+			name:    "usage of another user as a member",
+			as:      "test",
+			args:    []string{"usage", "root"},
+			wantMsg: "Error: permission denied: test can't act as root",
+		},
+		{
+			name:    "remove the limits",
+			as:      "root",
+			args:    []string{"set-quota", "test", "--max-folders", "0", "--max-folder-files", "0"},
+			wantMsg: "Set the quota of test successfully.",
+		},
+		{
+			name:    "create folder without a quota",
+			args:    []string{"create-folder", "test", "folder2"},
+			wantMsg: "Create folder2 successfully.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			cmd.As = step.as
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
+
+func TestTagCmd(t *testing.T) {
+	rootCmd := &cobra.Command{}
+	rootCmd.AddCommand(cmd.RegisterCmd)
+	rootCmd.AddCommand(cmd.CreateFolderCmd)
+	rootCmd.AddCommand(cmd.CreateFileCmd)
+	rootCmd.AddCommand(cmd.ListFoldersCmd)
+	rootCmd.AddCommand(cmd.ListFilesCmd)
+	rootCmd.AddCommand(cmd.TagCmd)
+	rootCmd.AddCommand(cmd.UntagCmd)
+	rootCmd.AddCommand(cmd.TagsCmd)
+
+	_, _ = executeCommand(rootCmd, "register", "test")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "photos")
+	_, _ = executeCommand(rootCmd, "create-folder", "test", "music")
+	_, _ = executeCommand(rootCmd, "create-file", "test", "photos", "beach")
+	_, _ = executeCommand(rootCmd, "create-file", "test", "photos", "forest")
+	defer func() {
+		_ = os.Remove("out/vfs.json")
+	}()
+
+	// the steps run in order
+	steps := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "tags without any",
+			args:    []string{"tags", "test"},
+			wantMsg: "Warning: The test doesn't have any tags.",
+		},
+		{
+			name:    "tag folder",
+			args:    []string{"tag", "test", "photos", "travel,family", "--file", ""},
+			wantMsg: "Tag photos with family,travel successfully.",
+		},
+		{
+			name:    "tag folder with an invalid tag",
+			args:    []string{"tag", "test", "music", "bad tag", "--file", ""},
+			wantMsg: "Error: invalid tag \"bad tag\"",
+		},
+		{
+			name:    "tag file",
+			args:    []string{"tag", "test", "photos", "travel", "--file", "beach"},
+			wantMsg: "Tag beach in photos with travel successfully.",
+		},
+		{
+			name:    "list folders by tag",
+			args:    []string{"list-folders", "test", "--sort-name", "asc", "--sort-created", "", "--tag", "family"},
+			wantMsg: "photos",
+		},
+		{
+			name:    "list folders by unused tag",
+			args:    []string{"list-folders", "test", "--sort-name", "asc", "--sort-created", "", "--tag", "work"},
+			wantMsg: "Warning: The test doesn't have any folders tagged work.",
+		},
+		{
+			name:    "list files by tag",
+			args:    []string{"list-files", "test", "photos", "--sort-name", "asc", "--sort-created", "", "--tag", "travel"},
+			wantMsg: "beach",
+		},
+		{
+			name:    "list files by unused tag",
+			args:    []string{"list-files", "test", "photos", "--sort-name", "asc", "--sort-created", "", "--tag", "work"},
+			wantMsg: "Warning: The folder doesn't have any files tagged work.",
+		},
+		{
+			name:    "tags with counts",
+			args:    []string{"tags", "test"},
+			wantMsg: "family folders:1 files:0\ntravel folders:1 files:1\n",
+		},
+		{
+			name:    "untag a missing tag",
+			args:    []string{"untag", "test", "music", "travel", "--file", ""},
+			wantMsg: "Error: the music isn't tagged travel",
+		},
+		{
+			name:    "untag file",
+			args:    []string{"untag", "test", "photos", "travel", "--file", "beach"},
+			wantMsg: "Untag travel from beach in photos successfully.",
+		},
+		{
+			name:    "untag folder",
+			args:    []string{"untag", "test", "photos", "family,travel", "--file", ""},
+			wantMsg: "Untag family,travel from photos successfully.",
+		},
+		{
+			name:    "tags after untagging",
+			args:    []string{"tags", "test"},
+			wantMsg: "Warning: The test doesn't have any tags.",
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			output, err := executeCommand(rootCmd, step.args...)
+			assert.NoError(t, err)
+			assert.Contains(t, output, step.wantMsg)
+		})
+	}
+}
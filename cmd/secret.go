@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// Environment variables holding the encryption secrets.
+const (
+	envKey           = "ISCOOL_KEY"
+	envPassphrase    = "ISCOOL_PASSPHRASE"
+	envNewKey        = "ISCOOL_NEW_KEY"
+	envNewPassphrase = "ISCOOL_NEW_PASSPHRASE"
+)
+
+// KeyFile is the file holding the key the store is encrypted with.
+var KeyFile string
+
+// loadSecret is used to read a secret from keyFile, or else from the keyEnv or passphraseEnv variables.
+// It returns nil when none of them is set.
+func loadSecret(keyFile, keyEnv, passphraseEnv string) (*store.Secret, error) {
+	switch {
+	case keyFile != "":
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file: %w", err)
+		}
+
+		return store.ParseKey(data)
+	case os.Getenv(keyEnv) != "":
+		return store.ParseKey([]byte(os.Getenv(keyEnv)))
+	case os.Getenv(passphraseEnv) != "":
+		return store.Passphrase(os.Getenv(passphraseEnv))
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/history"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	vfsI "github.com/blackhorseya/iscool-assessment/internal/vfs"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+// Environment variables of the sessions and the API tokens.
+const (
+	envConfigDir = "ISCOOL_CONFIG_DIR"
+	envToken     = "ISCOOL_TOKEN"
+)
+
+// sessionCommands are the commands whose first arg, the username, can be left out for the user logged in, by
+// the number of args they need with it. The optional args are left out along with it.
+var sessionCommands = map[*cobra.Command]int{
+	RenameUserCmd:    2,
+	SetPasswordCmd:   1,
+	UsageCmd:         1,
+	CreateFolderCmd:  2,
+	DeleteFolderCmd:  2,
+	ListFoldersCmd:   1,
+	RenameFolderCmd:  3,
+	CopyFolderCmd:    2,
+	MoveFolderCmd:    2,
+	ShareFolderCmd:   3,
+	UnshareFolderCmd: 3,
+	ListSharesCmd:    1,
+	CreateGroupCmd:   2,
+	AddMemberCmd:     3,
+	RemoveMemberCmd:  3,
+	ListGroupsCmd:    1,
+	CreateFileCmd:    3,
+	DeleteFileCmd:    3,
+	ListFilesCmd:     2,
+	RenameFileCmd:    4,
+	MoveFileCmd:      4,
+	CopyFileCmd:      4,
+	TagCmd:           3,
+	UntagCmd:         3,
+	TagsCmd:          1,
+	ExportCmd:        1,
+	ImportCmd:        2,
+	TokenCreateCmd:   1,
+	TokenListCmd:     1,
+	TokenRevokeCmd:   2,
+}
+
+// storeCommands are the commands changing a store as a whole rather than through fs, out of reach of the guard
+// of authenticate, with the settings of the stores a run of them changes, none when it only reads.
+var storeCommands = map[*cobra.Command]func(cmd *cobra.Command, args []string) []string{
+	SnapshotRestoreCmd: atOut,
+	RekeyCmd:           atOut,
+	FsckCmd: func(cmd *cobra.Command, args []string) []string {
+		if repair, _ := cmd.Flags().GetBool("repair"); !repair {
+			return nil
+		}
+
+		return atOut(cmd, args)
+	},
+	MigrateCmd: func(cmd *cobra.Command, args []string) []string {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			return nil
+		}
+
+		return atOut(cmd, args)
+	},
+	RevertCmd: atOut,
+	ShardCmd: func(_ *cobra.Command, args []string) []string {
+		return []string{store.SettingsPath(args[1])}
+	},
+}
+
+// loggedIn is the user of the valid session to the store at Out, or of the API token, and sessionErr why the
+// session isn't valid.
+var (
+	loggedIn   *model.User
+	sessionErr error
+)
+
+// session is a login to a store, kept in the config directory.
+type session struct {
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// authenticate is used to make next act as the user of --as, or else the one logged in. When the store requires
+// auth, the changes are refused unless the acting user is the one of a valid session. An API token replaces the
+// session, and next only acts as its user within its scopes.
+func authenticate(next vfs.VirtualFileSystem) (vfs.VirtualFileSystem, error) {
+	loggedIn, sessionErr = nil, nil
+
+	token := Token
+	if token == "" {
+		token = os.Getenv(envToken)
+	}
+	if token != "" {
+		return authenticateToken(next, token), nil
+	}
+
+	current, err := loadSession()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		loggedIn, sessionErr = next.Authenticate(current.Username, current.Token)
+	}
+
+	actor := As
+	if actor == "" && loggedIn != nil {
+		actor = loggedIn.Username
+	}
+	next = next.As(actor)
+
+	settings, err := store.ReadSettings(settingsPath())
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.RequireAuth && (loggedIn == nil || loggedIn.Username != actor) {
+		reason := sessionErr
+		next = vfsI.NewGuarded(next, func(command string, write bool) error {
+			if !write {
+				return nil
+			}
+			if reason != nil {
+				return fmt.Errorf("%s needs a valid session: %w", command, reason)
+			}
+
+			return fmt.Errorf(
+				"%w: %s needs the session of the acting user, login first",
+				vfs.ErrUnauthenticated,
+				command,
+			)
+		})
+	}
+
+	return next, nil
+}
+
+// authenticateToken is used to make next act as the user of token within its scopes. Nothing is allowed with a
+// token which isn't valid, rather than falling back to acting without it.
+func authenticateToken(next vfs.VirtualFileSystem, token string) vfs.VirtualFileSystem {
+	user, item, err := next.AuthenticateToken(token)
+	if err == nil && As != "" && As != user.Username {
+		err = fmt.Errorf("%w: a token acts as %s, not %s", vfs.ErrPermissionDenied, user.Username, As)
+	}
+	if err != nil {
+		return vfsI.NewGuarded(next, func(string, bool) error {
+			return err
+		})
+	}
+
+	loggedIn = user
+	return vfsI.NewWithScopes(next.As(user.Username), item.Scopes)
+}
+
+// withSession is used to let command leave out its first arg, the username, when it's given need-1 args, need
+// being the number it takes without its optional ones. The username logged in is put back before running.
+func withSession(command *cobra.Command, need int) {
+	args, run := command.Args, command.Run
+
+	command.Args = func(cmd *cobra.Command, given []string) error {
+		if len(given) == need-1 {
+			return nil
+		}
+
+		return args(cmd, given)
+	}
+	command.Run = func(cmd *cobra.Command, given []string) {
+		if len(given) == need-1 {
+			if loggedIn == nil {
+				err := errors.New("the username is missing, give it or login first")
+				if sessionErr != nil {
+					err = fmt.Errorf("the username is missing: %w", sessionErr)
+				}
+
+				cmd.Printf("Error: %v\n", err)
+				return
+			}
+
+			given = append([]string{loggedIn.Username}, given...)
+		}
+
+		run(cmd, given)
+	}
+}
+
+// withStoreAuth is used to refuse the runs of command changing a store requiring auth, the ones with the
+// settings given by changes, unless a logged in admin runs them.
+func withStoreAuth(command *cobra.Command, changes func(cmd *cobra.Command, args []string) []string) {
+	run := command.Run
+
+	command.Run = func(cmd *cobra.Command, args []string) {
+		for _, path := range changes(cmd, args) {
+			err := checkStoreAuth(cmd.Name(), path)
+			if err != nil {
+				cmd.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		run(cmd, args)
+	}
+}
+
+// checkStoreAuth is used to check that a logged in admin runs command when the store with the settings at path
+// requires auth.
+func checkStoreAuth(command, path string) error {
+	settings, err := store.ReadSettings(path)
+	if err != nil {
+		return err
+	}
+	if !settings.RequireAuth {
+		return nil
+	}
+
+	if sessionErr != nil {
+		return fmt.Errorf("%s needs a valid session: %w", command, sessionErr)
+	}
+	if loggedIn == nil || (As != "" && As != loggedIn.Username) || !loggedIn.Is(model.RoleAdmin) {
+		return fmt.Errorf(
+			"%w: %s changes the whole store, which needs the session of an admin, login first",
+			vfs.ErrUnauthenticated,
+			command,
+		)
+	}
+
+	return nil
+}
+
+// atOut returns the settings of the store at Out, changed by every run of a command.
+func atOut(*cobra.Command, []string) []string {
+	return []string{settingsPath()}
+}
+
+// settingsPath returns the path of the settings of the store at Out, empty for the stores without any.
+// A git-backed store keeps them next to its document.
+func settingsPath() string {
+	if utils.CheckPathType(Out) == "git" {
+		return store.SettingsPath(filepath.Join(Out, history.DocumentName))
+	}
+
+	return store.SettingsPath(Out)
+}
+
+// sessionsPath returns the path of the file of the sessions, in $ISCOOL_CONFIG_DIR or else in the config
+// directory of the user, like ~/.config/iscool/sessions.json.
+func sessionsPath() (string, error) {
+	dir := os.Getenv(envConfigDir)
+	if dir == "" {
+		config, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the config directory, set $%s: %w", envConfigDir, err)
+		}
+		dir = filepath.Join(config, "iscool")
+	}
+
+	return filepath.Join(dir, "sessions.json"), nil
+}
+
+// storeKey returns the key of the session to the store at Out, which is its absolute path unless it's a URL.
+func storeKey() string {
+	if strings.Contains(Out, "://") {
+		return Out
+	}
+
+	abs, err := filepath.Abs(Out)
+	if err != nil {
+		return Out
+	}
+
+	return abs
+}
+
+// readSessions is used to read the sessions by store. A missing file has none.
+func readSessions() (sessions map[string]*session, err error) {
+	path, err := sessionsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions = make(map[string]*session)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sessions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	err = json.Unmarshal(data, &sessions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// writeSessions is used to write the sessions by store, readable by the user only since they hold the tokens.
+func writeSessions(sessions map[string]*session) error {
+	path, err := sessionsPath()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sessions: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write sessions: %w", err)
+	}
+
+	return nil
+}
+
+// loadSession is used to read the session to the store at Out, nil when there is none.
+func loadSession() (*session, error) {
+	sessions, err := readSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions[storeKey()], nil
+}
+
+// saveSession is used to keep current as the session to the store at Out, replacing the previous one.
+func saveSession(current *session) error {
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+
+	sessions[storeKey()] = current
+
+	return writeSessions(sessions)
+}
+
+// removeSession is used to forget the session to the store at Out.
+func removeSession() error {
+	sessions, err := readSessions()
+	if err != nil {
+		return err
+	}
+
+	delete(sessions, storeKey())
+
+	return writeSessions(sessions)
+}
+
+// readPassword returns password, or the first line of the input of cmd when it's -.
+func readPassword(cmd *cobra.Command, password string) (string, error) {
+	if password != "-" {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read the password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func init() {
+	for command, need := range sessionCommands {
+		withSession(command, need)
+	}
+	for command, changes := range storeCommands {
+		withStoreAuth(command, changes)
+	}
+}
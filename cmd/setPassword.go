@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// SetPasswordCmd represents the setPassword command
+var SetPasswordCmd = &cobra.Command{
+	Use:   "set-password [username] [--password password]",
+	Short: "Set the password a user logs in with",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		flag, _ := cmd.Flags().GetString("password")
+
+		password, err := readPassword(cmd, flag)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		_, err = fs.SetPassword(username, password)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Set the password of %v successfully.\n", username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(SetPasswordCmd)
+
+	SetPasswordCmd.Flags().String("password", "-", "The password, - to read it from the first line of stdin")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// SetQuotaCmd represents the setQuota command
+var SetQuotaCmd = &cobra.Command{
+	Use:   "set-quota [username] [--max-folders n] [--max-folder-files n] [--max-files n] [--max-bytes n]",
+	Short: "Change the limits given of a user or group:<name>, 0 removing one, which only an admin can do",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		usage, err := fs.Usage(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		// the limits not given are kept
+		quota := model.Quota{}
+		if usage.Quota != nil {
+			quota = *usage.Quota
+		}
+		if cmd.Flags().Changed("max-folders") {
+			quota.MaxFolders, _ = cmd.Flags().GetInt("max-folders")
+		}
+		if cmd.Flags().Changed("max-folder-files") {
+			quota.MaxFolderFiles, _ = cmd.Flags().GetInt("max-folder-files")
+		}
+		if cmd.Flags().Changed("max-files") {
+			quota.MaxFiles, _ = cmd.Flags().GetInt("max-files")
+		}
+		if cmd.Flags().Changed("max-bytes") {
+			quota.MaxBytes, _ = cmd.Flags().GetInt64("max-bytes")
+		}
+
+		_, err = fs.SetQuota(username, &quota)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Set the quota of %v successfully.\n", username)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(SetQuotaCmd)
+
+	SetQuotaCmd.Flags().Int("max-folders", 0, "The most folders the user can have, 0 for no limit")
+	SetQuotaCmd.Flags().Int("max-folder-files", 0, "The most files each folder of the user can have, 0 for no limit")
+	SetQuotaCmd.Flags().Int("max-files", 0, "The most files the user can have, 0 for no limit")
+	SetQuotaCmd.Flags().Int64("max-bytes", 0, "The most bytes the files of the user can take, 0 for no limit")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"github.com/spf13/cobra"
+)
+
+// SettingsCmd represents the settings command
+var SettingsCmd = &cobra.Command{
+	Use:   "settings [--require-auth=true|false]",
+	Short: "Show the settings of the store, or change the ones given",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path := settingsPath()
+		settings, err := store.ReadSettings(path)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if cmd.Flags().Changed("require-auth") {
+			if path == "" {
+				cmd.Printf("Error: the %s can't keep settings, only the document and sharded stores do\n", Out)
+				return
+			}
+
+			// otherwise anyone could turn the auth off
+			if settings.RequireAuth && (loggedIn == nil || !loggedIn.Is(model.RoleAdmin)) {
+				cmd.Printf("Error: %v\n", fmt.Errorf(
+					"%w: the settings of a store requiring auth are changed by a logged in admin",
+					vfs.ErrUnauthenticated,
+				))
+				return
+			}
+
+			settings.RequireAuth, _ = cmd.Flags().GetBool("require-auth")
+			err = store.WriteSettings(path, settings)
+			if err != nil {
+				cmd.Printf("Error: %v\n", err)
+				return
+			}
+		}
+
+		cmd.Printf("require-auth: %v\n", settings.RequireAuth)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(SettingsCmd)
+
+	SettingsCmd.Flags().Bool("require-auth", false, "Demand a valid session for every change to the store")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// ShardCmd represents the shard command
+var ShardCmd = &cobra.Command{
+	Use:   "shard [json-file] [shards-dir]",
+	Short: "Migrate a single-file store into a sharded store",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		src := args[0]
+		dst := args[1]
+
+		count, err := store.Shard(src, dst)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Migrate %d users from %v to %v successfully.\n", count, src, dst)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ShardCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// ShareFolderCmd represents the shareFolder command
+var ShareFolderCmd = &cobra.Command{
+	Use:   "share-folder [username] [foldername] [grantee] [--access read|write]",
+	Short: "Share a folder with another user, who can then work on its files as owner/foldername",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		grantee := args[2]
+		value, _ := cmd.Flags().GetString("access")
+
+		access, err := model.ParseAccess(value)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		err = fs.ShareFolder(username, foldername, grantee, access)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Share %v/%v with %v to %v successfully.\n", username, foldername, grantee, access)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ShareFolderCmd)
+
+	ShareFolderCmd.Flags().String("access", string(model.AccessRead), "The access granted, read or write")
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"github.com/blackhorseya/iscool-assessment/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// SnapshotCmd represents the snapshot command
+var SnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Manage point-in-time snapshots of the store",
+}
+
+// SnapshotCreateCmd represents the snapshot create command
+var SnapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Archive the current content of the store",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		label, _ := cmd.Flags().GetString("label")
+
+		manager, err := newSnapshotManager(cmd)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		manifest, err := manager.Create(label)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf(
+			"Create snapshot %v (users: %d, folders: %d, files: %d) successfully.\n",
+			manifest.ID,
+			manifest.Users,
+			manifest.Folders,
+			manifest.Files,
+		)
+	},
+}
+
+// SnapshotListCmd represents the snapshot list command
+var SnapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots from the oldest to the newest",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, err := newSnapshotManager(cmd)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		manifests, err := manager.List()
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(manifests) == 0 {
+			cmd.Println("Warning: There are no snapshots.")
+			return
+		}
+
+		// List snapshots with the following fields: [id] [created at] [users] [folders] [files] [label]
+		for _, manifest := range manifests {
+			createdAt := manifest.CreatedAt.Format("2006-01-02 15:04:05")
+			cmd.Printf(
+				"%s %s %d %d %d %s\n",
+				manifest.ID,
+				createdAt,
+				manifest.Users,
+				manifest.Folders,
+				manifest.Files,
+				manifest.Label,
+			)
+		}
+	},
+}
+
+// SnapshotRestoreCmd represents the snapshot restore command
+var SnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore [id]",
+	Short: "Replace the content of the store with a snapshot",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id := args[0]
+
+		manager, err := newSnapshotManager(cmd)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		_, err = manager.Restore(id)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Restore snapshot %v successfully.\n", id)
+	},
+}
+
+// SnapshotDiffCmd represents the snapshot diff command
+var SnapshotDiffCmd = &cobra.Command{
+	Use:   "diff [id] [id]",
+	Short: "Show the changes between two snapshots",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		manager, err := newSnapshotManager(cmd)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		changes, err := manager.Diff(args[0], args[1])
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(changes) == 0 {
+			cmd.Println("No changes.")
+			return
+		}
+
+		for _, change := range changes {
+			cmd.Println(change.String())
+		}
+	},
+}
+
+func newSnapshotManager(cmd *cobra.Command) (*snapshot.Manager, error) {
+	dir, _ := cmd.Flags().GetString("dir")
+	return snapshot.New(Out, dir)
+}
+
+func init() {
+	rootCmd.AddCommand(SnapshotCmd)
+	SnapshotCmd.AddCommand(SnapshotCreateCmd)
+	SnapshotCmd.AddCommand(SnapshotListCmd)
+	SnapshotCmd.AddCommand(SnapshotRestoreCmd)
+	SnapshotCmd.AddCommand(SnapshotDiffCmd)
+
+	SnapshotCmd.PersistentFlags().String("dir", "", "Directory of the snapshots (default is the store path + .snapshots)")
+	SnapshotCreateCmd.Flags().String("label", "", "Label describing the snapshot")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// TagCmd represents the tag command
+var TagCmd = &cobra.Command{
+	Use:   "tag [username] [foldername] [tags] [--file filename]",
+	Short: "Tag a folder, or a file of it, with comma-separated tags like work,urgent",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		runTag(cmd, args, false)
+	},
+}
+
+// runTag is used to add the tags of args to a folder or its file of --file, or remove them when removed is set.
+func runTag(cmd *cobra.Command, args []string, removed bool) {
+	username := args[0]
+	foldername := args[1]
+	filename, _ := cmd.Flags().GetString("file")
+
+	tags, err := model.ParseTags(args[2])
+	if err != nil {
+		cmd.Printf("Error: %v\n", err)
+		return
+	}
+
+	switch {
+	case filename == "" && !removed:
+		_, err = fs.TagFolder(username, foldername, tags)
+	case filename == "":
+		_, err = fs.UntagFolder(username, foldername, tags)
+	case !removed:
+		_, err = fs.TagFile(username, foldername, filename, tags)
+	default:
+		_, err = fs.UntagFile(username, foldername, filename, tags)
+	}
+	if err != nil {
+		cmd.Printf("Error: %v\n", err)
+		return
+	}
+
+	target := foldername
+	if filename != "" {
+		target = filename + " in " + foldername
+	}
+	if removed {
+		cmd.Printf("Untag %v from %v successfully.\n", strings.Join(tags, ","), target)
+	} else {
+		cmd.Printf("Tag %v with %v successfully.\n", target, strings.Join(tags, ","))
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(TagCmd)
+
+	TagCmd.Flags().String("file", "", "Tag this file of the folder rather than the folder")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// TagsCmd represents the tags command
+var TagsCmd = &cobra.Command{
+	Use:   "tags [username]",
+	Short: "List the tags of a user with the number of folders and files tagged with each",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		items, err := fs.Tags(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(items) == 0 {
+			cmd.Printf("Warning: The %s doesn't have any tags.\n", username)
+			return
+		}
+
+		// List the tags in the following format: [tag] folders:[folders] files:[files]
+		for _, item := range items {
+			cmd.Printf("%s folders:%d files:%d\n", item.Tag, item.Folders, item.Files)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(TagsCmd)
+}
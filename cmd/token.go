@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/spf13/cobra"
+)
+
+// TokenCmd represents the token command
+var TokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage the API tokens of the users, which the commands act with through --token",
+}
+
+// TokenCreateCmd represents the token create command
+var TokenCreateCmd = &cobra.Command{
+	Use:   "create [username] --scope scopes [--expires 30d]",
+	Short: "Create an API token granting the scopes, like folders:read,files:write",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		scope, _ := cmd.Flags().GetString("scope")
+		expires, _ := cmd.Flags().GetString("expires")
+
+		scopes, err := model.ParseScopes(scope)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		ttl, err := parseExpires(expires)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		token, item, err := fs.CreateToken(username, scopes, ttl)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Create token %v successfully, keep it since it isn't shown again:\n%v\n", item.ID, token)
+	},
+}
+
+// TokenListCmd represents the token list command
+var TokenListCmd = &cobra.Command{
+	Use:   "list [username]",
+	Short: "List the API tokens of a user from the oldest to the newest",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		tokens, err := fs.ListTokens(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		if len(tokens) == 0 {
+			cmd.Printf("Warning: The %v doesn't have any tokens.\n", username)
+			return
+		}
+
+		// List tokens with the following fields: [id] [scopes] [created at] [expires at]
+		now := time.Now()
+		for _, token := range tokens {
+			scopes := make([]string, 0, len(token.Scopes))
+			for _, scope := range token.Scopes {
+				scopes = append(scopes, string(scope))
+			}
+
+			line := fmt.Sprintf(
+				"%s %s %s %s",
+				token.ID,
+				strings.Join(scopes, ","),
+				token.CreatedAt.Format("2006-01-02 15:04:05"),
+				token.ExpiresAt.Format("2006-01-02 15:04:05"),
+			)
+			if token.Expired(now) {
+				line += " expired"
+			}
+			cmd.Println(line)
+		}
+	},
+}
+
+// TokenRevokeCmd represents the token revoke command
+var TokenRevokeCmd = &cobra.Command{
+	Use:   "revoke [username] [id]",
+	Short: "Revoke an API token of a user",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		id := args[1]
+
+		err := fs.RevokeToken(username, id)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Revoke token %v successfully.\n", id)
+	},
+}
+
+// parseExpires is used to parse how long a token lasts, a number of days like 30d or a duration like 12h.
+func parseExpires(s string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(s, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+
+	ttl, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --expires: %s, use days like 30d or a duration like 12h", s)
+	}
+
+	return ttl, nil
+}
+
+func init() {
+	rootCmd.AddCommand(TokenCmd)
+	TokenCmd.AddCommand(TokenCreateCmd)
+	TokenCmd.AddCommand(TokenListCmd)
+	TokenCmd.AddCommand(TokenRevokeCmd)
+
+	TokenCreateCmd.Flags().String("scope", "", "The comma-separated scopes, like folders:read,files:write")
+	TokenCreateCmd.Flags().String("expires", "30d", "How long the token lasts, in days like 30d or like 12h")
+	_ = TokenCreateCmd.MarkFlagRequired("scope")
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// UnshareFolderCmd represents the unshareFolder command
+var UnshareFolderCmd = &cobra.Command{
+	Use:   "unshare-folder [username] [foldername] [grantee]",
+	Short: "Stop sharing a folder with another user",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+		foldername := args[1]
+		grantee := args[2]
+
+		err := fs.UnshareFolder(username, foldername, grantee)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		cmd.Printf("Unshare %v/%v with %v successfully.\n", username, foldername, grantee)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(UnshareFolderCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// UntagCmd represents the untag command
+var UntagCmd = &cobra.Command{
+	Use:   "untag [username] [foldername] [tags] [--file filename]",
+	Short: "Remove comma-separated tags from a folder, or a file of it",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		runTag(cmd, args, true)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(UntagCmd)
+
+	UntagCmd.Flags().String("file", "", "Untag this file of the folder rather than the folder")
+}
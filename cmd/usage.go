@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// UsageCmd represents the usage command
+var UsageCmd = &cobra.Command{
+	Use:   "usage [username]",
+	Short: "Show the folders and files of a user against its quota",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		username := args[0]
+
+		usage, err := fs.Usage(username)
+		if err != nil {
+			cmd.Printf("Error: %v\n", err)
+			return
+		}
+
+		var maxFolders, maxFolderFiles, maxFiles int
+		var maxBytes int64
+		if usage.Quota != nil {
+			maxFolders, maxFolderFiles = usage.Quota.MaxFolders, usage.Quota.MaxFolderFiles
+			maxFiles, maxBytes = usage.Quota.MaxFiles, usage.Quota.MaxBytes
+		}
+
+		// the fullest folder is the one closest to the limit of the files per folder
+		fullest, folderFiles := "", 0
+		for name, files := range usage.FilesByFolder {
+			if files > folderFiles || (files == folderFiles && fullest != "" && name < fullest) {
+				fullest, folderFiles = name, files
+			}
+		}
+
+		cmd.Printf("folders: %v/%v\n", usage.Folders, limit(int64(maxFolders)))
+		cmd.Printf("files: %v/%v\n", usage.Files, limit(int64(maxFiles)))
+		if fullest == "" {
+			cmd.Printf("files per folder: %v/%v\n", folderFiles, limit(int64(maxFolderFiles)))
+		} else {
+			cmd.Printf("files per folder: %v/%v in %v\n", folderFiles, limit(int64(maxFolderFiles)), fullest)
+		}
+		cmd.Printf("bytes: %v/%v\n", usage.Bytes, limit(maxBytes))
+	},
+}
+
+// limit returns the limit n of a quota, unlimited when it's 0.
+func limit(n int64) string {
+	if n == 0 {
+		return "unlimited"
+	}
+
+	return fmt.Sprint(n)
+}
+
+func init() {
+	rootCmd.AddCommand(UsageCmd)
+}
@@ -6,6 +6,7 @@ package cmd
 
 import (
 	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/group"
 	"github.com/blackhorseya/iscool-assessment/internal/repo/user"
 	vfsI "github.com/blackhorseya/iscool-assessment/internal/vfs"
 	vfs2 "github.com/blackhorseya/iscool-assessment/pkg/vfs"
@@ -17,6 +18,7 @@ func NewVFSWithJSON(path string) (vfs2.VirtualFileSystem, error) {
 		vfsI.New,
 		folder.NewJSONFile,
 		user.NewJSONFile,
+		group.NewJSONFile,
 	))
 }
 
@@ -25,5 +27,33 @@ func NewVFSWithSystem(path string) (vfs2.VirtualFileSystem, error) {
 		vfsI.New,
 		folder.NewSystem,
 		user.NewSystem,
+		group.NewUnsupported,
+	))
+}
+
+func NewVFSWithSharded(path string) (vfs2.VirtualFileSystem, error) {
+	panic(wire.Build(
+		vfsI.New,
+		folder.NewSharded,
+		user.NewSharded,
+		group.NewJSONFile,
+	))
+}
+
+func NewVFSWithS3(path string) (vfs2.VirtualFileSystem, error) {
+	panic(wire.Build(
+		vfsI.New,
+		folder.NewS3,
+		user.NewS3,
+		group.NewUnsupported,
+	))
+}
+
+func NewVFSWithRedis(path string) (vfs2.VirtualFileSystem, error) {
+	panic(wire.Build(
+		vfsI.New,
+		folder.NewRedis,
+		user.NewRedis,
+		group.NewUnsupported,
 	))
 }
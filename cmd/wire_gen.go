@@ -8,6 +8,7 @@ package cmd
 
 import (
 	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/group"
 	"github.com/blackhorseya/iscool-assessment/internal/repo/user"
 	vfs3 "github.com/blackhorseya/iscool-assessment/internal/vfs"
 	vfs2 "github.com/blackhorseya/iscool-assessment/pkg/vfs"
@@ -24,7 +25,11 @@ func NewVFSWithJSON(path string) (vfs2.VirtualFileSystem, error) {
 	if err != nil {
 		return nil, err
 	}
-	virtualFileSystem := vfs3.New(userManager, folderManager)
+	groupManager, err := group.NewJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+	virtualFileSystem := vfs3.New(userManager, folderManager, groupManager)
 	return virtualFileSystem, nil
 }
 
@@ -37,6 +42,61 @@ func NewVFSWithSystem(path string) (vfs2.VirtualFileSystem, error) {
 	if err != nil {
 		return nil, err
 	}
-	virtualFileSystem := vfs3.New(userManager, folderManager)
+	groupManager, err := group.NewUnsupported()
+	if err != nil {
+		return nil, err
+	}
+	virtualFileSystem := vfs3.New(userManager, folderManager, groupManager)
+	return virtualFileSystem, nil
+}
+
+func NewVFSWithSharded(path string) (vfs2.VirtualFileSystem, error) {
+	userManager, err := user.NewSharded(path)
+	if err != nil {
+		return nil, err
+	}
+	folderManager, err := folder.NewSharded(path)
+	if err != nil {
+		return nil, err
+	}
+	groupManager, err := group.NewJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+	virtualFileSystem := vfs3.New(userManager, folderManager, groupManager)
+	return virtualFileSystem, nil
+}
+
+func NewVFSWithS3(path string) (vfs2.VirtualFileSystem, error) {
+	userManager, err := user.NewS3(path)
+	if err != nil {
+		return nil, err
+	}
+	folderManager, err := folder.NewS3(path)
+	if err != nil {
+		return nil, err
+	}
+	groupManager, err := group.NewUnsupported()
+	if err != nil {
+		return nil, err
+	}
+	virtualFileSystem := vfs3.New(userManager, folderManager, groupManager)
+	return virtualFileSystem, nil
+}
+
+func NewVFSWithRedis(path string) (vfs2.VirtualFileSystem, error) {
+	userManager, err := user.NewRedis(path)
+	if err != nil {
+		return nil, err
+	}
+	folderManager, err := folder.NewRedis(path)
+	if err != nil {
+		return nil, err
+	}
+	groupManager, err := group.NewUnsupported()
+	if err != nil {
+		return nil, err
+	}
+	virtualFileSystem := vfs3.New(userManager, folderManager, groupManager)
 	return virtualFileSystem, nil
 }
@@ -0,0 +1,15 @@
+package model
+
+// Conflict is what renaming, moving or copying a file does when the destination file already exists.
+type Conflict int
+
+const (
+	// ConflictFail fails without changing anything, it's the default.
+	ConflictFail Conflict = iota
+
+	// ConflictOverwrite replaces the destination file.
+	ConflictOverwrite
+
+	// ConflictSkip keeps both the destination and the source as they are.
+	ConflictSkip
+)
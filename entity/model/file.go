@@ -6,12 +6,15 @@ import (
 
 // File represents a file in the virtual filesystem.
 type File struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	Name        string    `json:"name" yaml:"name" toml:"name"`
+	Description string    `json:"description" yaml:"description" toml:"description"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at" toml:"created_at"`
 
-	Owner  *User   `json:"-"`
-	Folder *Folder `json:"-"`
+	Owner  *User   `json:"-" yaml:"-" toml:"-"`
+	Folder *Folder `json:"-" yaml:"-" toml:"-"`
+
+	// Tags categorize the file, sorted without repeats.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
 }
 
 // NewFile creates a new File.
@@ -6,13 +6,19 @@ import (
 
 // Folder represents a folder with name, description, creation time and a list of files.
 type Folder struct {
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
+	Name        string    `json:"name" yaml:"name" toml:"name"`
+	Description string    `json:"description" yaml:"description" toml:"description"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at" toml:"created_at"`
 
-	Owner   *User              `json:"-"`
-	Files   map[string]*File   `json:"files"`
-	Folders map[string]*Folder `json:"-"`
+	Owner   *User              `json:"-" yaml:"-" toml:"-"`
+	Files   map[string]*File   `json:"files" yaml:"files" toml:"files"`
+	Folders map[string]*Folder `json:"-" yaml:"-" toml:"-"`
+
+	// Shares are the accesses granted to other users, by username.
+	Shares map[string]Access `json:"shares,omitempty" yaml:"shares,omitempty" toml:"shares,omitempty"`
+
+	// Tags categorize the folder, sorted without repeats.
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty" toml:"tags,omitempty"`
 }
 
 // NewFolder creates a new Folder.
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// GroupPrefix marks the name of a group where the owner of folders is expected, like group:<name>.
+const GroupPrefix = "group:"
+
+// Group represents a group of users owning folders together.
+type Group struct {
+	Name      string    `json:"name"`
+	Members   []string  `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Quota limits the folders of the group, which count for the group rather than the members adding them.
+	Quota *Quota `json:"quota,omitempty"`
+}
+
+// NewGroup creates a new Group with creator as its first member.
+func NewGroup(name string, creator *User) (*Group, error) {
+	err := ValidateInput(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Group{
+		Name:      name,
+		Members:   []string{creator.Username},
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// HasMember reports whether username is a member of the group.
+func (g *Group) HasMember(username string) bool {
+	return slices.Contains(g.Members, username)
+}
+
+// Owner returns the user owning the folders of the group, named group:<name>.
+func (g *Group) Owner() *User {
+	return &User{Username: GroupPrefix + g.Name, Quota: g.Quota}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestNewGroup(t *testing.T) {
+	creator := &User{Username: "user1"}
+
+	tests := []struct {
+		name      string
+		groupName string
+		wantErr   bool
+	}{
+		{name: "Valid input", groupName: "team-1", wantErr: false},
+		{name: "Empty name", groupName: "", wantErr: true},
+		{name: "Invalid characters in name", groupName: "team:1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewGroup(tt.groupName, creator)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewGroup() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !got.HasMember(creator.Username) || got.HasMember("user2") {
+				t.Errorf("NewGroup() members = %v, want only %s", got.Members, creator.Username)
+			}
+			if got.Owner().Username != GroupPrefix+tt.groupName {
+				t.Errorf("Owner() = %v, want %s", got.Owner().Username, GroupPrefix+tt.groupName)
+			}
+		})
+	}
+}
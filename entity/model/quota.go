@@ -0,0 +1,43 @@
+package model
+
+import (
+	"errors"
+)
+
+// Quota limits what a user stores, a zero limit being no limit.
+type Quota struct {
+	MaxFolders int `json:"max_folders" yaml:"max_folders" toml:"max_folders"`
+	MaxFiles   int `json:"max_files" yaml:"max_files" toml:"max_files"`
+
+	// MaxFolderFiles limits the files of each folder.
+	MaxFolderFiles int `json:"max_folder_files" yaml:"max_folder_files" toml:"max_folder_files"`
+
+	// MaxBytes limits the content of the files, which they don't have yet, so it's only kept for now.
+	MaxBytes int64 `json:"max_bytes" yaml:"max_bytes" toml:"max_bytes"`
+}
+
+// Validate is used to check that none of the limits are negative.
+func (q *Quota) Validate() error {
+	if q.MaxFolders < 0 || q.MaxFolderFiles < 0 || q.MaxFiles < 0 || q.MaxBytes < 0 {
+		return errors.New("the limits of a quota can't be negative")
+	}
+
+	return nil
+}
+
+// Unlimited reports whether the quota doesn't limit anything.
+func (q *Quota) Unlimited() bool {
+	return q == nil || *q == Quota{}
+}
+
+// Usage is what a user stores, reported against its quota.
+type Usage struct {
+	Username string `json:"username"`
+	Quota    *Quota `json:"quota,omitempty"`
+	Folders  int    `json:"folders"`
+	Files    int    `json:"files"`
+	Bytes    int64  `json:"bytes"`
+
+	// FilesByFolder is the number of files in each folder, by name.
+	FilesByFolder map[string]int `json:"files_by_folder"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestQuota_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quota   Quota
+		wantErr bool
+	}{
+		{name: "no limits", quota: Quota{}},
+		{name: "limits", quota: Quota{MaxFolders: 10, MaxFolderFiles: 5, MaxFiles: 20, MaxBytes: 1024}},
+		{name: "negative folders", quota: Quota{MaxFolders: -1}, wantErr: true},
+		{name: "negative bytes", quota: Quota{MaxBytes: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.quota.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQuota_Unlimited(t *testing.T) {
+	var none *Quota
+	if !none.Unlimited() {
+		t.Errorf("Unlimited() of nil = false, want true")
+	}
+	if !(&Quota{}).Unlimited() {
+		t.Errorf("Unlimited() of no limits = false, want true")
+	}
+	if (&Quota{MaxFiles: 1}).Unlimited() {
+		t.Errorf("Unlimited() of a limit = true, want false")
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"fmt"
+)
+
+// Role is what a user is allowed to do in the store.
+type Role string
+
+const (
+	// RoleAdmin acts on behalf of any user and grants the roles.
+	RoleAdmin Role = "admin"
+
+	// RoleMember works on its own folders and the ones shared with it, the role of the users by default.
+	RoleMember Role = "member"
+
+	// RoleReadonly lists the folders and files it could read as a member, without changing anything.
+	RoleReadonly Role = "readonly"
+)
+
+// ParseRole parses the role s, admin, member or readonly.
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleAdmin, RoleMember, RoleReadonly:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unsupported role: %s, use admin, member or readonly", s)
+	}
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestParseRole(t *testing.T) {
+	for _, s := range []string{"admin", "member", "readonly"} {
+		role, err := ParseRole(s)
+		if err != nil || string(role) != s {
+			t.Errorf("ParseRole(%q) = %v, %v", s, role, err)
+		}
+	}
+
+	if _, err := ParseRole("owner"); err == nil {
+		t.Errorf("ParseRole(owner) error = nil")
+	}
+}
+
+func TestUser_Is(t *testing.T) {
+	user, _ := NewUser("user1")
+	if !user.Is(RoleMember) || user.Is(RoleAdmin) {
+		t.Errorf("Is() of a user without a role, want a member")
+	}
+
+	user.Role = RoleReadonly
+	if !user.Is(RoleReadonly) || user.Is(RoleMember) {
+		t.Errorf("Is() of a readonly user, want readonly")
+	}
+}
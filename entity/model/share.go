@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+)
+
+// Access is the access to a folder granted to a user other than its owner.
+type Access string
+
+const (
+	// AccessRead allows listing the files of the folder.
+	AccessRead Access = "read"
+
+	// AccessWrite allows changing the files of the folder too.
+	AccessWrite Access = "write"
+)
+
+// ParseAccess parses the access s, read or write.
+func ParseAccess(s string) (Access, error) {
+	switch access := Access(s); access {
+	case AccessRead, AccessWrite:
+		return access, nil
+	default:
+		return "", fmt.Errorf("unsupported access: %s, use read or write", s)
+	}
+}
+
+// Allows reports whether a grant of access allows the access need.
+func (access Access) Allows(need Access) bool {
+	return access == AccessWrite || (access == AccessRead && need == AccessRead)
+}
+
+// Share is the access to the folder Folder of Owner granted to Grantee.
+type Share struct {
+	Owner   string `json:"owner"`
+	Folder  string `json:"folder"`
+	Grantee string `json:"grantee"`
+	Access  Access `json:"access"`
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestParseAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Access
+		wantErr bool
+	}{
+		{name: "read", s: "read", want: AccessRead, wantErr: false},
+		{name: "write", s: "write", want: AccessWrite, wantErr: false},
+		{name: "unsupported access", s: "admin", want: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAccess(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAccess() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseAccess() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccess_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		access Access
+		need   Access
+		want   bool
+	}{
+		{name: "read allows read", access: AccessRead, need: AccessRead, want: true},
+		{name: "read denies write", access: AccessRead, need: AccessWrite, want: false},
+		{name: "write allows read", access: AccessWrite, need: AccessRead, want: true},
+		{name: "write allows write", access: AccessWrite, need: AccessWrite, want: true},
+		{name: "no access denies read", access: "", need: AccessRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.access.Allows(tt.need); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
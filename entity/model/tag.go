@@ -0,0 +1,46 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseTags parses the comma-separated tags s, like work,urgent, returning them sorted without repeats.
+func ParseTags(s string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		err := ValidateInput(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return SortTags(tags), nil
+}
+
+// SortTags sorts tags in place and drops the repeated ones.
+func SortTags(tags []string) []string {
+	slices.Sort(tags)
+
+	return slices.Compact(tags)
+}
+
+// HasTag reports whether the folder is tagged tag.
+func (f *Folder) HasTag(tag string) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
+// HasTag reports whether the file is tagged tag.
+func (f *File) HasTag(tag string) bool {
+	return slices.Contains(f.Tags, tag)
+}
+
+// TagCount is the number of the folders and the files of a user tagged Tag.
+type TagCount struct {
+	Tag     string `json:"tag"`
+	Folders int    `json:"folders"`
+	Files   int    `json:"files"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{name: "one tag", s: "work", want: []string{"work"}, wantErr: false},
+		{name: "sorted without repeats", s: "urgent,work,urgent", want: []string{"urgent", "work"}, wantErr: false},
+		{name: "empty tag", s: "work,", want: nil, wantErr: true},
+		{name: "invalid tag", s: "work,to do", want: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTags(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTags() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolder_HasTag(t *testing.T) {
+	folder := &Folder{Name: "photos", Tags: []string{"urgent", "work"}}
+
+	if !folder.HasTag("work") {
+		t.Errorf("HasTag(work) = false, want true")
+	}
+	if folder.HasTag("home") {
+		t.Errorf("HasTag(home) = true, want false")
+	}
+	if (&File{Name: "beach"}).HasTag("work") {
+		t.Errorf("File.HasTag(work) = true, want false")
+	}
+}
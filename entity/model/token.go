@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Scope is what an API token allows, read or write access to a kind of resource like folders:read.
+type Scope string
+
+const (
+	ScopeUsersRead    Scope = "users:read"
+	ScopeUsersWrite   Scope = "users:write"
+	ScopeFoldersRead  Scope = "folders:read"
+	ScopeFoldersWrite Scope = "folders:write"
+	ScopeFilesRead    Scope = "files:read"
+	ScopeFilesWrite   Scope = "files:write"
+	ScopeSharesRead   Scope = "shares:read"
+	ScopeSharesWrite  Scope = "shares:write"
+	ScopeGroupsRead   Scope = "groups:read"
+	ScopeGroupsWrite  Scope = "groups:write"
+)
+
+// Scopes are the scopes a token can grant.
+var Scopes = []Scope{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeFoldersRead,
+	ScopeFoldersWrite,
+	ScopeFilesRead,
+	ScopeFilesWrite,
+	ScopeSharesRead,
+	ScopeSharesWrite,
+	ScopeGroupsRead,
+	ScopeGroupsWrite,
+}
+
+// ParseScopes parses the comma-separated scopes s, like folders:read,files:write, dropping the repeated ones.
+func ParseScopes(s string) (scopes []Scope, err error) {
+	for _, field := range strings.Split(s, ",") {
+		scope := Scope(strings.TrimSpace(field))
+		if !slices.Contains(Scopes, scope) {
+			return nil, fmt.Errorf("unsupported scope: %s, use <users|folders|files|shares|groups>:<read|write>", scope)
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// Grants reports whether the scopes allow need. The write scope of a resource allows reading it too.
+func Grants(scopes []Scope, need Scope) bool {
+	resource, _, _ := strings.Cut(string(need), ":")
+
+	return slices.Contains(scopes, need) || slices.Contains(scopes, Scope(resource+":write"))
+}
+
+// Token is an API token of a user, granting its scopes until it expires. Only the hash of the token is kept.
+type Token struct {
+	ID        string    `json:"id" yaml:"id" toml:"id"`
+	Hash      string    `json:"hash" yaml:"hash" toml:"hash"`
+	Scopes    []Scope   `json:"scopes" yaml:"scopes" toml:"scopes"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at" toml:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at" toml:"expires_at"`
+}
+
+// Expired reports whether the token has expired at now.
+func (t *Token) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []Scope
+		wantErr bool
+	}{
+		{name: "one", s: "folders:read", want: []Scope{ScopeFoldersRead}},
+		{name: "many", s: "folders:read, files:write", want: []Scope{ScopeFoldersRead, ScopeFilesWrite}},
+		{name: "repeated", s: "files:write,files:write", want: []Scope{ScopeFilesWrite}},
+		{name: "unknown resource", s: "tokens:write", wantErr: true},
+		{name: "unknown access", s: "files:delete", wantErr: true},
+		{name: "empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScopes(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseScopes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseScopes() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrants(t *testing.T) {
+	scopes := []Scope{ScopeFoldersRead, ScopeFilesWrite}
+
+	tests := []struct {
+		need Scope
+		want bool
+	}{
+		{need: ScopeFoldersRead, want: true},
+		{need: ScopeFoldersWrite, want: false},
+		{need: ScopeFilesRead, want: true},
+		{need: ScopeFilesWrite, want: true},
+		{need: ScopeSharesRead, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.need), func(t *testing.T) {
+			if got := Grants(scopes, tt.need); got != tt.want {
+				t.Errorf("Grants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToken_Expired(t *testing.T) {
+	now := time.Now()
+	token := &Token{ExpiresAt: now.Add(time.Hour)}
+	if token.Expired(now) || !token.Expired(now.Add(time.Hour)) {
+		t.Errorf("Expired() want false before the expiry time and true from it")
+	}
+}
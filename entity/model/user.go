@@ -1,9 +1,28 @@
 package model
 
+import (
+	"time"
+)
+
 // User represents a user with username and a list of folders.
 type User struct {
-	Username string             `json:"username"`
-	Folders  map[string]*Folder `json:"folders"`
+	Username string             `json:"username" yaml:"username" toml:"username"`
+	Folders  map[string]*Folder `json:"folders" yaml:"folders" toml:"folders"`
+
+	// Role is empty for the members, like the users registered before the roles.
+	Role Role `json:"role,omitempty" yaml:"role,omitempty" toml:"role,omitempty"`
+
+	// PasswordHash is empty for the users without a password, who can't log in.
+	PasswordHash string `json:"password_hash,omitempty" yaml:"password_hash,omitempty" toml:"password_hash,omitempty"`
+
+	// Sessions are the expiry times of the sessions logged in, by the hashes of their tokens.
+	Sessions map[string]time.Time `json:"sessions,omitempty" yaml:"sessions,omitempty" toml:"sessions,omitempty"`
+
+	// Tokens are the API tokens of the user by ID.
+	Tokens map[string]*Token `json:"tokens,omitempty" yaml:"tokens,omitempty" toml:"tokens,omitempty"`
+
+	// Quota is nil for the users without limits.
+	Quota *Quota `json:"quota,omitempty" yaml:"quota,omitempty" toml:"quota,omitempty"`
 }
 
 // NewUser creates a new User.
@@ -18,3 +37,12 @@ func NewUser(username string) (*User, error) {
 		Folders:  make(map[string]*Folder),
 	}, nil
 }
+
+// Is reports whether user has the role.
+func (u *User) Is(role Role) bool {
+	if u.Role == "" {
+		return role == RoleMember
+	}
+
+	return u.Role == role
+}
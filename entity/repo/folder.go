@@ -15,6 +15,46 @@ type FolderManager interface {
 	Delete(ctx context.Context, owner *model.User, foldername string) (err error)
 	Rename(ctx context.Context, owner *model.User, foldername, newFoldername string) (item *model.Folder, err error)
 	List(ctx context.Context, owner *model.User, sortBy string, order string) (items []*model.Folder, err error)
+	Restore(ctx context.Context, owner *model.User, folder *model.Folder) (item *model.Folder, err error)
+
+	// CopyFolder and MoveFolder put the folder foldername of owner with its files at newFoldername of target,
+	// owner or another user. Moved folders keep their files and created times, copies are created now.
+	CopyFolder(
+		ctx context.Context,
+		owner *model.User,
+		foldername string,
+		target *model.User,
+		newFoldername string,
+	) (item *model.Folder, err error)
+	MoveFolder(
+		ctx context.Context,
+		owner *model.User,
+		foldername string,
+		target *model.User,
+		newFoldername string,
+	) (item *model.Folder, err error)
+
+	// Share is used to grant grantee access to the folder foldername of owner, replacing a previous grant,
+	// and Unshare to revoke it.
+	Share(ctx context.Context, owner *model.User, foldername string, grantee *model.User, access model.Access) (err error)
+	Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error)
+
+	// ListShared is used to list the folders of the other users shared with grantee.
+	ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error)
+
+	// TagFolder is used to add tags to the folder foldername of owner, and UntagFolder to remove them,
+	// failing for a tag it doesn't have.
+	TagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (item *model.Folder, err error)
+	UntagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (item *model.Folder, err error)
+
+	// ListTagged is used to list the folders of owner tagged tag, looking them up in the tag index of the backend.
+	ListTagged(
+		ctx context.Context,
+		owner *model.User,
+		tag string,
+		sortBy string,
+		order string,
+	) (items []*model.Folder, err error)
 
 	CreateFile(
 		ctx context.Context,
@@ -35,4 +75,68 @@ type FolderManager interface {
 		sortBy string,
 		order string,
 	) (items []*model.File, err error)
+
+	// TagFile, UntagFile and ListTaggedFiles are the same for the files of folder.
+	TagFile(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		filename string,
+		tags []string,
+	) (item *model.File, err error)
+	UntagFile(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		filename string,
+		tags []string,
+	) (item *model.File, err error)
+	ListTaggedFiles(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		tag string,
+		sortBy string,
+		order string,
+	) (items []*model.File, err error)
+
+	// RenameFile, MoveFile and CopyFile resolve an existing destination file with conflict, returning
+	// a nil item when it's skipped. Renamed and moved files keep their descriptions and created times,
+	// copies keep the descriptions and are created now.
+	RenameFile(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		filename, newFilename string,
+		conflict model.Conflict,
+	) (item *model.File, err error)
+	MoveFile(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		filename string,
+		target *model.Folder,
+		conflict model.Conflict,
+	) (item *model.File, err error)
+	CopyFile(
+		ctx context.Context,
+		owner *model.User,
+		folder *model.Folder,
+		filename string,
+		target *model.Folder,
+		newFilename string,
+		conflict model.Conflict,
+	) (item *model.File, err error)
+
+	// Begin is used to start a transaction staging the changes until it's committed.
+	Begin(ctx context.Context) (tx FolderTx, err error)
+}
+
+// FolderTx is a transaction of a FolderManager. Its changes are only seen through it until Commit applies them
+// all at once, failing if the folders they touch changed since, and Rollback drops them.
+type FolderTx interface {
+	FolderManager
+
+	Commit(ctx context.Context) (err error)
+	Rollback(ctx context.Context) (err error)
 }
@@ -0,0 +1,26 @@
+//go:generate mockgen -destination=./mock_${GOFILE} -package=${GOPACKAGE} -source=${GOFILE}
+
+package repo
+
+import (
+	"context"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// GroupManager defines the interface for the groups of users, which own folders together.
+type GroupManager interface {
+	Create(ctx context.Context, name string, creator *model.User) (item *model.Group, err error)
+	GetByName(ctx context.Context, name string) (item *model.Group, err error)
+	AddMember(ctx context.Context, name string, member *model.User) (item *model.Group, err error)
+	RemoveMember(ctx context.Context, name string, member *model.User) (item *model.Group, err error)
+
+	// SetQuota is used to replace the quota of the group name, nil removing its limits.
+	SetQuota(ctx context.Context, name string, quota *model.Quota) (item *model.Group, err error)
+
+	// List is used to list the groups member belongs to, by name.
+	List(ctx context.Context, member *model.User) (items []*model.Group, err error)
+
+	// Folders returns the manager of the folders of the groups, owned by the Owner of each group.
+	Folders() FolderManager
+}
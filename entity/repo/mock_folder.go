@@ -35,6 +35,51 @@ func (m *MockFolderManager) EXPECT() *MockFolderManagerMockRecorder {
 	return m.recorder
 }
 
+// Begin mocks base method.
+func (m *MockFolderManager) Begin(ctx context.Context) (FolderTx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(FolderTx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockFolderManagerMockRecorder) Begin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockFolderManager)(nil).Begin), ctx)
+}
+
+// CopyFile mocks base method.
+func (m *MockFolderManager) CopyFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, target *model.Folder, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFile", ctx, owner, folder, filename, target, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFile indicates an expected call of CopyFile.
+func (mr *MockFolderManagerMockRecorder) CopyFile(ctx, owner, folder, filename, target, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockFolderManager)(nil).CopyFile), ctx, owner, folder, filename, target, newFilename, conflict)
+}
+
+// CopyFolder mocks base method.
+func (m *MockFolderManager) CopyFolder(ctx context.Context, owner *model.User, foldername string, target *model.User, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFolder", ctx, owner, foldername, target, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFolder indicates an expected call of CopyFolder.
+func (mr *MockFolderManagerMockRecorder) CopyFolder(ctx, owner, foldername, target, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFolder", reflect.TypeOf((*MockFolderManager)(nil).CopyFolder), ctx, owner, foldername, target, newFoldername)
+}
+
 // Create mocks base method.
 func (m *MockFolderManager) Create(ctx context.Context, owner *model.User, foldername, description string) (*model.Folder, error) {
 	m.ctrl.T.Helper()
@@ -138,6 +183,81 @@ func (mr *MockFolderManagerMockRecorder) ListFiles(ctx, owner, folder, sortBy, o
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFolderManager)(nil).ListFiles), ctx, owner, folder, sortBy, order)
 }
 
+// ListShared mocks base method.
+func (m *MockFolderManager) ListShared(ctx context.Context, grantee *model.User) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShared", ctx, grantee)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShared indicates an expected call of ListShared.
+func (mr *MockFolderManagerMockRecorder) ListShared(ctx, grantee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShared", reflect.TypeOf((*MockFolderManager)(nil).ListShared), ctx, grantee)
+}
+
+// ListTagged mocks base method.
+func (m *MockFolderManager) ListTagged(ctx context.Context, owner *model.User, tag, sortBy, order string) ([]*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagged", ctx, owner, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagged indicates an expected call of ListTagged.
+func (mr *MockFolderManagerMockRecorder) ListTagged(ctx, owner, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagged", reflect.TypeOf((*MockFolderManager)(nil).ListTagged), ctx, owner, tag, sortBy, order)
+}
+
+// ListTaggedFiles mocks base method.
+func (m *MockFolderManager) ListTaggedFiles(ctx context.Context, owner *model.User, folder *model.Folder, tag, sortBy, order string) ([]*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaggedFiles", ctx, owner, folder, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaggedFiles indicates an expected call of ListTaggedFiles.
+func (mr *MockFolderManagerMockRecorder) ListTaggedFiles(ctx, owner, folder, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaggedFiles", reflect.TypeOf((*MockFolderManager)(nil).ListTaggedFiles), ctx, owner, folder, tag, sortBy, order)
+}
+
+// MoveFile mocks base method.
+func (m *MockFolderManager) MoveFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, target *model.Folder, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", ctx, owner, folder, filename, target, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockFolderManagerMockRecorder) MoveFile(ctx, owner, folder, filename, target, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockFolderManager)(nil).MoveFile), ctx, owner, folder, filename, target, conflict)
+}
+
+// MoveFolder mocks base method.
+func (m *MockFolderManager) MoveFolder(ctx context.Context, owner *model.User, foldername string, target *model.User, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", ctx, owner, foldername, target, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFolder indicates an expected call of MoveFolder.
+func (mr *MockFolderManagerMockRecorder) MoveFolder(ctx, owner, foldername, target, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockFolderManager)(nil).MoveFolder), ctx, owner, foldername, target, newFoldername)
+}
+
 // Rename mocks base method.
 func (m *MockFolderManager) Rename(ctx context.Context, owner *model.User, foldername, newFoldername string) (*model.Folder, error) {
 	m.ctrl.T.Helper()
@@ -152,3 +272,528 @@ func (mr *MockFolderManagerMockRecorder) Rename(ctx, owner, foldername, newFolde
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockFolderManager)(nil).Rename), ctx, owner, foldername, newFoldername)
 }
+
+// RenameFile mocks base method.
+func (m *MockFolderManager) RenameFile(ctx context.Context, owner *model.User, folder *model.Folder, filename, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFile", ctx, owner, folder, filename, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFile indicates an expected call of RenameFile.
+func (mr *MockFolderManagerMockRecorder) RenameFile(ctx, owner, folder, filename, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFile", reflect.TypeOf((*MockFolderManager)(nil).RenameFile), ctx, owner, folder, filename, newFilename, conflict)
+}
+
+// Restore mocks base method.
+func (m *MockFolderManager) Restore(ctx context.Context, owner *model.User, folder *model.Folder) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, owner, folder)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockFolderManagerMockRecorder) Restore(ctx, owner, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockFolderManager)(nil).Restore), ctx, owner, folder)
+}
+
+// Share mocks base method.
+func (m *MockFolderManager) Share(ctx context.Context, owner *model.User, foldername string, grantee *model.User, access model.Access) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", ctx, owner, foldername, grantee, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockFolderManagerMockRecorder) Share(ctx, owner, foldername, grantee, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockFolderManager)(nil).Share), ctx, owner, foldername, grantee, access)
+}
+
+// TagFile mocks base method.
+func (m *MockFolderManager) TagFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFile", ctx, owner, folder, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFile indicates an expected call of TagFile.
+func (mr *MockFolderManagerMockRecorder) TagFile(ctx, owner, folder, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFile", reflect.TypeOf((*MockFolderManager)(nil).TagFile), ctx, owner, folder, filename, tags)
+}
+
+// TagFolder mocks base method.
+func (m *MockFolderManager) TagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFolder", ctx, owner, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFolder indicates an expected call of TagFolder.
+func (mr *MockFolderManagerMockRecorder) TagFolder(ctx, owner, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFolder", reflect.TypeOf((*MockFolderManager)(nil).TagFolder), ctx, owner, foldername, tags)
+}
+
+// Unshare mocks base method.
+func (m *MockFolderManager) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", ctx, owner, foldername, grantee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockFolderManagerMockRecorder) Unshare(ctx, owner, foldername, grantee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockFolderManager)(nil).Unshare), ctx, owner, foldername, grantee)
+}
+
+// UntagFile mocks base method.
+func (m *MockFolderManager) UntagFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFile", ctx, owner, folder, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFile indicates an expected call of UntagFile.
+func (mr *MockFolderManagerMockRecorder) UntagFile(ctx, owner, folder, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFile", reflect.TypeOf((*MockFolderManager)(nil).UntagFile), ctx, owner, folder, filename, tags)
+}
+
+// UntagFolder mocks base method.
+func (m *MockFolderManager) UntagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFolder", ctx, owner, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFolder indicates an expected call of UntagFolder.
+func (mr *MockFolderManagerMockRecorder) UntagFolder(ctx, owner, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFolder", reflect.TypeOf((*MockFolderManager)(nil).UntagFolder), ctx, owner, foldername, tags)
+}
+
+// MockFolderTx is a mock of FolderTx interface.
+type MockFolderTx struct {
+	ctrl     *gomock.Controller
+	recorder *MockFolderTxMockRecorder
+}
+
+// MockFolderTxMockRecorder is the mock recorder for MockFolderTx.
+type MockFolderTxMockRecorder struct {
+	mock *MockFolderTx
+}
+
+// NewMockFolderTx creates a new mock instance.
+func NewMockFolderTx(ctrl *gomock.Controller) *MockFolderTx {
+	mock := &MockFolderTx{ctrl: ctrl}
+	mock.recorder = &MockFolderTxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFolderTx) EXPECT() *MockFolderTxMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockFolderTx) Begin(ctx context.Context) (FolderTx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(FolderTx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockFolderTxMockRecorder) Begin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockFolderTx)(nil).Begin), ctx)
+}
+
+// Commit mocks base method.
+func (m *MockFolderTx) Commit(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockFolderTxMockRecorder) Commit(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockFolderTx)(nil).Commit), ctx)
+}
+
+// CopyFile mocks base method.
+func (m *MockFolderTx) CopyFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, target *model.Folder, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFile", ctx, owner, folder, filename, target, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFile indicates an expected call of CopyFile.
+func (mr *MockFolderTxMockRecorder) CopyFile(ctx, owner, folder, filename, target, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockFolderTx)(nil).CopyFile), ctx, owner, folder, filename, target, newFilename, conflict)
+}
+
+// CopyFolder mocks base method.
+func (m *MockFolderTx) CopyFolder(ctx context.Context, owner *model.User, foldername string, target *model.User, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFolder", ctx, owner, foldername, target, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFolder indicates an expected call of CopyFolder.
+func (mr *MockFolderTxMockRecorder) CopyFolder(ctx, owner, foldername, target, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFolder", reflect.TypeOf((*MockFolderTx)(nil).CopyFolder), ctx, owner, foldername, target, newFoldername)
+}
+
+// Create mocks base method.
+func (m *MockFolderTx) Create(ctx context.Context, owner *model.User, foldername, description string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, owner, foldername, description)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFolderTxMockRecorder) Create(ctx, owner, foldername, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFolderTx)(nil).Create), ctx, owner, foldername, description)
+}
+
+// CreateFile mocks base method.
+func (m *MockFolderTx) CreateFile(ctx context.Context, owner *model.User, folder *model.Folder, filename, description string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFile", ctx, owner, folder, filename, description)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFile indicates an expected call of CreateFile.
+func (mr *MockFolderTxMockRecorder) CreateFile(ctx, owner, folder, filename, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFile", reflect.TypeOf((*MockFolderTx)(nil).CreateFile), ctx, owner, folder, filename, description)
+}
+
+// Delete mocks base method.
+func (m *MockFolderTx) Delete(ctx context.Context, owner *model.User, foldername string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, owner, foldername)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFolderTxMockRecorder) Delete(ctx, owner, foldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFolderTx)(nil).Delete), ctx, owner, foldername)
+}
+
+// DeleteFile mocks base method.
+func (m *MockFolderTx) DeleteFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", ctx, owner, folder, filename)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockFolderTxMockRecorder) DeleteFile(ctx, owner, folder, filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockFolderTx)(nil).DeleteFile), ctx, owner, folder, filename)
+}
+
+// GetByName mocks base method.
+func (m *MockFolderTx) GetByName(ctx context.Context, owner *model.User, foldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", ctx, owner, foldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockFolderTxMockRecorder) GetByName(ctx, owner, foldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockFolderTx)(nil).GetByName), ctx, owner, foldername)
+}
+
+// List mocks base method.
+func (m *MockFolderTx) List(ctx context.Context, owner *model.User, sortBy, order string) ([]*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, owner, sortBy, order)
+	ret0, _ := ret[0].([]*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFolderTxMockRecorder) List(ctx, owner, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFolderTx)(nil).List), ctx, owner, sortBy, order)
+}
+
+// ListFiles mocks base method.
+func (m *MockFolderTx) ListFiles(ctx context.Context, owner *model.User, folder *model.Folder, sortBy, order string) ([]*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", ctx, owner, folder, sortBy, order)
+	ret0, _ := ret[0].([]*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFiles indicates an expected call of ListFiles.
+func (mr *MockFolderTxMockRecorder) ListFiles(ctx, owner, folder, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFolderTx)(nil).ListFiles), ctx, owner, folder, sortBy, order)
+}
+
+// ListShared mocks base method.
+func (m *MockFolderTx) ListShared(ctx context.Context, grantee *model.User) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShared", ctx, grantee)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShared indicates an expected call of ListShared.
+func (mr *MockFolderTxMockRecorder) ListShared(ctx, grantee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShared", reflect.TypeOf((*MockFolderTx)(nil).ListShared), ctx, grantee)
+}
+
+// ListTagged mocks base method.
+func (m *MockFolderTx) ListTagged(ctx context.Context, owner *model.User, tag, sortBy, order string) ([]*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagged", ctx, owner, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagged indicates an expected call of ListTagged.
+func (mr *MockFolderTxMockRecorder) ListTagged(ctx, owner, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagged", reflect.TypeOf((*MockFolderTx)(nil).ListTagged), ctx, owner, tag, sortBy, order)
+}
+
+// ListTaggedFiles mocks base method.
+func (m *MockFolderTx) ListTaggedFiles(ctx context.Context, owner *model.User, folder *model.Folder, tag, sortBy, order string) ([]*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaggedFiles", ctx, owner, folder, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaggedFiles indicates an expected call of ListTaggedFiles.
+func (mr *MockFolderTxMockRecorder) ListTaggedFiles(ctx, owner, folder, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaggedFiles", reflect.TypeOf((*MockFolderTx)(nil).ListTaggedFiles), ctx, owner, folder, tag, sortBy, order)
+}
+
+// MoveFile mocks base method.
+func (m *MockFolderTx) MoveFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, target *model.Folder, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", ctx, owner, folder, filename, target, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockFolderTxMockRecorder) MoveFile(ctx, owner, folder, filename, target, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockFolderTx)(nil).MoveFile), ctx, owner, folder, filename, target, conflict)
+}
+
+// MoveFolder mocks base method.
+func (m *MockFolderTx) MoveFolder(ctx context.Context, owner *model.User, foldername string, target *model.User, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", ctx, owner, foldername, target, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFolder indicates an expected call of MoveFolder.
+func (mr *MockFolderTxMockRecorder) MoveFolder(ctx, owner, foldername, target, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockFolderTx)(nil).MoveFolder), ctx, owner, foldername, target, newFoldername)
+}
+
+// Rename mocks base method.
+func (m *MockFolderTx) Rename(ctx context.Context, owner *model.User, foldername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, owner, foldername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockFolderTxMockRecorder) Rename(ctx, owner, foldername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockFolderTx)(nil).Rename), ctx, owner, foldername, newFoldername)
+}
+
+// RenameFile mocks base method.
+func (m *MockFolderTx) RenameFile(ctx context.Context, owner *model.User, folder *model.Folder, filename, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFile", ctx, owner, folder, filename, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFile indicates an expected call of RenameFile.
+func (mr *MockFolderTxMockRecorder) RenameFile(ctx, owner, folder, filename, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFile", reflect.TypeOf((*MockFolderTx)(nil).RenameFile), ctx, owner, folder, filename, newFilename, conflict)
+}
+
+// Restore mocks base method.
+func (m *MockFolderTx) Restore(ctx context.Context, owner *model.User, folder *model.Folder) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, owner, folder)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockFolderTxMockRecorder) Restore(ctx, owner, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockFolderTx)(nil).Restore), ctx, owner, folder)
+}
+
+// Rollback mocks base method.
+func (m *MockFolderTx) Rollback(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockFolderTxMockRecorder) Rollback(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockFolderTx)(nil).Rollback), ctx)
+}
+
+// Share mocks base method.
+func (m *MockFolderTx) Share(ctx context.Context, owner *model.User, foldername string, grantee *model.User, access model.Access) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", ctx, owner, foldername, grantee, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockFolderTxMockRecorder) Share(ctx, owner, foldername, grantee, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockFolderTx)(nil).Share), ctx, owner, foldername, grantee, access)
+}
+
+// TagFile mocks base method.
+func (m *MockFolderTx) TagFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFile", ctx, owner, folder, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFile indicates an expected call of TagFile.
+func (mr *MockFolderTxMockRecorder) TagFile(ctx, owner, folder, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFile", reflect.TypeOf((*MockFolderTx)(nil).TagFile), ctx, owner, folder, filename, tags)
+}
+
+// TagFolder mocks base method.
+func (m *MockFolderTx) TagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFolder", ctx, owner, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFolder indicates an expected call of TagFolder.
+func (mr *MockFolderTxMockRecorder) TagFolder(ctx, owner, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFolder", reflect.TypeOf((*MockFolderTx)(nil).TagFolder), ctx, owner, foldername, tags)
+}
+
+// Unshare mocks base method.
+func (m *MockFolderTx) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", ctx, owner, foldername, grantee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockFolderTxMockRecorder) Unshare(ctx, owner, foldername, grantee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockFolderTx)(nil).Unshare), ctx, owner, foldername, grantee)
+}
+
+// UntagFile mocks base method.
+func (m *MockFolderTx) UntagFile(ctx context.Context, owner *model.User, folder *model.Folder, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFile", ctx, owner, folder, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFile indicates an expected call of UntagFile.
+func (mr *MockFolderTxMockRecorder) UntagFile(ctx, owner, folder, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFile", reflect.TypeOf((*MockFolderTx)(nil).UntagFile), ctx, owner, folder, filename, tags)
+}
+
+// UntagFolder mocks base method.
+func (m *MockFolderTx) UntagFolder(ctx context.Context, owner *model.User, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFolder", ctx, owner, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFolder indicates an expected call of UntagFolder.
+func (mr *MockFolderTxMockRecorder) UntagFolder(ctx, owner, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFolder", reflect.TypeOf((*MockFolderTx)(nil).UntagFolder), ctx, owner, foldername, tags)
+}
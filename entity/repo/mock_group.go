@@ -0,0 +1,140 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: group.go
+
+// Package repo is a generated GoMock package.
+package repo
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/blackhorseya/iscool-assessment/entity/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockGroupManager is a mock of GroupManager interface.
+type MockGroupManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockGroupManagerMockRecorder
+}
+
+// MockGroupManagerMockRecorder is the mock recorder for MockGroupManager.
+type MockGroupManagerMockRecorder struct {
+	mock *MockGroupManager
+}
+
+// NewMockGroupManager creates a new mock instance.
+func NewMockGroupManager(ctrl *gomock.Controller) *MockGroupManager {
+	mock := &MockGroupManager{ctrl: ctrl}
+	mock.recorder = &MockGroupManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGroupManager) EXPECT() *MockGroupManagerMockRecorder {
+	return m.recorder
+}
+
+// AddMember mocks base method.
+func (m *MockGroupManager) AddMember(ctx context.Context, name string, member *model.User) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", ctx, name, member)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockGroupManagerMockRecorder) AddMember(ctx, name, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockGroupManager)(nil).AddMember), ctx, name, member)
+}
+
+// Create mocks base method.
+func (m *MockGroupManager) Create(ctx context.Context, name string, creator *model.User) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, name, creator)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockGroupManagerMockRecorder) Create(ctx, name, creator interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockGroupManager)(nil).Create), ctx, name, creator)
+}
+
+// Folders mocks base method.
+func (m *MockGroupManager) Folders() FolderManager {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Folders")
+	ret0, _ := ret[0].(FolderManager)
+	return ret0
+}
+
+// Folders indicates an expected call of Folders.
+func (mr *MockGroupManagerMockRecorder) Folders() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Folders", reflect.TypeOf((*MockGroupManager)(nil).Folders))
+}
+
+// GetByName mocks base method.
+func (m *MockGroupManager) GetByName(ctx context.Context, name string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByName", ctx, name)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByName indicates an expected call of GetByName.
+func (mr *MockGroupManagerMockRecorder) GetByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByName", reflect.TypeOf((*MockGroupManager)(nil).GetByName), ctx, name)
+}
+
+// List mocks base method.
+func (m *MockGroupManager) List(ctx context.Context, member *model.User) ([]*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, member)
+	ret0, _ := ret[0].([]*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockGroupManagerMockRecorder) List(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockGroupManager)(nil).List), ctx, member)
+}
+
+// RemoveMember mocks base method.
+func (m *MockGroupManager) RemoveMember(ctx context.Context, name string, member *model.User) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, name, member)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockGroupManagerMockRecorder) RemoveMember(ctx, name, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockGroupManager)(nil).RemoveMember), ctx, name, member)
+}
+
+// SetQuota mocks base method.
+func (m *MockGroupManager) SetQuota(ctx context.Context, name string, quota *model.Quota) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuota", ctx, name, quota)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQuota indicates an expected call of SetQuota.
+func (mr *MockGroupManagerMockRecorder) SetQuota(ctx, name, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuota", reflect.TypeOf((*MockGroupManager)(nil).SetQuota), ctx, name, quota)
+}
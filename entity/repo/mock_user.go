@@ -7,6 +7,7 @@ package repo
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	model "github.com/blackhorseya/iscool-assessment/entity/model"
 	gomock "go.uber.org/mock/gomock"
@@ -35,6 +36,50 @@ func (m *MockUserManager) EXPECT() *MockUserManagerMockRecorder {
 	return m.recorder
 }
 
+// AddSession mocks base method.
+func (m *MockUserManager) AddSession(ctx context.Context, username, tokenHash string, expiresAt time.Time) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSession", ctx, username, tokenHash, expiresAt)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSession indicates an expected call of AddSession.
+func (mr *MockUserManagerMockRecorder) AddSession(ctx, username, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSession", reflect.TypeOf((*MockUserManager)(nil).AddSession), ctx, username, tokenHash, expiresAt)
+}
+
+// AddToken mocks base method.
+func (m *MockUserManager) AddToken(ctx context.Context, username string, token *model.Token) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToken", ctx, username, token)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddToken indicates an expected call of AddToken.
+func (mr *MockUserManagerMockRecorder) AddToken(ctx, username, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToken", reflect.TypeOf((*MockUserManager)(nil).AddToken), ctx, username, token)
+}
+
+// Delete mocks base method.
+func (m *MockUserManager) Delete(ctx context.Context, username string, cascade bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, username, cascade)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserManagerMockRecorder) Delete(ctx, username, cascade interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserManager)(nil).Delete), ctx, username, cascade)
+}
+
 // GetByUsername mocks base method.
 func (m *MockUserManager) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +95,21 @@ func (mr *MockUserManagerMockRecorder) GetByUsername(ctx, username interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUsername", reflect.TypeOf((*MockUserManager)(nil).GetByUsername), ctx, username)
 }
 
+// List mocks base method.
+func (m *MockUserManager) List(ctx context.Context, order string, offset, limit int) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, order, offset, limit)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockUserManagerMockRecorder) List(ctx, order, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserManager)(nil).List), ctx, order, offset, limit)
+}
+
 // Register mocks base method.
 func (m *MockUserManager) Register(ctx context.Context, username string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -64,3 +124,93 @@ func (mr *MockUserManagerMockRecorder) Register(ctx, username interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockUserManager)(nil).Register), ctx, username)
 }
+
+// RemoveSession mocks base method.
+func (m *MockUserManager) RemoveSession(ctx context.Context, username, tokenHash string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveSession", ctx, username, tokenHash)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveSession indicates an expected call of RemoveSession.
+func (mr *MockUserManagerMockRecorder) RemoveSession(ctx, username, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSession", reflect.TypeOf((*MockUserManager)(nil).RemoveSession), ctx, username, tokenHash)
+}
+
+// RemoveToken mocks base method.
+func (m *MockUserManager) RemoveToken(ctx context.Context, username, id string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveToken", ctx, username, id)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveToken indicates an expected call of RemoveToken.
+func (mr *MockUserManagerMockRecorder) RemoveToken(ctx, username, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveToken", reflect.TypeOf((*MockUserManager)(nil).RemoveToken), ctx, username, id)
+}
+
+// Rename mocks base method.
+func (m *MockUserManager) Rename(ctx context.Context, username, newUsername string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rename", ctx, username, newUsername)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rename indicates an expected call of Rename.
+func (mr *MockUserManagerMockRecorder) Rename(ctx, username, newUsername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rename", reflect.TypeOf((*MockUserManager)(nil).Rename), ctx, username, newUsername)
+}
+
+// SetPassword mocks base method.
+func (m *MockUserManager) SetPassword(ctx context.Context, username, passwordHash string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", ctx, username, passwordHash)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockUserManagerMockRecorder) SetPassword(ctx, username, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockUserManager)(nil).SetPassword), ctx, username, passwordHash)
+}
+
+// SetQuota mocks base method.
+func (m *MockUserManager) SetQuota(ctx context.Context, username string, quota *model.Quota) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuota", ctx, username, quota)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQuota indicates an expected call of SetQuota.
+func (mr *MockUserManagerMockRecorder) SetQuota(ctx, username, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuota", reflect.TypeOf((*MockUserManager)(nil).SetQuota), ctx, username, quota)
+}
+
+// SetRole mocks base method.
+func (m *MockUserManager) SetRole(ctx context.Context, username string, role model.Role) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, username, role)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockUserManagerMockRecorder) SetRole(ctx, username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockUserManager)(nil).SetRole), ctx, username, role)
+}
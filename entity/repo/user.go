@@ -4,6 +4,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 )
@@ -12,4 +13,31 @@ import (
 type UserManager interface {
 	Register(ctx context.Context, username string) (item *model.User, err error)
 	GetByUsername(ctx context.Context, username string) (item *model.User, err error)
+
+	// SetRole is used to change the role of the user username.
+	SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error)
+
+	// SetPassword is used to change the password hash of the user username.
+	SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error)
+
+	// AddSession is used to add a session of the user username expiring at expiresAt by the hash of its token,
+	// dropping the expired ones, and RemoveSession to end one. AddToken is used to add an API token to the user,
+	// failing when its ID is taken, and RemoveToken to revoke one. Each changes its entry alone, so concurrent
+	// logins and tokens of a user are all kept.
+	AddSession(ctx context.Context, username, tokenHash string, expiresAt time.Time) (item *model.User, err error)
+	RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error)
+	AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error)
+	RemoveToken(ctx context.Context, username, id string) (item *model.User, err error)
+
+	// SetQuota is used to replace the quota of the user username, nil removing its limits.
+	SetQuota(ctx context.Context, username string, quota *model.Quota) (item *model.User, err error)
+
+	// Delete is used to remove the user username with its folders when cascade, failing when it has any
+	// otherwise, and Rename to rename it with its folders. Both update the shares granted to the user.
+	Delete(ctx context.Context, username string, cascade bool) (err error)
+	Rename(ctx context.Context, username, newUsername string) (item *model.User, err error)
+
+	// List is used to list the users by username, ascending unless order is desc, keeping the limit users
+	// after offset, all of them without a limit.
+	List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error)
 }
@@ -0,0 +1,103 @@
+// Package auth hashes the passwords and the tokens of the users, which the stores only keep as hashes.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Parameters of the argon2id hashes of the passwords.
+const (
+	passwordTime    = 1
+	passwordMemory  = 64 * 1024
+	passwordThreads = 4
+	passwordSize    = 32
+	saltSize        = 16
+	tokenSize       = 32
+)
+
+var errMalformedHash = errors.New("malformed password hash")
+
+// HashPassword is used to hash password with argon2id and a random salt, in the PHC string format like
+// $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>.
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("the password can't be empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, passwordTime, passwordMemory, passwordThreads, passwordSize)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		passwordMemory,
+		passwordTime,
+		passwordThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// VerifyPassword reports whether password matches passwordHash, with the parameters it was hashed with.
+func VerifyPassword(passwordHash, password string) (bool, error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errMalformedHash
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return false, errMalformedHash
+	}
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads)
+	if err != nil {
+		return false, errMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errMalformedHash
+	}
+	hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(hash) == 0 {
+		return false, errMalformedHash
+	}
+
+	got := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(hash)))
+
+	return subtle.ConstantTimeCompare(got, hash) == 1, nil
+}
+
+// NewToken is used to generate a random token, returned along with its hash.
+func NewToken() (token, tokenHash string, err error) {
+	b := make([]byte, tokenSize)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hash of token. Unlike the passwords, the tokens are random enough for a plain SHA-256.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=1,p=4$") {
+		t.Errorf("HashPassword() = %v, want an argon2id PHC string", hash)
+	}
+
+	again, _ := HashPassword("secret")
+	if again == hash {
+		t.Errorf("HashPassword() twice got the same hash, want different salts")
+	}
+
+	_, err = HashPassword("")
+	if err == nil {
+		t.Errorf("HashPassword() an empty password error = nil, want error")
+	}
+}
+
+func TestVerifyPassword(t *testing.T) {
+	hash, _ := HashPassword("secret")
+
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+		wantErr  bool
+	}{
+		{name: "match", hash: hash, password: "secret", want: true},
+		{name: "mismatch", hash: hash, password: "Secret", want: false},
+		{name: "empty hash", hash: "", password: "secret", wantErr: true},
+		{name: "other algorithm", hash: strings.Replace(hash, "argon2id", "bcrypt", 1), password: "secret", wantErr: true},
+		{name: "malformed salt", hash: strings.Replace(hash, "p=4$", "p=4$!", 1), password: "secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyPassword(tt.hash, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyPassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyPassword() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToken(t *testing.T) {
+	token, tokenHash, err := NewToken()
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	if len(token) != 43 {
+		t.Errorf("NewToken() token = %v, want 43 characters", token)
+	}
+	if tokenHash != HashToken(token) || tokenHash == token {
+		t.Errorf("NewToken() hash = %v, want the hash of the token", tokenHash)
+	}
+
+	other, _, _ := NewToken()
+	if other == token {
+		t.Errorf("NewToken() twice got the same token")
+	}
+}
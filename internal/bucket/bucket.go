@@ -0,0 +1,310 @@
+package bucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Scheme is the URL scheme of the stores kept in an S3-compatible bucket.
+const Scheme = "s3://"
+
+var (
+	// ErrNotFound is returned when the object doesn't exist.
+	ErrNotFound = errors.New("object not found")
+
+	// ErrExists is returned when a conditional write finds an existing object.
+	ErrExists = errors.New("object already exists")
+
+	// ErrChanged is returned when a conditional write finds the object changed since it was read.
+	ErrChanged = errors.New("object changed since it was read")
+)
+
+// Bucket is used to read and write the objects under a prefix of an S3-compatible bucket.
+type Bucket struct {
+	client *s3.Client
+	name   string
+	prefix string
+}
+
+// Open is used to open the bucket of a URL like s3://bucket/prefix. The client is configured from the
+// environment: $AWS_REGION, $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY, $AWS_SESSION_TOKEN and
+// $AWS_ENDPOINT_URL_S3 or $AWS_ENDPOINT_URL for S3-compatible services, addressed path-style.
+func Open(rawURL string) (*Bucket, error) {
+	if !strings.HasPrefix(rawURL, Scheme) {
+		return nil, fmt.Errorf("invalid bucket url: %s", rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid bucket url: %s", rawURL)
+	}
+
+	options := s3.Options{
+		Region: envOr("AWS_REGION", "us-east-1"),
+	}
+	if key := os.Getenv("AWS_ACCESS_KEY_ID"); key != "" {
+		options.Credentials = credentials.NewStaticCredentialsProvider(
+			key,
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			os.Getenv("AWS_SESSION_TOKEN"),
+		)
+	}
+	if endpoint := envOr("AWS_ENDPOINT_URL_S3", os.Getenv("AWS_ENDPOINT_URL")); endpoint != "" {
+		options.BaseEndpoint = aws.String(endpoint)
+		options.UsePathStyle = true
+	}
+
+	return New(s3.New(options), parsed.Host, parsed.Path), nil
+}
+
+// New is used to create a new Bucket on the objects of client under prefix in the bucket name.
+func New(client *s3.Client, name, prefix string) *Bucket {
+	return &Bucket{
+		client: client,
+		name:   name,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// Get is used to read the object at key.
+func (b *Bucket) Get(ctx context.Context, key string) ([]byte, error) {
+	data, _, err := b.GetVersion(ctx, key)
+
+	return data, err
+}
+
+// GetVersion is used to read the object at key along with its ETag, to replace it with Replace later.
+func (b *Bucket) GetVersion(ctx context.Context, key string) ([]byte, string, error) {
+	out, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(b.key(key)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, "", ErrNotFound
+		}
+
+		return nil, "", fmt.Errorf("failed to get %s: %w", key, err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	return data, aws.ToString(out.ETag), nil
+}
+
+// GetJSON is used to decode the JSON object at key into v.
+func (b *Bucket) GetJSON(ctx context.Context, key string, v any) error {
+	_, err := b.GetJSONVersion(ctx, key, v)
+
+	return err
+}
+
+// GetJSONVersion is used to decode the JSON object at key into v, returning its ETag.
+func (b *Bucket) GetJSONVersion(ctx context.Context, key string, v any) (string, error) {
+	data, etag, err := b.GetVersion(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+
+	return etag, nil
+}
+
+// Put is used to write the object at key, replacing any existing one.
+func (b *Bucket) Put(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(b.key(key)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// PutJSON is used to write v as the JSON object at key, replacing any existing one.
+func (b *Bucket) PutJSON(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+
+	return b.Put(ctx, key, data)
+}
+
+// Create is used to write the object at key only if it doesn't exist yet, so concurrent
+// writers can't overwrite each other.
+func (b *Bucket) Create(ctx context.Context, key string, data []byte) error {
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.name),
+		Key:         aws.String(b.key(key)),
+		Body:        bytes.NewReader(data),
+		IfNoneMatch: aws.String("*"),
+	})
+	if preconditionFailed(err) {
+		return ErrExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// CreateJSON is used to write v as the JSON object at key only if it doesn't exist yet.
+func (b *Bucket) CreateJSON(ctx context.Context, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+
+	return b.Create(ctx, key, data)
+}
+
+// Replace is used to write the object at key only if its ETag is still etag, so a read-modify-write
+// can't overwrite the changes made since the read. It returns ErrChanged otherwise.
+func (b *Bucket) Replace(ctx context.Context, key string, data []byte, etag string) error {
+	// the If-Match of PutObject is sent as a header, the input of this client not having the field
+	_, err := b.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(b.key(key)),
+		Body:   bytes.NewReader(data),
+	}, s3.WithAPIOptions(smithyhttp.SetHeaderValue("If-Match", etag)))
+	if preconditionFailed(err) {
+		return ErrChanged
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchKey" {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// ReplaceJSON is used to write v as the JSON object at key only if its ETag is still etag.
+func (b *Bucket) ReplaceJSON(ctx context.Context, key string, v any, etag string) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+
+	return b.Replace(ctx, key, data, etag)
+}
+
+// preconditionFailed reports whether err is the refusal of a conditional write.
+func preconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+
+	return errors.As(err, &apiErr) &&
+		(apiErr.ErrorCode() == "PreconditionFailed" || apiErr.ErrorCode() == "ConditionalRequestConflict")
+}
+
+// List is used to list the keys of every object under prefix.
+func (b *Bucket) List(ctx context.Context, prefix string) (keys []string, err error) {
+	pages := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.name),
+		Prefix: aws.String(b.key(prefix) + "/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+
+		for _, object := range page.Contents {
+			keys = append(keys, b.relative(aws.ToString(object.Key)))
+		}
+	}
+
+	return keys, nil
+}
+
+// Dirs is used to list the names directly under prefix, like the folders of a user.
+func (b *Bucket) Dirs(ctx context.Context, prefix string) (names []string, err error) {
+	pages := s3.NewListObjectsV2Paginator(b.client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(b.name),
+		Prefix:    aws.String(b.key(prefix) + "/"),
+		Delimiter: aws.String("/"),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+
+		for _, common := range page.CommonPrefixes {
+			names = append(names, path.Base(aws.ToString(common.Prefix)))
+		}
+	}
+
+	return names, nil
+}
+
+// Delete is used to remove the objects at keys, missing ones are ignored.
+func (b *Bucket) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(b.name),
+			Key:    aws.String(b.key(key)),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (b *Bucket) key(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+
+	return b.prefix + "/" + key
+}
+
+func (b *Bucket) relative(key string) string {
+	if b.prefix == "" {
+		return key
+	}
+
+	return strings.TrimPrefix(key, b.prefix+"/")
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
@@ -0,0 +1,80 @@
+// Package buckettest provides an in-process S3 fake for the tests of the bucket stores.
+package buckettest
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// Name is the name of the bucket created by Start.
+const Name = "iscool"
+
+// Start is used to run an S3 fake for the duration of t, pointing the environment read by bucket.Open at it.
+// It returns the URL of an empty store in a new bucket.
+func Start(t *testing.T) string {
+	t.Helper()
+
+	backend := s3mem.New()
+	err := backend.CreateBucket(Name)
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	server := httptest.NewServer(conditional(backend, gofakes3.New(backend).Server()))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	return "s3://" + Name + "/vfs"
+}
+
+// conditional is used to honor If-None-Match and If-Match on writes, which the fake ignores.
+func conditional(backend gofakes3.Backend, next http.Handler) http.Handler {
+	var mu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch, ifMatch := r.Header.Get("If-None-Match"), r.Header.Get("If-Match")
+		if r.Method != http.MethodPut || (ifNoneMatch != "*" && ifMatch == "") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		object, err := backend.HeadObject(bucket, key)
+		if ifMatch != "" && err != nil {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(
+				`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`,
+			))
+			return
+		}
+
+		exists := ifNoneMatch == "*" && err == nil
+		changed := ifMatch != "" && ifMatch != `"`+hex.EncodeToString(object.Hash)+`"`
+		if exists || changed {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = w.Write([]byte(
+				`<Error><Code>PreconditionFailed</Code>` +
+					`<Message>At least one of the pre-conditions you specified did not hold</Message></Error>`,
+			))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
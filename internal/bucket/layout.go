@@ -0,0 +1,94 @@
+package bucket
+
+// The keys of a store, relative to the prefix of its bucket:
+//
+//	users/<username>/user.json
+//	users/<username>/folders/<foldername>/meta.json
+//	users/<username>/folders/<foldername>/files/<filename>/meta.json
+//	users/<username>/folders/<foldername>/files/<filename>/content
+//	users/<username>/folders/<foldername>/tags/<tag>/<filename>
+//	users/<username>/sharers/<owner>
+//	users/<username>/tags/<tag>/<foldername>
+
+// UsersPrefix is the prefix of every user.
+const UsersPrefix = "users"
+
+// UserKey returns the key of the object of the user.
+func UserKey(username string) string {
+	return "users/" + username + "/user.json"
+}
+
+// FoldersPrefix returns the prefix of the folders of the user.
+func FoldersPrefix(username string) string {
+	return "users/" + username + "/folders"
+}
+
+// FolderPrefix returns the prefix of every object of the folder.
+func FolderPrefix(username, foldername string) string {
+	return FoldersPrefix(username) + "/" + foldername
+}
+
+// FolderKey returns the key of the metadata of the folder.
+func FolderKey(username, foldername string) string {
+	return FolderPrefix(username, foldername) + "/meta.json"
+}
+
+// FilesPrefix returns the prefix of the files of the folder.
+func FilesPrefix(username, foldername string) string {
+	return FolderPrefix(username, foldername) + "/files"
+}
+
+// FilePrefix returns the prefix of every object of the file.
+func FilePrefix(username, foldername, filename string) string {
+	return FilesPrefix(username, foldername) + "/" + filename
+}
+
+// FileKey returns the key of the metadata of the file.
+func FileKey(username, foldername, filename string) string {
+	return FilePrefix(username, foldername, filename) + "/meta.json"
+}
+
+// ContentKey returns the key of the content of the file.
+func ContentKey(username, foldername, filename string) string {
+	return FilePrefix(username, foldername, filename) + "/content"
+}
+
+// FileTagsPrefix returns the prefix of the markers of the tagged files of the folder.
+func FileTagsPrefix(username, foldername string) string {
+	return FolderPrefix(username, foldername) + "/tags"
+}
+
+// FileTagPrefix returns the prefix of the files of the folder tagged tag.
+func FileTagPrefix(username, foldername, tag string) string {
+	return FileTagsPrefix(username, foldername) + "/" + tag
+}
+
+// FileTagKey returns the key marking that the file of the folder is tagged tag.
+func FileTagKey(username, foldername, tag, filename string) string {
+	return FileTagPrefix(username, foldername, tag) + "/" + filename
+}
+
+// SharersPrefix returns the prefix of the users who have shared a folder with the user.
+func SharersPrefix(username string) string {
+	return "users/" + username + "/sharers"
+}
+
+// SharerKey returns the key marking that owner has shared a folder with the user.
+func SharerKey(username, owner string) string {
+	return SharersPrefix(username) + "/" + owner
+}
+
+// TagsPrefix returns the prefix of the markers of the tagged folders of the user.
+func TagsPrefix(username string) string {
+	return "users/" + username + "/tags"
+}
+
+// TagPrefix returns the prefix of the folders of the user tagged tag.
+func TagPrefix(username, tag string) string {
+	return TagsPrefix(username) + "/" + tag
+}
+
+// TagKey returns the key marking that the folder of the user is tagged tag.
+func TagKey(username, tag, foldername string) string {
+	return TagPrefix(username, tag) + "/" + foldername
+}
@@ -0,0 +1,165 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// DocumentName is the name of the JSON document holding the users in the repository.
+const DocumentName = "vfs.json"
+
+// Entry describes one commit of the history.
+type Entry struct {
+	Hash    string
+	Message string
+	Author  string
+	When    time.Time
+}
+
+// Short returns the abbreviated hash of the commit.
+func (e Entry) Short() string {
+	if len(e.Hash) < 7 {
+		return e.Hash
+	}
+
+	return e.Hash[:7]
+}
+
+// Repository is used to record every mutation of a store as a commit of a git repository.
+type Repository struct {
+	dir  string
+	repo *git.Repository
+}
+
+// Open is used to open the git repository at dir, initializing it if it doesn't exist.
+func Open(dir string) (*Repository, error) {
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = git.PlainInit(dir, false)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	return &Repository{
+		dir:  dir,
+		repo: repo,
+	}, nil
+}
+
+// Document returns the path of the JSON document in the working tree.
+func (r *Repository) Document() string {
+	return filepath.Join(r.dir, DocumentName)
+}
+
+// sidecars returns the names of the files kept with the document in the working tree, its groups and settings.
+func (r *Repository) sidecars() []string {
+	return []string{
+		filepath.Base(store.GroupsPath(r.Document())),
+		filepath.Base(store.SettingsPath(r.Document())),
+	}
+}
+
+// Commit is used to record the current content of the document and its sidecars with message.
+func (r *Repository) Commit(message string) (entry *Entry, err error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	_, err = worktree.Add(DocumentName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage document: %w", err)
+	}
+
+	for _, name := range r.sidecars() {
+		_, err = os.Stat(filepath.Join(r.dir, name))
+		switch {
+		case err == nil:
+			_, err = worktree.Add(name)
+		case errors.Is(err, os.ErrNotExist):
+			// a sidecar removed since is removed from the index too, one never added is left alone
+			_, err = worktree.Remove(name)
+			if errors.Is(err, index.ErrEntryNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stage %s: %w", name, err)
+		}
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	return newEntry(commit), nil
+}
+
+// Log is used to list the commits from the newest to the oldest.
+func (r *Repository) Log() (entries []*Entry, err error) {
+	_, err = r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read head: %w", err)
+	}
+
+	commits, err := r.repo.Log(&git.LogOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	defer commits.Close()
+
+	err = commits.ForEach(func(commit *object.Commit) error {
+		entries = append(entries, newEntry(commit))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// signature returns the author of the commits, taken from the environment like git does.
+func signature() *object.Signature {
+	return &object.Signature{
+		Name:  envOr("GIT_AUTHOR_NAME", "iscool"),
+		Email: envOr("GIT_AUTHOR_EMAIL", "iscool@localhost"),
+		When:  time.Now(),
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func newEntry(commit *object.Commit) *Entry {
+	return &Entry{
+		Hash:    commit.Hash.String(),
+		Message: commit.Message,
+		Author:  commit.Author.Name,
+		When:    commit.Author.When,
+	}
+}
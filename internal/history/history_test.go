@@ -0,0 +1,246 @@
+package history
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const testDir = "out/vfs.git"
+
+// mutate is used to apply fn to the document of r and commit it with message.
+func mutate(t *testing.T, r *Repository, message string, fn func(users map[string]*model.User)) *Entry {
+	t.Helper()
+
+	users := make(map[string]*model.User)
+	err := store.ReadFile(r.Document(), &users)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	fn(users)
+
+	err = store.WriteFile(r.Document(), users)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	entry, err := r.Commit(message)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	return entry
+}
+
+func register(username string) func(users map[string]*model.User) {
+	return func(users map[string]*model.User) {
+		users[username], _ = model.NewUser(username)
+	}
+}
+
+func createFolder(username, foldername, description string) func(users map[string]*model.User) {
+	return func(users map[string]*model.User) {
+		folder, _ := model.NewFolder(users[username], foldername, description)
+		folder.CreatedAt = time.Date(2024, 5, 27, 10, 0, 0, 0, time.UTC)
+		users[username].Folders[foldername] = folder
+	}
+}
+
+func load(t *testing.T, r *Repository) map[string]*model.User {
+	t.Helper()
+
+	users := make(map[string]*model.User)
+	err := store.ReadFile(r.Document(), &users)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	return users
+}
+
+func TestRepository_Log(t *testing.T) {
+	defer os.RemoveAll("out")
+
+	r, err := Open(testDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	entries, err := r.Log()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Log() of an empty repository = %v, error = %v", entries, err)
+	}
+
+	mutate(t, r, "alice: register", register("alice"))
+	mutate(t, r, "alice: create-folder photos", createFolder("alice", "photos", "holiday pictures"))
+
+	// reopening finds the existing repository
+	r, err = Open(testDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	entries, err = r.Log()
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Log() got %d entries, want 2", len(entries))
+	}
+	if entries[0].Message != "alice: create-folder photos" || entries[1].Message != "alice: register" {
+		t.Errorf("Log() messages = %q, %q", entries[0].Message, entries[1].Message)
+	}
+	if len(entries[0].Short()) != 7 || entries[0].Author != "iscool" {
+		t.Errorf("Log() entry = %+v", entries[0])
+	}
+}
+
+func TestRepository_Revert(t *testing.T) {
+	tests := []struct {
+		name    string
+		revert  int
+		want    func(t *testing.T, users map[string]*model.User)
+		wantErr bool
+	}{
+		{
+			name:   "revert the latest commit",
+			revert: 3,
+			want: func(t *testing.T, users map[string]*model.User) {
+				if users["bob"] != nil || users["alice"].Folders["photos"] == nil {
+					t.Errorf("Revert() users = %v", users)
+				}
+			},
+		},
+		{
+			name:   "revert an older commit keeps the later changes",
+			revert: 1,
+			want: func(t *testing.T, users map[string]*model.User) {
+				folder := users["alice"].Folders["photos"]
+				if folder != nil || users["alice"].Folders["music"] == nil || users["bob"] == nil {
+					t.Errorf("Revert() users = %v", users)
+				}
+			},
+		},
+		{
+			name:    "revert a commit changed since is a conflict",
+			revert:  0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.RemoveAll("out")
+
+			r, err := Open(testDir)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			entries := []*Entry{
+				mutate(t, r, "alice: register", register("alice")),
+				mutate(t, r, "alice: create-folder photos", createFolder("alice", "photos", "holiday pictures")),
+				mutate(t, r, "alice: create-folder music", createFolder("alice", "music", "")),
+				mutate(t, r, "bob: register", register("bob")),
+			}
+
+			before := load(t, r)
+			entry, err := r.Revert(entries[tt.revert].Short())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Revert() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if after := load(t, r); len(after) != len(before) {
+					t.Errorf("Revert() changed the document on conflict")
+				}
+				return
+			}
+
+			if entry.Message != `Revert "`+entries[tt.revert].Message+`"` {
+				t.Errorf("Revert() message = %q", entry.Message)
+			}
+			tt.want(t, load(t, r))
+		})
+	}
+}
+
+func TestRepository_Revert_Missing(t *testing.T) {
+	defer os.RemoveAll("out")
+
+	r, err := Open(testDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	mutate(t, r, "alice: register", register("alice"))
+
+	_, err = r.Revert("deadbeef")
+	if err == nil {
+		t.Errorf("Revert() of a missing commit error = nil")
+	}
+}
+
+func TestRepository_Sidecars(t *testing.T) {
+	defer os.RemoveAll("out")
+
+	r, err := Open(testDir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	mutate(t, r, "alice: register", register("alice"))
+
+	path := store.GroupsPath(r.Document())
+	group := &model.Group{Name: "team", Members: []string{"alice"}}
+	err = store.WriteGroups(path, &store.Groups{Groups: map[string]*model.Group{group.Name: group}})
+	if err != nil {
+		t.Fatalf("WriteGroups() error = %v", err)
+	}
+	entry, err := r.Commit("alice: create-group team")
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	// the groups are committed with the document
+	head, err := r.repo.CommitObject(plumbing.NewHash(entry.Hash))
+	if err != nil {
+		t.Fatalf("CommitObject() error = %v", err)
+	}
+	if _, err = head.File("vfs.groups.json"); err != nil {
+		t.Errorf("Commit() didn't record the groups, error = %v", err)
+	}
+
+	_, err = r.Revert(entry.Hash)
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Revert() kept the groups, error = %v", err)
+	}
+
+	// the removal is committed too, so reverting the revert brings the groups back
+	entries, _ := r.Log()
+	_, err = r.Revert(entries[0].Hash)
+	if err != nil {
+		t.Fatalf("Revert() of the revert error = %v", err)
+	}
+	groups := &store.Groups{}
+	err = store.ReadGroups(path, groups)
+	if err != nil || groups.Groups["team"] == nil {
+		t.Errorf("Revert() groups = %+v, error = %v, want team", groups, err)
+	}
+
+	// a sidecar changed since is a conflict
+	group.Members = append(group.Members, "bob")
+	_ = store.WriteGroups(path, &store.Groups{Groups: map[string]*model.Group{group.Name: group}})
+	_, err = r.Commit("alice: add-member team bob")
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if _, err = r.Revert(entries[0].Hash); err == nil {
+		t.Errorf("Revert() of groups changed since error = nil, want error")
+	}
+}
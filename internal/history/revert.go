@@ -0,0 +1,324 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Revert is used to undo the changes of the commit rev on top of the current content of the document,
+// and records the result as a new commit. Users, folders and files changed again since rev are
+// conflicts and leave the document untouched. The sidecars, like the groups, are reverted as a whole.
+func (r *Repository) Revert(rev string) (entry *Entry, err error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("the commit %s doesn't exist", rev)
+	}
+
+	commit, err := r.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	after, err := r.usersAt(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first commit is reverted to an empty store
+	before := make(map[string]*model.User)
+	var parent *object.Commit
+	if commit.NumParents() > 0 {
+		parent, err = commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit: %w", err)
+		}
+
+		before, err = r.usersAt(parent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	current := make(map[string]*model.User)
+	err = store.ReadFile(r.Document(), &current)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = newEntry(commit)
+	users, err := revert(flatten(before), flatten(after), flatten(current))
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert %s: %w", entry.Short(), err)
+	}
+
+	sidecars, err := r.revertSidecars(parent, commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert %s: %w", entry.Short(), err)
+	}
+
+	err = store.WriteFile(r.Document(), users)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, data := range sidecars {
+		path := filepath.Join(r.dir, name)
+		if data == nil {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, data, 0600)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to revert %s: %w", name, err)
+		}
+	}
+
+	return r.Commit(fmt.Sprintf("Revert %q", strings.TrimSpace(commit.Message)))
+}
+
+// revertSidecars returns the content the sidecars changed by commit get back, as recorded by parent, nil for the
+// ones it didn't have. A sidecar changed again since commit is a conflict.
+func (r *Repository) revertSidecars(parent, commit *object.Commit) (sidecars map[string][]byte, err error) {
+	sidecars = make(map[string][]byte)
+	for _, name := range r.sidecars() {
+		before, err := sidecarAt(parent, name)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := sidecarAt(commit, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(before, after) {
+			continue
+		}
+
+		current, err := os.ReadFile(filepath.Join(r.dir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if !bytes.Equal(current, after) {
+			return nil, fmt.Errorf("the %s has changed since", name)
+		}
+
+		sidecars[name] = before
+	}
+
+	return sidecars, nil
+}
+
+// sidecarAt is used to read the sidecar name as recorded by commit, nil when there is none.
+func sidecarAt(commit *object.Commit, name string) (data []byte, err error) {
+	if commit == nil {
+		return nil, nil
+	}
+
+	file, err := commit.File(name)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	return []byte(contents), nil
+}
+
+// usersAt is used to read the document as recorded by commit.
+func (r *Repository) usersAt(commit *object.Commit) (users map[string]*model.User, err error) {
+	users = make(map[string]*model.User)
+
+	file, err := commit.File(DocumentName)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return users, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+	defer reader.Close()
+
+	err = store.Read(reader, r.Document(), &users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// node holds the own fields of a user, folder or file, so that nodes compare with ==.
+type node struct {
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+
+	// Tags are joined by commas, which a tag can't contain.
+	Tags string `json:"tags,omitempty"`
+}
+
+func (n node) tags() []string {
+	if n.Tags == "" {
+		return nil
+	}
+
+	return strings.Split(n.Tags, ",")
+}
+
+// flatten is used to index the users, folders and files by their path, like alice/photos/beach,
+// with their own fields encoded as the value.
+func flatten(users map[string]*model.User) map[string]string {
+	nodes := make(map[string]string)
+	encode := func(n node) string {
+		data, _ := json.Marshal(n)
+		return string(data)
+	}
+
+	for username, user := range users {
+		if user == nil {
+			continue
+		}
+
+		nodes[username] = encode(node{})
+		for foldername, folder := range user.Folders {
+			if folder == nil {
+				continue
+			}
+
+			path := username + "/" + foldername
+			nodes[path] = encode(node{
+				Description: folder.Description,
+				CreatedAt:   folder.CreatedAt,
+				Tags:        strings.Join(folder.Tags, ","),
+			})
+			for filename, file := range folder.Files {
+				if file == nil {
+					continue
+				}
+
+				nodes[path+"/"+filename] = encode(node{
+					Description: file.Description,
+					CreatedAt:   file.CreatedAt,
+					Tags:        strings.Join(file.Tags, ","),
+				})
+			}
+		}
+	}
+
+	return nodes
+}
+
+// revert is used to apply the changes from after back to before on current.
+func revert(before, after, current map[string]string) (map[string]*model.User, error) {
+	paths := make(map[string]bool)
+	for path := range before {
+		paths[path] = true
+	}
+	for path := range after {
+		paths[path] = true
+	}
+
+	for path := range paths {
+		old, existed := before[path]
+		value, exists := after[path]
+		if existed == exists && old == value {
+			continue
+		}
+
+		got, ok := current[path]
+		if ok != exists || got != value {
+			return nil, fmt.Errorf("the %s has changed since", path)
+		}
+
+		if existed {
+			current[path] = old
+		} else {
+			delete(current, path)
+		}
+	}
+
+	return unflatten(current)
+}
+
+// unflatten is used to rebuild the users from their nodes, parents first.
+func unflatten(nodes map[string]string) (map[string]*model.User, error) {
+	paths := make([]string, 0, len(nodes))
+	for path := range nodes {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "/"), strings.Count(paths[j], "/")
+		if di != dj {
+			return di < dj
+		}
+
+		return paths[i] < paths[j]
+	})
+
+	users := make(map[string]*model.User)
+	for _, path := range paths {
+		var n node
+		err := json.Unmarshal([]byte(nodes[path]), &n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+
+		names := strings.Split(path, "/")
+		switch len(names) {
+		case 1:
+			users[names[0]] = &model.User{Username: names[0], Folders: make(map[string]*model.Folder)}
+		case 2:
+			user := users[names[0]]
+			if user == nil {
+				return nil, fmt.Errorf("the %s has changed since", path)
+			}
+
+			user.Folders[names[1]] = &model.Folder{
+				Name:        names[1],
+				Description: n.Description,
+				CreatedAt:   n.CreatedAt,
+				Owner:       user,
+				Files:       make(map[string]*model.File),
+				Tags:        n.tags(),
+			}
+		case 3:
+			user := users[names[0]]
+			if user == nil || user.Folders[names[1]] == nil {
+				return nil, fmt.Errorf("the %s has changed since", path)
+			}
+
+			folder := user.Folders[names[1]]
+			folder.Files[names[2]] = &model.File{
+				Name:        names[2],
+				Description: n.Description,
+				CreatedAt:   n.CreatedAt,
+				Owner:       user,
+				Folder:      folder,
+				Tags:        n.tags(),
+			}
+		}
+	}
+
+	return users, nil
+}
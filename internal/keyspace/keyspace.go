@@ -0,0 +1,93 @@
+package keyspace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// prefix namespaces the keys of the store, so it can share a database.
+const prefix = "iscool:"
+
+// Open is used to connect to the Redis server of a URL like redis://[user:password@]host:port/db,
+// or rediss:// for TLS.
+func Open(rawURL string) (*redis.Client, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	err = client.Ping(context.Background()).Err()
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// The keys of a store:
+//
+//	iscool:user:<username>                                   hash of the user
+//	iscool:user:<username>:folders                           sorted set of the foldernames by created time
+//	iscool:user:<username>:sharers                           set of the users who have shared a folder with it
+//	iscool:user:<username>:tag:<tag>                         set of the foldernames tagged tag
+//	iscool:user:<username>:folder:<foldername>               hash of the folder
+//	iscool:user:<username>:folder:<foldername>:files         sorted set of the filenames by created time
+//	iscool:user:<username>:folder:<foldername>:file:<name>   hash of the file
+//	iscool:user:<username>:folder:<foldername>:tag:<tag>     set of the filenames tagged tag
+
+// UserKey returns the key of the hash of the user.
+func UserKey(username string) string {
+	return prefix + "user:" + username
+}
+
+// UsersPattern returns the pattern matching the keys of the users, along with the keys of what they own.
+func UsersPattern() string {
+	return UserKey("*")
+}
+
+// UsernameOf returns the username of the key of the hash of a user, and whether key is one.
+func UsernameOf(key string) (string, bool) {
+	username, found := strings.CutPrefix(key, UserKey(""))
+
+	return username, found && username != "" && !strings.Contains(username, ":")
+}
+
+// FoldersKey returns the key of the sorted set of the folders of the user.
+func FoldersKey(username string) string {
+	return UserKey(username) + ":folders"
+}
+
+// SharersKey returns the key of the set of the users who have shared a folder with the user.
+func SharersKey(username string) string {
+	return UserKey(username) + ":sharers"
+}
+
+// TagKey returns the key of the set of the folders of the user tagged tag.
+func TagKey(username, tag string) string {
+	return UserKey(username) + ":tag:" + tag
+}
+
+// FolderKey returns the key of the hash of the folder.
+func FolderKey(username, foldername string) string {
+	return UserKey(username) + ":folder:" + foldername
+}
+
+// FilesKey returns the key of the sorted set of the files of the folder.
+func FilesKey(username, foldername string) string {
+	return FolderKey(username, foldername) + ":files"
+}
+
+// FileKey returns the key of the hash of the file.
+func FileKey(username, foldername, filename string) string {
+	return FolderKey(username, foldername) + ":file:" + filename
+}
+
+// FileTagKey returns the key of the set of the files of the folder tagged tag.
+func FileTagKey(username, foldername, tag string) string {
+	return FolderKey(username, foldername) + ":tag:" + tag
+}
@@ -2,40 +2,45 @@ package folder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
-	"sort"
 	"sync"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
-	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
 )
 
-const orderAsc = "asc"
-
 type jsonFile struct {
-	sync.Mutex
+	*sync.Mutex
 
 	users map[string]*model.User
 	path  string
+	save  func() error
 }
 
 // NewJSONFile is used to create a new JSONFile.
 func NewJSONFile(path string) (repo.FolderManager, error) {
-	instance := &jsonFile{
-		Mutex: sync.Mutex{},
-		users: make(map[string]*model.User),
-		path:  path,
-	}
-
-	err := instance.Load()
+	// the document is shared with the other manager of path, so neither works on a stale copy
+	doc, err := store.OpenDocument(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return instance, nil
+	return &jsonFile{
+		Mutex: &doc.Mutex,
+		users: doc.Users,
+		path:  path,
+	}, nil
+}
+
+// NewOwned is used to create a FolderManager over the folders of owners, which the caller keeps under mu
+// and writes with save.
+func NewOwned(mu *sync.Mutex, owners map[string]*model.User, save func() error) repo.FolderManager {
+	return &jsonFile{
+		Mutex: mu,
+		users: owners,
+		save:  save,
+	}
 }
 
 func (i *jsonFile) GetByName(
@@ -140,6 +145,11 @@ func (i *jsonFile) Rename(
 	folder.Name = newFoldername
 	user.Folders[newFoldername] = folder
 
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
 	return folder, nil
 }
 
@@ -162,26 +172,271 @@ func (i *jsonFile) List(
 		folders = append(folders, folder)
 	}
 
-	sort.Slice(folders, func(i, j int) bool {
-		switch sortBy {
-		case "name":
-			if order == orderAsc {
-				return folders[i].Name < folders[j].Name
-			}
-			return folders[i].Name > folders[j].Name
-		case "created":
-			if order == orderAsc {
-				return folders[i].CreatedAt.Before(folders[j].CreatedAt)
-			}
-			return folders[i].CreatedAt.After(folders[j].CreatedAt)
-		default:
-			return folders[i].Name < folders[j].Name
-		}
-	})
+	sortFolders(folders, sortBy, order)
 
 	return folders, nil
 }
 
+func (i *jsonFile) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	if _, exists = user.Folders[folder.Name]; exists {
+		return nil, fmt.Errorf("the %s has already existed", folder.Name)
+	}
+
+	restored, err := restored(owner, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Folders[restored.Name] = restored
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+func (i *jsonFile) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(owner, foldername, target, newFoldername, true)
+}
+
+func (i *jsonFile) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(owner, foldername, target, newFoldername, false)
+}
+
+// moveFolder is used to move the folder foldername of owner to newFoldername of target, or copy it,
+// in a single write.
+func (i *jsonFile) moveFolder(
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+	copied bool,
+) (item *model.Folder, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[foldername]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	to, exists := i.users[target.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", target.Username)
+	}
+
+	err = model.ValidateInput(newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, exists = to.Folders[newFoldername]; exists {
+		return nil, fmt.Errorf("the %s has already existed", newFoldername)
+	}
+
+	item = movedFolder(owner, to, folder, newFoldername, copied)
+	if !copied {
+		delete(user.Folders, foldername)
+	}
+	to.Folders[newFoldername] = item
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *jsonFile) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return i.share(owner, foldername, grantee, access)
+}
+
+func (i *jsonFile) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return i.share(owner, foldername, grantee, "")
+}
+
+// share is used to grant grantee access to the folder foldername of owner, or revoke it when access is empty.
+func (i *jsonFile) share(owner *model.User, foldername string, grantee *model.User, access model.Access) (err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[foldername]
+	if !exists {
+		return fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	if _, exists = i.users[grantee.Username]; !exists {
+		return fmt.Errorf("the %s doesn't exist", grantee.Username)
+	}
+
+	return i.grant(folder, grantee, access)
+}
+
+// grant is used to update the shares of folder and save them, the lock must be held.
+func (i *jsonFile) grant(folder *model.Folder, grantee *model.User, access model.Access) (err error) {
+	shares, err := granted(folder.Shares, folder.Name, grantee.Username, access)
+	if err != nil {
+		return err
+	}
+
+	previous := folder.Shares
+	folder.Shares = shares
+
+	err = i.Save()
+	if err != nil {
+		folder.Shares = previous
+		return err
+	}
+
+	return nil
+}
+
+func (i *jsonFile) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if _, exists := i.users[grantee.Username]; !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", grantee.Username)
+	}
+
+	for username, user := range i.users {
+		if username == grantee.Username {
+			continue
+		}
+
+		var folders []*model.Folder
+		for _, folder := range user.Folders {
+			folders = append(folders, folder)
+		}
+
+		items = append(items, sharesOf(username, folders, grantee.Username)...)
+	}
+
+	sortShares(items)
+
+	return items, nil
+}
+
+func (i *jsonFile) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(owner, foldername, tags, false)
+}
+
+func (i *jsonFile) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(owner, foldername, tags, true)
+}
+
+// tagFolder is used to add tags to the folder foldername of owner, or remove them when removed is set.
+func (i *jsonFile) tagFolder(owner *model.User, foldername string, tags []string, removed bool) (*model.Folder, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[foldername]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	updated, err := tagged(folder.Tags, foldername, tags, removed)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := folder.Tags
+	folder.Tags = updated
+
+	err = i.Save()
+	if err != nil {
+		folder.Tags = previous
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// ListTagged filters the folders in memory, which are indexed by the document itself.
+func (i *jsonFile) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	for _, folder := range user.Folders {
+		if folder.HasTag(tag) {
+			items = append(items, folder)
+		}
+	}
+
+	sortFolders(items, sortBy, order)
+
+	return items, nil
+}
+
 func (i *jsonFile) CreateFile(
 	ctx context.Context,
 	owner *model.User,
@@ -278,51 +533,257 @@ func (i *jsonFile) ListFiles(
 		files = append(files, file)
 	}
 
-	sort.Slice(files, func(i, j int) bool {
-		switch sortBy {
-		case "name":
-			if order == orderAsc {
-				return files[i].Name < files[j].Name
-			}
-			return files[i].Name > files[j].Name
-		case "created":
-			if order == orderAsc {
-				return files[i].CreatedAt.Before(files[j].CreatedAt)
-			}
-			return files[i].CreatedAt.After(files[j].CreatedAt)
-		default:
-			return files[i].Name < files[j].Name
-		}
-	})
+	sortFiles(files, sortBy, order)
 
 	return files, nil
 }
 
-// Save is used to save the data to the file.
-func (i *jsonFile) Save() (err error) {
-	// Ensure the directory exists
-	if err = utils.EnsureDir(i.path); err != nil {
-		return fmt.Errorf("failed to ensure directory: %w", err)
+func (i *jsonFile) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(owner, dir, filename, tags, false)
+}
+
+func (i *jsonFile) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(owner, dir, filename, tags, true)
+}
+
+// tagFile is used to add tags to the file filename in dir, or remove them when removed is set.
+func (i *jsonFile) tagFile(
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+	removed bool,
+) (*model.File, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[dir.Name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", dir.Name)
+	}
+
+	file, exists := folder.Files[filename]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", filename)
+	}
+
+	updated, err := tagged(file.Tags, filename, tags, removed)
+	if err != nil {
+		return nil, err
 	}
 
-	data, err := json.MarshalIndent(i.users, "", "  ")
+	previous := file.Tags
+	file.Tags = updated
+
+	err = i.Save()
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		file.Tags = previous
+		return nil, err
 	}
 
-	return os.WriteFile(i.path, data, 0600)
+	return file, nil
 }
 
-// Load is used to load the data from the file.
-func (i *jsonFile) Load() (err error) {
-	data, err := os.ReadFile(i.path)
+func (i *jsonFile) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[dir.Name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", dir.Name)
+	}
+
+	for _, file := range folder.Files {
+		if file.HasTag(tag) {
+			items = append(items, file)
+		}
+	}
+
+	sortFiles(items, sortBy, order)
+
+	return items, nil
+}
+
+func (i *jsonFile) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(owner, dir, filename, dir, newFilename, conflict, false)
+}
+
+func (i *jsonFile) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(owner, dir, filename, target, filename, conflict, false)
+}
+
+func (i *jsonFile) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(owner, dir, filename, target, newFilename, conflict, true)
+}
+
+// move is used to move filename of dir to newFilename of target, or copy it, in a single write.
+func (i *jsonFile) move(
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+	copied bool,
+) (item *model.File, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[owner.Username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[dir.Name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", dir.Name)
+	}
+
+	file, exists := folder.Files[filename]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", filename)
+	}
+
+	to, exists := user.Folders[target.Name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", target.Name)
+	}
+
+	err = checkMove(folder, filename, to, newFilename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
+		return nil, err
+	}
+
+	if _, exists = to.Files[newFilename]; exists {
+		skip, err := skipExisting(newFilename, conflict)
+		if skip || err != nil {
+			return nil, err
+		}
+	}
+
+	item = moved(owner, to, file, newFilename, copied)
+	if !copied {
+		delete(folder.Files, filename)
+	}
+	to.Files[newFilename] = item
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *jsonFile) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return begin(i, i.apply), nil
+}
+
+// apply is used to commit a transaction, writing the document once.
+func (i *jsonFile) apply(ctx context.Context, owners map[string]*model.User, base, staged tree) error {
+	i.Lock()
+	defer i.Unlock()
+
+	for username, folders := range base {
+		user, exists := i.users[username]
+		if !exists {
+			return fmt.Errorf("the %s doesn't exist", username)
+		}
+
+		err := checkUnchanged(username, folders, user.Folders)
+		if err != nil {
+			return err
 		}
+	}
+
+	previous := make(map[string]map[string]*model.Folder, len(staged))
+	for username, folders := range staged {
+		user := i.users[username]
+		previous[username] = user.Folders
+
+		user.Folders = make(map[string]*model.Folder, len(folders))
+		for foldername, folder := range folders {
+			user.Folders[foldername] = copyFolder(user, folder)
+		}
+	}
+
+	err := i.Save()
+	if err != nil {
+		for username, folders := range previous {
+			i.users[username].Folders = folders
+		}
+
+		return err
+	}
 
-		return fmt.Errorf("failed to read file: %w", err)
+	return nil
+}
+
+// Save is used to save the data to the file.
+func (i *jsonFile) Save() (err error) {
+	if i.save != nil {
+		return i.save()
 	}
 
-	return json.Unmarshal(data, &i.users)
+	// the staged copy of a transaction has no path, it's only kept in memory
+	if i.path == "" {
+		return nil
+	}
+
+	return store.WriteFile(i.path, i.users)
+}
+
+// Load is used to load the data from the file.
+func (i *jsonFile) Load() (err error) {
+	return store.ReadFile(i.path, &i.users)
 }
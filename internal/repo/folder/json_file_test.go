@@ -125,7 +125,7 @@ func Test_jsonFile_GetByName(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: tt.fields.users,
 				path:  tt.fields.path,
 			}
@@ -194,7 +194,7 @@ func Test_jsonFile_Create(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -258,7 +258,7 @@ func Test_jsonFile_Delete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -338,7 +338,7 @@ func Test_jsonFile_Rename(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -411,7 +411,7 @@ func Test_jsonFile_List(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -500,7 +500,7 @@ func Test_jsonFile_CreateFile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -578,7 +578,7 @@ func Test_jsonFile_DeleteFile(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
@@ -665,7 +665,7 @@ func Test_jsonFile_ListFiles(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
-				Mutex: sync.Mutex{},
+				Mutex: &sync.Mutex{},
 				users: map[string]*model.User{
 					user1.Username: user1,
 				},
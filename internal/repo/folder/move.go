@@ -0,0 +1,72 @@
+package folder
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// checkMove is used to validate moving or copying filename of folder to newFilename of target.
+func checkMove(folder *model.Folder, filename string, target *model.Folder, newFilename string) error {
+	err := model.ValidateInput(newFilename)
+	if err != nil {
+		return err
+	}
+
+	if folder.Name == target.Name && filename == newFilename {
+		return fmt.Errorf("the %s can't replace itself", filename)
+	}
+
+	return nil
+}
+
+// skipExisting is used to resolve an existing destination newFilename with conflict,
+// failing or telling whether the move or copy is skipped.
+func skipExisting(newFilename string, conflict model.Conflict) (skip bool, err error) {
+	switch conflict {
+	case model.ConflictOverwrite:
+		return false, nil
+	case model.ConflictSkip:
+		return true, nil
+	default:
+		return false, fmt.Errorf("the %s has already existed", newFilename)
+	}
+}
+
+// moved is used to create the file newFilename of target moved from file, or copied which creates it now.
+func moved(owner *model.User, target *model.Folder, file *model.File, newFilename string, copied bool) *model.File {
+	createdAt := file.CreatedAt
+	if copied {
+		createdAt = time.Now()
+	}
+
+	return &model.File{
+		Name:        newFilename,
+		Description: file.Description,
+		CreatedAt:   createdAt,
+		Owner:       owner,
+		Folder:      target,
+		Tags:        slices.Clone(file.Tags),
+	}
+}
+
+// movedFolder is used to create the folder newFoldername of target moved from folder of owner with its files,
+// or copied which creates them now. Only a folder staying with its owner keeps its shares.
+func movedFolder(owner, target *model.User, folder *model.Folder, newFoldername string, copied bool) *model.Folder {
+	item := copyFolder(target, folder)
+	item.Name = newFoldername
+	if copied || owner.Username != target.Username {
+		item.Shares = nil
+	}
+	if copied {
+		now := time.Now()
+		item.CreatedAt = now
+		for _, file := range item.Files {
+			file.CreatedAt = now
+		}
+	}
+
+	return item
+}
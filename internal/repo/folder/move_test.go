@@ -0,0 +1,349 @@
+package folder
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// filesOf is used to list the files of the folder foldername of owner in i by name.
+func filesOf(t *testing.T, i repo.FolderManager, owner *model.User, foldername string) map[string]*model.File {
+	t.Helper()
+	ctx := context.Background()
+
+	folder, err := i.GetByName(ctx, owner, foldername)
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+
+	files, err := i.ListFiles(ctx, owner, folder, "name", "asc")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+
+	got := make(map[string]*model.File, len(files))
+	for _, file := range files {
+		got[file.Name] = file
+	}
+
+	return got
+}
+
+// checkFileMoves is used to check that i renames, moves and copies the files of owner.
+func checkFileMoves(t *testing.T, i repo.FolderManager, owner *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	folder1, _ := i.Create(ctx, owner, "folder1", "")
+	folder2, _ := i.Create(ctx, owner, "folder2", "")
+	file1, err := i.CreateFile(ctx, owner, folder1, "file1", "description of file1")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	_, _ = i.CreateFile(ctx, owner, folder1, "file2", "description of file2")
+	_, _ = i.CreateFile(ctx, owner, folder2, "file3", "description of file3")
+
+	_, err = i.RenameFile(ctx, owner, folder1, "missing", "file4", model.ConflictFail)
+	if err == nil {
+		t.Errorf("RenameFile() a missing file error = nil, want error")
+	}
+	_, err = i.RenameFile(ctx, owner, folder1, "file1", "invalid name", model.ConflictFail)
+	if err == nil {
+		t.Errorf("RenameFile() to an invalid name error = nil, want error")
+	}
+	_, err = i.RenameFile(ctx, owner, folder1, "file1", "file1", model.ConflictOverwrite)
+	if err == nil {
+		t.Errorf("RenameFile() to itself error = nil, want error")
+	}
+	_, err = i.RenameFile(ctx, owner, folder1, "file1", "file2", model.ConflictFail)
+	if err == nil {
+		t.Errorf("RenameFile() to an existing file error = nil, want error")
+	}
+
+	item, err := i.RenameFile(ctx, owner, folder1, "file1", "file2", model.ConflictSkip)
+	if err != nil || item != nil {
+		t.Errorf("RenameFile() skipped got = %v, error = %v, want nil", item, err)
+	}
+	if got := filesOf(t, i, owner, "folder1"); len(got) != 2 || got["file2"].Description != "description of file2" {
+		t.Errorf("ListFiles() after a skip got = %v", got)
+	}
+
+	item, err = i.RenameFile(ctx, owner, folder1, "file1", "file4", model.ConflictFail)
+	if err != nil || item.Name != "file4" {
+		t.Fatalf("RenameFile() got = %v, error = %v", item, err)
+	}
+	got := filesOf(t, i, owner, "folder1")
+	if _, exists := got["file1"]; exists || got["file4"] == nil {
+		t.Fatalf("ListFiles() after RenameFile() got = %v", got)
+	}
+	if got["file4"].Description != file1.Description || !got["file4"].CreatedAt.Equal(file1.CreatedAt) {
+		t.Errorf("RenameFile() got = %+v, want the description and created time of %+v", got["file4"], file1)
+	}
+
+	item, err = i.MoveFile(ctx, owner, folder1, "file4", folder2, model.ConflictFail)
+	if err != nil || item.Folder.Name != "folder2" {
+		t.Fatalf("MoveFile() got = %v, error = %v", item, err)
+	}
+	if got = filesOf(t, i, owner, "folder1"); len(got) != 1 || got["file2"] == nil {
+		t.Errorf("ListFiles() of the source after MoveFile() got = %v", got)
+	}
+	got = filesOf(t, i, owner, "folder2")
+	if len(got) != 2 || got["file4"] == nil || !got["file4"].CreatedAt.Equal(file1.CreatedAt) {
+		t.Errorf("ListFiles() of the target after MoveFile() got = %v", got)
+	}
+
+	item, err = i.CopyFile(ctx, owner, folder2, "file4", folder1, "file2", model.ConflictOverwrite)
+	if err != nil || item == nil {
+		t.Fatalf("CopyFile() got = %v, error = %v", item, err)
+	}
+	got = filesOf(t, i, owner, "folder1")
+	if len(got) != 1 || got["file2"].Description != file1.Description || !got["file2"].CreatedAt.After(file1.CreatedAt) {
+		t.Errorf("ListFiles() after CopyFile() got = %+v, want a new copy of file4", got["file2"])
+	}
+	if got = filesOf(t, i, owner, "folder2"); len(got) != 2 || got["file4"] == nil {
+		t.Errorf("ListFiles() of the source after CopyFile() got = %v", got)
+	}
+
+	_, err = i.CopyFile(ctx, owner, folder2, "file3", folder2, "file5", model.ConflictFail)
+	if err != nil {
+		t.Errorf("CopyFile() in the same folder error = %v", err)
+	}
+	if got = filesOf(t, i, owner, "folder2"); len(got) != 3 {
+		t.Errorf("ListFiles() after CopyFile() in the same folder got = %v", got)
+	}
+}
+
+func Test_jsonFile_MoveFile(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkFileMoves(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+		path:  "out/vfs.json",
+	}, owner)
+}
+
+func Test_staged_MoveFile(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	i := &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+	}
+
+	tx, _ := i.Begin(context.Background())
+	checkFileMoves(t, tx, owner)
+
+	err := tx.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := filesOf(t, i, owner, "folder2"); len(got) != 3 {
+		t.Errorf("ListFiles() after Commit() got = %v", got)
+	}
+}
+
+func Test_objects_MoveFile(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkFileMoves(t, i, owner)
+}
+
+func Test_hashes_MoveFile(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkFileMoves(t, i, owner)
+}
+
+// checkFolderMoves is used to check that i copies and moves the folders of owner, including to other.
+func checkFolderMoves(t *testing.T, i repo.FolderManager, owner, other *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	folder1, err := i.Create(ctx, owner, "folder1", "description of folder1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	file1, _ := i.CreateFile(ctx, owner, folder1, "file1", "description of file1")
+	_, _ = i.CreateFile(ctx, owner, folder1, "file2", "description of file2")
+
+	_, err = i.CopyFolder(ctx, owner, "missing", owner, "folder2")
+	if err == nil {
+		t.Errorf("CopyFolder() a missing folder error = nil, want error")
+	}
+	_, err = i.CopyFolder(ctx, owner, "folder1", &model.User{Username: "user3"}, "folder1")
+	if err == nil {
+		t.Errorf("CopyFolder() to a missing user error = nil, want error")
+	}
+	_, err = i.CopyFolder(ctx, owner, "folder1", owner, "invalid name")
+	if err == nil {
+		t.Errorf("CopyFolder() to an invalid name error = nil, want error")
+	}
+	_, err = i.MoveFolder(ctx, owner, "folder1", owner, "folder1")
+	if err == nil {
+		t.Errorf("MoveFolder() to itself error = nil, want error")
+	}
+
+	item, err := i.CopyFolder(ctx, owner, "folder1", other, "folder1")
+	if err != nil {
+		t.Fatalf("CopyFolder() error = %v", err)
+	}
+	if item.Owner.Username != other.Username || !item.CreatedAt.After(folder1.CreatedAt) {
+		t.Errorf("CopyFolder() got = %+v, want a new folder of %s", item, other.Username)
+	}
+	for _, file := range item.Files {
+		if file.Owner != item.Owner || file.Folder != item {
+			t.Errorf("CopyFolder() got file %+v, want it to refer to the copy", file)
+		}
+	}
+	got := filesOf(t, i, other, "folder1")
+	if len(got) != 2 || got["file1"].Description != file1.Description || !got["file1"].CreatedAt.After(file1.CreatedAt) {
+		t.Errorf("ListFiles() of the copy got = %+v", got["file1"])
+	}
+	if got = filesOf(t, i, owner, "folder1"); len(got) != 2 {
+		t.Errorf("ListFiles() of the source after CopyFolder() got = %v", got)
+	}
+
+	_, err = i.CopyFolder(ctx, owner, "folder1", other, "folder1")
+	if err == nil {
+		t.Errorf("CopyFolder() to an existing folder error = nil, want error")
+	}
+
+	item, err = i.MoveFolder(ctx, owner, "folder1", other, "folder2")
+	if err != nil {
+		t.Fatalf("MoveFolder() error = %v", err)
+	}
+	if item.Description != folder1.Description || !item.CreatedAt.Equal(folder1.CreatedAt) {
+		t.Errorf("MoveFolder() got = %+v, want the description and created time of %+v", item, folder1)
+	}
+	if folders := names(t, i, owner); len(folders) != 0 {
+		t.Errorf("List() of the source after MoveFolder() got = %v, want none", folders)
+	}
+	got = filesOf(t, i, other, "folder2")
+	if len(got) != 2 || !got["file1"].CreatedAt.Equal(file1.CreatedAt) {
+		t.Errorf("ListFiles() after MoveFolder() got = %v", got)
+	}
+
+	_, err = i.MoveFolder(ctx, other, "folder2", other, "folder3")
+	if err != nil {
+		t.Errorf("MoveFolder() to a new name error = %v", err)
+	}
+	if folders := names(t, i, other); len(folders) != 2 || folders[1] != "folder3" {
+		t.Errorf("List() after MoveFolder() to a new name got = %v, want [folder1 folder3]", folders)
+	}
+}
+
+func Test_jsonFile_MoveFolder(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	other, _ := model.NewUser("user2")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkFolderMoves(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner, other.Username: other},
+		path:  "out/vfs.json",
+	}, owner, other)
+}
+
+func Test_sharded_MoveFolder(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	for _, user := range []*model.User{owner, other} {
+		_ = store.WriteFile(store.ShardPath(path, user.Username), map[string]*model.User{
+			user.Username: {Username: user.Username, Folders: map[string]*model.Folder{}},
+		})
+	}
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	checkFolderMoves(t, i, owner, other)
+}
+
+func Test_staged_MoveFolder(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	other, _ := model.NewUser("user2")
+	i := &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner, other.Username: other},
+	}
+
+	tx, _ := i.Begin(context.Background())
+	checkFolderMoves(t, tx, owner, other)
+
+	err := tx.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if folders := names(t, i, other); len(folders) != 2 {
+		t.Errorf("List() after Commit() got = %v, want [folder1 folder3]", folders)
+	}
+}
+
+func Test_objects_MoveFolder(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(other.Username), other)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkFolderMoves(t, i, owner, other)
+}
+
+func Test_hashes_MoveFolder(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+	server.HSet(keyspace.UserKey(other.Username), "username", other.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkFolderMoves(t, i, owner, other)
+}
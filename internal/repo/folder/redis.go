@@ -0,0 +1,1225 @@
+package folder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxRetries bounds the retries of a transaction aborted by a concurrent write to a watched key.
+const maxRetries = 10
+
+type hashes struct {
+	rdb *redis.Client
+}
+
+// NewRedis is used to create a new FolderManager backed by Redis hashes, indexed by sorted sets
+// scored by created time so the lists sorted by created time are served by Redis.
+func NewRedis(path string) (repo.FolderManager, error) {
+	rdb, err := keyspace.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hashes{
+		rdb: rdb,
+	}, nil
+}
+
+func (i *hashes) GetByName(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return getFolder(ctx, i.rdb, owner, foldername)
+}
+
+func (i *hashes) Create(
+	ctx context.Context,
+	owner *model.User,
+	foldername, description string,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := model.NewFolder(owner, foldername, description)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FolderKey(owner.Username, foldername)
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return fmt.Errorf("the %s has already existed", foldername)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fieldsOf(folder.Name, folder.Description, folder.CreatedAt))
+			pipe.ZAdd(ctx, keyspace.FoldersKey(owner.Username), redis.Z{
+				Score:  score(folder.CreatedAt),
+				Member: foldername,
+			})
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (i *hashes) Delete(ctx context.Context, owner *model.User, foldername string) (err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	key := keyspace.FolderKey(owner.Username, foldername)
+	filesKey := keyspace.FilesKey(owner.Username, foldername)
+
+	return i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, foldername)
+		if err != nil {
+			return err
+		}
+
+		filenames, err := tx.ZRange(ctx, filesKey, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, filename := range filenames {
+				pipe.Del(ctx, keyspace.FileKey(owner.Username, foldername, filename))
+			}
+			pipe.Del(ctx, filesKey, key)
+			pipe.ZRem(ctx, keyspace.FoldersKey(owner.Username), foldername)
+			indexFolder(ctx, pipe, owner.Username, foldername, folder.Tags, true)
+			return nil
+		})
+		return err
+	}, key, filesKey)
+}
+
+func (i *hashes) Rename(
+	ctx context.Context,
+	owner *model.User,
+	foldername, newFoldername string,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = model.ValidateInput(newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	oldKey := keyspace.FolderKey(owner.Username, foldername)
+	newKey := keyspace.FolderKey(owner.Username, newFoldername)
+	oldFilesKey := keyspace.FilesKey(owner.Username, foldername)
+	newFilesKey := keyspace.FilesKey(owner.Username, newFoldername)
+
+	// the folder, its files and the target name are watched, so the rename is atomic
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, foldername)
+		if err != nil {
+			return err
+		}
+
+		exists, err := tx.Exists(ctx, newKey).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return fmt.Errorf("the %s has already existed", newFoldername)
+		}
+
+		files, err := tx.ZRangeWithScores(ctx, oldFilesKey, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+
+		fields := make([]map[string]string, len(files))
+		for n, file := range files {
+			fields[n], err = tx.HGetAll(ctx, keyspace.FileKey(owner.Username, foldername, file.Member.(string))).Result()
+			if err != nil {
+				return err
+			}
+		}
+
+		folder.Name = newFoldername
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, newKey, folderFields(folder))
+			pipe.ZAdd(ctx, keyspace.FoldersKey(owner.Username), redis.Z{
+				Score:  score(folder.CreatedAt),
+				Member: newFoldername,
+			})
+			indexFolder(ctx, pipe, owner.Username, foldername, folder.Tags, true)
+			indexFolder(ctx, pipe, owner.Username, newFoldername, folder.Tags, false)
+			for n, file := range files {
+				filename := file.Member.(string)
+				pipe.HSet(ctx, keyspace.FileKey(owner.Username, newFoldername, filename), fields[n])
+				pipe.Del(ctx, keyspace.FileKey(owner.Username, foldername, filename))
+				indexFile(ctx, pipe, owner.Username, newFoldername, filename, parseTags(fields[n]), false)
+			}
+			if len(files) > 0 {
+				pipe.ZAdd(ctx, newFilesKey, files...)
+			}
+			pipe.Del(ctx, oldKey, oldFilesKey)
+			pipe.ZRem(ctx, keyspace.FoldersKey(owner.Username), foldername)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = folder
+		return nil
+	}, oldKey, oldFilesKey, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) List(
+	ctx context.Context,
+	owner *model.User,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	foldernames, err := rangeOf(ctx, i.rdb, keyspace.FoldersKey(owner.Username), sortBy, order)
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []*model.Folder
+	for _, foldername := range foldernames {
+		folder, err := getFolder(ctx, i.rdb, owner, foldername)
+		if err != nil {
+			return nil, err
+		}
+
+		folders = append(folders, folder)
+	}
+
+	if sortBy != "created" {
+		sortFolders(folders, sortBy, order)
+	}
+
+	return folders, nil
+}
+
+func (i *hashes) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := restored(owner, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FolderKey(owner.Username, restored.Name)
+	filesKey := keyspace.FilesKey(owner.Username, restored.Name)
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return fmt.Errorf("the %s has already existed", restored.Name)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, folderFields(restored))
+			pipe.ZAdd(ctx, keyspace.FoldersKey(owner.Username), redis.Z{
+				Score:  score(restored.CreatedAt),
+				Member: restored.Name,
+			})
+			indexFolder(ctx, pipe, owner.Username, restored.Name, restored.Tags, false)
+			pipe.Del(ctx, filesKey)
+			for _, file := range restored.Files {
+				putFile(ctx, pipe, owner.Username, file)
+			}
+			return nil
+		})
+		return err
+	}, key, filesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+func (i *hashes) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, true)
+}
+
+func (i *hashes) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, false)
+}
+
+// moveFolder is used to move the folder foldername of owner to newFoldername of target, or copy it,
+// in a single MULTI watching both folders.
+func (i *hashes) moveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+	copied bool,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.checkOwner(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	err = model.ValidateInput(newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FolderKey(owner.Username, foldername)
+	filesKey := keyspace.FilesKey(owner.Username, foldername)
+	newKey := keyspace.FolderKey(target.Username, newFoldername)
+	newFilesKey := keyspace.FilesKey(target.Username, newFoldername)
+
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, foldername)
+		if err != nil {
+			return err
+		}
+
+		exists, err := tx.Exists(ctx, newKey).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return fmt.Errorf("the %s has already existed", newFoldername)
+		}
+
+		filenames, err := tx.ZRange(ctx, filesKey, 0, -1).Result()
+		if err != nil {
+			return err
+		}
+		for _, filename := range filenames {
+			fields, err := tx.HGetAll(ctx, keyspace.FileKey(owner.Username, foldername, filename)).Result()
+			if err != nil {
+				return fmt.Errorf("failed to get %s: %w", filename, err)
+			}
+
+			name, description, createdAt := parseFields(fields)
+			folder.Files[name] = &model.File{
+				Name:        name,
+				Description: description,
+				CreatedAt:   createdAt,
+				Tags:        parseTags(fields),
+			}
+		}
+
+		moved := movedFolder(owner, target, folder, newFoldername, copied)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if !copied {
+				for _, file := range folder.Files {
+					pipe.Del(ctx, keyspace.FileKey(owner.Username, foldername, file.Name))
+					indexFile(ctx, pipe, owner.Username, foldername, file.Name, file.Tags, true)
+				}
+				pipe.Del(ctx, key, filesKey)
+				pipe.ZRem(ctx, keyspace.FoldersKey(owner.Username), foldername)
+				indexFolder(ctx, pipe, owner.Username, foldername, folder.Tags, true)
+			}
+
+			pipe.HSet(ctx, newKey, folderFields(moved))
+			pipe.ZAdd(ctx, keyspace.FoldersKey(target.Username), redis.Z{
+				Score:  score(moved.CreatedAt),
+				Member: moved.Name,
+			})
+			indexFolder(ctx, pipe, target.Username, moved.Name, moved.Tags, false)
+			pipe.Del(ctx, newFilesKey)
+			for _, file := range moved.Files {
+				putFile(ctx, pipe, target.Username, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = moved
+		return nil
+	}, key, filesKey, newKey, newFilesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return i.share(ctx, owner, foldername, grantee, access)
+}
+
+func (i *hashes) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return i.share(ctx, owner, foldername, grantee, "")
+}
+
+// share is used to update the shares of the folder foldername, watching the folder so concurrent changes
+// of its shares don't overwrite each other.
+func (i *hashes) share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	err = i.checkOwner(ctx, grantee)
+	if err != nil {
+		return err
+	}
+
+	key := keyspace.FolderKey(owner.Username, foldername)
+	return i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, foldername)
+		if err != nil {
+			return err
+		}
+
+		folder.Shares, err = granted(folder.Shares, foldername, grantee.Username, access)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HDel(ctx, key, "shares")
+			pipe.HSet(ctx, key, folderFields(folder))
+			// the sharer is only a hint for ListShared, which checks the folders themselves
+			if access != "" {
+				pipe.SAdd(ctx, keyspace.SharersKey(grantee.Username), owner.Username)
+			}
+			return nil
+		})
+		return err
+	}, key)
+}
+
+func (i *hashes) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	err = i.checkOwner(ctx, grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames, err := i.rdb.SMembers(ctx, keyspace.SharersKey(grantee.Username)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sharers of %s: %w", grantee.Username, err)
+	}
+
+	for _, username := range usernames {
+		folders, err := i.List(ctx, &model.User{Username: username}, "", "")
+		if err != nil {
+			// skip the owners who have been removed since
+			continue
+		}
+
+		items = append(items, sharesOf(username, folders, grantee.Username)...)
+	}
+
+	sortShares(items)
+
+	return items, nil
+}
+
+func (i *hashes) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(ctx, owner, foldername, tags, false)
+}
+
+func (i *hashes) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(ctx, owner, foldername, tags, true)
+}
+
+// tagFolder is used to update the tags of the folder foldername and their sets, watching the folder so
+// concurrent changes of its tags don't overwrite each other.
+func (i *hashes) tagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+	removed bool,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FolderKey(owner.Username, foldername)
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, foldername)
+		if err != nil {
+			return err
+		}
+
+		folder.Tags, err = tagged(folder.Tags, foldername, tags, removed)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HDel(ctx, key, "tags")
+			pipe.HSet(ctx, key, folderFields(folder))
+			indexFolder(ctx, pipe, owner.Username, foldername, tags, removed)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = folder
+		return nil
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	foldernames, err := i.rdb.SMembers(ctx, keyspace.TagKey(owner.Username, tag)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the folders tagged %s: %w", tag, err)
+	}
+
+	for _, foldername := range foldernames {
+		folder, err := getFolder(ctx, i.rdb, owner, foldername)
+		if err != nil {
+			// skip the folders which have been removed since
+			continue
+		}
+
+		if folder.HasTag(tag) {
+			items = append(items, folder)
+		}
+	}
+
+	sortFolders(items, sortBy, order)
+
+	return items, nil
+}
+
+func (i *hashes) CreateFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, description string,
+) (item *model.File, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	folderKey := keyspace.FolderKey(owner.Username, dir.Name)
+	key := keyspace.FileKey(owner.Username, dir.Name, filename)
+
+	// the folder is watched, so a file is never added to a folder being deleted or renamed
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, dir.Name)
+		if err != nil {
+			return err
+		}
+
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			return fmt.Errorf("the %s has already existed", filename)
+		}
+
+		file, err := model.NewFile(owner, folder, filename, description)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fileFields(file))
+			pipe.ZAdd(ctx, keyspace.FilesKey(owner.Username, folder.Name), redis.Z{
+				Score:  score(file.CreatedAt),
+				Member: filename,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = file
+		return nil
+	}, folderKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) DeleteFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+) (err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	key := keyspace.FileKey(owner.Username, dir.Name, filename)
+
+	return i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, dir.Name)
+		if err != nil {
+			return err
+		}
+
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("the %s doesn't exist", filename)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			pipe.ZRem(ctx, keyspace.FilesKey(owner.Username, folder.Name), filename)
+			indexFile(ctx, pipe, owner.Username, folder.Name, filename, parseTags(fields), true)
+			return nil
+		})
+		return err
+	}, keyspace.FolderKey(owner.Username, dir.Name), key)
+}
+
+func (i *hashes) ListFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := getFolder(ctx, i.rdb, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames, err := rangeOf(ctx, i.rdb, keyspace.FilesKey(owner.Username, folder.Name), sortBy, order)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*model.File
+	for _, filename := range filenames {
+		fields, err := i.rdb.HGetAll(ctx, keyspace.FileKey(owner.Username, folder.Name, filename)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+
+		name, description, createdAt := parseFields(fields)
+		files = append(files, &model.File{
+			Name:        name,
+			Description: description,
+			CreatedAt:   createdAt,
+			Owner:       owner,
+			Folder:      folder,
+			Tags:        parseTags(fields),
+		})
+	}
+
+	if sortBy != "created" {
+		sortFiles(files, sortBy, order)
+	}
+
+	return files, nil
+}
+
+func (i *hashes) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(ctx, owner, dir, filename, tags, false)
+}
+
+func (i *hashes) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(ctx, owner, dir, filename, tags, true)
+}
+
+// tagFile is used to update the tags of filename in dir and their sets, watching the folder and the file.
+func (i *hashes) tagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+	removed bool,
+) (item *model.File, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FileKey(owner.Username, dir.Name, filename)
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		folder, err := getFolder(ctx, tx, owner, dir.Name)
+		if err != nil {
+			return err
+		}
+
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("the %s doesn't exist", filename)
+		}
+
+		name, description, createdAt := parseFields(fields)
+		file := &model.File{Name: name, Description: description, CreatedAt: createdAt, Owner: owner, Folder: folder}
+		file.Tags, err = tagged(parseTags(fields), filename, tags, removed)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HDel(ctx, key, "tags")
+			pipe.HSet(ctx, key, fileFields(file))
+			indexFile(ctx, pipe, owner.Username, folder.Name, filename, tags, removed)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = file
+		return nil
+	}, keyspace.FolderKey(owner.Username, dir.Name), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := getFolder(ctx, i.rdb, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames, err := i.rdb.SMembers(ctx, keyspace.FileTagKey(owner.Username, folder.Name, tag)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the files tagged %s: %w", tag, err)
+	}
+
+	// the set is only a hint, the files removed or untagged since are skipped
+	for _, filename := range filenames {
+		fields, err := i.rdb.HGetAll(ctx, keyspace.FileKey(owner.Username, folder.Name, filename)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		name, description, createdAt := parseFields(fields)
+		file := &model.File{
+			Name:        name,
+			Description: description,
+			CreatedAt:   createdAt,
+			Owner:       owner,
+			Folder:      folder,
+			Tags:        parseTags(fields),
+		}
+		if file.HasTag(tag) {
+			items = append(items, file)
+		}
+	}
+
+	sortFiles(items, sortBy, order)
+
+	return items, nil
+}
+
+func (i *hashes) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, dir, newFilename, conflict, false)
+}
+
+func (i *hashes) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, target, filename, conflict, false)
+}
+
+func (i *hashes) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, target, newFilename, conflict, true)
+}
+
+// move is used to move filename of dir to newFilename of target, or copy it, in a single MULTI watching
+// both folders and both files.
+func (i *hashes) move(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+	copied bool,
+) (item *model.File, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	key := keyspace.FileKey(owner.Username, dir.Name, filename)
+	newKey := keyspace.FileKey(owner.Username, target.Name, newFilename)
+
+	err = i.transaction(ctx, func(tx *redis.Tx) error {
+		item = nil
+
+		folder, err := getFolder(ctx, tx, owner, dir.Name)
+		if err != nil {
+			return err
+		}
+
+		fields, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", filename, err)
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("the %s doesn't exist", filename)
+		}
+
+		to, err := getFolder(ctx, tx, owner, target.Name)
+		if err != nil {
+			return err
+		}
+
+		err = checkMove(folder, filename, to, newFilename)
+		if err != nil {
+			return err
+		}
+
+		exists, err := tx.Exists(ctx, newKey).Result()
+		if err != nil {
+			return err
+		}
+		if exists != 0 {
+			skip, err := skipExisting(newFilename, conflict)
+			if skip || err != nil {
+				return err
+			}
+		}
+
+		name, description, createdAt := parseFields(fields)
+		source := &model.File{Name: name, Description: description, CreatedAt: createdAt, Tags: parseTags(fields)}
+		file := moved(owner, to, source, newFilename, copied)
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if !copied {
+				pipe.Del(ctx, key)
+				pipe.ZRem(ctx, keyspace.FilesKey(owner.Username, folder.Name), filename)
+				indexFile(ctx, pipe, owner.Username, folder.Name, filename, source.Tags, true)
+			}
+			putFile(ctx, pipe, owner.Username, file)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		item = file
+		return nil
+	}, keyspace.FolderKey(owner.Username, dir.Name), keyspace.FolderKey(owner.Username, target.Name), key, newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (i *hashes) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return begin(i, i.apply), nil
+}
+
+// apply is used to commit a transaction in a single MULTI, watching every key it touches so a concurrent
+// change makes it fail rather than being overwritten.
+func (i *hashes) apply(ctx context.Context, owners map[string]*model.User, base, staged tree) error {
+	var keys []string
+	for username := range base {
+		keys = append(keys, keyspace.FoldersKey(username))
+		for _, folders := range []map[string]*model.Folder{base[username], staged[username]} {
+			for foldername := range folders {
+				keys = append(keys, keyspace.FolderKey(username, foldername), keyspace.FilesKey(username, foldername))
+			}
+		}
+	}
+
+	return i.transaction(ctx, func(tx *redis.Tx) error {
+		for username, folders := range base {
+			current, err := loadFolders(ctx, i, owners[username])
+			if err != nil {
+				return err
+			}
+
+			err = checkUnchanged(username, folders, current)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for username := range base {
+				c := diff(base[username], staged[username])
+				for _, folder := range c.removedFolders {
+					for _, file := range folder.Files {
+						pipe.Del(ctx, keyspace.FileKey(username, folder.Name, file.Name))
+						indexFile(ctx, pipe, username, folder.Name, file.Name, file.Tags, true)
+					}
+					pipe.Del(ctx, keyspace.FolderKey(username, folder.Name), keyspace.FilesKey(username, folder.Name))
+					pipe.ZRem(ctx, keyspace.FoldersKey(username), folder.Name)
+					indexFolder(ctx, pipe, username, folder.Name, folder.Tags, true)
+				}
+				for _, file := range c.removedFiles {
+					pipe.Del(ctx, keyspace.FileKey(username, file.Folder.Name, file.Name))
+					pipe.ZRem(ctx, keyspace.FilesKey(username, file.Folder.Name), file.Name)
+					indexFile(ctx, pipe, username, file.Folder.Name, file.Name, file.Tags, true)
+				}
+				for _, folder := range c.putFolders {
+					// the hash is replaced rather than updated, so no field of a previous folder is left
+					pipe.Del(ctx, keyspace.FolderKey(username, folder.Name))
+					pipe.HSet(ctx, keyspace.FolderKey(username, folder.Name), folderFields(folder))
+					pipe.ZAdd(ctx, keyspace.FoldersKey(username), redis.Z{
+						Score:  score(folder.CreatedAt),
+						Member: folder.Name,
+					})
+					indexFolder(ctx, pipe, username, folder.Name, folder.Tags, false)
+					for _, file := range folder.Files {
+						putFile(ctx, pipe, username, file)
+					}
+				}
+				for _, file := range c.putFiles {
+					putFile(ctx, pipe, username, file)
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+}
+
+// putFile is used to queue the writes of file of username.
+func putFile(ctx context.Context, pipe redis.Pipeliner, username string, file *model.File) {
+	key := keyspace.FileKey(username, file.Folder.Name, file.Name)
+
+	// the hash is replaced rather than updated, so no tags of a replaced file are left
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, fileFields(file))
+	pipe.ZAdd(ctx, keyspace.FilesKey(username, file.Folder.Name), redis.Z{
+		Score:  score(file.CreatedAt),
+		Member: file.Name,
+	})
+	indexFile(ctx, pipe, username, file.Folder.Name, file.Name, file.Tags, false)
+}
+
+// indexFolder is used to queue adding the folder foldername of username to the sets of its tags, or removing it
+// when removed is set. The sets are only a hint for ListTagged, which checks the folders themselves.
+func indexFolder(ctx context.Context, pipe redis.Pipeliner, username, foldername string, tags []string, removed bool) {
+	for _, tag := range tags {
+		if removed {
+			pipe.SRem(ctx, keyspace.TagKey(username, tag), foldername)
+		} else {
+			pipe.SAdd(ctx, keyspace.TagKey(username, tag), foldername)
+		}
+	}
+}
+
+// indexFile is the same as indexFolder for the file filename of the folder foldername.
+func indexFile(
+	ctx context.Context,
+	pipe redis.Pipeliner,
+	username, foldername, filename string,
+	tags []string,
+	removed bool,
+) {
+	for _, tag := range tags {
+		if removed {
+			pipe.SRem(ctx, keyspace.FileTagKey(username, foldername, tag), filename)
+		} else {
+			pipe.SAdd(ctx, keyspace.FileTagKey(username, foldername, tag), filename)
+		}
+	}
+}
+
+func (i *hashes) checkOwner(ctx context.Context, owner *model.User) error {
+	// invalid names can't have a hash and must not reach the keys of another user
+	if model.ValidateInput(owner.Username) != nil {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	exists, err := i.rdb.Exists(ctx, keyspace.UserKey(owner.Username)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", owner.Username, err)
+	}
+	if exists == 0 {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	return nil
+}
+
+// transaction is used to run fn in a MULTI/EXEC transaction watching keys, retried when a watched key
+// is changed concurrently.
+func (i *hashes) transaction(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	for n := 0; n < maxRetries; n++ {
+		err := i.rdb.Watch(ctx, fn, keys...)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+
+		return err
+	}
+
+	return errors.New("too many concurrent changes, try again")
+}
+
+func getFolder(ctx context.Context, rdb redis.Cmdable, owner *model.User, foldername string) (*model.Folder, error) {
+	if model.ValidateInput(foldername) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	fields, err := rdb.HGetAll(ctx, keyspace.FolderKey(owner.Username, foldername)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", foldername, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	var shares map[string]model.Access
+	if data, exists := fields["shares"]; exists {
+		err = json.Unmarshal([]byte(data), &shares)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal shares of %s: %w", foldername, err)
+		}
+	}
+
+	name, description, createdAt := parseFields(fields)
+	return &model.Folder{
+		Name:        name,
+		Description: description,
+		CreatedAt:   createdAt,
+		Owner:       owner,
+		Files:       make(map[string]*model.File),
+		Folders:     make(map[string]*model.Folder),
+		Shares:      shares,
+		Tags:        parseTags(fields),
+	}, nil
+}
+
+// rangeOf is used to read the members of the sorted set at key, by created time when sortBy is created.
+func rangeOf(ctx context.Context, rdb redis.Cmdable, key string, sortBy string, order string) ([]string, error) {
+	var cmd *redis.StringSliceCmd
+	if sortBy == "created" && order != orderAsc {
+		cmd = rdb.ZRevRange(ctx, key, 0, -1)
+	} else {
+		cmd = rdb.ZRange(ctx, key, 0, -1)
+	}
+
+	members, err := cmd.Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", key, err)
+	}
+
+	return members, nil
+}
+
+func fieldsOf(name, description string, createdAt time.Time) map[string]string {
+	return map[string]string{
+		"name":        name,
+		"description": description,
+		"created_at":  createdAt.Format(time.RFC3339Nano),
+	}
+}
+
+// folderFields returns the fields of the hash of folder, with its shares as JSON.
+func folderFields(folder *model.Folder) map[string]string {
+	fields := fieldsOf(folder.Name, folder.Description, folder.CreatedAt)
+	if len(folder.Shares) > 0 {
+		// a map of strings can't fail to marshal
+		data, _ := json.Marshal(folder.Shares)
+		fields["shares"] = string(data)
+	}
+	if len(folder.Tags) > 0 {
+		fields["tags"] = strings.Join(folder.Tags, ",")
+	}
+
+	return fields
+}
+
+// fileFields returns the fields of the hash of file.
+func fileFields(file *model.File) map[string]string {
+	fields := fieldsOf(file.Name, file.Description, file.CreatedAt)
+	if len(file.Tags) > 0 {
+		fields["tags"] = strings.Join(file.Tags, ",")
+	}
+
+	return fields
+}
+
+func parseFields(fields map[string]string) (name, description string, createdAt time.Time) {
+	createdAt, _ = time.Parse(time.RFC3339Nano, fields["created_at"])
+	return fields["name"], fields["description"], createdAt
+}
+
+// parseTags returns the tags of a hash, joined by commas which a valid tag can't contain.
+func parseTags(fields map[string]string) []string {
+	if fields["tags"] == "" {
+		return nil
+	}
+
+	return strings.Split(fields["tags"], ",")
+}
+
+// score returns the score of a member created at createdAt, in microseconds which a float64 holds exactly.
+func score(createdAt time.Time) float64 {
+	return float64(createdAt.UnixMicro())
+}
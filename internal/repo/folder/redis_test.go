@@ -0,0 +1,164 @@
+package folder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+)
+
+func Test_hashes(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	_, err = i.Create(ctx, &model.User{Username: "user2"}, "folder1", "description")
+	if err == nil {
+		t.Errorf("Create() for a missing user error = nil, want error")
+	}
+
+	// created in the reverse order of their names
+	for _, foldername := range []string{"folder3", "folder2", "folder1"} {
+		_, err = i.Create(ctx, owner, foldername, "description of "+foldername)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = i.Create(ctx, owner, "folder1", "description")
+	if err == nil {
+		t.Errorf("Create() an existing folder error = nil, want error")
+	}
+
+	tests := []struct {
+		sortBy string
+		order  string
+		want   string
+	}{
+		{sortBy: "name", order: "asc", want: "folder1"},
+		{sortBy: "name", order: "desc", want: "folder3"},
+		{sortBy: "created", order: "asc", want: "folder3"},
+		{sortBy: "created", order: "desc", want: "folder1"},
+	}
+	for _, tt := range tests {
+		folders, err := i.List(ctx, owner, tt.sortBy, tt.order)
+		if err != nil || len(folders) != 3 || folders[0].Name != tt.want {
+			t.Errorf("List(%s, %s) got = %v, error = %v", tt.sortBy, tt.order, folders, err)
+		}
+	}
+
+	folder, err := i.GetByName(ctx, owner, "folder1")
+	if err != nil || folder.Description != "description of folder1" {
+		t.Fatalf("GetByName() got = %v, error = %v", folder, err)
+	}
+
+	_, err = i.CreateFile(ctx, owner, folder, "file1", "description")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	_, err = i.CreateFile(ctx, owner, folder, "file1", "description")
+	if err == nil {
+		t.Errorf("CreateFile() an existing file error = nil, want error")
+	}
+
+	_, err = i.Rename(ctx, owner, "folder1", "folder2")
+	if err == nil {
+		t.Errorf("Rename() to an existing folder error = nil, want error")
+	}
+
+	folder, err = i.Rename(ctx, owner, "folder1", "folder4")
+	if err != nil || folder.Name != "folder4" {
+		t.Fatalf("Rename() got = %v, error = %v", folder, err)
+	}
+
+	if server.Exists(keyspace.FolderKey(owner.Username, "folder1")) ||
+		server.Exists(keyspace.FileKey(owner.Username, "folder1", "file1")) {
+		t.Errorf("Rename() left the keys of the old folder")
+	}
+
+	files, err := i.ListFiles(ctx, owner, folder, "created", "asc")
+	if err != nil || len(files) != 1 || files[0].Name != "file1" || files[0].Description != "description" {
+		t.Errorf("ListFiles() got = %v, error = %v", files, err)
+	}
+
+	err = i.DeleteFile(ctx, owner, folder, "file1")
+	if err != nil {
+		t.Errorf("DeleteFile() error = %v", err)
+	}
+
+	err = i.DeleteFile(ctx, owner, folder, "file1")
+	if err == nil {
+		t.Errorf("DeleteFile() a missing file error = nil, want error")
+	}
+
+	err = i.Delete(ctx, owner, "folder4")
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	folders, err := i.List(ctx, owner, "name", "asc")
+	if err != nil || len(folders) != 2 {
+		t.Errorf("List() after Delete() got = %v, error = %v", folders, err)
+	}
+}
+
+func Test_hashes_Rename_Concurrent(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+
+	i, _ := NewRedis("redis://" + server.Addr())
+	folder, _ := i.Create(ctx, owner, "folder1", "description")
+	_, _ = i.CreateFile(ctx, owner, folder, "file1", "description")
+
+	// every worker renames the same folder to its own name, only one may win
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+
+			worker, _ := NewRedis("redis://" + server.Addr())
+			_, err := worker.Rename(ctx, owner, "folder1", fmt.Sprintf("renamed%d", n))
+			errs <- err
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+
+	renamed := 0
+	for err := range errs {
+		if err == nil {
+			renamed++
+		}
+	}
+	if renamed != 1 {
+		t.Errorf("Rename() concurrently succeeded %d times, want 1", renamed)
+	}
+
+	folders, _ := i.List(ctx, owner, "name", "asc")
+	if len(folders) != 1 {
+		t.Fatalf("List() after the renames got = %v", folders)
+	}
+
+	files, err := i.ListFiles(ctx, owner, folders[0], "name", "asc")
+	if err != nil || len(files) != 1 {
+		t.Errorf("ListFiles() after the renames got = %v, error = %v", files, err)
+	}
+}
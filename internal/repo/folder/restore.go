@@ -0,0 +1,51 @@
+package folder
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// restored is used to copy folder with its files for owner, keeping their descriptions and created times.
+func restored(owner *model.User, folder *model.Folder) (*model.Folder, error) {
+	err := model.ValidateInput(folder.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range folder.Files {
+		err = model.ValidateInput(file.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return copyFolder(owner, folder), nil
+}
+
+// copyFolder is used to copy folder with its files for owner.
+func copyFolder(owner *model.User, folder *model.Folder) *model.Folder {
+	copied := &model.Folder{
+		Name:        folder.Name,
+		Description: folder.Description,
+		CreatedAt:   folder.CreatedAt,
+		Owner:       owner,
+		Files:       make(map[string]*model.File, len(folder.Files)),
+		Folders:     make(map[string]*model.Folder),
+		Shares:      maps.Clone(folder.Shares),
+		Tags:        slices.Clone(folder.Tags),
+	}
+	for _, file := range folder.Files {
+		copied.Files[file.Name] = &model.File{
+			Name:        file.Name,
+			Description: file.Description,
+			CreatedAt:   file.CreatedAt,
+			Owner:       owner,
+			Folder:      copied,
+			Tags:        slices.Clone(file.Tags),
+		}
+	}
+
+	return copied
+}
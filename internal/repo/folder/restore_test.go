@@ -0,0 +1,111 @@
+package folder
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+)
+
+// checkRestore is used to check that i restores a folder of owner as it was.
+func checkRestore(t *testing.T, i repo.FolderManager, owner *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	createdAt := time.Date(2024, 5, 27, 10, 30, 0, 123456000, time.UTC)
+	folder := &model.Folder{
+		Name:        "folder1",
+		Description: "restored",
+		CreatedAt:   createdAt,
+		Files:       map[string]*model.File{},
+	}
+	for n, filename := range []string{"file2", "file1"} {
+		folder.Files[filename] = &model.File{
+			Name:        filename,
+			Description: "description of " + filename,
+			CreatedAt:   createdAt.Add(time.Duration(n) * time.Second),
+		}
+	}
+
+	_, err := i.Restore(ctx, &model.User{Username: "user2"}, folder)
+	if err == nil {
+		t.Errorf("Restore() for a missing user error = nil, want error")
+	}
+
+	_, err = i.Restore(ctx, owner, &model.Folder{Name: "invalid name"})
+	if err == nil {
+		t.Errorf("Restore() an invalid name error = nil, want error")
+	}
+
+	_, err = i.Restore(ctx, owner, folder)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	_, err = i.Restore(ctx, owner, folder)
+	if err == nil {
+		t.Errorf("Restore() an existing folder error = nil, want error")
+	}
+
+	got, err := i.GetByName(ctx, owner, "folder1")
+	if err != nil || got.Description != "restored" || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("GetByName() got = %+v, error = %v", got, err)
+	}
+
+	files, err := i.ListFiles(ctx, owner, got, "created", "asc")
+	if err != nil || len(files) != 2 || files[0].Name != "file2" {
+		t.Fatalf("ListFiles() got = %v, error = %v", files, err)
+	}
+	if files[1].Description != "description of file1" || !files[1].CreatedAt.Equal(createdAt.Add(time.Second)) {
+		t.Errorf("ListFiles() got = %+v", files[1])
+	}
+}
+
+func Test_jsonFile_Restore(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkRestore(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+		path:  "out/vfs.json",
+	}, owner)
+}
+
+func Test_objects_Restore(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkRestore(t, i, owner)
+}
+
+func Test_hashes_Restore(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkRestore(t, i, owner)
+}
@@ -0,0 +1,1059 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+)
+
+// meta is the object holding the metadata of a folder or a file.
+type meta struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	CreatedAt   time.Time               `json:"created_at"`
+	Shares      map[string]model.Access `json:"shares,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+}
+
+// attempts is the number of times a change of the metadata is tried when it's changed concurrently.
+const attempts = 5
+
+type objects struct {
+	bucket *bucket.Bucket
+}
+
+// NewS3 is used to create a new FolderManager backed by the objects of an S3-compatible bucket.
+// Creations and the changes of the shares and tags are conditional writes, so concurrent writers can't
+// overwrite each other.
+func NewS3(path string) (repo.FolderManager, error) {
+	b, err := bucket.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objects{
+		bucket: b,
+	}, nil
+}
+
+func (i *objects) GetByName(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+) (item *model.Folder, err error) {
+	return i.getFolder(ctx, owner, foldername)
+}
+
+func (i *objects) Create(
+	ctx context.Context,
+	owner *model.User,
+	foldername, description string,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := model.NewFolder(owner, foldername, description)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.bucket.CreateJSON(ctx, bucket.FolderKey(owner.Username, foldername), metaOfFolder(folder))
+	if errors.Is(err, bucket.ErrExists) {
+		return nil, fmt.Errorf("the %s has already existed", foldername)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (i *objects) Delete(ctx context.Context, owner *model.User, foldername string) (err error) {
+	folder, err := i.getFolder(ctx, owner, foldername)
+	if err != nil {
+		return err
+	}
+
+	keys, err := i.bucket.List(ctx, bucket.FolderPrefix(owner.Username, foldername))
+	if err != nil {
+		return err
+	}
+	keys = append(keys, folderTagKeys(owner.Username, foldername, folder.Tags)...)
+
+	// the metadata goes last, so an interrupted delete leaves a folder that can be deleted again
+	return i.bucket.Delete(ctx, withMetaLast(keys, bucket.FolderKey(owner.Username, foldername))...)
+}
+
+func (i *objects) Rename(
+	ctx context.Context,
+	owner *model.User,
+	foldername, newFoldername string,
+) (item *model.Folder, err error) {
+	folder, err := i.getFolder(ctx, owner, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	err = model.ValidateInput(newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	// objects can't be renamed: claim the new name, copy the objects, then drop the old ones
+	folder.Name = newFoldername
+	err = i.bucket.CreateJSON(ctx, bucket.FolderKey(owner.Username, newFoldername), metaOfFolder(folder))
+	if errors.Is(err, bucket.ErrExists) {
+		return nil, fmt.Errorf("the %s has already existed", newFoldername)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	oldPrefix := bucket.FolderPrefix(owner.Username, foldername)
+	newPrefix := bucket.FolderPrefix(owner.Username, newFoldername)
+	oldKey := bucket.FolderKey(owner.Username, foldername)
+
+	err = i.mark(ctx, folderTagKeys(owner.Username, newFoldername, folder.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	// the markers of the tags of the files are under the folder, so they're copied with it
+	keys, err := i.bucket.List(ctx, oldPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key == oldKey {
+			continue
+		}
+
+		data, err := i.bucket.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.bucket.Put(ctx, newPrefix+strings.TrimPrefix(key, oldPrefix), data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys = append(keys, folderTagKeys(owner.Username, foldername, folder.Tags)...)
+	err = i.bucket.Delete(ctx, withMetaLast(keys, oldKey)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (i *objects) List(
+	ctx context.Context,
+	owner *model.User,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	foldernames, err := i.bucket.Dirs(ctx, bucket.FoldersPrefix(owner.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	var folders []*model.Folder
+	for _, foldername := range foldernames {
+		folder, err := i.getFolder(ctx, owner, foldername)
+		if err != nil {
+			// skip the leftovers of an interrupted delete or rename
+			continue
+		}
+
+		folders = append(folders, folder)
+	}
+
+	sortFolders(folders, sortBy, order)
+
+	return folders, nil
+}
+
+func (i *objects) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	restored, err := restored(owner, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	// claiming the folder first keeps the files from joining an existing folder
+	err = i.bucket.CreateJSON(ctx, bucket.FolderKey(owner.Username, restored.Name), metaOfFolder(restored))
+	if errors.Is(err, bucket.ErrExists) {
+		return nil, fmt.Errorf("the %s has already existed", restored.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.mark(ctx, folderTagKeys(owner.Username, restored.Name, restored.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range restored.Files {
+		err = i.putFile(ctx, owner, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return restored, nil
+}
+
+func (i *objects) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, true)
+}
+
+func (i *objects) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, false)
+}
+
+// moveFolder is used to move the folder foldername of owner to newFoldername of target, or copy it.
+// The copy is written first, claiming its name with a conditional write, and the source is deleted last,
+// so an interrupted move leaves both folders rather than none.
+func (i *objects) moveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+	copied bool,
+) (item *model.Folder, err error) {
+	folder, err := i.getFolder(ctx, owner, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := i.ListFiles(ctx, owner, folder, "name", orderAsc)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		folder.Files[file.Name] = file
+	}
+
+	item, err = i.Restore(ctx, target, movedFolder(owner, target, folder, newFoldername, copied))
+	if err != nil {
+		return nil, err
+	}
+
+	if !copied {
+		err = i.Delete(ctx, owner, foldername)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func (i *objects) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return i.share(ctx, owner, foldername, grantee, access)
+}
+
+func (i *objects) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return i.share(ctx, owner, foldername, grantee, "")
+}
+
+// share is used to rewrite the metadata of the folder foldername with the shares updated.
+func (i *objects) share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	err = i.checkOwner(ctx, grantee)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.updateFolder(ctx, owner, foldername, func(folder *model.Folder) error {
+		shares, err := granted(folder.Shares, foldername, grantee.Username, access)
+		if err != nil {
+			return err
+		}
+		folder.Shares = shares
+
+		// the sharer is only a hint for ListShared, which checks the folders themselves
+		if access != "" {
+			return i.bucket.Put(ctx, bucket.SharerKey(grantee.Username, owner.Username), nil)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (i *objects) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	err = i.checkOwner(ctx, grantee)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := bucket.SharersPrefix(grantee.Username)
+	keys, err := i.bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		owner := &model.User{Username: strings.TrimPrefix(key, prefix+"/")}
+		folders, err := i.List(ctx, owner, "", "")
+		if err != nil {
+			// skip the owners who have been removed since
+			continue
+		}
+
+		items = append(items, sharesOf(owner.Username, folders, grantee.Username)...)
+	}
+
+	sortShares(items)
+
+	return items, nil
+}
+
+func (i *objects) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(ctx, owner, foldername, tags, false)
+}
+
+func (i *objects) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return i.tagFolder(ctx, owner, foldername, tags, true)
+}
+
+// tagFolder is used to rewrite the metadata of the folder foldername with the tags updated, the markers of the
+// added tags being put before and the ones of the removed tags deleted after, so the markers are never missing
+// unless the same tag is added and removed concurrently, which fsck reports.
+func (i *objects) tagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+	removed bool,
+) (item *model.Folder, err error) {
+	keys := folderTagKeys(owner.Username, foldername, tags)
+	folder, err := i.updateFolder(ctx, owner, foldername, func(folder *model.Folder) error {
+		next, err := tagged(folder.Tags, foldername, tags, removed)
+		if err != nil {
+			return err
+		}
+		folder.Tags = next
+
+		if removed {
+			return nil
+		}
+
+		return i.mark(ctx, keys)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if removed {
+		err = i.bucket.Delete(ctx, keys...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return folder, nil
+}
+
+func (i *objects) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := bucket.TagPrefix(owner.Username, tag)
+	keys, err := i.bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		folder, err := i.getFolder(ctx, owner, strings.TrimPrefix(key, prefix+"/"))
+		if err != nil {
+			// skip the folders which have been removed since
+			continue
+		}
+
+		if folder.HasTag(tag) {
+			items = append(items, folder)
+		}
+	}
+
+	sortFolders(items, sortBy, order)
+
+	return items, nil
+}
+
+func (i *objects) CreateFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, description string,
+) (item *model.File, err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := model.NewFile(owner, folder, filename, description)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.bucket.CreateJSON(ctx, bucket.FileKey(owner.Username, folder.Name, filename), metaOfFile(file))
+	if errors.Is(err, bucket.ErrExists) {
+		return nil, fmt.Errorf("the %s has already existed", filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// files have no content yet, the object is kept so the layout is complete
+	err = i.bucket.Put(ctx, bucket.ContentKey(owner.Username, folder.Name, filename), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (i *objects) DeleteFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+) (err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return err
+	}
+
+	file, err := i.getFile(ctx, owner, folder, filename)
+	if err != nil {
+		return err
+	}
+
+	keys := fileTagKeys(owner.Username, folder.Name, filename, file.Tags)
+	keys = append(keys, bucket.ContentKey(owner.Username, folder.Name, filename))
+
+	return i.bucket.Delete(ctx, append(keys, bucket.FileKey(owner.Username, folder.Name, filename))...)
+}
+
+func (i *objects) ListFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	filenames, err := i.bucket.Dirs(ctx, bucket.FilesPrefix(owner.Username, folder.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*model.File
+	for _, filename := range filenames {
+		file, err := i.getFile(ctx, owner, folder, filename)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	sortFiles(files, sortBy, order)
+
+	return files, nil
+}
+
+func (i *objects) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(ctx, owner, dir, filename, tags, false)
+}
+
+func (i *objects) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return i.tagFile(ctx, owner, dir, filename, tags, true)
+}
+
+// tagFile is the same as tagFolder for the file filename of dir.
+func (i *objects) tagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+	removed bool,
+) (item *model.File, err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := fileTagKeys(owner.Username, folder.Name, filename, tags)
+	file, err := i.updateFile(ctx, owner, folder, filename, func(file *model.File) error {
+		next, err := tagged(file.Tags, filename, tags, removed)
+		if err != nil {
+			return err
+		}
+		file.Tags = next
+
+		if removed {
+			return nil
+		}
+
+		return i.mark(ctx, keys)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if removed {
+		err = i.bucket.Delete(ctx, keys...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return file, nil
+}
+
+func (i *objects) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := bucket.FileTagPrefix(owner.Username, folder.Name, tag)
+	keys, err := i.bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		file, err := i.getFile(ctx, owner, folder, strings.TrimPrefix(key, prefix+"/"))
+		if err != nil {
+			continue
+		}
+
+		if file.HasTag(tag) {
+			items = append(items, file)
+		}
+	}
+
+	sortFiles(items, sortBy, order)
+
+	return items, nil
+}
+
+func (i *objects) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, dir, newFilename, conflict, false)
+}
+
+func (i *objects) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, target, filename, conflict, false)
+}
+
+func (i *objects) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return i.move(ctx, owner, dir, filename, target, newFilename, conflict, true)
+}
+
+// move is used to move filename of dir to newFilename of target, or copy it. The destination is written
+// first, conditionally unless it's overwritten, and the source is deleted last, so an interrupted move
+// leaves both files rather than none.
+func (i *objects) move(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+	copied bool,
+) (item *model.File, err error) {
+	folder, err := i.getFolder(ctx, owner, dir.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := i.getFile(ctx, owner, folder, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := i.getFolder(ctx, owner, target.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkMove(folder, filename, to, newFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	item = moved(owner, to, file, newFilename, copied)
+	key := bucket.FileKey(owner.Username, to.Name, newFilename)
+	if conflict == model.ConflictOverwrite {
+		err = i.bucket.PutJSON(ctx, key, metaOfFile(item))
+	} else {
+		err = i.bucket.CreateJSON(ctx, key, metaOfFile(item))
+	}
+	if errors.Is(err, bucket.ErrExists) {
+		_, err = skipExisting(newFilename, conflict)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := i.bucket.Get(ctx, bucket.ContentKey(owner.Username, folder.Name, filename))
+	if err != nil && !errors.Is(err, bucket.ErrNotFound) {
+		return nil, err
+	}
+
+	err = i.bucket.Put(ctx, bucket.ContentKey(owner.Username, to.Name, newFilename), content)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.mark(ctx, fileTagKeys(owner.Username, to.Name, newFilename, item.Tags))
+	if err != nil {
+		return nil, err
+	}
+
+	if !copied {
+		keys := fileTagKeys(owner.Username, folder.Name, filename, file.Tags)
+		keys = append(keys, bucket.ContentKey(owner.Username, folder.Name, filename))
+		err = i.bucket.Delete(ctx, append(keys, bucket.FileKey(owner.Username, folder.Name, filename))...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func (i *objects) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return begin(i, i.apply), nil
+}
+
+// apply is used to commit a transaction. The folders are checked first, but S3 can't write several objects
+// at once: the removals then the writes are applied one object after the other, the creations being
+// conditional, so a concurrent change in between makes it fail part way. The changes already applied are
+// then undone, the last one first.
+func (i *objects) apply(ctx context.Context, owners map[string]*model.User, base, staged tree) error {
+	for _, username := range sortedNames(base) {
+		current, err := loadFolders(ctx, i, owners[username])
+		if err != nil {
+			return err
+		}
+
+		err = checkUnchanged(username, base[username], current)
+		if err != nil {
+			return err
+		}
+	}
+
+	var undo []func() error
+	for _, username := range sortedNames(base) {
+		owner := owners[username]
+		c := diff(base[username], staged[username])
+
+		for _, folder := range c.removedFolders {
+			err := i.Delete(ctx, owner, folder.Name)
+			if err != nil {
+				return rollback(err, undo)
+			}
+			undo = append(undo, func() error {
+				_, err := i.Restore(ctx, owner, folder)
+				return err
+			})
+		}
+		for _, file := range c.removedFiles {
+			err := i.DeleteFile(ctx, owner, file.Folder, file.Name)
+			if err != nil {
+				return rollback(err, undo)
+			}
+			undo = append(undo, func() error {
+				return i.putFile(ctx, owner, file)
+			})
+		}
+		for _, folder := range c.putFolders {
+			_, err := i.Restore(ctx, owner, folder)
+			if err != nil {
+				return rollback(err, undo)
+			}
+			undo = append(undo, func() error {
+				return i.Delete(ctx, owner, folder.Name)
+			})
+		}
+		for _, file := range c.putFiles {
+			err := i.putFile(ctx, owner, file)
+			if err != nil {
+				return rollback(err, undo)
+			}
+			undo = append(undo, func() error {
+				return i.DeleteFile(ctx, owner, file.Folder, file.Name)
+			})
+		}
+	}
+
+	return nil
+}
+
+// putFile is used to create the objects of file as it is.
+func (i *objects) putFile(ctx context.Context, owner *model.User, file *model.File) error {
+	err := i.bucket.CreateJSON(ctx, bucket.FileKey(owner.Username, file.Folder.Name, file.Name), metaOfFile(file))
+	if errors.Is(err, bucket.ErrExists) {
+		return fmt.Errorf("the %s has already existed", file.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = i.bucket.Put(ctx, bucket.ContentKey(owner.Username, file.Folder.Name, file.Name), nil)
+	if err != nil {
+		return err
+	}
+
+	return i.mark(ctx, fileTagKeys(owner.Username, file.Folder.Name, file.Name, file.Tags))
+}
+
+// mark is used to put the empty markers at keys. They're only a hint for the lists by tag, which check
+// the folders and files themselves.
+func (i *objects) mark(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		err := i.bucket.Put(ctx, key, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// folderTagKeys returns the keys of the markers of the folder foldername of username tagged tags.
+func folderTagKeys(username, foldername string, tags []string) []string {
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, bucket.TagKey(username, tag, foldername))
+	}
+
+	return keys
+}
+
+// fileTagKeys returns the keys of the markers of the file filename of the folder foldername tagged tags.
+func fileTagKeys(username, foldername, filename string, tags []string) []string {
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, bucket.FileTagKey(username, foldername, tag, filename))
+	}
+
+	return keys
+}
+
+func (i *objects) checkOwner(ctx context.Context, owner *model.User) error {
+	if model.ValidateInput(owner.Username) != nil {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	_, err := i.bucket.Get(ctx, bucket.UserKey(owner.Username))
+	if errors.Is(err, bucket.ErrNotFound) {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	return err
+}
+
+func (i *objects) getFolder(ctx context.Context, owner *model.User, foldername string) (*model.Folder, error) {
+	folder, _, err := i.getFolderVersion(ctx, owner, foldername)
+
+	return folder, err
+}
+
+// getFolderVersion is the same as getFolder, also returning the ETag of the metadata.
+func (i *objects) getFolderVersion(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+) (*model.Folder, string, error) {
+	err := i.checkOwner(ctx, owner)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// invalid names can't have an object and must not escape their prefix
+	if model.ValidateInput(foldername) != nil {
+		return nil, "", fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	var m meta
+	etag, err := i.bucket.GetJSONVersion(ctx, bucket.FolderKey(owner.Username, foldername), &m)
+	if errors.Is(err, bucket.ErrNotFound) {
+		return nil, "", fmt.Errorf("the %s doesn't exist", foldername)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &model.Folder{
+		Name:        m.Name,
+		Description: m.Description,
+		CreatedAt:   m.CreatedAt,
+		Owner:       owner,
+		Files:       make(map[string]*model.File),
+		Folders:     make(map[string]*model.Folder),
+		Shares:      m.Shares,
+		Tags:        m.Tags,
+	}, etag, nil
+}
+
+// updateFolder is used to rewrite the metadata of the folder foldername changed by change. The metadata is
+// only written if it's unchanged since it was read, the change being retried on the latest one otherwise, so
+// concurrent changes of the same folder can't overwrite each other.
+func (i *objects) updateFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	change func(folder *model.Folder) error,
+) (*model.Folder, error) {
+	for attempt := 0; attempt < attempts; attempt++ {
+		folder, etag, err := i.getFolderVersion(ctx, owner, foldername)
+		if err != nil {
+			return nil, err
+		}
+
+		err = change(folder)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.bucket.ReplaceJSON(ctx, bucket.FolderKey(owner.Username, foldername), metaOfFolder(folder), etag)
+		if errors.Is(err, bucket.ErrChanged) {
+			continue
+		}
+		if errors.Is(err, bucket.ErrNotFound) {
+			return nil, fmt.Errorf("the %s doesn't exist", foldername)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return folder, nil
+	}
+
+	return nil, fmt.Errorf("the %s is being changed concurrently, try again", foldername)
+}
+
+func (i *objects) getFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+) (*model.File, error) {
+	file, _, err := i.getFileVersion(ctx, owner, folder, filename)
+
+	return file, err
+}
+
+// getFileVersion is the same as getFile, also returning the ETag of the metadata.
+func (i *objects) getFileVersion(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+) (*model.File, string, error) {
+	if model.ValidateInput(filename) != nil {
+		return nil, "", fmt.Errorf("the %s doesn't exist", filename)
+	}
+
+	var m meta
+	etag, err := i.bucket.GetJSONVersion(ctx, bucket.FileKey(owner.Username, folder.Name, filename), &m)
+	if errors.Is(err, bucket.ErrNotFound) {
+		return nil, "", fmt.Errorf("the %s doesn't exist", filename)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &model.File{
+		Name:        m.Name,
+		Description: m.Description,
+		CreatedAt:   m.CreatedAt,
+		Owner:       owner,
+		Folder:      folder,
+		Tags:        m.Tags,
+	}, etag, nil
+}
+
+// updateFile is the same as updateFolder for the file filename of folder.
+func (i *objects) updateFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+	change func(file *model.File) error,
+) (*model.File, error) {
+	for attempt := 0; attempt < attempts; attempt++ {
+		file, etag, err := i.getFileVersion(ctx, owner, folder, filename)
+		if err != nil {
+			return nil, err
+		}
+
+		err = change(file)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.bucket.ReplaceJSON(ctx, bucket.FileKey(owner.Username, folder.Name, filename), metaOfFile(file), etag)
+		if errors.Is(err, bucket.ErrChanged) {
+			continue
+		}
+		if errors.Is(err, bucket.ErrNotFound) {
+			return nil, fmt.Errorf("the %s doesn't exist", filename)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return file, nil
+	}
+
+	return nil, fmt.Errorf("the %s is being changed concurrently, try again", filename)
+}
+
+func metaOfFolder(folder *model.Folder) meta {
+	return meta{
+		Name:        folder.Name,
+		Description: folder.Description,
+		CreatedAt:   folder.CreatedAt,
+		Shares:      folder.Shares,
+		Tags:        folder.Tags,
+	}
+}
+
+func metaOfFile(file *model.File) meta {
+	return meta{Name: file.Name, Description: file.Description, CreatedAt: file.CreatedAt, Tags: file.Tags}
+}
+
+// withMetaLast returns keys with the metadata key moved to the end.
+func withMetaLast(keys []string, metaKey string) []string {
+	ordered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key != metaKey {
+			ordered = append(ordered, key)
+		}
+	}
+
+	return append(ordered, metaKey)
+}
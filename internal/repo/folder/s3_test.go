@@ -0,0 +1,229 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+)
+
+func Test_objects(t *testing.T) {
+	path := buckettest.Start(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(ctx, bucket.UserKey(owner.Username), owner)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	_, err = i.Create(ctx, &model.User{Username: "user2"}, "folder1", "description")
+	if err == nil {
+		t.Errorf("Create() for a missing user error = nil, want error")
+	}
+
+	folder, err := i.Create(ctx, owner, "folder1", "description")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, err = i.Create(ctx, owner, "folder1", "description")
+	if err == nil {
+		t.Errorf("Create() an existing folder error = nil, want error")
+	}
+
+	_, err = i.CreateFile(ctx, owner, folder, "file1", "description")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	_, err = i.Create(ctx, owner, "folder0", "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, err = i.Rename(ctx, owner, "folder1", "folder0")
+	if err == nil {
+		t.Errorf("Rename() to an existing folder error = nil, want error")
+	}
+
+	_, err = i.Rename(ctx, owner, "folder1", "folder2")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	folders, err := i.List(ctx, owner, "name", "desc")
+	if err != nil || len(folders) != 2 || folders[0].Name != "folder2" {
+		t.Errorf("List() got = %v, error = %v", folders, err)
+	}
+
+	folder, err = i.GetByName(ctx, owner, "folder2")
+	if err != nil || folder.Description != "description" {
+		t.Fatalf("GetByName() got = %v, error = %v", folder, err)
+	}
+
+	files, err := i.ListFiles(ctx, owner, folder, "name", "asc")
+	if err != nil || len(files) != 1 || files[0].Name != "file1" {
+		t.Errorf("ListFiles() got = %v, error = %v", files, err)
+	}
+
+	err = i.DeleteFile(ctx, owner, folder, "file1")
+	if err != nil {
+		t.Errorf("DeleteFile() error = %v", err)
+	}
+
+	err = i.Delete(ctx, owner, "folder2")
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	keys, _ := b.List(ctx, bucket.FolderPrefix(owner.Username, "folder2"))
+	if len(keys) != 0 {
+		t.Errorf("Delete() left the objects %v", keys)
+	}
+
+	_, err = i.GetByName(ctx, owner, "folder2")
+	if err == nil {
+		t.Errorf("GetByName() a deleted folder error = nil, want error")
+	}
+}
+
+func Test_objects_Create_Concurrent(t *testing.T) {
+	path := buckettest.Start(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(ctx, bucket.UserKey(owner.Username), owner)
+
+	// every writer has its own client, like workers sharing the bucket
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for n := 0; n < writers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			i, _ := NewS3(path)
+			_, err := i.Create(ctx, owner, "folder1", "description")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Errorf("Create() concurrently succeeded %d times, want 1", created)
+	}
+}
+
+func Test_objects_Share_Concurrent(t *testing.T) {
+	path := buckettest.Start(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(ctx, bucket.UserKey(owner.Username), owner)
+
+	i, _ := NewS3(path)
+	_, err := i.Create(ctx, owner, "folder1", "description")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// a write with the ETag of a stale read is refused
+	var m meta
+	etag, _ := b.GetJSONVersion(ctx, bucket.FolderKey(owner.Username, "folder1"), &m)
+	_ = b.PutJSON(ctx, bucket.FolderKey(owner.Username, "folder1"), meta{Name: "folder1", Description: "changed"})
+	err = b.ReplaceJSON(ctx, bucket.FolderKey(owner.Username, "folder1"), m, etag)
+	if !errors.Is(err, bucket.ErrChanged) {
+		t.Errorf("ReplaceJSON() with a stale etag error = %v, want %v", err, bucket.ErrChanged)
+	}
+
+	// fewer writers than attempts, so every share gets through its retries
+	const writers = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for n := 0; n < writers; n++ {
+		grantee := &model.User{Username: fmt.Sprintf("grantee%d", n)}
+		_ = b.CreateJSON(ctx, bucket.UserKey(grantee.Username), grantee)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			i, _ := NewS3(path)
+			errs <- i.Share(ctx, owner, "folder1", grantee, model.AccessRead)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Share() concurrently error = %v", err)
+		}
+	}
+
+	folder, err := i.GetByName(ctx, owner, "folder1")
+	if err != nil || len(folder.Shares) != writers || folder.Description != "changed" {
+		t.Errorf("Share() concurrently got = %v, error = %v, want %d shares", folder, err, writers)
+	}
+}
+
+func Test_objects_TagFolder_Concurrent(t *testing.T) {
+	path := buckettest.Start(t)
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(ctx, bucket.UserKey(owner.Username), owner)
+
+	i, _ := NewS3(path)
+	_, err := i.Create(ctx, owner, "folder1", "description")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	const writers = 4
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for n := 0; n < writers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			i, _ := NewS3(path)
+			_, err := i.TagFolder(ctx, owner, "folder1", []string{fmt.Sprintf("tag%d", n)})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("TagFolder() concurrently error = %v", err)
+		}
+	}
+
+	folder, err := i.GetByName(ctx, owner, "folder1")
+	if err != nil || len(folder.Tags) != writers {
+		t.Errorf("TagFolder() concurrently got = %v, error = %v, want %d tags", folder, err, writers)
+	}
+}
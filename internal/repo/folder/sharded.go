@@ -0,0 +1,555 @@
+package folder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+type sharded struct {
+	sync.Mutex
+
+	locks map[string]*sync.Mutex
+	path  string
+}
+
+// NewSharded is used to create a new FolderManager backed by one document per user.
+func NewSharded(path string) (repo.FolderManager, error) {
+	return &sharded{
+		Mutex: sync.Mutex{},
+		locks: make(map[string]*sync.Mutex),
+		path:  strings.TrimRight(path, "/"),
+	}, nil
+}
+
+func (i *sharded) GetByName(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.GetByName(ctx, owner, foldername)
+}
+
+func (i *sharded) Create(
+	ctx context.Context,
+	owner *model.User,
+	foldername, description string,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Create(ctx, owner, foldername, description)
+}
+
+func (i *sharded) Delete(ctx context.Context, owner *model.User, foldername string) (err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return err
+	}
+
+	return shard.Delete(ctx, owner, foldername)
+}
+
+func (i *sharded) Rename(
+	ctx context.Context,
+	owner *model.User,
+	foldername, newFoldername string,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Rename(ctx, owner, foldername, newFoldername)
+}
+
+func (i *sharded) List(
+	ctx context.Context,
+	owner *model.User,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.List(ctx, owner, sortBy, order)
+}
+
+func (i *sharded) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Restore(ctx, owner, folder)
+}
+
+func (i *sharded) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, true)
+}
+
+func (i *sharded) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return i.moveFolder(ctx, owner, foldername, target, newFoldername, false)
+}
+
+// moveFolder is used to move the folder foldername of owner to newFoldername of target, or copy it.
+// Between two users both shards are locked, and the target is written before the source so an interrupted
+// move leaves both folders rather than none.
+func (i *sharded) moveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+	copied bool,
+) (item *model.Folder, err error) {
+	if owner.Username == target.Username {
+		defer i.lock(owner.Username)()
+
+		shard, err := i.open(owner)
+		if err != nil {
+			return nil, err
+		}
+
+		return shard.moveFolder(owner, foldername, target, newFoldername, copied)
+	}
+
+	// the shards are always locked in the same order, so two opposite moves can't deadlock
+	for _, username := range sortedNames(map[string]*model.User{owner.Username: owner, target.Username: target}) {
+		defer i.lock(username)()
+	}
+
+	from, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := i.open(target)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := from.GetByName(ctx, owner, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err = to.Restore(ctx, target, movedFolder(owner, target, folder, newFoldername, copied))
+	if err != nil {
+		return nil, err
+	}
+
+	if !copied {
+		err = from.Delete(ctx, owner, foldername)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func (i *sharded) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return i.share(owner, foldername, grantee, access)
+}
+
+func (i *sharded) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return i.share(owner, foldername, grantee, "")
+}
+
+// share is used to update the shares of the folder foldername in the shard of owner, the shard of grantee is
+// only read to check it exists.
+func (i *sharded) share(owner *model.User, foldername string, grantee *model.User, access model.Access) (err error) {
+	other, err := i.open(grantee)
+	if err != nil {
+		return err
+	}
+
+	if _, exists := other.users[grantee.Username]; !exists {
+		return fmt.Errorf("the %s doesn't exist", grantee.Username)
+	}
+
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return err
+	}
+
+	user, exists := shard.users[owner.Username]
+	if !exists {
+		return fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	folder, exists := user.Folders[foldername]
+	if !exists {
+		return fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	return shard.grant(folder, grantee, access)
+}
+
+func (i *sharded) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	usernames, err := store.ReadIndex(i.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, username := range usernames {
+		if username == grantee.Username {
+			continue
+		}
+
+		folders, err := i.List(ctx, &model.User{Username: username}, "", "")
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, sharesOf(username, folders, grantee.Username)...)
+	}
+
+	sortShares(items)
+
+	return items, nil
+}
+
+func (i *sharded) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.TagFolder(ctx, owner, foldername, tags)
+}
+
+func (i *sharded) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.UntagFolder(ctx, owner, foldername, tags)
+}
+
+func (i *sharded) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.ListTagged(ctx, owner, tag, sortBy, order)
+}
+
+func (i *sharded) CreateFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, description string,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.CreateFile(ctx, owner, dir, filename, description)
+}
+
+func (i *sharded) DeleteFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+) (err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return err
+	}
+
+	return shard.DeleteFile(ctx, owner, dir, filename)
+}
+
+func (i *sharded) ListFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.ListFiles(ctx, owner, dir, sortBy, order)
+}
+
+func (i *sharded) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.TagFile(ctx, owner, dir, filename, tags)
+}
+
+func (i *sharded) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.UntagFile(ctx, owner, dir, filename, tags)
+}
+
+func (i *sharded) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.ListTaggedFiles(ctx, owner, dir, tag, sortBy, order)
+}
+
+func (i *sharded) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.RenameFile(ctx, owner, dir, filename, newFilename, conflict)
+}
+
+func (i *sharded) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.MoveFile(ctx, owner, dir, filename, target, conflict)
+}
+
+func (i *sharded) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	defer i.lock(owner.Username)()
+
+	shard, err := i.open(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.CopyFile(ctx, owner, dir, filename, target, newFilename, conflict)
+}
+
+// lock is used to lock the shard of username and returns the function releasing it.
+func (i *sharded) lock(username string) func() {
+	i.Lock()
+	locker, exists := i.locks[username]
+	if !exists {
+		locker = &sync.Mutex{}
+		i.locks[username] = locker
+	}
+	i.Unlock()
+
+	locker.Lock()
+
+	return locker.Unlock
+}
+
+func (i *sharded) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return begin(i, i.apply), nil
+}
+
+// apply is used to commit a transaction. Every shard it touches is locked and checked before any is written,
+// then they're written one after the other, the ones already written being written back as they were when
+// one fails.
+func (i *sharded) apply(ctx context.Context, owners map[string]*model.User, base, staged tree) error {
+	usernames := sortedNames(base)
+	shards := make(map[string]*jsonFile, len(usernames))
+	for _, username := range usernames {
+		defer i.lock(username)()
+
+		shard, err := i.open(owners[username])
+		if err != nil {
+			return err
+		}
+
+		user, exists := shard.users[username]
+		if !exists {
+			return fmt.Errorf("the %s doesn't exist", username)
+		}
+
+		err = checkUnchanged(username, base[username], user.Folders)
+		if err != nil {
+			return err
+		}
+		shards[username] = shard
+	}
+
+	var undo []func() error
+	for _, username := range usernames {
+		shard := shards[username]
+		previous := shard.users[username].Folders
+		err := shard.apply(
+			ctx,
+			map[string]*model.User{username: owners[username]},
+			tree{username: base[username]},
+			tree{username: staged[username]},
+		)
+		if err != nil {
+			return rollback(err, undo)
+		}
+
+		undo = append(undo, func() error {
+			shard.users[username].Folders = previous
+			return shard.Save()
+		})
+	}
+
+	return nil
+}
+
+// open is used to load the shard of owner as a single-user jsonFile.
+func (i *sharded) open(owner *model.User) (*jsonFile, error) {
+	// invalid usernames can't have a shard and must not escape the store directory
+	if model.ValidateInput(owner.Username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", owner.Username)
+	}
+
+	shard := &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: make(map[string]*model.User),
+		path:  store.ShardPath(i.path, owner.Username),
+	}
+
+	err := shard.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return shard, nil
+}
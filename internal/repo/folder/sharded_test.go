@@ -0,0 +1,80 @@
+package folder
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+func Test_sharded(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	ctx := context.Background()
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	_ = store.WriteFile(store.ShardPath(path, owner.Username), map[string]*model.User{
+		owner.Username: {Username: owner.Username, Folders: map[string]*model.Folder{}},
+	})
+	_ = store.WriteFile(store.ShardPath(path, other.Username), map[string]*model.User{
+		other.Username: {Username: other.Username, Folders: map[string]*model.Folder{}},
+	})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	folder, err := i.Create(ctx, owner, "folder1", "description")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	_, err = i.CreateFile(ctx, owner, folder, "file1", "description")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	_, err = i.Rename(ctx, owner, "folder1", "folder2")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+
+	// a fresh instance must see what the previous one persisted
+	i, _ = NewSharded(path)
+
+	folder, err = i.GetByName(ctx, owner, "folder2")
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+
+	files, err := i.ListFiles(ctx, owner, folder, "name", "asc")
+	if err != nil || len(files) != 1 {
+		t.Errorf("ListFiles() got = %v, error = %v", files, err)
+	}
+
+	folders, err := i.List(ctx, other, "name", "asc")
+	if err != nil || len(folders) != 0 {
+		t.Errorf("List() of another shard got = %v, error = %v", folders, err)
+	}
+
+	err = i.DeleteFile(ctx, owner, folder, "file1")
+	if err != nil {
+		t.Errorf("DeleteFile() error = %v", err)
+	}
+
+	err = i.Delete(ctx, owner, "folder2")
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	_, err = i.List(ctx, &model.User{Username: "../user1"}, "name", "asc")
+	if err == nil {
+		t.Errorf("List() with an escaping username error = nil, want error")
+	}
+}
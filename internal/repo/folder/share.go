@@ -0,0 +1,66 @@
+package folder
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// errShareInTx is returned by the transactions, which can't change the shares.
+var errShareInTx = errors.New("the shares can't be changed in a transaction")
+
+// granted is used to copy the shares of the folder foldername with grantee granted access,
+// or revoked when access is empty.
+func granted(
+	shares map[string]model.Access,
+	foldername, grantee string,
+	access model.Access,
+) (map[string]model.Access, error) {
+	if access == "" {
+		if _, exists := shares[grantee]; !exists {
+			return nil, fmt.Errorf("the %s isn't shared with %s", foldername, grantee)
+		}
+	} else if _, err := model.ParseAccess(string(access)); err != nil {
+		return nil, err
+	}
+
+	updated := maps.Clone(shares)
+	if updated == nil {
+		updated = make(map[string]model.Access, 1)
+	}
+
+	if access == "" {
+		delete(updated, grantee)
+	} else {
+		updated[grantee] = access
+	}
+
+	if len(updated) == 0 {
+		return nil, nil
+	}
+
+	return updated, nil
+}
+
+// sharesOf is used to list the folders of owner shared with grantee.
+func sharesOf(owner string, folders []*model.Folder, grantee string) []*model.Share {
+	var items []*model.Share
+	for _, folder := range folders {
+		if access, exists := folder.Shares[grantee]; exists {
+			items = append(items, &model.Share{Owner: owner, Folder: folder.Name, Grantee: grantee, Access: access})
+		}
+	}
+
+	return items
+}
+
+// sortShares is used to sort items by owner then folder.
+func sortShares(items []*model.Share) {
+	slices.SortFunc(items, func(a, b *model.Share) int {
+		return cmp.Or(cmp.Compare(a.Owner, b.Owner), cmp.Compare(a.Folder, b.Folder))
+	})
+}
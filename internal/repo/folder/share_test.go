@@ -0,0 +1,181 @@
+package folder
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// checkShares is used to check that i shares the folders of owner with other.
+func checkShares(t *testing.T, i repo.FolderManager, owner, other *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, _ = i.Create(ctx, owner, "folder1", "")
+	_, _ = i.Create(ctx, owner, "folder2", "")
+
+	if err := i.Share(ctx, owner, "missing", other, model.AccessRead); err == nil {
+		t.Errorf("Share() a missing folder error = nil, want error")
+	}
+	if err := i.Share(ctx, owner, "folder1", &model.User{Username: "user3"}, model.AccessRead); err == nil {
+		t.Errorf("Share() with a missing user error = nil, want error")
+	}
+	if err := i.Share(ctx, owner, "folder1", other, "admin"); err == nil {
+		t.Errorf("Share() an unsupported access error = nil, want error")
+	}
+	if err := i.Unshare(ctx, owner, "folder1", other); err == nil {
+		t.Errorf("Unshare() a folder not shared error = nil, want error")
+	}
+
+	for _, share := range []*model.Share{
+		{Folder: "folder1", Access: model.AccessRead},
+		{Folder: "folder2", Access: model.AccessWrite},
+		{Folder: "folder1", Access: model.AccessWrite},
+	} {
+		if err := i.Share(ctx, owner, share.Folder, other, share.Access); err != nil {
+			t.Fatalf("Share() error = %v", err)
+		}
+	}
+
+	folder, _ := i.GetByName(ctx, owner, "folder1")
+	if want := map[string]model.Access{other.Username: model.AccessWrite}; !reflect.DeepEqual(folder.Shares, want) {
+		t.Errorf("GetByName() shares got = %v, want %v", folder.Shares, want)
+	}
+
+	_, err := i.Rename(ctx, owner, "folder2", "folder3")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	_, err = i.CopyFolder(ctx, owner, "folder3", owner, "folder4")
+	if err != nil {
+		t.Fatalf("CopyFolder() error = %v", err)
+	}
+
+	got, err := i.ListShared(ctx, other)
+	if err != nil {
+		t.Fatalf("ListShared() error = %v", err)
+	}
+	want := []*model.Share{
+		{Owner: owner.Username, Folder: "folder1", Grantee: other.Username, Access: model.AccessWrite},
+		{Owner: owner.Username, Folder: "folder3", Grantee: other.Username, Access: model.AccessWrite},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListShared() got = %v, want %v", got, want)
+	}
+
+	if got, _ = i.ListShared(ctx, owner); len(got) != 0 {
+		t.Errorf("ListShared() of the owner got = %v, want none", got)
+	}
+
+	if err = i.Unshare(ctx, owner, "folder1", other); err != nil {
+		t.Fatalf("Unshare() error = %v", err)
+	}
+	if folder, _ = i.GetByName(ctx, owner, "folder1"); len(folder.Shares) != 0 {
+		t.Errorf("GetByName() shares after Unshare() got = %v, want none", folder.Shares)
+	}
+	if got, _ = i.ListShared(ctx, other); len(got) != 1 {
+		t.Errorf("ListShared() after Unshare() got = %v, want [folder3]", got)
+	}
+}
+
+func Test_jsonFile_Share(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	other, _ := model.NewUser("user2")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkShares(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner, other.Username: other},
+		path:  "out/vfs.json",
+	}, owner, other)
+}
+
+func Test_sharded_Share(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	for _, user := range []*model.User{owner, other} {
+		_ = store.WriteFile(store.ShardPath(path, user.Username), map[string]*model.User{
+			user.Username: {Username: user.Username, Folders: map[string]*model.Folder{}},
+		})
+	}
+	_ = store.WriteIndex(path, []string{owner.Username, other.Username})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	checkShares(t, i, owner, other)
+}
+
+func Test_staged_Share(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	other, _ := model.NewUser("user2")
+	i := &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner, other.Username: other},
+	}
+	_, _ = i.Create(context.Background(), owner, "folder1", "")
+	_ = i.Share(context.Background(), owner, "folder1", other, model.AccessRead)
+
+	tx, _ := i.Begin(context.Background())
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
+
+	if err := tx.Share(context.Background(), owner, "folder1", other, model.AccessWrite); err == nil {
+		t.Errorf("Share() in a transaction error = nil, want error")
+	}
+	if got, _ := tx.ListShared(context.Background(), other); len(got) != 1 {
+		t.Errorf("ListShared() in a transaction got = %v, want [folder1]", got)
+	}
+}
+
+func Test_objects_Share(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(other.Username), other)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkShares(t, i, owner, other)
+}
+
+func Test_hashes_Share(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+	server.HSet(keyspace.UserKey(other.Username), "username", other.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkShares(t, i, owner, other)
+}
@@ -0,0 +1,49 @@
+package folder
+
+import (
+	"sort"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+const orderAsc = "asc"
+
+// sortFolders is used to sort folders by name or created time, by name ascending by default.
+func sortFolders(folders []*model.Folder, sortBy string, order string) {
+	sort.Slice(folders, func(i, j int) bool {
+		switch sortBy {
+		case "name":
+			if order == orderAsc {
+				return folders[i].Name < folders[j].Name
+			}
+			return folders[i].Name > folders[j].Name
+		case "created":
+			if order == orderAsc {
+				return folders[i].CreatedAt.Before(folders[j].CreatedAt)
+			}
+			return folders[i].CreatedAt.After(folders[j].CreatedAt)
+		default:
+			return folders[i].Name < folders[j].Name
+		}
+	})
+}
+
+// sortFiles is used to sort files by name or created time, by name ascending by default.
+func sortFiles(files []*model.File, sortBy string, order string) {
+	sort.Slice(files, func(i, j int) bool {
+		switch sortBy {
+		case "name":
+			if order == orderAsc {
+				return files[i].Name < files[j].Name
+			}
+			return files[i].Name > files[j].Name
+		case "created":
+			if order == orderAsc {
+				return files[i].CreatedAt.Before(files[j].CreatedAt)
+			}
+			return files[i].CreatedAt.After(files[j].CreatedAt)
+		default:
+			return files[i].Name < files[j].Name
+		}
+	})
+}
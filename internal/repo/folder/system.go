@@ -9,6 +9,10 @@ import (
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
 )
 
+// errUnsupported is returned by what the directory store can't keep beyond its folders and files, like the
+// shares, the tags or the transactions.
+var errUnsupported = errors.New("this isn't supported by the directory store, use a document or sharded store")
+
 type system struct {
 	path string
 }
@@ -58,6 +62,80 @@ func (i *system) List(
 	return nil, errors.New("not implement yet")
 }
 
+func (i *system) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return errUnsupported
+}
+
+func (i *system) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return errUnsupported
+}
+
+func (i *system) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	return nil, errUnsupported
+}
+
 func (i *system) CreateFile(
 	ctx context.Context,
 	owner *model.User,
@@ -88,3 +166,71 @@ func (i *system) ListFiles(
 	// todo: 2024/5/27|sean|implement me
 	return nil, errors.New("not implement yet")
 }
+
+func (i *system) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	return nil, errUnsupported
+}
+
+func (i *system) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return nil, errUnsupported
+}
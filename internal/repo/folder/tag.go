@@ -0,0 +1,40 @@
+package folder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// tagged is used to copy the tags of the folder or file name with tags added, or removed when removed is set.
+func tagged(current []string, name string, tags []string, removed bool) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, errors.New("at least one tag is required")
+	}
+
+	updated := slices.Clone(current)
+	for _, tag := range tags {
+		err := model.ValidateInput(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+
+		has := slices.Contains(updated, tag)
+		switch {
+		case removed && !has:
+			return nil, fmt.Errorf("the %s isn't tagged %s", name, tag)
+		case removed:
+			updated = slices.DeleteFunc(updated, func(other string) bool { return other == tag })
+		case !has:
+			updated = append(updated, tag)
+		}
+	}
+
+	if len(updated) == 0 {
+		return nil, nil
+	}
+
+	return model.SortTags(updated), nil
+}
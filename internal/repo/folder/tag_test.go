@@ -0,0 +1,311 @@
+package folder
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// taggedNames is used to list the names of the folders of owner tagged tag, or of the files of foldername
+// when it's set.
+func taggedNames(t *testing.T, i repo.FolderManager, owner *model.User, foldername, tag, order string) []string {
+	t.Helper()
+	ctx := context.Background()
+
+	var got []string
+	if foldername == "" {
+		folders, err := i.ListTagged(ctx, owner, tag, "created", order)
+		if err != nil {
+			t.Fatalf("ListTagged() error = %v", err)
+		}
+		for _, folder := range folders {
+			got = append(got, folder.Name)
+		}
+
+		return got
+	}
+
+	files, err := i.ListTaggedFiles(ctx, owner, &model.Folder{Name: foldername}, tag, "created", order)
+	if err != nil {
+		t.Fatalf("ListTaggedFiles() error = %v", err)
+	}
+	for _, file := range files {
+		got = append(got, file.Name)
+	}
+
+	return got
+}
+
+// checkTags is used to check that i tags the folders and files of owner, and lists them by tag as they're
+// renamed, moved, copied and deleted.
+func checkTags(t *testing.T, i repo.FolderManager, owner *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	var got []string
+
+	for _, foldername := range []string{"folder1", "folder2", "folder3"} {
+		_, _ = i.Create(ctx, owner, foldername, "")
+	}
+	folder1, _ := i.GetByName(ctx, owner, "folder1")
+	_, _ = i.CreateFile(ctx, owner, folder1, "file1", "")
+	_, _ = i.CreateFile(ctx, owner, folder1, "file2", "")
+
+	if _, err := i.TagFolder(ctx, owner, "missing", []string{"work"}); err == nil {
+		t.Errorf("TagFolder() a missing folder error = nil, want error")
+	}
+	if _, err := i.TagFolder(ctx, owner, "folder1", []string{"to do"}); err == nil {
+		t.Errorf("TagFolder() an invalid tag error = nil, want error")
+	}
+	if _, err := i.TagFolder(ctx, owner, "folder1", nil); err == nil {
+		t.Errorf("TagFolder() without tags error = nil, want error")
+	}
+	if _, err := i.UntagFolder(ctx, owner, "folder1", []string{"work"}); err == nil {
+		t.Errorf("UntagFolder() a tag the folder doesn't have error = nil, want error")
+	}
+	if _, err := i.TagFile(ctx, owner, folder1, "missing", []string{"work"}); err == nil {
+		t.Errorf("TagFile() a missing file error = nil, want error")
+	}
+
+	_, err := i.TagFolder(ctx, owner, "folder1", []string{"work", "urgent"})
+	if err != nil {
+		t.Fatalf("TagFolder() error = %v", err)
+	}
+	_, _ = i.TagFolder(ctx, owner, "folder2", []string{"work"})
+	item, err := i.TagFolder(ctx, owner, "folder1", []string{"work"})
+	if err != nil {
+		t.Fatalf("TagFolder() a tag the folder has error = %v", err)
+	}
+	if want := []string{"urgent", "work"}; !reflect.DeepEqual(item.Tags, want) {
+		t.Errorf("TagFolder() tags got = %v, want %v", item.Tags, want)
+	}
+	if folder, _ := i.GetByName(ctx, owner, "folder1"); !reflect.DeepEqual(folder.Tags, item.Tags) {
+		t.Errorf("GetByName() tags got = %v, want %v", folder.Tags, item.Tags)
+	}
+
+	file, err := i.TagFile(ctx, owner, folder1, "file2", []string{"work", "draft"})
+	if err != nil {
+		t.Fatalf("TagFile() error = %v", err)
+	}
+	if want := []string{"draft", "work"}; !reflect.DeepEqual(file.Tags, want) {
+		t.Errorf("TagFile() tags got = %v, want %v", file.Tags, want)
+	}
+	_, _ = i.TagFile(ctx, owner, folder1, "file1", []string{"draft"})
+
+	got = taggedNames(t, i, owner, "", "work", orderAsc)
+	if want := []string{"folder1", "folder2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTagged() got = %v, want %v", got, want)
+	}
+	got = taggedNames(t, i, owner, "", "work", "desc")
+	if want := []string{"folder2", "folder1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTagged() by created time descending got = %v, want %v", got, want)
+	}
+	got = taggedNames(t, i, owner, "folder1", "draft", orderAsc)
+	if want := []string{"file1", "file2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTaggedFiles() got = %v, want %v", got, want)
+	}
+
+	_, err = i.Rename(ctx, owner, "folder2", "folder4")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	folder3, _ := i.GetByName(ctx, owner, "folder3")
+	_, err = i.MoveFile(ctx, owner, folder1, "file2", folder3, model.ConflictFail)
+	if err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	_, err = i.CopyFolder(ctx, owner, "folder1", owner, "folder5")
+	if err != nil {
+		t.Fatalf("CopyFolder() error = %v", err)
+	}
+
+	got = taggedNames(t, i, owner, "", "work", orderAsc)
+	if want := []string{"folder1", "folder4", "folder5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTagged() after Rename() and CopyFolder() got = %v, want %v", got, want)
+	}
+	got = taggedNames(t, i, owner, "folder1", "draft", orderAsc)
+	if want := []string{"file1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTaggedFiles() after MoveFile() got = %v, want %v", got, want)
+	}
+	got = taggedNames(t, i, owner, "folder3", "work", orderAsc)
+	if want := []string{"file2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTaggedFiles() of the target of MoveFile() got = %v, want %v", got, want)
+	}
+	got = taggedNames(t, i, owner, "folder5", "draft", orderAsc)
+	if want := []string{"file1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTaggedFiles() of the copy got = %v, want %v", got, want)
+	}
+
+	item, err = i.UntagFolder(ctx, owner, "folder1", []string{"urgent", "work"})
+	if err != nil {
+		t.Fatalf("UntagFolder() error = %v", err)
+	}
+	if len(item.Tags) != 0 {
+		t.Errorf("UntagFolder() tags got = %v, want none", item.Tags)
+	}
+	_, err = i.UntagFile(ctx, owner, &model.Folder{Name: "folder5"}, "file1", []string{"draft"})
+	if err != nil {
+		t.Fatalf("UntagFile() error = %v", err)
+	}
+	_ = i.Delete(ctx, owner, "folder4")
+	_, _ = i.Create(ctx, owner, "folder4", "")
+	_ = i.DeleteFile(ctx, owner, folder1, "file1")
+	_, _ = i.CreateFile(ctx, owner, folder1, "file1", "")
+
+	got = taggedNames(t, i, owner, "", "work", orderAsc)
+	if want := []string{"folder5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListTagged() after UntagFolder() and Delete() got = %v, want %v", got, want)
+	}
+	if got = taggedNames(t, i, owner, "folder5", "draft", orderAsc); len(got) != 0 {
+		t.Errorf("ListTaggedFiles() after UntagFile() got = %v, want none", got)
+	}
+	if got = taggedNames(t, i, owner, "folder1", "draft", orderAsc); len(got) != 0 {
+		t.Errorf("ListTaggedFiles() after DeleteFile() got = %v, want none", got)
+	}
+}
+
+// checkTxTags is used to check that the tags changed in a transaction of i are indexed once it's committed.
+func checkTxTags(t *testing.T, i repo.FolderManager, owner *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	folder1, _ := i.Create(ctx, owner, "folder1", "")
+	_, _ = i.CreateFile(ctx, owner, folder1, "file1", "")
+	_, _ = i.Create(ctx, owner, "folder2", "")
+	_, _ = i.TagFolder(ctx, owner, "folder2", []string{"work"})
+
+	tx, err := i.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	_, _ = tx.TagFolder(ctx, owner, "folder1", []string{"work"})
+	_, _ = tx.UntagFolder(ctx, owner, "folder2", []string{"work"})
+	_, _ = tx.TagFile(ctx, owner, folder1, "file1", []string{"draft"})
+
+	if got := taggedNames(t, i, owner, "", "work", orderAsc); !reflect.DeepEqual(got, []string{"folder2"}) {
+		t.Errorf("ListTagged() before Commit() got = %v, want [folder2]", got)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := taggedNames(t, i, owner, "", "work", orderAsc); !reflect.DeepEqual(got, []string{"folder1"}) {
+		t.Errorf("ListTagged() after Commit() got = %v, want [folder1]", got)
+	}
+	if got := taggedNames(t, i, owner, "folder1", "draft", orderAsc); !reflect.DeepEqual(got, []string{"file1"}) {
+		t.Errorf("ListTaggedFiles() after Commit() got = %v, want [file1]", got)
+	}
+}
+
+func Test_jsonFile_Tags(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	other, _ := model.NewUser("user2")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkTags(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+		path:  "out/vfs.json",
+	}, owner)
+	checkTxTags(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{other.Username: other},
+		path:  "out/vfs.json",
+	}, other)
+}
+
+func Test_sharded_Tags(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	for _, user := range []*model.User{owner, other} {
+		_ = store.WriteFile(store.ShardPath(path, user.Username), map[string]*model.User{
+			user.Username: {Username: user.Username, Folders: map[string]*model.Folder{}},
+		})
+	}
+	_ = store.WriteIndex(path, []string{owner.Username, other.Username})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	checkTags(t, i, owner)
+	checkTxTags(t, i, other)
+}
+
+func Test_staged_Tags(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	i := &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+	}
+
+	tx, _ := i.Begin(context.Background())
+	checkTags(t, tx, owner)
+
+	err := tx.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if got := taggedNames(t, i, owner, "", "work", orderAsc); !reflect.DeepEqual(got, []string{"folder5"}) {
+		t.Errorf("ListTagged() after Commit() got = %v, want [folder5]", got)
+	}
+}
+
+func Test_objects_Tags(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(other.Username), other)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkTags(t, i, owner)
+	checkTxTags(t, i, other)
+}
+
+func Test_hashes_Tags(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	other := &model.User{Username: "user2"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+	server.HSet(keyspace.UserKey(other.Username), "username", other.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkTags(t, i, owner)
+	checkTxTags(t, i, other)
+
+	// the sets are only hints, a folder left in one without the tag isn't listed
+	_, _ = server.SAdd(keyspace.TagKey(owner.Username, "work"), "folder3", "missing")
+	if got := taggedNames(t, i, owner, "", "work", orderAsc); !reflect.DeepEqual(got, []string{"folder5"}) {
+		t.Errorf("ListTagged() with stale members got = %v, want [folder5]", got)
+	}
+}
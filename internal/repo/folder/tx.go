@@ -0,0 +1,559 @@
+package folder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+)
+
+// errTxDone is returned by the transactions already committed or rolled back.
+var errTxDone = errors.New("the transaction has already been committed or rolled back")
+
+// tree is the folders of some users with their files, by username then foldername.
+type tree map[string]map[string]*model.Folder
+
+// applyFunc is used to replace the folders of owners, which were base when a transaction loaded them,
+// with the staged ones. It must fail without applying anything when they changed since base.
+type applyFunc func(ctx context.Context, owners map[string]*model.User, base, staged tree) error
+
+// staged is a transaction of a FolderManager. It loads the folders of an owner from the source on first use,
+// stages the changes in an in-memory copy of them, and hands both versions to apply on commit.
+type staged struct {
+	sync.Mutex
+
+	source repo.FolderManager
+	apply  applyFunc
+
+	copy   *jsonFile
+	base   tree
+	owners map[string]*model.User
+	done   bool
+}
+
+func begin(source repo.FolderManager, apply applyFunc) *staged {
+	return &staged{
+		source: source,
+		apply:  apply,
+		copy: &jsonFile{
+			Mutex: &sync.Mutex{},
+			users: make(map[string]*model.User),
+		},
+		base:   make(tree),
+		owners: make(map[string]*model.User),
+	}
+}
+
+func (i *staged) GetByName(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.GetByName(ctx, owner, foldername)
+}
+
+func (i *staged) Create(
+	ctx context.Context,
+	owner *model.User,
+	foldername, description string,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.Create(ctx, owner, foldername, description)
+}
+
+func (i *staged) Delete(ctx context.Context, owner *model.User, foldername string) (err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	return i.copy.Delete(ctx, owner, foldername)
+}
+
+func (i *staged) Rename(
+	ctx context.Context,
+	owner *model.User,
+	foldername, newFoldername string,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.Rename(ctx, owner, foldername, newFoldername)
+}
+
+func (i *staged) List(
+	ctx context.Context,
+	owner *model.User,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.List(ctx, owner, sortBy, order)
+}
+
+func (i *staged) Restore(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.Restore(ctx, owner, folder)
+}
+
+func (i *staged) CopyFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	err = errors.Join(i.load(ctx, owner), i.load(ctx, target))
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.CopyFolder(ctx, owner, foldername, target, newFoldername)
+}
+
+func (i *staged) MoveFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+	newFoldername string,
+) (item *model.Folder, err error) {
+	err = errors.Join(i.load(ctx, owner), i.load(ctx, target))
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.MoveFolder(ctx, owner, foldername, target, newFoldername)
+}
+
+func (i *staged) Share(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	grantee *model.User,
+	access model.Access,
+) (err error) {
+	return errShareInTx
+}
+
+func (i *staged) Unshare(ctx context.Context, owner *model.User, foldername string, grantee *model.User) (err error) {
+	return errShareInTx
+}
+
+func (i *staged) ListShared(ctx context.Context, grantee *model.User) (items []*model.Share, err error) {
+	return i.source.ListShared(ctx, grantee)
+}
+
+func (i *staged) TagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.TagFolder(ctx, owner, foldername, tags)
+}
+
+func (i *staged) UntagFolder(
+	ctx context.Context,
+	owner *model.User,
+	foldername string,
+	tags []string,
+) (item *model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.UntagFolder(ctx, owner, foldername, tags)
+}
+
+func (i *staged) ListTagged(
+	ctx context.Context,
+	owner *model.User,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.ListTagged(ctx, owner, tag, sortBy, order)
+}
+
+func (i *staged) CreateFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename, description string,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.CreateFile(ctx, owner, folder, filename, description)
+}
+
+func (i *staged) DeleteFile(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	filename string,
+) (err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	return i.copy.DeleteFile(ctx, owner, folder, filename)
+}
+
+func (i *staged) ListFiles(
+	ctx context.Context,
+	owner *model.User,
+	folder *model.Folder,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.ListFiles(ctx, owner, folder, sortBy, order)
+}
+
+func (i *staged) TagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.TagFile(ctx, owner, dir, filename, tags)
+}
+
+func (i *staged) UntagFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	tags []string,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.UntagFile(ctx, owner, dir, filename, tags)
+}
+
+func (i *staged) ListTaggedFiles(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.ListTaggedFiles(ctx, owner, dir, tag, sortBy, order)
+}
+
+func (i *staged) RenameFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.RenameFile(ctx, owner, dir, filename, newFilename, conflict)
+}
+
+func (i *staged) MoveFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.MoveFile(ctx, owner, dir, filename, target, conflict)
+}
+
+func (i *staged) CopyFile(
+	ctx context.Context,
+	owner *model.User,
+	dir *model.Folder,
+	filename string,
+	target *model.Folder,
+	newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	err = i.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.copy.CopyFile(ctx, owner, dir, filename, target, newFilename, conflict)
+}
+
+func (i *staged) Begin(ctx context.Context) (tx repo.FolderTx, err error) {
+	return nil, errors.New("the transactions can't be nested")
+}
+
+func (i *staged) Commit(ctx context.Context) (err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.done {
+		return errTxDone
+	}
+	i.done = true
+
+	if len(i.base) == 0 {
+		return nil
+	}
+
+	changed := make(tree, len(i.base))
+	for username := range i.base {
+		changed[username] = i.copy.users[username].Folders
+	}
+
+	return i.apply(ctx, i.owners, i.base, changed)
+}
+
+func (i *staged) Rollback(ctx context.Context) (err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.done {
+		return errTxDone
+	}
+	i.done = true
+
+	return nil
+}
+
+// load is used to copy the folders of owner from the source the first time the transaction touches them.
+func (i *staged) load(ctx context.Context, owner *model.User) error {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.done {
+		return errTxDone
+	}
+
+	if _, loaded := i.base[owner.Username]; loaded {
+		return nil
+	}
+
+	folders, err := loadFolders(ctx, i.source, owner)
+	if err != nil {
+		return err
+	}
+
+	user := &model.User{Username: owner.Username, Folders: make(map[string]*model.Folder, len(folders))}
+	for foldername, folder := range folders {
+		user.Folders[foldername] = copyFolder(user, folder)
+	}
+
+	i.owners[owner.Username] = owner
+	i.base[owner.Username] = folders
+	i.copy.users[owner.Username] = user
+
+	return nil
+}
+
+// loadFolders is used to read the folders of owner with their files from source.
+func loadFolders(ctx context.Context, source repo.FolderManager, owner *model.User) (map[string]*model.Folder, error) {
+	folders, err := source.List(ctx, owner, "name", orderAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]*model.Folder, len(folders))
+	for _, folder := range folders {
+		files, err := source.ListFiles(ctx, owner, folder, "name", orderAsc)
+		if err != nil {
+			return nil, err
+		}
+
+		withFiles := &model.Folder{
+			Name:        folder.Name,
+			Description: folder.Description,
+			CreatedAt:   folder.CreatedAt,
+			Files:       make(map[string]*model.File, len(files)),
+			Shares:      folder.Shares,
+			Tags:        folder.Tags,
+		}
+		for _, file := range files {
+			withFiles.Files[file.Name] = file
+		}
+		loaded[folder.Name] = copyFolder(owner, withFiles)
+	}
+
+	return loaded, nil
+}
+
+// rollback is used to undo the changes already applied by a commit failing with err, the last one first.
+// The failures to undo are joined to err, as they leave the changes applied part way.
+func rollback(err error, undo []func() error) error {
+	for n := len(undo) - 1; n >= 0; n-- {
+		undoErr := undo[n]()
+		if undoErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to roll back: %w", undoErr))
+		}
+	}
+
+	return err
+}
+
+// checkUnchanged is used to fail when the folders of username aren't base anymore.
+func checkUnchanged(username string, base, current map[string]*model.Folder) error {
+	if len(base) != len(current) {
+		return fmt.Errorf("the %s has changed since the transaction began", username)
+	}
+
+	for foldername, folder := range base {
+		other, exists := current[foldername]
+		if !exists || !sameFolder(folder, other) || len(folder.Files) != len(other.Files) {
+			return fmt.Errorf("the %s has changed since the transaction began", username)
+		}
+
+		for filename, file := range folder.Files {
+			otherFile, exists := other.Files[filename]
+			if !exists || !sameFile(file, otherFile) {
+				return fmt.Errorf("the %s has changed since the transaction began", username)
+			}
+		}
+	}
+
+	return nil
+}
+
+func sameFolder(a, b *model.Folder) bool {
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		maps.Equal(a.Shares, b.Shares) &&
+		slices.Equal(a.Tags, b.Tags)
+}
+
+func sameFile(a, b *model.File) bool {
+	return a.Name == b.Name &&
+		a.Description == b.Description &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		slices.Equal(a.Tags, b.Tags)
+}
+
+// changes are the writes turning the folders of a user from base into staged.
+type changes struct {
+	// removedFolders are removed with their files, before putFolders are written with theirs.
+	removedFolders []*model.Folder
+	putFolders     []*model.Folder
+
+	// removedFiles and putFiles are the changes in the folders kept as they were.
+	removedFiles []*model.File
+	putFiles     []*model.File
+}
+
+// diff is used to list the changes from base to staged, a folder whose description, created time or tags
+// changed is replaced as a whole.
+func diff(base, staged map[string]*model.Folder) (c changes) {
+	for _, foldername := range sortedNames(base) {
+		folder := base[foldername]
+		other, exists := staged[foldername]
+		if !exists || !sameFolder(folder, other) {
+			c.removedFolders = append(c.removedFolders, folder)
+			continue
+		}
+
+		for _, filename := range sortedNames(folder.Files) {
+			file := folder.Files[filename]
+			if otherFile, exists := other.Files[filename]; !exists || !sameFile(file, otherFile) {
+				c.removedFiles = append(c.removedFiles, file)
+			}
+		}
+		for _, filename := range sortedNames(other.Files) {
+			file := other.Files[filename]
+			if baseFile, exists := folder.Files[filename]; !exists || !sameFile(file, baseFile) {
+				c.putFiles = append(c.putFiles, file)
+			}
+		}
+	}
+
+	for _, foldername := range sortedNames(staged) {
+		folder := staged[foldername]
+		if other, exists := base[foldername]; !exists || !sameFolder(folder, other) {
+			c.putFolders = append(c.putFolders, folder)
+		}
+	}
+
+	return c
+}
+
+func sortedNames[T any](items map[string]T) []string {
+	names := make([]string, 0, len(items))
+	for name := range items {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
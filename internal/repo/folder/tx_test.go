@@ -0,0 +1,275 @@
+package folder
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// names is used to list the names of the folders of owner in i.
+func names(t *testing.T, i repo.FolderManager, owner *model.User) []string {
+	t.Helper()
+
+	folders, err := i.List(context.Background(), owner, "name", "asc")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var got []string
+	for _, folder := range folders {
+		got = append(got, folder.Name)
+	}
+
+	return got
+}
+
+// checkTx is used to check that the transactions of i are isolated, atomic and detect conflicts.
+func checkTx(t *testing.T, i repo.FolderManager, owner *model.User) {
+	t.Helper()
+	ctx := context.Background()
+
+	folder1, err := i.Create(ctx, owner, "folder1", "description")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	file1, err := i.CreateFile(ctx, owner, folder1, "file1", "description")
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+
+	tx, err := i.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err = tx.Begin(ctx); err == nil {
+		t.Errorf("Begin() in a transaction error = nil, want error")
+	}
+
+	folder2, err := tx.Create(ctx, owner, "folder2", "staged")
+	if err != nil {
+		t.Fatalf("Create() in a transaction error = %v", err)
+	}
+	_, _ = tx.CreateFile(ctx, owner, folder2, "file2", "staged")
+	_, _ = tx.CreateFile(ctx, owner, folder1, "file3", "staged")
+	_, _ = tx.Rename(ctx, owner, "folder1", "folder3")
+
+	if got := names(t, i, owner); len(got) != 1 || got[0] != "folder1" {
+		t.Errorf("List() before Commit() got = %v, want [folder1]", got)
+	}
+	if got := names(t, tx, owner); len(got) != 2 || got[0] != "folder2" || got[1] != "folder3" {
+		t.Errorf("List() in a transaction got = %v, want [folder2 folder3]", got)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err = tx.Commit(ctx); err == nil {
+		t.Errorf("Commit() twice error = nil, want error")
+	}
+
+	if got := names(t, i, owner); len(got) != 2 || got[0] != "folder2" || got[1] != "folder3" {
+		t.Errorf("List() after Commit() got = %v, want [folder2 folder3]", got)
+	}
+	folder3, err := i.GetByName(ctx, owner, "folder3")
+	if err != nil || !folder3.CreatedAt.Equal(folder1.CreatedAt) {
+		t.Fatalf("GetByName() got = %v, error = %v", folder3, err)
+	}
+	files, err := i.ListFiles(ctx, owner, folder3, "name", "asc")
+	if err != nil || len(files) != 2 || !files[0].CreatedAt.Equal(file1.CreatedAt) || files[1].Name != "file3" {
+		t.Errorf("ListFiles() got = %v, error = %v", files, err)
+	}
+
+	tx, _ = i.Begin(ctx)
+	_, _ = tx.Create(ctx, owner, "folder4", "")
+	err = tx.Rollback(ctx)
+	if err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if _, err = tx.Create(ctx, owner, "folder5", ""); err == nil {
+		t.Errorf("Create() after Rollback() error = nil, want error")
+	}
+	if got := names(t, i, owner); len(got) != 2 {
+		t.Errorf("List() after Rollback() got = %v, want [folder2 folder3]", got)
+	}
+
+	tx, _ = i.Begin(ctx)
+	_, _ = tx.Create(ctx, owner, "folder4", "")
+	_, _ = i.Create(ctx, owner, "folder6", "")
+	err = tx.Commit(ctx)
+	if err == nil {
+		t.Errorf("Commit() after a concurrent change error = nil, want error")
+	}
+	if got := names(t, i, owner); len(got) != 3 || got[0] != "folder2" || got[2] != "folder6" {
+		t.Errorf("List() after a conflict got = %v, want [folder2 folder3 folder6]", got)
+	}
+}
+
+// checkTxUndo is used to check that a commit of i failing on the folder "broken" of user2, once fail is
+// called, undoes the changes already applied to user1.
+func checkTxUndo(t *testing.T, i repo.FolderManager, user1, user2 *model.User, fail func()) {
+	t.Helper()
+	ctx := context.Background()
+
+	folder1, _ := i.Create(ctx, user1, "folder1", "description")
+	_, _ = i.CreateFile(ctx, user1, folder1, "file1", "description")
+	_, _ = i.Create(ctx, user2, "kept", "description")
+
+	tx, _ := i.Begin(ctx)
+	staged, _ := tx.GetByName(ctx, user1, "folder1")
+	_ = tx.DeleteFile(ctx, user1, staged, "file1")
+	_, _ = tx.Create(ctx, user1, "folder2", "staged")
+	_, _ = tx.Create(ctx, user2, "broken", "staged")
+
+	fail()
+	err := tx.Commit(ctx)
+	if err == nil {
+		t.Fatalf("Commit() failing part way error = nil, want error")
+	}
+
+	if got := names(t, i, user1); len(got) != 1 || got[0] != "folder1" {
+		t.Errorf("List() after a failed Commit() got = %v, want [folder1]", got)
+	}
+	if got := names(t, i, user2); len(got) != 1 || got[0] != "kept" {
+		t.Errorf("List() after a failed Commit() got = %v, want [kept]", got)
+	}
+	files, err := i.ListFiles(ctx, user1, folder1, "name", "asc")
+	if err != nil || len(files) != 1 || files[0].Name != "file1" {
+		t.Errorf("ListFiles() after a failed Commit() got = %v, error = %v, want [file1]", files, err)
+	}
+}
+
+// refusing is used to point the S3 clients created afterward at a proxy of the fake refusing the writes of
+// the keys ending with suffix.
+func refusing(t *testing.T, suffix string) {
+	t.Helper()
+
+	target, _ := url.Parse(os.Getenv("AWS_ENDPOINT_URL_S3"))
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, suffix) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_ENDPOINT_URL_S3", server.URL)
+}
+
+func Test_jsonFile_Begin(t *testing.T) {
+	owner, _ := model.NewUser("user1")
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	checkTx(t, &jsonFile{
+		Mutex: &sync.Mutex{},
+		users: map[string]*model.User{owner.Username: owner},
+		path:  "out/vfs.json",
+	}, owner)
+}
+
+func Test_sharded_Begin(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	owner := &model.User{Username: "user1"}
+	_ = store.WriteFile(store.ShardPath(path, owner.Username), map[string]*model.User{
+		owner.Username: {Username: owner.Username, Folders: map[string]*model.Folder{}},
+	})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	checkTx(t, i, owner)
+}
+
+func Test_sharded_Begin_Undo(t *testing.T) {
+	const path = "out/vfs.shards"
+
+	user1, user2 := &model.User{Username: "user1"}, &model.User{Username: "user2"}
+	for _, user := range []*model.User{user1, user2} {
+		_ = store.WriteFile(store.ShardPath(path, user.Username), map[string]*model.User{
+			user.Username: {Username: user.Username, Folders: map[string]*model.Folder{}},
+		})
+	}
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewSharded(path)
+	if err != nil {
+		t.Fatalf("NewSharded() error = %v", err)
+	}
+
+	// the shard of user2 can't be written once its temporary file is taken by a directory
+	checkTxUndo(t, i, user1, user2, func() {
+		_ = os.MkdirAll(store.ShardPath(path, user2.Username)+".tmp", 0700)
+	})
+}
+
+func Test_objects_Begin(t *testing.T) {
+	path := buckettest.Start(t)
+	owner := &model.User{Username: "user1"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(owner.Username), owner)
+
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkTx(t, i, owner)
+}
+
+func Test_objects_Begin_Undo(t *testing.T) {
+	path := buckettest.Start(t)
+	user1, user2 := &model.User{Username: "user1"}, &model.User{Username: "user2"}
+
+	b, _ := bucket.Open(path)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(user1.Username), user1)
+	_ = b.CreateJSON(context.Background(), bucket.UserKey(user2.Username), user2)
+
+	refusing(t, bucket.FolderKey(user2.Username, "broken"))
+	i, err := NewS3(path)
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+
+	checkTxUndo(t, i, user1, user2, func() {})
+}
+
+func Test_hashes_Begin(t *testing.T) {
+	server := miniredis.RunT(t)
+	owner := &model.User{Username: "user1"}
+	server.HSet(keyspace.UserKey(owner.Username), "username", owner.Username)
+
+	i, err := NewRedis("redis://" + server.Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	checkTx(t, i, owner)
+}
@@ -0,0 +1,203 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+type jsonFile struct {
+	*sync.Mutex
+
+	groups  map[string]*model.Group
+	owners  map[string]*model.User
+	folders repo.FolderManager
+	path    string
+}
+
+// NewJSONFile is used to create a new GroupManager keeping the groups of the store at path with their folders
+// in a JSON file, next to a document or in the directory of a sharded store.
+func NewJSONFile(path string) (repo.GroupManager, error) {
+	doc := &store.Groups{}
+	err := store.ReadGroups(store.GroupsPath(path), doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return newJSONFile(doc, store.GroupsPath(path)), nil
+}
+
+// newJSONFile is used to create a jsonFile over doc written to path, or kept in memory when path is empty.
+func newJSONFile(doc *store.Groups, path string) *jsonFile {
+	if doc.Groups == nil {
+		doc.Groups = make(map[string]*model.Group)
+	}
+	if doc.Owners == nil {
+		doc.Owners = make(map[string]*model.User)
+	}
+
+	i := &jsonFile{
+		Mutex:  &sync.Mutex{},
+		groups: doc.Groups,
+		owners: doc.Owners,
+		path:   path,
+	}
+	// the folders are saved with the groups, under the same lock
+	i.folders = folder.NewOwned(i.Mutex, i.owners, i.Save)
+
+	return i
+}
+
+func (i *jsonFile) Create(ctx context.Context, name string, creator *model.User) (item *model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if _, exists := i.groups[name]; exists {
+		return nil, fmt.Errorf("the %s has already existed", name)
+	}
+
+	group, err := model.NewGroup(name, creator)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := group.Owner()
+	owner.Folders = make(map[string]*model.Folder)
+	i.groups[name] = group
+	i.owners[owner.Username] = owner
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (i *jsonFile) GetByName(ctx context.Context, name string) (item *model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	group, exists := i.groups[name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", name)
+	}
+
+	return group, nil
+}
+
+func (i *jsonFile) AddMember(ctx context.Context, name string, member *model.User) (item *model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	group, exists := i.groups[name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", name)
+	}
+
+	if group.HasMember(member.Username) {
+		return nil, fmt.Errorf("the %s is already a member of %s", member.Username, name)
+	}
+
+	group.Members = append(group.Members, member.Username)
+	sort.Strings(group.Members)
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (i *jsonFile) RemoveMember(ctx context.Context, name string, member *model.User) (item *model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	group, exists := i.groups[name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", name)
+	}
+
+	if !group.HasMember(member.Username) {
+		return nil, fmt.Errorf("the %s isn't a member of %s", member.Username, name)
+	}
+
+	// a group without members would keep its folders out of reach
+	if len(group.Members) == 1 {
+		return nil, fmt.Errorf("the %s is the last member of %s", member.Username, name)
+	}
+
+	members := make([]string, 0, len(group.Members)-1)
+	for _, username := range group.Members {
+		if username != member.Username {
+			members = append(members, username)
+		}
+	}
+	group.Members = members
+
+	err = i.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (i *jsonFile) SetQuota(ctx context.Context, name string, quota *model.Quota) (item *model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	group, exists := i.groups[name]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", name)
+	}
+
+	previous := group.Quota
+	group.Quota = quota
+
+	err = i.Save()
+	if err != nil {
+		group.Quota = previous
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func (i *jsonFile) List(ctx context.Context, member *model.User) (items []*model.Group, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	for _, group := range i.groups {
+		if group.HasMember(member.Username) {
+			items = append(items, group)
+		}
+	}
+
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].Name < items[b].Name
+	})
+
+	return items, nil
+}
+
+func (i *jsonFile) Folders() repo.FolderManager {
+	return i.folders
+}
+
+// Save is used to save the groups to the file, the lock must be held.
+func (i *jsonFile) Save() (err error) {
+	// groups kept in memory have no path
+	if i.path == "" {
+		return nil
+	}
+
+	return store.WriteGroups(i.path, &store.Groups{Groups: i.groups, Owners: i.owners})
+}
@@ -0,0 +1,161 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+)
+
+// checkGroups is used to check that i manages the groups of user1 and user2 and their folders.
+func checkGroups(t *testing.T, i repo.GroupManager) {
+	t.Helper()
+	ctx := context.Background()
+	user1 := &model.User{Username: "user1"}
+	user2 := &model.User{Username: "user2"}
+
+	group, err := i.Create(ctx, "team", user1)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err = i.Create(ctx, "team", user2); err == nil {
+		t.Errorf("Create() an existing group error = nil, want error")
+	}
+	if _, err = i.Create(ctx, "invalid name", user1); err == nil {
+		t.Errorf("Create() an invalid name error = nil, want error")
+	}
+
+	if _, err = i.AddMember(ctx, "missing", user2); err == nil {
+		t.Errorf("AddMember() to a missing group error = nil, want error")
+	}
+	if _, err = i.AddMember(ctx, "team", user1); err == nil {
+		t.Errorf("AddMember() an existing member error = nil, want error")
+	}
+	group, err = i.AddMember(ctx, "team", user2)
+	if err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if want := []string{"user1", "user2"}; !reflect.DeepEqual(group.Members, want) {
+		t.Errorf("AddMember() members = %v, want %v", group.Members, want)
+	}
+
+	if _, err = i.SetQuota(ctx, "missing", &model.Quota{MaxFolders: 1}); err == nil {
+		t.Errorf("SetQuota() of a missing group error = nil, want error")
+	}
+	group, err = i.SetQuota(ctx, "team", &model.Quota{MaxFolders: 1})
+	if err != nil {
+		t.Fatalf("SetQuota() error = %v", err)
+	}
+	if quota := group.Owner().Quota; quota == nil || quota.MaxFolders != 1 {
+		t.Errorf("SetQuota() owner quota = %v, want 1 folder", quota)
+	}
+
+	folder, err := i.Folders().Create(ctx, group.Owner(), "folder1", "")
+	if err != nil {
+		t.Fatalf("Folders().Create() error = %v", err)
+	}
+	_, err = i.Folders().CreateFile(ctx, group.Owner(), folder, "file1", "")
+	if err != nil {
+		t.Fatalf("Folders().CreateFile() error = %v", err)
+	}
+
+	if items, _ := i.List(ctx, user2); len(items) != 1 || items[0].Name != "team" {
+		t.Errorf("List() got = %v, want [team]", items)
+	}
+
+	if _, err = i.RemoveMember(ctx, "team", &model.User{Username: "user3"}); err == nil {
+		t.Errorf("RemoveMember() a missing member error = nil, want error")
+	}
+	if _, err = i.RemoveMember(ctx, "team", user1); err != nil {
+		t.Fatalf("RemoveMember() error = %v", err)
+	}
+	if _, err = i.RemoveMember(ctx, "team", user2); err == nil {
+		t.Errorf("RemoveMember() the last member error = nil, want error")
+	}
+	if items, _ := i.List(ctx, user1); len(items) != 0 {
+		t.Errorf("List() after RemoveMember() got = %v, want none", items)
+	}
+}
+
+func Test_jsonFile(t *testing.T) {
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	i, err := NewJSONFile("out/vfs.json")
+	if err != nil {
+		t.Fatalf("NewJSONFile() error = %v", err)
+	}
+	checkGroups(t, i)
+
+	// the groups and their folders are read back from the file
+	reloaded, err := NewJSONFile("out/vfs.json")
+	if err != nil {
+		t.Fatalf("NewJSONFile() error = %v", err)
+	}
+	group, err := reloaded.GetByName(context.Background(), "team")
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+	if want := []string{"user2"}; !reflect.DeepEqual(group.Members, want) {
+		t.Errorf("GetByName() members = %v, want %v", group.Members, want)
+	}
+	if group.Quota == nil || group.Quota.MaxFolders != 1 {
+		t.Errorf("GetByName() quota = %v, want 1 folder", group.Quota)
+	}
+
+	files, err := reloaded.Folders().ListFiles(
+		context.Background(),
+		group.Owner(),
+		&model.Folder{Name: "folder1"},
+		"name",
+		"asc",
+	)
+	if err != nil || len(files) != 1 {
+		t.Errorf("Folders().ListFiles() got = %v, error = %v, want [file1]", files, err)
+	}
+}
+
+func Test_memory(t *testing.T) {
+	i, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	checkGroups(t, i)
+
+	if _, err = os.Stat("out"); !os.IsNotExist(err) {
+		t.Errorf("NewMemory() wrote out, error = %v", err)
+	}
+}
+
+func Test_unsupported(t *testing.T) {
+	i, err := NewUnsupported()
+	if err != nil {
+		t.Fatalf("NewUnsupported() error = %v", err)
+	}
+
+	ctx := context.Background()
+	user1 := &model.User{Username: "user1"}
+	if _, err = i.Create(ctx, "team", user1); !errors.Is(err, errUnsupported) {
+		t.Errorf("Create() error = %v, want %v", err, errUnsupported)
+	}
+	if _, err = i.GetByName(ctx, "team"); !errors.Is(err, errUnsupported) {
+		t.Errorf("GetByName() error = %v, want %v", err, errUnsupported)
+	}
+	if _, err = i.AddMember(ctx, "team", user1); !errors.Is(err, errUnsupported) {
+		t.Errorf("AddMember() error = %v, want %v", err, errUnsupported)
+	}
+	if _, err = i.SetQuota(ctx, "team", &model.Quota{MaxFolders: 1}); !errors.Is(err, errUnsupported) {
+		t.Errorf("SetQuota() error = %v, want %v", err, errUnsupported)
+	}
+
+	// nobody belongs to a group, so the users are still deleted or renamed
+	if items, err := i.List(ctx, user1); err != nil || len(items) != 0 {
+		t.Errorf("List() got = %v, error = %v, want none", items, err)
+	}
+}
@@ -0,0 +1,12 @@
+package group
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// NewMemory is used to create a new GroupManager keeping the groups with their folders in memory, lost when
+// the process exits, like for the tests.
+func NewMemory() (repo.GroupManager, error) {
+	return newJSONFile(&store.Groups{}, ""), nil
+}
@@ -0,0 +1,48 @@
+package group
+
+import (
+	"context"
+	"errors"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+// errUnsupported is returned by the groups of the stores which can't keep them.
+var errUnsupported = errors.New("the groups can't be kept by this store, only the document and sharded stores do")
+
+type unsupported struct {
+	*jsonFile
+}
+
+// NewUnsupported is used to create a new GroupManager for the stores which can't keep the groups, like S3 buckets,
+// Redis and directory stores. Nobody belongs to a group there, and creating or changing one fails rather than
+// keeping it until the process exits.
+func NewUnsupported() (repo.GroupManager, error) {
+	return &unsupported{jsonFile: newJSONFile(&store.Groups{}, "")}, nil
+}
+
+func (i *unsupported) Create(ctx context.Context, name string, creator *model.User) (item *model.Group, err error) {
+	return nil, errUnsupported
+}
+
+func (i *unsupported) GetByName(ctx context.Context, name string) (item *model.Group, err error) {
+	return nil, errUnsupported
+}
+
+func (i *unsupported) AddMember(ctx context.Context, name string, member *model.User) (item *model.Group, err error) {
+	return nil, errUnsupported
+}
+
+func (i *unsupported) RemoveMember(
+	ctx context.Context,
+	name string,
+	member *model.User,
+) (item *model.Group, err error) {
+	return nil, errUnsupported
+}
+
+func (i *unsupported) SetQuota(ctx context.Context, name string, quota *model.Quota) (item *model.Group, err error) {
+	return nil, errUnsupported
+}
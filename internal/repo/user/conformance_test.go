@@ -0,0 +1,393 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+)
+
+// Test_conformance checks the behaviors every backend shares, each on a new store of the backend. The folders of
+// a backend are nil when its FolderManager can't keep them.
+func Test_conformance(t *testing.T) {
+	backends := []struct {
+		name      string
+		open      func(t *testing.T) (repo.UserManager, repo.FolderManager)
+		behaviors []string
+	}{
+		{
+			name: "jsonFile",
+			open: func(t *testing.T) (repo.UserManager, repo.FolderManager) {
+				path := filepath.Join(t.TempDir(), "vfs.json")
+				i, err := NewJSONFile(path)
+				if err != nil {
+					t.Fatalf("NewJSONFile() error = %v", err)
+				}
+				folders, err := folder.NewJSONFile(path)
+				if err != nil {
+					t.Fatalf("folder.NewJSONFile() error = %v", err)
+				}
+				return i, folders
+			},
+			behaviors: []string{"roles", "quota", "auth", "lifecycle"},
+		},
+		{
+			name: "sharded",
+			open: func(t *testing.T) (repo.UserManager, repo.FolderManager) {
+				path := filepath.Join(t.TempDir(), "vfs.shards")
+				i, _ := NewSharded(path)
+				folders, _ := folder.NewSharded(path)
+				return i, folders
+			},
+			behaviors: []string{"roles", "quota", "auth", "lifecycle"},
+		},
+		{
+			name: "objects",
+			open: func(t *testing.T) (repo.UserManager, repo.FolderManager) {
+				i, err := NewS3(buckettest.Start(t))
+				if err != nil {
+					t.Fatalf("NewS3() error = %v", err)
+				}
+				return i, nil
+			},
+			behaviors: []string{"roles", "quota", "auth"},
+		},
+		{
+			name: "hashes",
+			open: func(t *testing.T) (repo.UserManager, repo.FolderManager) {
+				i, err := NewRedis("redis://" + miniredis.RunT(t).Addr())
+				if err != nil {
+					t.Fatalf("NewRedis() error = %v", err)
+				}
+				return i, nil
+			},
+			behaviors: []string{"roles", "quota", "auth"},
+		},
+		{
+			name: "system",
+			open: func(t *testing.T) (repo.UserManager, repo.FolderManager) {
+				i, _ := NewSystem(filepath.Join(t.TempDir(), "vfs"))
+				return i, nil
+			},
+			behaviors: []string{"lifecycle"},
+		},
+	}
+
+	behaviors := []struct {
+		name  string
+		check func(t *testing.T, i repo.UserManager, folders repo.FolderManager)
+	}{
+		{name: "roles", check: checkRoles},
+		{name: "quota", check: checkQuota},
+		{name: "auth", check: checkAuth},
+		{name: "lifecycle", check: checkLifecycle},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			for _, behavior := range behaviors {
+				if !slices.Contains(backend.behaviors, behavior.name) {
+					continue
+				}
+
+				t.Run(behavior.name, func(t *testing.T) {
+					i, folders := backend.open(t)
+					behavior.check(t, i, folders)
+				})
+			}
+		})
+	}
+}
+
+// checkRoles is used to check that i keeps the roles of its users.
+func checkRoles(t *testing.T, i repo.UserManager, _ repo.FolderManager) {
+	t.Helper()
+	ctx := context.Background()
+
+	_, err := i.Register(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	user, err := i.GetByUsername(ctx, "user1")
+	if err != nil || !user.Is(model.RoleMember) {
+		t.Errorf("GetByUsername() a new user got = %v, error = %v, want a member", user, err)
+	}
+
+	user, err = i.SetRole(ctx, "user1", model.RoleAdmin)
+	if err != nil || user.Role != model.RoleAdmin {
+		t.Errorf("SetRole() got = %v, error = %v", user, err)
+	}
+
+	user, err = i.GetByUsername(ctx, "user1")
+	if err != nil || user.Role != model.RoleAdmin {
+		t.Errorf("GetByUsername() after SetRole() got = %v, error = %v", user, err)
+	}
+
+	_, err = i.SetRole(ctx, "user2", model.RoleAdmin)
+	if err == nil {
+		t.Errorf("SetRole() a missing user error = nil, want error")
+	}
+}
+
+// checkQuota is used to check that i keeps the quotas of its users, and removes them.
+func checkQuota(t *testing.T, i repo.UserManager, _ repo.FolderManager) {
+	t.Helper()
+	ctx := context.Background()
+	quota := model.Quota{MaxFolders: 2, MaxFolderFiles: 3, MaxFiles: 5, MaxBytes: 1024}
+
+	_, err := i.Register(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	user, err := i.SetQuota(ctx, "user1", &quota)
+	if err != nil || user.Quota == nil || *user.Quota != quota {
+		t.Errorf("SetQuota() got = %v, error = %v", user, err)
+	}
+
+	user, err = i.GetByUsername(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetByUsername() error = %v", err)
+	}
+	if user.Quota == nil || *user.Quota != quota {
+		t.Errorf("GetByUsername() quota = %v, want %v", user.Quota, quota)
+	}
+
+	_, err = i.SetQuota(ctx, "user1", nil)
+	if err != nil {
+		t.Errorf("SetQuota() nil error = %v", err)
+	}
+
+	user, err = i.GetByUsername(ctx, "user1")
+	if err != nil || user.Quota != nil {
+		t.Errorf("GetByUsername() quota = %v, error = %v, want nil", user.Quota, err)
+	}
+
+	_, err = i.SetQuota(ctx, "user2", &quota)
+	if err == nil {
+		t.Errorf("SetQuota() a missing user error = nil, want error")
+	}
+}
+
+// checkAuth is used to check that i keeps the password hashes, the sessions and the tokens of its users, the
+// sessions and the tokens added concurrently included.
+func checkAuth(t *testing.T, i repo.UserManager, _ repo.FolderManager) {
+	t.Helper()
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	_, err := i.Register(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	user, err := i.SetPassword(ctx, "user1", "$argon2id$hash")
+	if err != nil || user.PasswordHash != "$argon2id$hash" {
+		t.Errorf("SetPassword() got = %v, error = %v", user, err)
+	}
+
+	_, err = i.AddSession(ctx, "user1", "expired-hash", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Errorf("AddSession() error = %v", err)
+	}
+	user, err = i.AddSession(ctx, "user1", "token-hash", expiresAt)
+	if err != nil || len(user.Sessions) != 1 {
+		t.Errorf("AddSession() got = %v, error = %v, want the expired session dropped", user, err)
+	}
+
+	user, err = i.GetByUsername(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetByUsername() error = %v", err)
+	}
+	if user.PasswordHash != "$argon2id$hash" {
+		t.Errorf("GetByUsername() password hash = %v, want $argon2id$hash", user.PasswordHash)
+	}
+	if !user.Sessions["token-hash"].Equal(expiresAt) {
+		t.Errorf("GetByUsername() sessions = %v, want token-hash", user.Sessions)
+	}
+
+	token := &model.Token{
+		ID:        "abcd1234",
+		Hash:      "token-hash",
+		Scopes:    []model.Scope{model.ScopeFilesWrite},
+		ExpiresAt: expiresAt,
+	}
+	user, err = i.AddToken(ctx, "user1", token)
+	if err != nil || len(user.Tokens) != 1 {
+		t.Errorf("AddToken() got = %v, error = %v", user, err)
+	}
+	if _, err = i.AddToken(ctx, "user1", token); err == nil {
+		t.Errorf("AddToken() a taken ID error = nil, want error")
+	}
+
+	user, err = i.GetByUsername(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetByUsername() error = %v", err)
+	}
+	if got := user.Tokens["abcd1234"]; got == nil || got.Hash != "token-hash" || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("GetByUsername() tokens = %v, want abcd1234", user.Tokens)
+	}
+
+	// the concurrent changes of the sessions and the tokens are all kept, four writers being less than the
+	// attempts of the stores which retry
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("token%d", n)
+			if n%2 == 0 {
+				_, err := i.AddToken(ctx, "user1", &model.Token{ID: id, ExpiresAt: expiresAt})
+				if err != nil {
+					t.Errorf("AddToken() concurrently error = %v", err)
+				}
+				return
+			}
+			if _, err := i.AddSession(ctx, "user1", id+"-hash", expiresAt); err != nil {
+				t.Errorf("AddSession() concurrently error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	user, _ = i.GetByUsername(ctx, "user1")
+	if len(user.Tokens) != 3 || len(user.Sessions) != 3 {
+		t.Errorf("GetByUsername() tokens = %v, sessions = %v, want all of them", user.Tokens, user.Sessions)
+	}
+
+	user, err = i.RemoveToken(ctx, "user1", "abcd1234")
+	if err != nil || user.Tokens["abcd1234"] != nil || len(user.Tokens) != 2 {
+		t.Errorf("RemoveToken() got = %v, error = %v", user, err)
+	}
+	if _, err = i.RemoveToken(ctx, "user1", "abcd1234"); err == nil {
+		t.Errorf("RemoveToken() a missing token error = nil, want error")
+	}
+
+	user, err = i.RemoveSession(ctx, "user1", "token-hash")
+	if err != nil || len(user.Sessions) != 2 {
+		t.Errorf("RemoveSession() got = %v, error = %v", user, err)
+	}
+
+	_, err = i.SetPassword(ctx, "user2", "$argon2id$hash")
+	if err == nil {
+		t.Errorf("SetPassword() a missing user error = nil, want error")
+	}
+	_, err = i.AddSession(ctx, "user2", "token-hash", expiresAt)
+	if err == nil {
+		t.Errorf("AddSession() a missing user error = nil, want error")
+	}
+}
+
+// checkLifecycle is used to check that i lists, renames and deletes its users, with folders created by folders
+// when it isn't nil.
+func checkLifecycle(t *testing.T, i repo.UserManager, folders repo.FolderManager) {
+	t.Helper()
+	ctx := context.Background()
+
+	for _, username := range []string{"user1", "user2", "user3"} {
+		_, err := i.Register(ctx, username)
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	usernames := func(order string, offset, limit int) (got []string) {
+		users, err := i.List(ctx, order, offset, limit)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, user := range users {
+			got = append(got, user.Username)
+		}
+		return got
+	}
+	if got := usernames("asc", 0, 0); !reflect.DeepEqual(got, []string{"user1", "user2", "user3"}) {
+		t.Errorf("List() = %v, want all the users", got)
+	}
+	if got := usernames("desc", 1, 1); !reflect.DeepEqual(got, []string{"user2"}) {
+		t.Errorf("List() a page = %v, want user2", got)
+	}
+	if got := usernames("asc", 3, 1); len(got) != 0 {
+		t.Errorf("List() after the last page = %v, want none", got)
+	}
+
+	if _, err := i.Rename(ctx, "user1", "user2"); err == nil {
+		t.Errorf("Rename() to an existing user error = nil, want error")
+	}
+	if _, err := i.Rename(ctx, "user1", "invalid!"); err == nil {
+		t.Errorf("Rename() to an invalid username error = nil, want error")
+	}
+	if _, err := i.Rename(ctx, "missing", "user4"); err == nil {
+		t.Errorf("Rename() a missing user error = nil, want error")
+	}
+
+	if folders != nil {
+		user1, _ := i.GetByUsername(ctx, "user1")
+		user2, _ := i.GetByUsername(ctx, "user2")
+		_, _ = folders.Create(ctx, user1, "folder1", "")
+		_, _ = folders.Create(ctx, user2, "folder2", "")
+		_ = folders.Share(ctx, user2, "folder2", user1, model.AccessWrite)
+		_, _ = i.AddToken(ctx, "user1", &model.Token{ID: "token1"})
+		_, _ = i.AddSession(ctx, "user1", "hash", time.Now().Add(time.Hour))
+	}
+
+	user, err := i.Rename(ctx, "user1", "user4")
+	if err != nil || user.Username != "user4" {
+		t.Fatalf("Rename() got = %v, error = %v", user, err)
+	}
+	if _, err = i.GetByUsername(ctx, "user1"); err == nil {
+		t.Errorf("GetByUsername() a renamed user error = nil, want error")
+	}
+
+	if folders != nil {
+		user4, _ := i.GetByUsername(ctx, "user4")
+		if len(user4.Tokens) != 0 || len(user4.Sessions) != 0 {
+			t.Errorf("Rename() kept the tokens %v and the sessions %v, want them revoked", user4.Tokens, user4.Sessions)
+		}
+		if _, err = folders.GetByName(ctx, user4, "folder1"); err != nil {
+			t.Errorf("GetByName() a folder of a renamed user error = %v", err)
+		}
+
+		shared, _ := folders.ListShared(ctx, user4)
+		if len(shared) != 1 || shared[0].Access != model.AccessWrite {
+			t.Errorf("ListShared() a renamed user = %v, want folder2", shared)
+		}
+
+		if err = i.Delete(ctx, "user4", false); err == nil {
+			t.Errorf("Delete() a user with folders error = nil, want error")
+		}
+	}
+
+	if err = i.Delete(ctx, "user4", true); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err = i.Delete(ctx, "user4", true); err == nil {
+		t.Errorf("Delete() a deleted user error = nil, want error")
+	}
+	if got := usernames("asc", 0, 0); !reflect.DeepEqual(got, []string{"user2", "user3"}) {
+		t.Errorf("List() after Delete() = %v", got)
+	}
+
+	if folders != nil {
+		user2, _ := i.GetByUsername(ctx, "user2")
+		folder2, _ := folders.GetByName(ctx, user2, "folder2")
+		if len(folder2.Shares) != 0 {
+			t.Errorf("Delete() kept the shares %v", folder2.Shares)
+		}
+	}
+
+	if err = i.Delete(ctx, "user3", false); err != nil {
+		t.Errorf("Delete() a user without folders error = %v", err)
+	}
+}
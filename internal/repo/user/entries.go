@@ -0,0 +1,72 @@
+package user
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// addSession is used to add the session of tokenHash expiring at expiresAt to user, dropping the expired ones.
+// Like the other entries below, the sessions are copied rather than changed, so the user can be put back.
+func addSession(user *model.User, tokenHash string, expiresAt time.Time) error {
+	sessions := unexpired(user.Sessions)
+	sessions[tokenHash] = expiresAt
+	user.Sessions = sessions
+
+	return nil
+}
+
+// removeSession is used to end the session of tokenHash of user, which may have ended already.
+func removeSession(user *model.User, tokenHash string) error {
+	sessions := unexpired(user.Sessions)
+	delete(sessions, tokenHash)
+	user.Sessions = sessions
+
+	return nil
+}
+
+// addToken is used to add token to user, unless its ID is taken.
+func addToken(user *model.User, token *model.Token) error {
+	if _, exists := user.Tokens[token.ID]; exists {
+		return fmt.Errorf("the token %s has already existed, try again", token.ID)
+	}
+
+	tokens := maps.Clone(user.Tokens)
+	if tokens == nil {
+		tokens = make(map[string]*model.Token)
+	}
+	tokens[token.ID] = token
+	user.Tokens = tokens
+
+	return nil
+}
+
+// removeToken is used to revoke the token id of user.
+func removeToken(user *model.User, id string) error {
+	if _, exists := user.Tokens[id]; !exists {
+		return fmt.Errorf("the token %s doesn't exist", id)
+	}
+
+	tokens := maps.Clone(user.Tokens)
+	delete(tokens, id)
+	user.Tokens = tokens
+
+	return nil
+}
+
+// unexpired is used to copy the sessions which haven't expired yet, so they don't pile up.
+func unexpired(sessions map[string]time.Time) map[string]time.Time {
+	kept := maps.Clone(sessions)
+	if kept == nil {
+		kept = make(map[string]time.Time)
+	}
+
+	now := time.Now()
+	maps.DeleteFunc(kept, func(_ string, expiresAt time.Time) bool {
+		return !now.Before(expiresAt)
+	})
+
+	return kept
+}
@@ -2,18 +2,17 @@ package user
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"sync"
+	"time"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
-	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
 )
 
 type jsonFile struct {
-	sync.Mutex
+	*sync.Mutex
 
 	users map[string]*model.User
 	path  string
@@ -21,18 +20,17 @@ type jsonFile struct {
 
 // NewJSONFile is used to create a new JSONFile.
 func NewJSONFile(path string) (repo.UserManager, error) {
-	instance := &jsonFile{
-		Mutex: sync.Mutex{},
-		users: make(map[string]*model.User),
-		path:  path,
-	}
-
-	err := instance.Load()
+	// the document is shared with the other manager of path, so neither works on a stale copy
+	doc, err := store.OpenDocument(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return instance, nil
+	return &jsonFile{
+		Mutex: &doc.Mutex,
+		users: doc.Users,
+		path:  path,
+	}, nil
 }
 
 func (i *jsonFile) Register(ctx context.Context, username string) (item *model.User, err error) {
@@ -70,31 +68,168 @@ func (i *jsonFile) GetByUsername(ctx context.Context, username string) (item *mo
 	return user, nil
 }
 
-// Save is used to save the data to the file.
-func (i *jsonFile) Save() (err error) {
-	// Ensure the directory exists
-	if err = utils.EnsureDir(i.path); err != nil {
-		return fmt.Errorf("failed to ensure directory: %w", err)
+func (i *jsonFile) SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		user.Role = role
+		return nil
+	})
+}
+
+func (i *jsonFile) SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+func (i *jsonFile) AddSession(
+	ctx context.Context,
+	username, tokenHash string,
+	expiresAt time.Time,
+) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		return addSession(user, tokenHash, expiresAt)
+	})
+}
+
+func (i *jsonFile) RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		return removeSession(user, tokenHash)
+	})
+}
+
+func (i *jsonFile) AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		return addToken(user, token)
+	})
+}
+
+func (i *jsonFile) RemoveToken(ctx context.Context, username, id string) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		return removeToken(user, id)
+	})
+}
+
+func (i *jsonFile) SetQuota(
+	ctx context.Context,
+	username string,
+	quota *model.Quota,
+) (item *model.User, err error) {
+	return i.update(username, func(user *model.User) error {
+		user.Quota = quota
+		return nil
+	})
+}
+
+// update is used to change the user username and save it, putting it back when the change or the save fails.
+func (i *jsonFile) update(username string, change func(user *model.User) error) (item *model.User, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
 	}
 
-	data, err := json.MarshalIndent(i.users, "", "  ")
+	previous := *user
+	err = change(user)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data: %w", err)
+		*user = previous
+		return nil, err
 	}
 
-	return os.WriteFile(i.path, data, 0600)
+	err = i.Save()
+	if err != nil {
+		*user = previous
+		return nil, err
+	}
+
+	return user, nil
 }
 
-// Load is used to load the data from the file.
-func (i *jsonFile) Load() (err error) {
-	data, err := os.ReadFile(i.path)
+func (i *jsonFile) Delete(ctx context.Context, username string, cascade bool) (err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[username]
+	if !exists {
+		return fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	if len(user.Folders) > 0 && !cascade {
+		return fmt.Errorf("the %s still has folders, delete them first or cascade", username)
+	}
+
+	delete(i.users, username)
+	_, undo := regrant(i.users, username, "")
+
+	err = i.Save()
+	if err != nil {
+		undo()
+		i.users[username] = user
+		return err
+	}
+
+	return nil
+}
+
+func (i *jsonFile) Rename(ctx context.Context, username, newUsername string) (item *model.User, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, exists := i.users[username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	if _, exists = i.users[newUsername]; exists {
+		return nil, fmt.Errorf("the %s has already existed", newUsername)
+	}
+
+	err = model.ValidateInput(newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(i.users, username)
+	unrename := renamed(user, newUsername)
+	i.users[newUsername] = user
+	_, undo := regrant(i.users, username, newUsername)
+
+	err = i.Save()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+		undo()
+		delete(i.users, newUsername)
+		unrename()
+		i.users[username] = user
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (i *jsonFile) List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error) {
+	i.Lock()
+	defer i.Unlock()
 
-		return fmt.Errorf("failed to read file: %w", err)
+	usernames := make([]string, 0, len(i.users))
+	for username := range i.users {
+		usernames = append(usernames, username)
 	}
 
-	return json.Unmarshal(data, &i.users)
+	for _, username := range page(usernames, order, offset, limit) {
+		items = append(items, i.users[username])
+	}
+
+	return items, nil
+}
+
+// Save is used to save the data to the file.
+func (i *jsonFile) Save() (err error) {
+	return store.WriteFile(i.path, i.users)
+}
+
+// Load is used to load the data from the file.
+func (i *jsonFile) Load() (err error) {
+	return store.ReadFile(i.path, &i.users)
 }
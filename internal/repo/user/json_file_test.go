@@ -3,6 +3,7 @@ package user
 import (
 	"context"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
@@ -73,6 +74,7 @@ func Test_jsonFile_Save(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
+				Mutex: &sync.Mutex{},
 				users: tt.fields.users,
 				path:  tt.fields.path,
 			}
@@ -116,6 +118,7 @@ func Test_jsonFile_Load(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
+				Mutex: &sync.Mutex{},
 				users: tt.fields.users,
 				path:  tt.fields.path,
 			}
@@ -172,6 +175,7 @@ func Test_jsonFile_Register(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
+				Mutex: &sync.Mutex{},
 				users: tt.fields.users,
 				path:  tt.fields.path,
 			}
@@ -229,6 +233,7 @@ func Test_jsonFile_GetByUsername(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			i := &jsonFile{
+				Mutex: &sync.Mutex{},
 				users: tt.fields.users,
 				path:  tt.fields.path,
 			}
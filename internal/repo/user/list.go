@@ -0,0 +1,33 @@
+package user
+
+import (
+	"errors"
+	"sort"
+)
+
+// errUnsupported is returned by the stores which can't remove or rename a user with its folders and shares.
+var errUnsupported = errors.New(
+	"the users can't be removed or renamed in this store, only in the document and sharded stores",
+)
+
+// page is used to sort usernames, ascending unless order is desc, and to keep the limit usernames after offset,
+// all of them without a limit.
+func page(usernames []string, order string, offset, limit int) []string {
+	sort.Slice(usernames, func(i, j int) bool {
+		if order == "desc" {
+			return usernames[i] > usernames[j]
+		}
+		return usernames[i] < usernames[j]
+	})
+
+	if offset >= len(usernames) {
+		return nil
+	}
+	usernames = usernames[offset:]
+
+	if limit > 0 && limit < len(usernames) {
+		usernames = usernames[:limit]
+	}
+
+	return usernames
+}
@@ -0,0 +1,279 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/keyspace"
+	"github.com/redis/go-redis/v9"
+)
+
+type hashes struct {
+	rdb *redis.Client
+}
+
+// NewRedis is used to create a new UserManager backed by one Redis hash per user.
+func NewRedis(path string) (repo.UserManager, error) {
+	rdb, err := keyspace.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hashes{
+		rdb: rdb,
+	}, nil
+}
+
+func (i *hashes) Register(ctx context.Context, username string) (item *model.User, err error) {
+	user, err := model.NewUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := i.rdb.HSetNX(ctx, keyspace.UserKey(username), "username", username).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", username, err)
+	}
+	if !created {
+		return nil, fmt.Errorf("the %s has already existed", username)
+	}
+
+	return user, nil
+}
+
+func (i *hashes) GetByUsername(ctx context.Context, username string) (item *model.User, err error) {
+	// invalid usernames can't have a hash and must not reach the keys of another user
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	return getUser(ctx, i.rdb, username)
+}
+
+func (i *hashes) SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) {
+		user.Role = role
+	}, "role", string(role))
+}
+
+func (i *hashes) SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) {
+		user.PasswordHash = passwordHash
+	}, "password_hash", passwordHash)
+}
+
+func (i *hashes) AddSession(
+	ctx context.Context,
+	username, tokenHash string,
+	expiresAt time.Time,
+) (item *model.User, err error) {
+	return i.updateEntries(ctx, username, "sessions", func(user *model.User) error {
+		return addSession(user, tokenHash, expiresAt)
+	})
+}
+
+func (i *hashes) RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error) {
+	return i.updateEntries(ctx, username, "sessions", func(user *model.User) error {
+		return removeSession(user, tokenHash)
+	})
+}
+
+func (i *hashes) AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error) {
+	return i.updateEntries(ctx, username, "tokens", func(user *model.User) error {
+		return addToken(user, token)
+	})
+}
+
+func (i *hashes) RemoveToken(ctx context.Context, username, id string) (item *model.User, err error) {
+	return i.updateEntries(ctx, username, "tokens", func(user *model.User) error {
+		return removeToken(user, id)
+	})
+}
+
+func (i *hashes) SetQuota(
+	ctx context.Context,
+	username string,
+	quota *model.Quota,
+) (item *model.User, err error) {
+	value, err := json.Marshal(quota)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the quota of %s: %w", username, err)
+	}
+
+	return i.update(ctx, username, func(user *model.User) {
+		user.Quota = quota
+	}, "quota", string(value))
+}
+
+// update is used to set the fields and values of the hash of the user username, which change applies to the
+// user read back.
+func (i *hashes) update(
+	ctx context.Context,
+	username string,
+	change func(user *model.User),
+	values ...any,
+) (item *model.User, err error) {
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	// the user is watched, so a field is never set on a user being removed
+	err = i.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		item, err = getUser(ctx, tx, username)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, keyspace.UserKey(username), values...).Err()
+		})
+		if err != nil {
+			return err
+		}
+
+		change(item)
+		return nil
+	}, keyspace.UserKey(username))
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil, errors.New("too many concurrent changes, try again")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// updateEntries is used to change the sessions or the tokens of the user username, field naming which, and set
+// that field of its hash to the entries left. The hash is watched from the read, so a change made concurrently is
+// never lost: the change is tried again on the user read back instead.
+func (i *hashes) updateEntries(
+	ctx context.Context,
+	username, field string,
+	change func(user *model.User) error,
+) (item *model.User, err error) {
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		err = i.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			item, err = getUser(ctx, tx, username)
+			if err != nil {
+				return err
+			}
+
+			err = change(item)
+			if err != nil {
+				return err
+			}
+
+			entries := map[string]any{"sessions": item.Sessions, "tokens": item.Tokens}
+			value, err := json.Marshal(entries[field])
+			if err != nil {
+				return fmt.Errorf("failed to encode the %s of %s: %w", field, username, err)
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				return pipe.HSet(ctx, keyspace.UserKey(username), field, string(value)).Err()
+			})
+			return err
+		}, keyspace.UserKey(username))
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return item, nil
+	}
+
+	return nil, fmt.Errorf("the %s is being changed concurrently, try again", username)
+}
+
+func getUser(ctx context.Context, rdb redis.Cmdable, username string) (*model.User, error) {
+	fields, err := rdb.HGetAll(ctx, keyspace.UserKey(username)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", username, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	var sessions map[string]time.Time
+	if fields["sessions"] != "" {
+		err = json.Unmarshal([]byte(fields["sessions"]), &sessions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the sessions of %s: %w", username, err)
+		}
+	}
+
+	var tokens map[string]*model.Token
+	if fields["tokens"] != "" {
+		err = json.Unmarshal([]byte(fields["tokens"]), &tokens)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the tokens of %s: %w", username, err)
+		}
+	}
+
+	var quota *model.Quota
+	if fields["quota"] != "" {
+		err = json.Unmarshal([]byte(fields["quota"]), &quota)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the quota of %s: %w", username, err)
+		}
+	}
+
+	// folders are separate hashes, listed by the FolderManager
+	return &model.User{
+		Username:     username,
+		Folders:      make(map[string]*model.Folder),
+		Role:         model.Role(fields["role"]),
+		PasswordHash: fields["password_hash"],
+		Sessions:     sessions,
+		Tokens:       tokens,
+		Quota:        quota,
+	}, nil
+}
+
+// Delete can't remove the folders of the user nor update the shares granted to it across the store in one
+// transaction, so it isn't supported.
+func (i *hashes) Delete(ctx context.Context, username string, cascade bool) (err error) {
+	return errUnsupported
+}
+
+// Rename can't move every key of the user in one transaction, so it isn't supported.
+func (i *hashes) Rename(ctx context.Context, username, newUsername string) (item *model.User, err error) {
+	return nil, errUnsupported
+}
+
+// List scans the keys of the users, as there's no index of them.
+func (i *hashes) List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error) {
+	var usernames []string
+	keys := i.rdb.Scan(ctx, 0, keyspace.UsersPattern(), 0).Iterator()
+	for keys.Next(ctx) {
+		if username, ok := keyspace.UsernameOf(keys.Val()); ok {
+			usernames = append(usernames, username)
+		}
+	}
+	if err = keys.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list the users: %w", err)
+	}
+
+	for _, username := range page(usernames, order, offset, limit) {
+		user, err := getUser(ctx, i.rdb, username)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, user)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func Test_hashes(t *testing.T) {
+	ctx := context.Background()
+
+	i, err := NewRedis("redis://" + miniredis.RunT(t).Addr())
+	if err != nil {
+		t.Fatalf("NewRedis() error = %v", err)
+	}
+
+	_, err = i.Register(ctx, "user1")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	_, err = i.Register(ctx, "user1")
+	if err == nil {
+		t.Errorf("Register() an existing user error = nil, want error")
+	}
+
+	user, err := i.GetByUsername(ctx, "user1")
+	if err != nil || user.Username != "user1" {
+		t.Errorf("GetByUsername() got = %v, error = %v", user, err)
+	}
+
+	_, err = i.GetByUsername(ctx, "user2")
+	if err == nil {
+		t.Errorf("GetByUsername() a missing user error = nil, want error")
+	}
+
+	_, _ = i.Register(ctx, "user0")
+	_, _ = i.SetRole(ctx, "user1", model.RoleAdmin)
+	users, err := i.List(ctx, "desc", 0, 0)
+	if err != nil || len(users) != 2 || users[0].Username != "user1" || !users[0].Is(model.RoleAdmin) {
+		t.Errorf("List() got = %v, error = %v", users, err)
+	}
+	users, err = i.List(ctx, "asc", 1, 1)
+	if err != nil || len(users) != 1 || users[0].Username != "user1" {
+		t.Errorf("List() a page got = %v, error = %v", users, err)
+	}
+
+	if err = i.Delete(ctx, "user1", true); !errors.Is(err, errUnsupported) {
+		t.Errorf("Delete() error = %v, want %v", err, errUnsupported)
+	}
+	if _, err = i.Rename(ctx, "user1", "user2"); !errors.Is(err, errUnsupported) {
+		t.Errorf("Rename() error = %v, want %v", err, errUnsupported)
+	}
+
+	_, err = NewRedis("redis://127.0.0.1:1")
+	if err == nil {
+		t.Errorf("NewRedis() an unreachable server error = nil, want error")
+	}
+}
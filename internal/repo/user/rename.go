@@ -0,0 +1,61 @@
+package user
+
+import (
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// renamed is used to rename user to username, along with the owner of its folders and files. Its tokens and
+// sessions are revoked, as the tokens name the user and the sessions were opened under the previous name; undo
+// renames the user back with them.
+func renamed(user *model.User, username string) (undo func()) {
+	previous, tokens, sessions := user.Username, user.Tokens, user.Sessions
+	owned(user, username)
+	user.Tokens, user.Sessions = nil, nil
+
+	return func() {
+		owned(user, previous)
+		user.Tokens, user.Sessions = tokens, sessions
+	}
+}
+
+// owned is used to name user username and set it as the owner of its folders and files.
+func owned(user *model.User, username string) {
+	user.Username = username
+	for _, folder := range user.Folders {
+		folder.Owner = user
+		for _, file := range folder.Files {
+			file.Owner = user
+		}
+	}
+}
+
+// regrant is used to move the shares granted to username in the folders of users to newUsername, or to revoke
+// them when newUsername is empty. It reports whether any folder changed, and undo puts the shares back.
+func regrant(users map[string]*model.User, username, newUsername string) (changed bool, undo func()) {
+	var folders []*model.Folder
+	var accesses []model.Access
+	for _, user := range users {
+		for _, folder := range user.Folders {
+			access, exists := folder.Shares[username]
+			if !exists {
+				continue
+			}
+
+			delete(folder.Shares, username)
+			if newUsername != "" {
+				folder.Shares[newUsername] = access
+			}
+			folders = append(folders, folder)
+			accesses = append(accesses, access)
+		}
+	}
+
+	undo = func() {
+		for n, folder := range folders {
+			delete(folder.Shares, newUsername)
+			folder.Shares[username] = accesses[n]
+		}
+	}
+
+	return len(folders) > 0, undo
+}
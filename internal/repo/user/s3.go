@@ -0,0 +1,200 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+)
+
+// attempts is the number of times a change of a user is tried when it's changed concurrently.
+const attempts = 5
+
+type objects struct {
+	bucket *bucket.Bucket
+}
+
+// NewS3 is used to create a new UserManager backed by the objects of an S3-compatible bucket,
+// with one users/<username>/user.json object per user. The changes of a user are conditional writes, so concurrent
+// writers can't overwrite each other.
+func NewS3(path string) (repo.UserManager, error) {
+	b, err := bucket.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objects{
+		bucket: b,
+	}, nil
+}
+
+func (i *objects) Register(ctx context.Context, username string) (item *model.User, err error) {
+	user, err := model.NewUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.bucket.CreateJSON(ctx, bucket.UserKey(username), user)
+	if errors.Is(err, bucket.ErrExists) {
+		return nil, fmt.Errorf("the %s has already existed", username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (i *objects) GetByUsername(ctx context.Context, username string) (item *model.User, err error) {
+	// invalid usernames can't have an object and must not escape their prefix
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	user := &model.User{}
+	err = i.bucket.GetJSON(ctx, bucket.UserKey(username), user)
+	if errors.Is(err, bucket.ErrNotFound) {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// folders are separate objects, listed by the FolderManager
+	user.Folders = make(map[string]*model.Folder)
+
+	return user, nil
+}
+
+func (i *objects) SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.Role = role
+		return nil
+	})
+}
+
+func (i *objects) SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+func (i *objects) AddSession(
+	ctx context.Context,
+	username, tokenHash string,
+	expiresAt time.Time,
+) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return addSession(user, tokenHash, expiresAt)
+	})
+}
+
+func (i *objects) RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return removeSession(user, tokenHash)
+	})
+}
+
+func (i *objects) AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return addToken(user, token)
+	})
+}
+
+func (i *objects) RemoveToken(ctx context.Context, username, id string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return removeToken(user, id)
+	})
+}
+
+func (i *objects) SetQuota(
+	ctx context.Context,
+	username string,
+	quota *model.Quota,
+) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.Quota = quota
+		return nil
+	})
+}
+
+// update is used to change the user username and put its object back, only if it's unchanged since it was read.
+// The change is tried again on the user read back when a concurrent writer got there first.
+func (i *objects) update(
+	ctx context.Context,
+	username string,
+	change func(user *model.User) error,
+) (*model.User, error) {
+	// invalid usernames can't have an object and must not escape their prefix
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		user := &model.User{}
+		etag, err := i.bucket.GetJSONVersion(ctx, bucket.UserKey(username), user)
+		if errors.Is(err, bucket.ErrNotFound) {
+			return nil, fmt.Errorf("the %s doesn't exist", username)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = change(user)
+		if err != nil {
+			return nil, err
+		}
+
+		err = i.bucket.ReplaceJSON(ctx, bucket.UserKey(username), user, etag)
+		if errors.Is(err, bucket.ErrChanged) {
+			continue
+		}
+		if errors.Is(err, bucket.ErrNotFound) {
+			return nil, fmt.Errorf("the %s doesn't exist", username)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// folders are separate objects, listed by the FolderManager
+		user.Folders = make(map[string]*model.Folder)
+
+		return user, nil
+	}
+
+	return nil, fmt.Errorf("the %s is being changed concurrently, try again", username)
+}
+
+// Delete can't remove the folders of the user nor update the shares granted to it across the bucket in one
+// step, so it isn't supported.
+func (i *objects) Delete(ctx context.Context, username string, cascade bool) (err error) {
+	return errUnsupported
+}
+
+// Rename can't move every object of the user at once, so it isn't supported.
+func (i *objects) Rename(ctx context.Context, username, newUsername string) (item *model.User, err error) {
+	return nil, errUnsupported
+}
+
+func (i *objects) List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error) {
+	usernames, err := i.bucket.Dirs(ctx, bucket.UsersPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, username := range page(usernames, order, offset, limit) {
+		user, err := i.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, user)
+	}
+
+	return items, nil
+}
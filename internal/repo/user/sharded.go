@@ -0,0 +1,296 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+type sharded struct {
+	sync.Mutex
+
+	path string
+}
+
+// NewSharded is used to create a new UserManager backed by one document per user.
+func NewSharded(path string) (repo.UserManager, error) {
+	return &sharded{
+		Mutex: sync.Mutex{},
+		path:  strings.TrimRight(path, "/"),
+	}, nil
+}
+
+func (i *sharded) Register(ctx context.Context, username string) (item *model.User, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	usernames, err := store.ReadIndex(i.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(usernames, username) {
+		return nil, fmt.Errorf("the %s has already existed", username)
+	}
+
+	user, err := model.NewUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.WriteFile(store.ShardPath(i.path, username), map[string]*model.User{username: user})
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.WriteIndex(i.path, append(usernames, username))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (i *sharded) GetByUsername(ctx context.Context, username string) (item *model.User, err error) {
+	// invalid usernames can't have a shard and must not escape the store directory
+	if model.ValidateInput(username) != nil {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	users := make(map[string]*model.User)
+	err = store.ReadFile(store.ShardPath(i.path, username), &users)
+	if err != nil {
+		return nil, err
+	}
+
+	user, exists := users[username]
+	if !exists {
+		return nil, fmt.Errorf("the %s doesn't exist", username)
+	}
+
+	return user, nil
+}
+
+func (i *sharded) SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.Role = role
+		return nil
+	})
+}
+
+func (i *sharded) SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.PasswordHash = passwordHash
+		return nil
+	})
+}
+
+func (i *sharded) AddSession(
+	ctx context.Context,
+	username, tokenHash string,
+	expiresAt time.Time,
+) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return addSession(user, tokenHash, expiresAt)
+	})
+}
+
+func (i *sharded) RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return removeSession(user, tokenHash)
+	})
+}
+
+func (i *sharded) AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return addToken(user, token)
+	})
+}
+
+func (i *sharded) RemoveToken(ctx context.Context, username, id string) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		return removeToken(user, id)
+	})
+}
+
+func (i *sharded) SetQuota(
+	ctx context.Context,
+	username string,
+	quota *model.Quota,
+) (item *model.User, err error) {
+	return i.update(ctx, username, func(user *model.User) error {
+		user.Quota = quota
+		return nil
+	})
+}
+
+// update is used to change the user username and write its shard.
+func (i *sharded) update(
+	ctx context.Context,
+	username string,
+	change func(user *model.User) error,
+) (*model.User, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, err := i.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = change(user)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.WriteFile(store.ShardPath(i.path, username), map[string]*model.User{username: user})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (i *sharded) Delete(ctx context.Context, username string, cascade bool) (err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	user, err := i.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if len(user.Folders) > 0 && !cascade {
+		return fmt.Errorf("the %s still has folders, delete them first or cascade", username)
+	}
+
+	usernames, err := store.ReadIndex(i.path)
+	if err != nil {
+		return err
+	}
+
+	usernames = slices.DeleteFunc(usernames, func(name string) bool {
+		return name == username
+	})
+	err = store.WriteIndex(i.path, usernames)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(store.ShardPath(i.path, username))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", username, err)
+	}
+
+	return i.regrant(usernames, username, "")
+}
+
+// Rename writes the shard of newUsername before removing the one of username, so an interrupted rename leaves
+// both users rather than none.
+func (i *sharded) Rename(ctx context.Context, username, newUsername string) (item *model.User, err error) {
+	i.Lock()
+	defer i.Unlock()
+
+	err = model.ValidateInput(newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames, err := store.ReadIndex(i.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(usernames, newUsername) {
+		return nil, fmt.Errorf("the %s has already existed", newUsername)
+	}
+
+	user, err := i.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed(user, newUsername)
+	err = store.WriteFile(store.ShardPath(i.path, newUsername), map[string]*model.User{newUsername: user})
+	if err != nil {
+		return nil, err
+	}
+
+	usernames = slices.DeleteFunc(usernames, func(name string) bool {
+		return name == username
+	})
+	usernames = append(usernames, newUsername)
+	err = store.WriteIndex(i.path, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.Remove(store.ShardPath(i.path, username))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove %s: %w", username, err)
+	}
+
+	err = i.regrant(usernames, username, newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (i *sharded) List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error) {
+	usernames, err := store.ReadIndex(i.path)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the shards of the page are read
+	for _, username := range page(usernames, order, offset, limit) {
+		user, err := i.GetByUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, user)
+	}
+
+	return items, nil
+}
+
+// regrant is used to move the shares granted to username in the shards of usernames to newUsername,
+// or to revoke them when newUsername is empty.
+func (i *sharded) regrant(usernames []string, username, newUsername string) (err error) {
+	for _, owner := range usernames {
+		if owner == newUsername {
+			continue
+		}
+
+		path := store.ShardPath(i.path, owner)
+		users := make(map[string]*model.User)
+		err = store.ReadFile(path, &users)
+		if err != nil {
+			return err
+		}
+
+		changed, _ := regrant(users, username, newUsername)
+		if !changed {
+			continue
+		}
+
+		err = store.WriteFile(path, users)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
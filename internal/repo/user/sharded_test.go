@@ -0,0 +1,111 @@
+package user
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func Test_sharded_Register(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		username string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "register new user",
+			args: args{
+				ctx:      context.Background(),
+				username: "newUser",
+			},
+			wantErr: false,
+		},
+		{
+			name: "register existing user",
+			args: args{
+				ctx:      context.Background(),
+				username: "newUser",
+			},
+			wantErr: true,
+		},
+		{
+			name: "register with invalid username",
+			args: args{
+				ctx:      context.Background(),
+				username: "invalidUsername!",
+			},
+			wantErr: true,
+		},
+	}
+
+	i, _ := NewSharded("out/vfs.shards")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := i.Register(tt.args.ctx, tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+
+	_ = os.RemoveAll("out")
+}
+
+func Test_sharded_GetByUsername(t *testing.T) {
+	type args struct {
+		ctx      context.Context
+		username string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "get by existing username",
+			args: args{
+				ctx:      context.Background(),
+				username: "existingUser",
+			},
+			wantErr: false,
+		},
+		{
+			name: "get by non-existing username",
+			args: args{
+				ctx:      context.Background(),
+				username: "nonExistingUser",
+			},
+			wantErr: true,
+		},
+		{
+			name: "get by username escaping the store",
+			args: args{
+				ctx:      context.Background(),
+				username: "../existingUser",
+			},
+			wantErr: true,
+		},
+	}
+
+	i, _ := NewSharded("out/vfs.shards")
+	_, _ = i.Register(context.Background(), "existingUser")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := i.GetByUsername(tt.args.ctx, tt.args.username)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetByUsername() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.Username != tt.args.username {
+				t.Errorf("GetByUsername() got = %v, want %v", got.Username, tt.args.username)
+			}
+		})
+	}
+
+	_ = os.RemoveAll("out")
+}
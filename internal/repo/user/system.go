@@ -2,13 +2,20 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
 )
 
+// errUnsupportedDirectory is returned by what the directory store can't keep beyond its users, like their roles,
+// passwords, sessions, tokens and quotas, errUnsupported being the removals and renames of the other stores.
+var errUnsupportedDirectory = errors.New("this isn't supported by the directory store, use a document or sharded store")
+
 type system struct {
 	path string
 }
@@ -53,3 +60,111 @@ func (i *system) GetByUsername(ctx context.Context, username string) (item *mode
 
 	return user, nil
 }
+
+func (i *system) SetRole(ctx context.Context, username string, role model.Role) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) SetPassword(ctx context.Context, username, passwordHash string) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) AddSession(
+	ctx context.Context,
+	username, tokenHash string,
+	expiresAt time.Time,
+) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) RemoveSession(ctx context.Context, username, tokenHash string) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) AddToken(ctx context.Context, username string, token *model.Token) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) RemoveToken(ctx context.Context, username, id string) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) SetQuota(
+	ctx context.Context,
+	username string,
+	quota *model.Quota,
+) (item *model.User, err error) {
+	return nil, errUnsupportedDirectory
+}
+
+func (i *system) Delete(ctx context.Context, username string, cascade bool) (err error) {
+	user, err := i.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(i.path + "/" + user.Username)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) > 0 && !cascade {
+		return fmt.Errorf("the %s still has folders, delete them first or cascade", username)
+	}
+
+	return os.RemoveAll(i.path + "/" + user.Username)
+}
+
+func (i *system) Rename(ctx context.Context, username, newUsername string) (item *model.User, err error) {
+	user, err := i.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed, err := model.NewUser(newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(i.path + "/" + renamed.Username)
+	if err == nil {
+		return nil, fmt.Errorf("the %s has already existed", newUsername)
+	}
+
+	err = os.Rename(i.path+"/"+user.Username, i.path+"/"+renamed.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	return renamed, nil
+}
+
+func (i *system) List(ctx context.Context, order string, offset, limit int) (items []*model.User, err error) {
+	entries, err := os.ReadDir(i.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	// every directory is a user
+	var usernames []string
+	for _, entry := range entries {
+		if entry.IsDir() && model.ValidateInput(entry.Name()) == nil {
+			usernames = append(usernames, entry.Name())
+		}
+	}
+
+	for _, username := range page(usernames, order, offset, limit) {
+		user, err := model.NewUser(username)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, user)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,104 @@
+// Package script parses the batch scripts, one command per line in the syntax of the command line.
+package script
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Line is a command of a script.
+type Line struct {
+	// Number is the line number in the script, from 1.
+	Number int
+
+	// Text is the line as written, without the surrounding spaces.
+	Text string
+
+	// Args are the words of the line, the command name first.
+	Args []string
+}
+
+// Parse is used to read the commands of the script r. Blank lines and lines starting with # are skipped.
+// Every line is parsed before any is returned, so a script with a syntax error is never partially run.
+func Parse(r io.Reader) (lines []Line, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		args, err := Split(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		lines = append(lines, Line{Number: number, Text: text, Args: args})
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the script: %w", err)
+	}
+
+	return lines, nil
+}
+
+// Split is used to split line into words like a POSIX shell: words are separated by spaces, single
+// quotes keep everything literally, double quotes keep everything but the escapes \" and \\, and a
+// backslash outside quotes escapes the next character.
+func Split(line string) (args []string, err error) {
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, errors.New("unfinished escape at the end of the line")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+
+	return args, nil
+}
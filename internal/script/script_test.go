@@ -0,0 +1,63 @@
+package script
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "words", line: "create-folder  alice\tphotos", want: []string{"create-folder", "alice", "photos"}},
+		{name: "double quotes", line: `create-folder alice photos "my \"best\" photos\n"`, want: []string{
+			"create-folder", "alice", "photos", `my "best" photos\n`,
+		}},
+		{name: "escape in single quotes", line: `create-file alice photos beach 'it\'s'`, want: nil, wantErr: true},
+		{name: "literal single quotes", line: `create-file alice photos beach 'a \ b'`, want: []string{
+			"create-file", "alice", "photos", "beach", `a \ b`,
+		}},
+		{name: "escaped space", line: `list-files alice my\ photos`, want: []string{"list-files", "alice", "my photos"}},
+		{name: "empty word", line: `create-folder alice photos ""`, want: []string{"create-folder", "alice", "photos", ""}},
+		{name: "joined quotes", line: `register a"l"'i'ce`, want: []string{"register", "alice"}},
+		{name: "unclosed quote", line: `create-folder alice "photos`, wantErr: true},
+		{name: "unfinished escape", line: `create-folder alice photos\`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Split(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Split() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	lines, err := Parse(strings.NewReader("# provisioning\nregister alice\n\n  create-folder alice photos 'my photos'  \n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Line{
+		{Number: 2, Text: "register alice", Args: []string{"register", "alice"}},
+		{Number: 4, Text: "create-folder alice photos 'my photos'", Args: []string{
+			"create-folder", "alice", "photos", "my photos",
+		}},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Parse() got = %+v, want %+v", lines, want)
+	}
+
+	_, err = Parse(strings.NewReader("register alice\ncreate-folder alice 'photos\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Parse() error = %v, want an error of line 2", err)
+	}
+}
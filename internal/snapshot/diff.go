@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// Change operations reported by Diff.
+const (
+	OpAdded    = "+"
+	OpRemoved  = "-"
+	OpModified = "~"
+)
+
+// Change describes one difference between two snapshots.
+type Change struct {
+	Op     string
+	Path   string
+	Detail string
+}
+
+func (c Change) String() string {
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s", c.Op, c.Path)
+	}
+
+	return fmt.Sprintf("%s %s (%s)", c.Op, c.Path, c.Detail)
+}
+
+// Diff is used to list the changes from the snapshot a to the snapshot b, the folders of the groups being listed
+// under group:<name> like their owner.
+func (m *Manager) Diff(a, b string) (changes []Change, err error) {
+	_, before, beforeGroups, err := m.open(a)
+	if err != nil {
+		return nil, err
+	}
+
+	_, after, afterGroups, err := m.open(b)
+	if err != nil {
+		return nil, err
+	}
+
+	maps.Copy(before, beforeGroups.Owners)
+	maps.Copy(after, afterGroups.Owners)
+
+	return Compare(before, after), nil
+}
+
+// Compare is used to list the changes from before to after, sorted by path.
+func Compare(before, after map[string]*model.User) (changes []Change) {
+	for _, username := range union(before, after) {
+		oldUser, newUser := before[username], after[username]
+		switch {
+		case oldUser == nil:
+			changes = append(changes, Change{Op: OpAdded, Path: username})
+		case newUser == nil:
+			changes = append(changes, Change{Op: OpRemoved, Path: username})
+		}
+
+		oldFolders, newFolders := foldersOf(oldUser), foldersOf(newUser)
+		for _, foldername := range union(oldFolders, newFolders) {
+			path := username + "/" + foldername
+			oldFolder, newFolder := oldFolders[foldername], newFolders[foldername]
+			switch {
+			case oldFolder == nil:
+				changes = append(changes, Change{Op: OpAdded, Path: path})
+			case newFolder == nil:
+				changes = append(changes, Change{Op: OpRemoved, Path: path})
+			default:
+				if detail := modified(
+					oldFolder.Description, newFolder.Description,
+					oldFolder.CreatedAt.Equal(newFolder.CreatedAt),
+				); detail != "" {
+					changes = append(changes, Change{Op: OpModified, Path: path, Detail: detail})
+				}
+			}
+
+			oldFiles, newFiles := filesOf(oldFolder), filesOf(newFolder)
+			for _, filename := range union(oldFiles, newFiles) {
+				filePath := path + "/" + filename
+				oldFile, newFile := oldFiles[filename], newFiles[filename]
+				switch {
+				case oldFile == nil:
+					changes = append(changes, Change{Op: OpAdded, Path: filePath})
+				case newFile == nil:
+					changes = append(changes, Change{Op: OpRemoved, Path: filePath})
+				default:
+					if detail := modified(
+						oldFile.Description, newFile.Description,
+						oldFile.CreatedAt.Equal(newFile.CreatedAt),
+					); detail != "" {
+						changes = append(changes, Change{Op: OpModified, Path: filePath, Detail: detail})
+					}
+				}
+			}
+		}
+	}
+
+	return changes
+}
+
+func modified(oldDescription, newDescription string, sameCreatedAt bool) string {
+	switch {
+	case oldDescription != newDescription && !sameCreatedAt:
+		return "description, created_at"
+	case oldDescription != newDescription:
+		return "description"
+	case !sameCreatedAt:
+		return "created_at"
+	default:
+		return ""
+	}
+}
+
+func foldersOf(user *model.User) map[string]*model.Folder {
+	if user == nil {
+		return nil
+	}
+
+	return user.Folders
+}
+
+func filesOf(folder *model.Folder) map[string]*model.File {
+	if folder == nil {
+		return nil
+	}
+
+	return folder.Files
+}
+
+// union returns the sorted keys present in a or b.
+func union[T any](a, b map[string]T) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		seen[key] = true
+	}
+	for key := range b {
+		seen[key] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,442 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+const (
+	manifestEntry = "manifest.json"
+	dataEntry     = "data.json"
+	groupsEntry   = "groups.json"
+	extension     = ".tar.gz"
+)
+
+// Manifest describes the content of a snapshot archive.
+type Manifest struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Checksum  string    `json:"checksum"`
+	Version   int       `json:"version"`
+	Users     int       `json:"users"`
+	Folders   int       `json:"folders"`
+	Files     int       `json:"files"`
+
+	// GroupsChecksum and Groups describe the groups archived next to the users, the folders they own
+	// being counted with the others. The snapshots taken before the groups were archived have none.
+	GroupsChecksum string `json:"groups_checksum,omitempty"`
+	Groups         int    `json:"groups,omitempty"`
+}
+
+// Manager is used to create and restore point-in-time snapshots of a store.
+type Manager struct {
+	path string
+	dir  string
+}
+
+// New is used to create a new Manager for the store at path keeping its archives in dir.
+// An empty dir defaults to DefaultDir(path). Only the document and sharded stores are archived, the git, S3 and
+// Redis stores keeping their own history or copies.
+func New(path, dir string) (*Manager, error) {
+	pathType := utils.CheckPathType(path)
+	if !utils.IsDocument(pathType) && pathType != "sharded" {
+		return nil, fmt.Errorf(
+			"the snapshots only archive document and sharded stores, the %s is a %s store",
+			path,
+			pathType,
+		)
+	}
+
+	if dir == "" {
+		dir = DefaultDir(path)
+	}
+
+	return &Manager{
+		path: path,
+		dir:  dir,
+	}, nil
+}
+
+// DefaultDir returns the directory holding the snapshots of the store at path.
+func DefaultDir(path string) string {
+	return strings.TrimRight(path, "/") + ".snapshots"
+}
+
+// Create is used to archive the current content of the store, its users and its groups.
+func (m *Manager) Create(label string) (manifest *Manifest, err error) {
+	users, err := store.Load(m.path)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := &store.Groups{}
+	err = store.ReadGroups(store.GroupsPath(m.path), groups)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(m.dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	createdAt := time.Now().UTC()
+	manifest = &Manifest{
+		ID:        m.nextID(createdAt),
+		Label:     label,
+		CreatedAt: createdAt,
+		Version:   store.CurrentVersion,
+	}
+
+	data, groupsData, err := seal(manifest, users, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.write(m.archivePath(manifest.ID), manifest, data, groupsData)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// List is used to list the snapshots from the oldest to the newest.
+func (m *Manager) List() (manifests []*Manifest, err error) {
+	matches, err := filepath.Glob(filepath.Join(m.dir, "*"+extension))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, match := range matches {
+		manifest, _, _, err := m.read(strings.TrimSuffix(filepath.Base(match), extension))
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].ID < manifests[j].ID
+	})
+
+	return manifests, nil
+}
+
+// Restore is used to replace the content of the store with the snapshot id, its groups included.
+func (m *Manager) Restore(id string) (manifest *Manifest, err error) {
+	manifest, users, groups, err := m.open(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = store.Save(m.path, users)
+	if err != nil {
+		return nil, err
+	}
+
+	// the snapshots taken before the groups were archived leave the current ones
+	if manifest.GroupsChecksum == "" {
+		return manifest, nil
+	}
+
+	path := store.GroupsPath(m.path)
+	if len(groups.Groups) == 0 && len(groups.Owners) == 0 {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove groups: %w", err)
+		}
+
+		return manifest, nil
+	}
+
+	err = store.WriteGroups(path, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Rekey is used to re-encrypt the store with next, nil decrypting it, like store.Rekey, along with its snapshots
+// which wouldn't open with the previous Secret anymore. The snapshots are all read before anything is changed.
+func (m *Manager) Rekey(next *store.Secret) (err error) {
+	manifests, err := m.List()
+	if err != nil {
+		return err
+	}
+
+	users := make([]map[string]*model.User, len(manifests))
+	groups := make([]*store.Groups, len(manifests))
+	for i, manifest := range manifests {
+		_, users[i], groups[i], err = m.open(manifest.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = store.Rekey(m.path, next)
+	if err != nil {
+		return err
+	}
+
+	for i, manifest := range manifests {
+		// the snapshots without groups stay without, so restoring them still leaves the current ones
+		archived := groups[i]
+		if manifest.GroupsChecksum == "" {
+			archived = nil
+		}
+
+		data, groupsData, err := seal(manifest, users[i], archived)
+		if err != nil {
+			return fmt.Errorf("failed to rekey snapshot %s: %w", manifest.ID, err)
+		}
+
+		err = m.replace(manifest, data, groupsData)
+		if err != nil {
+			return fmt.Errorf("failed to rekey snapshot %s: %w", manifest.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// open is used to read and decode the snapshot id. The groups are empty when the snapshot has none.
+func (m *Manager) open(
+	id string,
+) (manifest *Manifest, users map[string]*model.User, groups *store.Groups, err error) {
+	manifest, data, groupsData, err := m.read(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if checksum(data) != manifest.Checksum ||
+		(manifest.GroupsChecksum != "" && checksum(groupsData) != manifest.GroupsChecksum) {
+		return nil, nil, nil, fmt.Errorf("the snapshot %s is corrupted: checksum mismatch", id)
+	}
+
+	users = make(map[string]*model.User)
+	err = store.Unmarshal(data, &users)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decode snapshot %s: %w", id, err)
+	}
+
+	groups = &store.Groups{}
+	if groupsData != nil {
+		err = store.UnmarshalGroups(groupsData, groups)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode the groups of snapshot %s: %w", id, err)
+		}
+	}
+
+	return manifest, users, groups, nil
+}
+
+// seal is used to encode users and groups for the archive of manifest, setting its checksums and counts.
+// Without groups, the archive has none.
+func seal(manifest *Manifest, users map[string]*model.User, groups *store.Groups) (data, groupsData []byte, err error) {
+	data, err = store.Marshal(users)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest.Checksum = checksum(data)
+	manifest.Users, manifest.Folders, manifest.Files = count(users)
+	manifest.GroupsChecksum, manifest.Groups = "", 0
+	if groups == nil {
+		return data, nil, nil
+	}
+
+	groupsData, err = store.MarshalGroups(groups)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifest.GroupsChecksum = checksum(groupsData)
+	manifest.Groups = len(groups.Groups)
+	_, folders, files := count(groups.Owners)
+	manifest.Folders += folders
+	manifest.Files += files
+
+	return data, groupsData, nil
+}
+
+func (m *Manager) archivePath(id string) string {
+	return filepath.Join(m.dir, id+extension)
+}
+
+// nextID is used to derive a unique, sortable id from the creation time.
+func (m *Manager) nextID(createdAt time.Time) string {
+	base := createdAt.Format("20060102-150405.000")
+	id := base
+	for n := 1; ; n++ {
+		if _, err := os.Stat(m.archivePath(id)); os.IsNotExist(err) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// replace is used to write the snapshot of manifest over its archive through a temporary file, so that the archive
+// is never left half written.
+func (m *Manager) replace(manifest *Manifest, data, groups []byte) (err error) {
+	path := m.archivePath(manifest.ID)
+	temp := path + ".tmp"
+
+	_ = os.Remove(temp)
+	err = m.write(temp, manifest, data, groups)
+	if err != nil {
+		_ = os.Remove(temp)
+		return err
+	}
+
+	err = os.Rename(temp, path)
+	if err != nil {
+		_ = os.Remove(temp)
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) write(path string, manifest *Manifest, data, groups []byte) (err error) {
+	header, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	zw := gzip.NewWriter(file)
+	tw := tar.NewWriter(zw)
+	type entry struct {
+		name string
+		body []byte
+	}
+	entries := []entry{{name: manifestEntry, body: header}, {name: dataEntry, body: data}}
+	if groups != nil {
+		entries = append(entries, entry{name: groupsEntry, body: groups})
+	}
+
+	for _, entry := range entries {
+		err = tw.WriteHeader(&tar.Header{
+			Name:    entry.name,
+			Mode:    0600,
+			Size:    int64(len(entry.body)),
+			ModTime: manifest.CreatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+
+		_, err = tw.Write(entry.body)
+		if err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return zw.Close()
+}
+
+func (m *Manager) read(id string) (manifest *Manifest, data, groups []byte, err error) {
+	if model.ValidateInput(strings.ReplaceAll(id, ".", "-")) != nil {
+		return nil, nil, nil, fmt.Errorf("the snapshot %s doesn't exist", id)
+	}
+
+	file, err := os.Open(m.archivePath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, fmt.Errorf("the snapshot %s doesn't exist", id)
+		}
+
+		return nil, nil, nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+	}
+
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read snapshot %s: %w", id, err)
+		}
+
+		switch header.Name {
+		case manifestEntry:
+			manifest = new(Manifest)
+			err = json.Unmarshal(body, manifest)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to unmarshal manifest of %s: %w", id, err)
+			}
+		case dataEntry:
+			data = body
+		case groupsEntry:
+			groups = body
+		}
+	}
+
+	if manifest == nil || data == nil {
+		return nil, nil, nil, fmt.Errorf("the snapshot %s is incomplete", id)
+	}
+
+	return manifest, data, groups, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func count(users map[string]*model.User) (usersCount, foldersCount, filesCount int) {
+	for _, user := range users {
+		usersCount++
+		for _, folder := range user.Folders {
+			foldersCount++
+			filesCount += len(folder.Files)
+		}
+	}
+
+	return usersCount, foldersCount, filesCount
+}
@@ -0,0 +1,227 @@
+package snapshot
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
+)
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "single-file store",
+			path: "out/vfs.json",
+		},
+		{
+			name: "sharded store",
+			path: "out/vfs.shards",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				_ = os.RemoveAll("out")
+			}()
+
+			alice, _ := model.NewUser("alice")
+			photos, _ := model.NewFolder(alice, "photos", "holiday pictures")
+			alice.Folders["photos"] = photos
+			_ = store.Save(tt.path, map[string]*model.User{"alice": alice})
+
+			m, err := New(tt.path, "")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			first, err := m.Create("before")
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if first.Users != 1 || first.Folders != 1 || first.Files != 0 || first.Label != "before" {
+				t.Errorf("Create() manifest = %+v", first)
+			}
+
+			bob, _ := model.NewUser("bob")
+			_ = store.Save(tt.path, map[string]*model.User{"bob": bob})
+
+			second, err := m.Create("")
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			manifests, err := m.List()
+			if err != nil || len(manifests) != 2 || manifests[0].ID != first.ID {
+				t.Fatalf("List() manifests = %v, error = %v", manifests, err)
+			}
+
+			changes, err := m.Diff(first.ID, second.ID)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			want := []string{"- alice", "- alice/photos", "+ bob"}
+			if len(changes) != len(want) {
+				t.Fatalf("Diff() changes = %v, want %v", changes, want)
+			}
+			for i, change := range changes {
+				if change.String() != want[i] {
+					t.Errorf("Diff() change = %v, want %v", change, want[i])
+				}
+			}
+
+			_, err = m.Restore(first.ID)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			users, _ := store.Load(tt.path)
+			if len(users) != 1 || users["alice"] == nil || users["alice"].Folders["photos"] == nil {
+				t.Errorf("Restore() users = %v", users)
+			}
+
+			_, err = m.Restore("missing")
+			if err == nil {
+				t.Errorf("Restore() of a missing snapshot error = nil")
+			}
+		})
+	}
+}
+
+func TestNew_Unsupported(t *testing.T) {
+	for _, path := range []string{"out/vfs.git", "s3://bucket/vfs", "redis://localhost:6379/0"} {
+		if _, err := New(path, ""); err == nil {
+			t.Errorf("New() of %s error = nil, want error", path)
+		}
+	}
+}
+
+func TestManager_Groups(t *testing.T) {
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	alice, _ := model.NewUser("alice")
+	_ = store.Save("out/vfs.json", map[string]*model.User{"alice": alice})
+	eng, _ := model.NewGroup("eng", alice)
+	owner := eng.Owner()
+	owner.Folders = map[string]*model.Folder{}
+	docs, _ := model.NewFolder(owner, "docs", "")
+	owner.Folders["docs"] = docs
+	path := store.GroupsPath("out/vfs.json")
+	_ = store.WriteGroups(path, &store.Groups{
+		Groups: map[string]*model.Group{"eng": eng},
+		Owners: map[string]*model.User{owner.Username: owner},
+	})
+
+	m, err := New("out/vfs.json", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	first, err := m.Create("")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if first.Groups != 1 || first.Folders != 1 || first.GroupsChecksum == "" {
+		t.Errorf("Create() manifest = %+v, want the group and its folder counted", first)
+	}
+
+	_ = os.Remove(path)
+	second, err := m.Create("")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	changes, err := m.Diff(first.ID, second.ID)
+	if err != nil || len(changes) != 2 || changes[1].String() != "- group:eng/docs" {
+		t.Errorf("Diff() changes = %v, error = %v, want the group folder removed", changes, err)
+	}
+
+	_, err = m.Restore(first.ID)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	groups := &store.Groups{}
+	err = store.ReadGroups(path, groups)
+	if err != nil || groups.Groups["eng"] == nil || groups.Owners[owner.Username].Folders["docs"] == nil {
+		t.Errorf("Restore() groups = %+v, error = %v, want the group and its folder back", groups, err)
+	}
+
+	_, err = m.Restore(second.ID)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Restore() of a snapshot without groups left the groups file, error = %v", err)
+	}
+}
+
+func TestManager_Rekey(t *testing.T) {
+	defer func() {
+		store.SetSecret(nil)
+		_ = os.RemoveAll("out")
+	}()
+
+	alice, _ := model.NewUser("alice")
+	_ = store.Save("out/vfs.json", map[string]*model.User{"alice": alice})
+
+	m, err := New("out/vfs.json", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	first, err := m.Create("plaintext")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	secret, err := store.Passphrase("secret")
+	if err != nil {
+		t.Fatalf("Passphrase() error = %v", err)
+	}
+	err = m.Rekey(secret)
+	if err != nil {
+		t.Fatalf("Rekey() error = %v", err)
+	}
+
+	// the snapshot is sealed with the new secret like the store, and restored with it
+	store.SetSecret(nil)
+	if _, err = m.Restore(first.ID); err == nil {
+		t.Errorf("Restore() without the secret error = nil, want error")
+	}
+	store.SetSecret(secret)
+	if _, err = m.Restore(first.ID); err != nil {
+		t.Errorf("Restore() error = %v", err)
+	}
+
+	err = m.Rekey(nil)
+	if err != nil {
+		t.Fatalf("Rekey() to decrypt error = %v", err)
+	}
+	if _, err = m.Restore(first.ID); err != nil {
+		t.Errorf("Restore() after decrypting error = %v", err)
+	}
+	manifests, err := m.List()
+	if err != nil || len(manifests) != 1 || manifests[0].Label != "plaintext" {
+		t.Errorf("List() manifests = %v, error = %v, want the rekeyed snapshot", manifests, err)
+	}
+}
+
+func TestCompare_Modified(t *testing.T) {
+	before := map[string]*model.User{
+		"alice": {Username: "alice", Folders: map[string]*model.Folder{
+			"photos": {Name: "photos", Description: "old", Files: map[string]*model.File{}},
+		}},
+	}
+	after := map[string]*model.User{
+		"alice": {Username: "alice", Folders: map[string]*model.Folder{
+			"photos": {Name: "photos", Description: "new", Files: map[string]*model.File{}},
+		}},
+	}
+
+	changes := Compare(before, after)
+	if len(changes) != 1 || changes[0].String() != "~ alice/photos (description)" {
+		t.Errorf("Compare() changes = %v", changes)
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// Codec encodes and decodes the users of a document in one serialization format.
+type Codec interface {
+	// Name returns the name of the format, as accepted by SetFormat.
+	Name() string
+
+	// Extensions returns the file extensions of the format, the first one being the preferred.
+	Extensions() []string
+
+	// Encode is used to write users to w with the current schema version.
+	Encode(w io.Writer, users map[string]*model.User) error
+
+	// Decode is used to read a document from r into users and returns its schema version.
+	Decode(r io.Reader, users *map[string]*model.User) (version int, err error)
+}
+
+// codecs holds the supported formats, the first one being the default.
+var codecs = []Codec{
+	jsonCodec{},
+	gobCodec{},
+	msgpackCodec{},
+	protobufCodec{},
+	yamlCodec{},
+	tomlCodec{},
+}
+
+// format is the name of the Codec forced for every document, empty to pick it by extension.
+var format string
+
+// SetFormat is used to force the serialization format of every document, empty picks it by extension.
+func SetFormat(name string) error {
+	if name != "" && CodecByName(name) == nil {
+		return fmt.Errorf("unsupported format: %s, use one of %s", name, strings.Join(Formats(), ", "))
+	}
+
+	format = name
+	return nil
+}
+
+// Formats returns the names of the supported formats.
+func Formats() (names []string) {
+	for _, codec := range codecs {
+		names = append(names, codec.Name())
+	}
+
+	return names
+}
+
+// CodecByName returns the Codec of the format name, nil if it isn't supported.
+func CodecByName(name string) Codec {
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec
+		}
+	}
+
+	return nil
+}
+
+// CodecFor returns the Codec of the document at path, from the forced format or the extension of path.
+// Unknown extensions use the default JSON codec.
+func CodecFor(path string) Codec {
+	if format != "" {
+		return CodecByName(format)
+	}
+
+	ext := strings.TrimSuffix(path, compressionOf(path))
+	if i := strings.LastIndex(ext, "."); i >= 0 {
+		ext = ext[i:]
+	}
+
+	for _, codec := range codecs {
+		if slices.Contains(codec.Extensions(), ext) {
+			return codec
+		}
+	}
+
+	return codecs[0]
+}
+
+// checkVersion is used by the binary codecs, which were introduced with version 1
+// and don't have migrations of their own.
+func checkVersion(version int) error {
+	if version != CurrentVersion {
+		return fmt.Errorf("unsupported schema version %d, the binary formats only read version %d", version, CurrentVersion)
+	}
+
+	return nil
+}
+
+// upgradeDocument is used by the text codecs to decode a generic document of another schema
+// version, or a bare map of users, going through JSON to reuse its migrations.
+func upgradeDocument(doc any, users *map[string]*model.User) (version int, err error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert data: %w", err)
+	}
+
+	return Decode(bytes.NewReader(data), users)
+}
+
+// detach is used to copy users without the back-references, which formats without
+// field tags would otherwise follow forever.
+func detach(users map[string]*model.User) map[string]*model.User {
+	copied := make(map[string]*model.User, len(users))
+	for key, user := range users {
+		if user == nil {
+			continue
+		}
+
+		u := *user
+		u.Folders = make(map[string]*model.Folder, len(user.Folders))
+		for foldername, folder := range user.Folders {
+			if folder == nil {
+				continue
+			}
+
+			f := *folder
+			f.Owner = nil
+			f.Folders = nil
+			f.Files = make(map[string]*model.File, len(folder.Files))
+			for filename, file := range folder.Files {
+				if file == nil {
+					continue
+				}
+
+				c := *file
+				c.Owner = nil
+				c.Folder = nil
+				f.Files[filename] = &c
+			}
+			u.Folders[foldername] = &f
+		}
+		copied[key] = &u
+	}
+
+	return copied
+}
+
+// merge is used to add the decoded users to users, like decoding into an existing map does.
+func merge(users *map[string]*model.User, decoded map[string]*model.User) {
+	if *users == nil {
+		*users = make(map[string]*model.User, len(decoded))
+	}
+
+	for key, user := range decoded {
+		(*users)[key] = user
+	}
+}
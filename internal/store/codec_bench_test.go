@@ -0,0 +1,65 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// fixture returns 100 users with 10 folders of 100 files each, 100k files in total.
+func fixture() map[string]*model.User {
+	created := time.Date(2024, 5, 27, 10, 0, 0, 0, time.UTC)
+	users := make(map[string]*model.User)
+	for u := 0; u < 100; u++ {
+		user, _ := model.NewUser(fmt.Sprintf("user-%03d", u))
+		for f := 0; f < 10; f++ {
+			folder, _ := model.NewFolder(user, fmt.Sprintf("folder-%02d", f), "a folder of the benchmark fixture")
+			folder.CreatedAt = created
+			for n := 0; n < 100; n++ {
+				file, _ := model.NewFile(user, folder, fmt.Sprintf("file-%03d", n), "a file of the benchmark fixture")
+				file.CreatedAt = created.Add(time.Duration(n) * time.Second)
+				folder.Files[file.Name] = file
+			}
+			user.Folders[folder.Name] = folder
+		}
+		users[user.Username] = user
+	}
+
+	return users
+}
+
+func BenchmarkCodecs(b *testing.B) {
+	users := fixture()
+	dir := b.TempDir()
+
+	for _, codec := range codecs {
+		path := filepath.Join(dir, "vfs"+codec.Extensions()[0])
+
+		b.Run("save/"+codec.Name(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := WriteFile(path, users); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(info.Size()), "file-bytes")
+		})
+
+		b.Run("load/"+codec.Name(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				loaded := make(map[string]*model.User)
+				if err := ReadFile(path, &loaded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+type gobCodec struct{}
+
+// gobDocument is the envelope of a gob document.
+type gobDocument struct {
+	Version int
+	Users   map[string]*model.User
+}
+
+func (gobCodec) Name() string {
+	return "gob"
+}
+
+func (gobCodec) Extensions() []string {
+	return []string{".gob"}
+}
+
+func (gobCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	err := gob.NewEncoder(w).Encode(gobDocument{Version: CurrentVersion, Users: detach(users)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return nil
+}
+
+func (gobCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	var doc gobDocument
+	err = gob.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	err = checkVersion(doc.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	merge(users, doc.Users)
+
+	return doc.Version, nil
+}
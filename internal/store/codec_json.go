@@ -0,0 +1,26 @@
+package store
+
+import (
+	"io"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// jsonCodec is the default format, the only one with migrations from older schema versions.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Extensions() []string {
+	return []string{".json"}
+}
+
+func (jsonCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	return Encode(w, users)
+}
+
+func (jsonCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	return Decode(r, users)
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string {
+	return "msgpack"
+}
+
+func (msgpackCodec) Extensions() []string {
+	return []string{".msgpack", ".mpk"}
+}
+
+func (msgpackCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	encoder := msgpack.NewEncoder(w)
+	// reuse the json tags, so the back-references are skipped and the keys match the JSON documents
+	encoder.SetCustomStructTag("json")
+
+	err := encoder.Encode(envelope{Version: CurrentVersion, Data: users})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return nil
+}
+
+func (msgpackCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	decoder := msgpack.NewDecoder(r)
+	decoder.SetCustomStructTag("json")
+
+	var doc struct {
+		Version int                    `json:"version"`
+		Data    map[string]*model.User `json:"data"`
+	}
+	err = decoder.Decode(&doc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	err = checkVersion(doc.Version)
+	if err != nil {
+		return 0, err
+	}
+
+	merge(users, doc.Data)
+
+	return doc.Version, nil
+}
@@ -0,0 +1,433 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protobufCodec encodes the documents following store.proto.
+type protobufCodec struct{}
+
+var errMalformed = errors.New("malformed protobuf document")
+
+func (protobufCodec) Name() string {
+	return "protobuf"
+}
+
+func (protobufCodec) Extensions() []string {
+	return []string{".pb"}
+}
+
+func (protobufCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	b := protowire.AppendTag(nil, 1, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(CurrentVersion))
+
+	for _, key := range sortedKeys(users) {
+		if users[key] == nil {
+			continue
+		}
+
+		b = appendEntry(b, 2, key, appendUser(nil, users[key]))
+	}
+
+	_, err := w.Write(b)
+	if err != nil {
+		return fmt.Errorf("failed to write data: %w", err)
+	}
+
+	return nil
+}
+
+func (protobufCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	decoded := make(map[string]*model.User)
+	err = consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			version = int(varint)
+		case num == 2 && typ == protowire.BytesType:
+			user := &model.User{Folders: make(map[string]*model.Folder)}
+			key, err := consumeEntry(value, func(value []byte) error {
+				return consumeUser(value, user)
+			})
+			if err != nil {
+				return err
+			}
+			decoded[key] = user
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	err = checkVersion(version)
+	if err != nil {
+		return 0, err
+	}
+
+	merge(users, decoded)
+
+	return version, nil
+}
+
+func appendUser(b []byte, user *model.User) []byte {
+	b = appendString(b, 1, user.Username)
+	for _, key := range sortedKeys(user.Folders) {
+		folder := user.Folders[key]
+		if folder == nil {
+			continue
+		}
+
+		value := appendString(nil, 1, folder.Name)
+		value = appendString(value, 2, folder.Description)
+		value = appendTimestamp(value, 3, folder.CreatedAt)
+		for _, filename := range sortedKeys(folder.Files) {
+			file := folder.Files[filename]
+			if file == nil {
+				continue
+			}
+
+			fileValue := appendString(nil, 1, file.Name)
+			fileValue = appendString(fileValue, 2, file.Description)
+			fileValue = appendTimestamp(fileValue, 3, file.CreatedAt)
+			for _, tag := range file.Tags {
+				fileValue = appendString(fileValue, 4, tag)
+			}
+			value = appendEntry(value, 4, filename, fileValue)
+		}
+		for _, grantee := range sortedKeys(folder.Shares) {
+			value = appendEntry(value, 5, grantee, []byte(folder.Shares[grantee]))
+		}
+		for _, tag := range folder.Tags {
+			value = appendString(value, 6, tag)
+		}
+
+		b = appendEntry(b, 2, key, value)
+	}
+	b = appendString(b, 3, string(user.Role))
+	b = appendString(b, 4, user.PasswordHash)
+	for _, tokenHash := range sortedKeys(user.Sessions) {
+		b = appendEntry(b, 5, tokenHash, timestamp(user.Sessions[tokenHash]))
+	}
+	for _, id := range sortedKeys(user.Tokens) {
+		token := user.Tokens[id]
+		if token == nil {
+			continue
+		}
+
+		value := appendString(nil, 1, token.ID)
+		value = appendString(value, 2, token.Hash)
+		for _, scope := range token.Scopes {
+			value = appendString(value, 3, string(scope))
+		}
+		value = appendTimestamp(value, 4, token.CreatedAt)
+		value = appendTimestamp(value, 5, token.ExpiresAt)
+		b = appendEntry(b, 6, id, value)
+	}
+	if user.Quota != nil {
+		value := appendVarint(nil, 1, uint64(user.Quota.MaxFolders))
+		value = appendVarint(value, 2, uint64(user.Quota.MaxFolderFiles))
+		value = appendVarint(value, 3, uint64(user.Quota.MaxFiles))
+		value = appendVarint(value, 4, uint64(user.Quota.MaxBytes))
+		b = protowire.AppendTag(b, 7, protowire.BytesType)
+		b = protowire.AppendBytes(b, value)
+	}
+
+	return b
+}
+
+func consumeUser(b []byte, user *model.User) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			user.Username = string(value)
+		case 2:
+			folder := &model.Folder{Files: make(map[string]*model.File), Folders: make(map[string]*model.Folder)}
+			key, err := consumeEntry(value, func(value []byte) error {
+				return consumeFolder(value, folder)
+			})
+			if err != nil {
+				return err
+			}
+			user.Folders[key] = folder
+		case 3:
+			user.Role = model.Role(value)
+		case 4:
+			user.PasswordHash = string(value)
+		case 5:
+			var expiresAt time.Time
+			tokenHash, err := consumeEntry(value, func(value []byte) error {
+				return consumeTimestamp(value, &expiresAt)
+			})
+			if err != nil {
+				return err
+			}
+			if user.Sessions == nil {
+				user.Sessions = make(map[string]time.Time)
+			}
+			user.Sessions[tokenHash] = expiresAt
+		case 6:
+			token := &model.Token{}
+			id, err := consumeEntry(value, func(value []byte) error {
+				return consumeToken(value, token)
+			})
+			if err != nil {
+				return err
+			}
+			if user.Tokens == nil {
+				user.Tokens = make(map[string]*model.Token)
+			}
+			user.Tokens[id] = token
+		case 7:
+			user.Quota = &model.Quota{}
+			return consumeQuota(value, user.Quota)
+		}
+
+		return nil
+	})
+}
+
+func consumeToken(b []byte, token *model.Token) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			token.ID = string(value)
+		case 2:
+			token.Hash = string(value)
+		case 3:
+			token.Scopes = append(token.Scopes, model.Scope(value))
+		case 4:
+			return consumeTimestamp(value, &token.CreatedAt)
+		case 5:
+			return consumeTimestamp(value, &token.ExpiresAt)
+		}
+
+		return nil
+	})
+}
+
+func consumeQuota(b []byte, quota *model.Quota) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, _ []byte, varint uint64) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			quota.MaxFolders = int(varint)
+		case 2:
+			quota.MaxFolderFiles = int(varint)
+		case 3:
+			quota.MaxFiles = int(varint)
+		case 4:
+			quota.MaxBytes = int64(varint)
+		}
+
+		return nil
+	})
+}
+
+func consumeFolder(b []byte, folder *model.Folder) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			folder.Name = string(value)
+		case 2:
+			folder.Description = string(value)
+		case 3:
+			return consumeTimestamp(value, &folder.CreatedAt)
+		case 4:
+			file := &model.File{}
+			key, err := consumeEntry(value, func(value []byte) error {
+				return consumeFile(value, file)
+			})
+			if err != nil {
+				return err
+			}
+			folder.Files[key] = file
+		case 5:
+			var access model.Access
+			grantee, err := consumeEntry(value, func(value []byte) error {
+				access = model.Access(value)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if folder.Shares == nil {
+				folder.Shares = make(map[string]model.Access)
+			}
+			folder.Shares[grantee] = access
+		case 6:
+			folder.Tags = append(folder.Tags, string(value))
+		}
+
+		return nil
+	})
+}
+
+func consumeFile(b []byte, file *model.File) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			file.Name = string(value)
+		case 2:
+			file.Description = string(value)
+		case 3:
+			return consumeTimestamp(value, &file.CreatedAt)
+		case 4:
+			file.Tags = append(file.Tags, string(value))
+		}
+
+		return nil
+	})
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendTimestamp(b []byte, num protowire.Number, t time.Time) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, timestamp(t))
+}
+
+// timestamp is used to encode t as a Timestamp message.
+func timestamp(t time.Time) []byte {
+	value := protowire.AppendTag(nil, 1, protowire.VarintType)
+	value = protowire.AppendVarint(value, uint64(t.Unix()))
+	if nanos := t.Nanosecond(); nanos != 0 {
+		value = protowire.AppendTag(value, 2, protowire.VarintType)
+		value = protowire.AppendVarint(value, uint64(nanos))
+	}
+
+	return value
+}
+
+func consumeTimestamp(b []byte, t *time.Time) error {
+	var seconds, nanos int64
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, _ []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			seconds = int64(varint)
+		case num == 2 && typ == protowire.VarintType:
+			nanos = int64(int32(varint))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*t = time.Unix(seconds, nanos).UTC()
+	return nil
+}
+
+// appendEntry is used to append an entry of a map<string, message> field.
+func appendEntry(b []byte, num protowire.Number, key string, value []byte) []byte {
+	entry := protowire.AppendTag(nil, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, key)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, value)
+
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, entry)
+}
+
+// consumeEntry is used to read an entry of a map<string, message> field.
+func consumeEntry(b []byte, value func([]byte) error) (key string, err error) {
+	err = consumeFields(b, func(num protowire.Number, typ protowire.Type, field []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+
+		switch num {
+		case 1:
+			key = string(field)
+		case 2:
+			return value(field)
+		}
+
+		return nil
+	})
+
+	return key, err
+}
+
+// consumeFields is used to iterate the fields of a message, skipping the unknown wire types.
+func consumeFields(
+	b []byte,
+	field func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error,
+) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errMalformed
+		}
+		b = b[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errMalformed
+		}
+		b = b[n:]
+
+		err := field(num, typ, value, varint)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
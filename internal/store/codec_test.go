@@ -0,0 +1,313 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestCodecFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		format string
+		want   string
+	}{
+		{name: "json", path: "out/vfs.json", want: "json"},
+		{name: "gob", path: "out/vfs.gob", want: "gob"},
+		{name: "compressed msgpack", path: "out/vfs.mpk.gz", want: "msgpack"},
+		{name: "protobuf", path: "out/vfs.pb", want: "protobuf"},
+		{name: "yaml", path: "out/vfs.yml", want: "yaml"},
+		{name: "toml", path: "out/vfs.toml", want: "toml"},
+		{name: "unknown extension", path: "out/vfs.txt", want: "json"},
+		{name: "forced format", path: "out/vfs.json", format: "gob", want: "gob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetFormat(tt.format)
+			if err != nil {
+				t.Fatalf("SetFormat() error = %v", err)
+			}
+			defer func() {
+				_ = SetFormat("")
+			}()
+
+			if got := CodecFor(tt.path).Name(); got != tt.want {
+				t.Errorf("CodecFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := SetFormat("xml"); err == nil {
+		t.Errorf("SetFormat() with an unsupported format error = nil")
+	}
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 27, 10, 0, 0, 123456789, time.UTC)
+
+	for _, codec := range codecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			// linked back-references must not be followed
+			alice, _ := model.NewUser("alice")
+			alice.Role = model.RoleAdmin
+			alice.PasswordHash = "$argon2id$hash"
+			alice.Sessions = map[string]time.Time{"token-hash": created.Add(time.Hour)}
+			alice.Tokens = map[string]*model.Token{"abcd1234": {
+				ID:        "abcd1234",
+				Hash:      "token-hash",
+				Scopes:    []model.Scope{model.ScopeFoldersRead, model.ScopeFilesWrite},
+				CreatedAt: created,
+				ExpiresAt: created.Add(time.Hour),
+			}}
+			alice.Quota = &model.Quota{MaxFolders: 10, MaxFiles: 100, MaxBytes: 1 << 30}
+			photos, _ := model.NewFolder(alice, "photos", "holiday pictures")
+			photos.CreatedAt = created
+			beach, _ := model.NewFile(alice, photos, "beach", "")
+			beach.CreatedAt = created.Add(time.Minute)
+			beach.Tags = []string{"summer"}
+			photos.Files["beach"] = beach
+			photos.Shares = map[string]model.Access{"bob": model.AccessWrite}
+			photos.Tags = []string{"family", "travel"}
+			alice.Folders["photos"] = photos
+
+			buf := new(bytes.Buffer)
+			err := codec.Encode(buf, map[string]*model.User{"alice": alice})
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			users := make(map[string]*model.User)
+			version, err := codec.Decode(buf, &users)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if version != CurrentVersion {
+				t.Errorf("Decode() version = %v, want %v", version, CurrentVersion)
+			}
+
+			if users["alice"].Role != model.RoleAdmin {
+				t.Errorf("Decode() role = %v, want %v", users["alice"].Role, model.RoleAdmin)
+			}
+			if users["alice"].PasswordHash != alice.PasswordHash {
+				t.Errorf("Decode() password hash = %v, want %v", users["alice"].PasswordHash, alice.PasswordHash)
+			}
+			if !users["alice"].Sessions["token-hash"].Equal(created.Add(time.Hour)) {
+				t.Errorf("Decode() sessions = %v, want token-hash", users["alice"].Sessions)
+			}
+			token := users["alice"].Tokens["abcd1234"]
+			if token == nil || token.Hash != "token-hash" || len(token.Scopes) != 2 {
+				t.Fatalf("Decode() tokens = %v, want abcd1234", users["alice"].Tokens)
+			}
+			if !token.CreatedAt.Equal(created) || !token.ExpiresAt.Equal(created.Add(time.Hour)) {
+				t.Errorf("Decode() token times = %v, %v", token.CreatedAt, token.ExpiresAt)
+			}
+
+			if quota := users["alice"].Quota; quota == nil || *quota != *alice.Quota {
+				t.Errorf("Decode() quota = %v, want %v", quota, alice.Quota)
+			}
+
+			folder := users["alice"].Folders["photos"]
+			if folder.Name != "photos" || folder.Description != "holiday pictures" || !folder.CreatedAt.Equal(created) {
+				t.Errorf("Decode() folder = %+v", folder)
+			}
+			if len(folder.Shares) != 1 || folder.Shares["bob"] != model.AccessWrite {
+				t.Errorf("Decode() shares = %v, want bob with write access", folder.Shares)
+			}
+			if !slices.Equal(folder.Tags, photos.Tags) {
+				t.Errorf("Decode() folder tags = %v, want %v", folder.Tags, photos.Tags)
+			}
+
+			file := folder.Files["beach"]
+			if file.Name != "beach" || file.Description != "" || !file.CreatedAt.Equal(beach.CreatedAt) {
+				t.Errorf("Decode() file = %+v", file)
+			}
+			if !slices.Equal(file.Tags, beach.Tags) {
+				t.Errorf("Decode() file tags = %v, want %v", file.Tags, beach.Tags)
+			}
+		})
+	}
+}
+
+func TestWriteFile_Formats(t *testing.T) {
+	for _, path := range []string{"out/vfs.gob", "out/vfs.msgpack.zst", "out/vfs.pb.gz", "out/vfs.yaml", "out/vfs.toml"} {
+		t.Run(path, func(t *testing.T) {
+			err := Save(path, map[string]*model.User{
+				"alice": {Username: "alice", Folders: map[string]*model.Folder{}},
+			})
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			users, err := Load(path)
+			if err != nil || users["alice"] == nil {
+				t.Errorf("Load() users = %v, error = %v", users, err)
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
+
+func TestTextCodecs_RoundTrip(t *testing.T) {
+	// non-UTC offsets and nanoseconds must survive, like the quoting of descriptions
+	created := time.Date(2024, 5, 27, 10, 0, 0, 1, time.FixedZone("", 8*60*60))
+	descriptions := []string{
+		"",
+		"holiday pictures",
+		"multi\nline\n",
+		`quotes " and ' and \ backslash`,
+		"# not a comment: [not a table]",
+		"假期照片 🏖",
+		"  padded  ",
+	}
+
+	for _, codec := range []Codec{yamlCodec{}, tomlCodec{}} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			alice, _ := model.NewUser("alice")
+			for i, description := range descriptions {
+				name := string(rune('a' + i))
+				folder, _ := model.NewFolder(alice, name, description)
+				folder.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+				file, _ := model.NewFile(alice, folder, name, description)
+				file.CreatedAt = folder.CreatedAt.Add(time.Nanosecond)
+				folder.Files[name] = file
+				alice.Folders[name] = folder
+			}
+
+			buf := new(bytes.Buffer)
+			err := codec.Encode(buf, map[string]*model.User{"alice": alice})
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			users := make(map[string]*model.User)
+			_, err = codec.Decode(buf, &users)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			for name, want := range alice.Folders {
+				got := users["alice"].Folders[name]
+				if got.Description != want.Description || !got.CreatedAt.Equal(want.CreatedAt) {
+					t.Errorf("Decode() folder = %+v, want %+v", got, want)
+				}
+
+				gotFile, wantFile := got.Files[name], want.Files[name]
+				if gotFile.Description != wantFile.Description || !gotFile.CreatedAt.Equal(wantFile.CreatedAt) {
+					t.Errorf("Decode() file = %+v, want %+v", gotFile, wantFile)
+				}
+			}
+		})
+	}
+}
+
+func TestTextCodecs_HandWritten(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   Codec
+		doc     string
+		version int
+	}{
+		{
+			name:  "yaml",
+			codec: yamlCodec{},
+			doc: `version: 1
+data:
+  alice:
+    username: alice
+    folders:
+      photos:
+        name: photos
+        description: holiday pictures
+        created_at: 2024-05-27T10:00:00Z
+        files:
+          beach:
+            name: beach
+            description: sunset at the beach
+            created_at: 2024-05-27T10:05:00Z
+`,
+			version: 1,
+		},
+		{
+			name:  "yaml without envelope",
+			codec: yamlCodec{},
+			doc: `alice:
+  username: alice
+  folders:
+    photos:
+      name: photos
+      description: holiday pictures
+      created_at: 2024-05-27T10:00:00Z
+      files:
+        beach: {name: beach, description: sunset at the beach, created_at: "2024-05-27T10:05:00Z"}
+`,
+			version: 0,
+		},
+		{
+			name:  "toml",
+			codec: tomlCodec{},
+			doc: `version = 1
+
+[data.alice]
+username = "alice"
+
+[data.alice.folders.photos]
+name = "photos"
+description = "holiday pictures"
+created_at = 2024-05-27T10:00:00Z
+
+[data.alice.folders.photos.files.beach]
+name = "beach"
+description = "sunset at the beach"
+created_at = 2024-05-27T10:05:00Z
+`,
+			version: 1,
+		},
+		{
+			name:  "toml without envelope",
+			codec: tomlCodec{},
+			doc: `[alice]
+username = "alice"
+
+[alice.folders.photos]
+name = "photos"
+description = "holiday pictures"
+created_at = 2024-05-27T10:00:00Z
+
+[alice.folders.photos.files.beach]
+name = "beach"
+description = "sunset at the beach"
+created_at = 2024-05-27T10:05:00Z
+`,
+			version: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := make(map[string]*model.User)
+			version, err := tt.codec.Decode(bytes.NewBufferString(tt.doc), &users)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if version != tt.version {
+				t.Errorf("Decode() version = %v, want %v", version, tt.version)
+			}
+
+			folder := users["alice"].Folders["photos"]
+			if folder.Description != "holiday pictures" || !folder.CreatedAt.Equal(time.Date(2024, 5, 27, 10, 0, 0, 0, time.UTC)) {
+				t.Errorf("Decode() folder = %+v", folder)
+			}
+
+			file := folder.Files["beach"]
+			if file.Description != "sunset at the beach" || !file.CreatedAt.Equal(time.Date(2024, 5, 27, 10, 5, 0, 0, time.UTC)) {
+				t.Errorf("Decode() file = %+v", file)
+			}
+		})
+	}
+}
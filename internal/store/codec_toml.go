@@ -0,0 +1,55 @@
+package store
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// tomlCodec is a hand-editable format, mostly used for test fixtures.
+type tomlCodec struct{}
+
+func (tomlCodec) Name() string {
+	return "toml"
+}
+
+func (tomlCodec) Extensions() []string {
+	return []string{".toml"}
+}
+
+func (tomlCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	err := toml.NewEncoder(w).Encode(envelope{Version: CurrentVersion, Data: users})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return nil
+}
+
+func (tomlCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	var doc struct {
+		Version *int                   `toml:"version"`
+		Data    map[string]*model.User `toml:"data"`
+	}
+	err = toml.Unmarshal(data, &doc)
+	if err != nil || doc.Version == nil || *doc.Version != CurrentVersion {
+		var raw map[string]any
+		err = toml.Unmarshal(data, &raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+
+		return upgradeDocument(raw, users)
+	}
+
+	merge(users, doc.Data)
+
+	return *doc.Version, nil
+}
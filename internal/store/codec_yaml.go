@@ -0,0 +1,58 @@
+package store
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"gopkg.in/yaml.v3"
+)
+
+// yamlCodec is a hand-editable format, mostly used for test fixtures.
+type yamlCodec struct{}
+
+func (yamlCodec) Name() string {
+	return "yaml"
+}
+
+func (yamlCodec) Extensions() []string {
+	return []string{".yaml", ".yml"}
+}
+
+func (yamlCodec) Encode(w io.Writer, users map[string]*model.User) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(envelope{Version: CurrentVersion, Data: users})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+func (yamlCodec) Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read data: %w", err)
+	}
+
+	var doc struct {
+		Version *int                   `yaml:"version"`
+		Data    map[string]*model.User `yaml:"data"`
+	}
+	err = yaml.Unmarshal(data, &doc)
+	if err != nil || doc.Version == nil || *doc.Version != CurrentVersion {
+		var raw any
+		err = yaml.Unmarshal(data, &raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+
+		return upgradeDocument(raw, users)
+	}
+
+	merge(users, doc.Data)
+
+	return *doc.Version, nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression algorithms, chosen by the extension of the document when writing
+// and detected from the content when reading.
+const (
+	compressionNone = ""
+	compressionGzip = ".gz"
+	compressionZstd = ".zst"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compressionOf returns the compression algorithm of the document at path.
+func compressionOf(path string) string {
+	switch {
+	case strings.HasSuffix(path, compressionGzip):
+		return compressionGzip
+	case strings.HasSuffix(path, compressionZstd):
+		return compressionZstd
+	default:
+		return compressionNone
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// compressor is used to wrap w with the compression algorithm.
+func compressor(compression string, w io.Writer) (io.WriteCloser, error) {
+	switch compression {
+	case compressionGzip:
+		return gzip.NewWriter(w), nil
+	case compressionZstd:
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{Writer: w}, nil
+	}
+}
+
+// decompressor is used to wrap r with the algorithm it was compressed with, if any.
+func decompressor(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(head, zstdMagic):
+		decoder, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return decoder.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestWriteFile_Compression(t *testing.T) {
+	key, _ := ParseKey([]byte(strings.Repeat("k", keySize)))
+	defer SetSecret(nil)
+
+	tests := []struct {
+		name      string
+		path      string
+		secret    *Secret
+		wantMagic []byte
+	}{
+		{
+			name:      "gzip",
+			path:      "out/vfs.json.gz",
+			wantMagic: gzipMagic,
+		},
+		{
+			name:      "zstd",
+			path:      "out/vfs.json.zst",
+			wantMagic: zstdMagic,
+		},
+		{
+			name:      "plain",
+			path:      "out/vfs.json",
+			wantMagic: []byte("{"),
+		},
+		{
+			name:      "gzip then encrypted",
+			path:      "out/vfs.json.gz",
+			secret:    key,
+			wantMagic: magic,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSecret(tt.secret)
+			description := strings.Repeat("a long description ", 100)
+			err := WriteFile(tt.path, map[string]*model.User{
+				"alice": {Username: "alice", Folders: map[string]*model.Folder{
+					"notes": {Name: "notes", Description: description, Files: map[string]*model.File{}},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			raw, _ := os.ReadFile(tt.path)
+			if !bytes.HasPrefix(raw, tt.wantMagic) {
+				t.Errorf("WriteFile() content starts with %x, want %x", raw[:4], tt.wantMagic)
+			}
+
+			users := make(map[string]*model.User)
+			err = ReadFile(tt.path, &users)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if users["alice"].Folders["notes"].Description != description {
+				t.Errorf("ReadFile() lost the description")
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
+
+func TestDecode_Legacy(t *testing.T) {
+	tests := []struct {
+		name        string
+		document    string
+		wantVersion int
+		wantUsers   int
+	}{
+		{
+			name:        "current version",
+			document:    `{"version": 1, "data": {"alice": {"username": "alice"}}}`,
+			wantVersion: 1,
+			wantUsers:   1,
+		},
+		{
+			name:        "data before version",
+			document:    `{"data": {"alice": {"username": "alice"}}, "version": 1}`,
+			wantVersion: 1,
+			wantUsers:   1,
+		},
+		{
+			name:        "legacy document with a user named version",
+			document:    `{"version": {"username": "version"}, "data": {"username": "data"}}`,
+			wantVersion: 0,
+			wantUsers:   2,
+		},
+		{
+			name:        "empty legacy document",
+			document:    `{}`,
+			wantVersion: 0,
+			wantUsers:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := make(map[string]*model.User)
+			version, err := Decode(strings.NewReader(tt.document), &users)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if version != tt.wantVersion || len(users) != tt.wantUsers {
+				t.Errorf("Decode() version = %d, users = %v", version, users)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// An encrypted document is laid out as:
+//
+//	magic | mode | salt (16 bytes, zero for raw keys) | nonce (12 bytes) | AES-256-GCM ciphertext
+//
+// The header up to the nonce is authenticated as additional data.
+const (
+	modeRawKey     byte = 1
+	modePassphrase byte = 2
+
+	keySize  = 32
+	saltSize = 16
+)
+
+var magic = []byte("ISCOOL-ENC1")
+
+var (
+	// ErrKeyRequired is returned when reading an encrypted document without a key.
+	ErrKeyRequired = errors.New("the document is encrypted, provide a key or passphrase")
+
+	// ErrWrongKey is returned when a document can't be decrypted with the configured key.
+	ErrWrongKey = errors.New("wrong encryption key or passphrase, or the document is corrupted")
+)
+
+// Secret is the material the documents are encrypted with.
+type Secret struct {
+	sync.Mutex
+
+	mode byte
+	key  []byte
+
+	// derived caches the Argon2id keys of a passphrase by salt.
+	derived map[string][]byte
+}
+
+// secret is the Secret applied to every document; nil keeps them in plaintext.
+var secret *Secret
+
+// SetSecret is used to encrypt the documents written from now on with s, nil disables encryption.
+func SetSecret(s *Secret) {
+	secret = s
+}
+
+// Passphrase is used to create a Secret deriving its keys from passphrase with Argon2id.
+func Passphrase(passphrase string) (*Secret, error) {
+	if passphrase == "" {
+		return nil, errors.New("the passphrase must not be empty")
+	}
+
+	return &Secret{
+		mode:    modePassphrase,
+		key:     []byte(passphrase),
+		derived: make(map[string][]byte),
+	}, nil
+}
+
+// ParseKey is used to create a Secret from a 32 bytes key, either raw or encoded in hex or base64.
+func ParseKey(text []byte) (*Secret, error) {
+	key := text
+	if len(key) != keySize {
+		trimmed := strings.TrimSpace(string(text))
+		if decoded, err := hex.DecodeString(trimmed); err == nil {
+			key = decoded
+		} else if decoded, err = base64.StdEncoding.DecodeString(trimmed); err == nil {
+			key = decoded
+		}
+	}
+
+	if len(key) != keySize {
+		return nil, fmt.Errorf("the key must be %d bytes, raw or encoded in hex or base64", keySize)
+	}
+
+	return &Secret{
+		mode: modeRawKey,
+		key:  key,
+	}, nil
+}
+
+// keyFor is used to get the AES key of a document with salt.
+func (s *Secret) keyFor(salt []byte) []byte {
+	if s.mode == modeRawKey {
+		return s.key
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if key, exists := s.derived[string(salt)]; exists {
+		return key
+	}
+
+	key := argon2.IDKey(s.key, salt, 1, 64*1024, 4, keySize)
+	s.derived[string(salt)] = key
+
+	return key
+}
+
+// isEncrypted reports whether raw is an encrypted document.
+func isEncrypted(raw []byte) bool {
+	return bytes.HasPrefix(raw, magic)
+}
+
+// seal is used to encrypt a plaintext document with the configured secret, if any.
+func seal(plaintext []byte) ([]byte, error) {
+	if secret == nil {
+		return plaintext, nil
+	}
+
+	salt := make([]byte, saltSize)
+	if secret.mode == modePassphrase {
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+
+	aead, err := newAEAD(secret.keyFor(salt))
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(magic)+1+saltSize)
+	header = append(header, magic...)
+	header = append(header, secret.mode)
+	header = append(header, salt...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := append(header, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// open is used to decrypt raw with the configured secret, plaintext documents are returned as is.
+func open(raw []byte) ([]byte, error) {
+	if !isEncrypted(raw) {
+		return raw, nil
+	}
+
+	if secret == nil {
+		return nil, ErrKeyRequired
+	}
+
+	headerSize := len(magic) + 1 + saltSize
+	if len(raw) < headerSize {
+		return nil, ErrWrongKey
+	}
+
+	header, mode, salt := raw[:headerSize], raw[len(magic)], raw[len(magic)+1:headerSize]
+	if mode != secret.mode {
+		if mode == modePassphrase {
+			return nil, fmt.Errorf("%w: the document was encrypted with a passphrase", ErrWrongKey)
+		}
+
+		return nil, fmt.Errorf("%w: the document was encrypted with a key", ErrWrongKey)
+	}
+
+	aead, err := newAEAD(secret.keyFor(salt))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) < headerSize+aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+
+	nonce, ciphertext := raw[headerSize:headerSize+aead.NonceSize()], raw[headerSize+aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
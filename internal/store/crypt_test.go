@@ -0,0 +1,168 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr bool
+	}{
+		{
+			name:    "raw key",
+			text:    strings.Repeat("k", keySize),
+			wantErr: false,
+		},
+		{
+			name:    "hex key",
+			text:    strings.Repeat("ab", keySize) + "\n",
+			wantErr: false,
+		},
+		{
+			name:    "base64 key",
+			text:    "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+			wantErr: false,
+		},
+		{
+			name:    "too short key",
+			text:    "short",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKey([]byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryption(t *testing.T) {
+	key, _ := ParseKey([]byte(strings.Repeat("k", keySize)))
+	otherKey, _ := ParseKey([]byte(strings.Repeat("o", keySize)))
+	passphrase, _ := Passphrase("correct horse battery staple")
+	otherPassphrase, _ := Passphrase("wrong")
+	defer SetSecret(nil)
+
+	tests := []struct {
+		name    string
+		write   *Secret
+		read    *Secret
+		wantErr error
+	}{
+		{
+			name:    "raw key",
+			write:   key,
+			read:    key,
+			wantErr: nil,
+		},
+		{
+			name:    "passphrase",
+			write:   passphrase,
+			read:    passphrase,
+			wantErr: nil,
+		},
+		{
+			name:    "plaintext document read with a key",
+			write:   nil,
+			read:    key,
+			wantErr: nil,
+		},
+		{
+			name:    "wrong key",
+			write:   key,
+			read:    otherKey,
+			wantErr: ErrWrongKey,
+		},
+		{
+			name:    "wrong passphrase",
+			write:   passphrase,
+			read:    otherPassphrase,
+			wantErr: ErrWrongKey,
+		},
+		{
+			name:    "key instead of passphrase",
+			write:   passphrase,
+			read:    key,
+			wantErr: ErrWrongKey,
+		},
+		{
+			name:    "missing key",
+			write:   key,
+			read:    nil,
+			wantErr: ErrKeyRequired,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetSecret(tt.write)
+			err := WriteFile("out/vfs.json", map[string]*model.User{
+				"alice": {Username: "alice", Folders: map[string]*model.Folder{
+					"notes": {Name: "notes", Description: "sensitive", Files: map[string]*model.File{}},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			raw, _ := os.ReadFile("out/vfs.json")
+			if encrypted := tt.write != nil; bytes.Contains(raw, []byte("sensitive")) == encrypted {
+				t.Errorf("WriteFile() plaintext leaked = %v", encrypted)
+			}
+
+			SetSecret(tt.read)
+			users := make(map[string]*model.User)
+			err = ReadFile("out/vfs.json", &users)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadFile() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && users["alice"].Folders["notes"].Description != "sensitive" {
+				t.Errorf("ReadFile() users = %v", users)
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
+
+func TestRekey(t *testing.T) {
+	key, _ := ParseKey([]byte(strings.Repeat("k", keySize)))
+	next, _ := Passphrase("next")
+	defer SetSecret(nil)
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	SetSecret(key)
+	_ = Save("out/vfs.shards", map[string]*model.User{
+		"alice": {Username: "alice", Folders: map[string]*model.Folder{}},
+	})
+
+	err := Rekey("out/vfs.shards", next)
+	if err != nil {
+		t.Fatalf("Rekey() error = %v", err)
+	}
+
+	SetSecret(key)
+	_, err = Load("out/vfs.shards")
+	if !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Load() with the old key error = %v, want %v", err, ErrWrongKey)
+	}
+
+	SetSecret(next)
+	users, err := Load("out/vfs.shards")
+	if err != nil || users["alice"] == nil {
+		t.Errorf("Load() with the new key users = %v, error = %v", users, err)
+	}
+}
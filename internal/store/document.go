@@ -0,0 +1,54 @@
+package store
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// Document is the users of a document store with the lock guarding them. The user and folder managers
+// of the same path share it, so each one sees the changes of the other within a process.
+type Document struct {
+	sync.Mutex
+
+	Users map[string]*model.User
+}
+
+var documents = struct {
+	sync.Mutex
+	byPath map[string]*Document
+}{byPath: make(map[string]*Document)}
+
+// OpenDocument is used to get the Document of path shared by the process, reloaded from the file
+// so a new manager never starts from a stale copy.
+func OpenDocument(path string) (*Document, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = filepath.Clean(path)
+	}
+
+	documents.Lock()
+	doc, exists := documents.byPath[key]
+	if !exists {
+		doc = &Document{Users: make(map[string]*model.User)}
+		documents.byPath[key] = doc
+	}
+	documents.Unlock()
+
+	users := make(map[string]*model.User)
+	err = ReadFile(path, &users)
+	if err != nil {
+		return nil, err
+	}
+
+	// the map is refilled in place, the managers sharing it keep their reference
+	doc.Lock()
+	defer doc.Unlock()
+	clear(doc.Users)
+	for username, user := range users {
+		doc.Users[username] = user
+	}
+
+	return doc, nil
+}
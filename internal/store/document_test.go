@@ -0,0 +1,46 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestOpenDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vfs.json")
+
+	first, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	users := first.Users
+	users["user1"] = &model.User{Username: "user1", Folders: map[string]*model.Folder{}}
+
+	err = WriteFile(path, users)
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	second, err := OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	if second != first || len(users) != 1 {
+		t.Errorf("OpenDocument() didn't share the document of %s", path)
+	}
+
+	// a new document replaces the stale copy, in the map already shared
+	err = WriteFile(path, map[string]*model.User{"user2": {Username: "user2"}})
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, err = OpenDocument(path)
+	if err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	if _, exists := users["user2"]; !exists || len(users) != 1 {
+		t.Errorf("OpenDocument() users = %v, want only user2", users)
+	}
+}
@@ -0,0 +1,251 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// ReadFile is used to read the users document at path into users, upgrading older schema versions.
+// A missing file is not an error and leaves users untouched.
+func ReadFile(path string, users *map[string]*model.User) (err error) {
+	_, err = readDocument(path, users)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// WriteFile is used to write users to the document at path with the current schema version.
+// The document is serialized with CodecFor(path), compressed according to the extension of path
+// and encrypted when a Secret is set.
+func WriteFile(path string, users map[string]*model.User) (err error) {
+	// Ensure the directory exists
+	if err = utils.EnsureDir(path); err != nil {
+		return fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	// write next to the document and rename it, so a failure never leaves a truncated document
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	// encryption needs the whole plaintext, otherwise the document is streamed to the file
+	var sink io.Writer = file
+	var plaintext *bytes.Buffer
+	if secret != nil {
+		plaintext = new(bytes.Buffer)
+		sink = plaintext
+	}
+
+	cw, err := compressor(compressionOf(path), sink)
+	if err != nil {
+		return fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	w := bufio.NewWriter(cw)
+	err = CodecFor(path).Encode(w, users)
+	if err != nil {
+		return err
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	err = cw.Close()
+	if err != nil {
+		return fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	if plaintext != nil {
+		sealed, err := seal(plaintext.Bytes())
+		if err != nil {
+			return err
+		}
+
+		_, err = file.Write(sealed)
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// readDocument is used to read the document at path into users and returns its schema version.
+func readDocument(path string, users *map[string]*model.User) (version int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, err
+		}
+
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	return decodeDocument(file, path, users)
+}
+
+// Read is used to read a document from r into users, as if it was stored at path.
+// It serves documents kept outside the file system, like the blobs of a git repository.
+func Read(r io.Reader, path string, users *map[string]*model.User) error {
+	_, err := decodeDocument(r, path, users)
+	return err
+}
+
+// decodeDocument is used to decrypt, decompress and decode the document at path read from r.
+func decodeDocument(reader io.Reader, path string, users *map[string]*model.User) (version int, err error) {
+	var src io.Reader = bufio.NewReader(reader)
+	if head, _ := src.(*bufio.Reader).Peek(len(magic)); isEncrypted(head) {
+		raw, err := io.ReadAll(src)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		plaintext, err := open(raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+
+		src = bytes.NewReader(plaintext)
+	}
+
+	r, err := decompressor(src)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	defer r.Close()
+
+	version, err = CodecFor(path).Decode(r, users)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	return version, nil
+}
+
+// Encode is used to stream users to w as a plaintext document with the current schema version.
+func Encode(w io.Writer, users map[string]*model.User) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(envelope{Version: CurrentVersion, Data: users})
+	if err != nil {
+		return fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	return nil
+}
+
+// Decode is used to stream a plaintext document of any known schema version from r into users.
+// It returns the schema version the document was written with.
+//
+// Documents in the current version are decoded straight into users, older ones are buffered
+// so the migrations can rewrite them.
+func Decode(r io.Reader, users *map[string]*model.User) (version int, err error) {
+	decoder := json.NewDecoder(r)
+
+	tok, err := decoder.Token()
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+	if tok != json.Delim('{') {
+		return 0, errors.New("failed to unmarshal data: the document must be a JSON object")
+	}
+
+	fields := make(map[string]json.RawMessage)
+	version = -1
+	for decoder.More() {
+		tok, err = decoder.Token()
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+		key, _ := tok.(string)
+
+		if key == "data" && version == CurrentVersion && len(fields) == 1 {
+			err = decoder.Decode(users)
+			if err != nil {
+				return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+			}
+
+			return version, nil
+		}
+
+		var raw json.RawMessage
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+		}
+		fields[key] = raw
+
+		if key == "version" && len(fields) == 1 && json.Unmarshal(raw, &version) != nil {
+			version = -1
+		}
+	}
+
+	// not in the current layout, rebuild the document and upgrade it
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	version, data := decodeEnvelope(raw)
+	data, err = upgrade(version, data)
+	if err != nil {
+		return 0, err
+	}
+
+	err = json.Unmarshal(data, users)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unmarshal data: %w", err)
+	}
+
+	return version, nil
+}
+
+// Marshal is used to encode users as a document with the current schema version,
+// encrypted when a Secret is set.
+func Marshal(users map[string]*model.User) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := Encode(buf, users)
+	if err != nil {
+		return nil, err
+	}
+
+	return seal(buf.Bytes())
+}
+
+// Unmarshal is used to decode a document of any known schema version into users.
+func Unmarshal(raw []byte, users *map[string]*model.User) error {
+	raw, err := open(raw)
+	if err != nil {
+		return err
+	}
+
+	_, err = Decode(bytes.NewReader(raw), users)
+	return err
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestWriteFileAndReadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		users   map[string]*model.User
+		wantErr bool
+	}{
+		{
+			name: "round trip a document",
+			path: "out/vfs.json",
+			users: map[string]*model.User{
+				"user1": {
+					Username: "user1",
+					Folders: map[string]*model.Folder{
+						"folder1": {Name: "folder1", Description: "description", Files: map[string]*model.File{}},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "write to invalid path",
+			path:    "",
+			users:   map[string]*model.User{},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WriteFile(tt.path, tt.users)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+
+			got := make(map[string]*model.User)
+			err = ReadFile(tt.path, &got)
+			if err != nil {
+				t.Errorf("ReadFile() error = %v", err)
+				return
+			}
+
+			for username, user := range tt.users {
+				if got[username] == nil || len(got[username].Folders) != len(user.Folders) {
+					t.Errorf("ReadFile() got = %v, want %v", got[username], user)
+				}
+			}
+		})
+	}
+
+	_ = os.RemoveAll("out")
+}
+
+func TestReadFile_NotExist(t *testing.T) {
+	users := map[string]*model.User{"user1": {Username: "user1"}}
+
+	err := ReadFile("out/non-existing.json", &users)
+	if err != nil {
+		t.Errorf("ReadFile() error = %v, want nil", err)
+	}
+
+	if len(users) != 1 {
+		t.Errorf("ReadFile() changed users = %v", users)
+	}
+}
@@ -0,0 +1,508 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// now is the clock used to detect and repair future timestamps.
+var now = time.Now
+
+// Problem describes an integrity issue found in a store.
+type Problem struct {
+	// Path locates the item as document, or document:username/foldername/filename, or bucket:key.
+	Path     string
+	Message  string
+	Repaired bool
+}
+
+func (p Problem) String() string {
+	status := "not repaired"
+	if p.Repaired {
+		status = "repaired"
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", p.Path, p.Message, status)
+}
+
+// Fsck is used to verify every document of the store at path with its groups, or the objects of a bucket.
+// With repair, the problems that can be fixed safely are fixed and written back.
+func Fsck(path string, repair bool) (problems []Problem, err error) {
+	if utils.CheckPathType(path) == "s3" {
+		return checkBucket(path, repair)
+	}
+
+	paths, err := Documents(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the shares and groups refer to the users of every document, the shards of a store holding one each
+	documents := make([]map[string]*model.User, len(paths))
+	known := make(map[string]bool)
+	for n, doc := range paths {
+		documents[n] = make(map[string]*model.User)
+		err = ReadFile(doc, &documents[n])
+		if err != nil {
+			return nil, err
+		}
+
+		for username := range documents[n] {
+			known[username] = true
+		}
+	}
+
+	for n, doc := range paths {
+		users := documents[n]
+		found := Check(users, known, repair)
+		for _, problem := range found {
+			problem.Path = doc + ":" + problem.Path
+			problems = append(problems, problem)
+		}
+
+		if repair && slices.ContainsFunc(found, func(p Problem) bool { return p.Repaired }) {
+			err = WriteFile(doc, users)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	found, err := checkGroups(GroupsPath(path), known, repair)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, found...)
+
+	if utils.CheckPathType(path) == "sharded" {
+		found, err := checkIndex(path, repair)
+		if err != nil {
+			return nil, err
+		}
+
+		problems = append(problems, found...)
+	}
+
+	return problems, nil
+}
+
+// Check is used to verify the users in memory, repairing them in place with repair. The folders can only be
+// shared with the users in known, the users themselves when it's nil.
+func Check(users map[string]*model.User, known map[string]bool, repair bool) (problems []Problem) {
+	report := func(path, message string, repaired bool) {
+		problems = append(problems, Problem{Path: path, Message: message, Repaired: repaired})
+	}
+
+	if known == nil {
+		known = make(map[string]bool, len(users))
+		for username := range users {
+			known[username] = true
+		}
+	}
+
+	for _, key := range sortedKeys(users) {
+		user := users[key]
+		if user == nil {
+			report(key, "user is empty", repair)
+			if repair {
+				delete(users, key)
+			}
+			continue
+		}
+
+		key = checkName(users, key, &user.Username, key, report, repair)
+
+		if user.Folders == nil {
+			report(key, "folders are missing", repair)
+			if repair {
+				user.Folders = make(map[string]*model.Folder)
+			}
+			continue
+		}
+
+		for _, foldername := range sortedKeys(user.Folders) {
+			folder := user.Folders[foldername]
+			path := key + "/" + foldername
+			if folder == nil {
+				report(path, "folder is empty", repair)
+				if repair {
+					delete(user.Folders, foldername)
+				}
+				continue
+			}
+
+			foldername = checkName(user.Folders, foldername, &folder.Name, path, report, repair)
+			path = key + "/" + foldername
+			checkCreatedAt(&folder.CreatedAt, path, report, repair)
+			checkShares(folder, key, known, path, report, repair)
+
+			if folder.Files == nil {
+				report(path, "files are missing", repair)
+				if repair {
+					folder.Files = make(map[string]*model.File)
+				}
+				continue
+			}
+
+			for _, filename := range sortedKeys(folder.Files) {
+				file := folder.Files[filename]
+				filePath := path + "/" + filename
+				if file == nil {
+					report(filePath, "file is empty", repair)
+					if repair {
+						delete(folder.Files, filename)
+					}
+					continue
+				}
+
+				filename = checkName(folder.Files, filename, &file.Name, filePath, report, repair)
+				filePath = path + "/" + filename
+				checkCreatedAt(&file.CreatedAt, filePath, report, repair)
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkName is used to verify that the key of an item matches its embedded name and is valid.
+// It returns the key under which the item is stored afterward.
+func checkName[T any](
+	items map[string]*T,
+	key string,
+	name *string,
+	path string,
+	report func(path, message string, repaired bool),
+	repair bool,
+) string {
+	keyErr := model.ValidateInput(key)
+
+	if *name != key {
+		switch {
+		case keyErr == nil:
+			// the key is what every lookup uses, so it wins
+			report(path, fmt.Sprintf("embedded name %q doesn't match the key", *name), repair)
+			if repair {
+				*name = key
+			}
+		case model.ValidateInput(*name) == nil && items[*name] == nil:
+			report(path, fmt.Sprintf("invalid key is stored as %q", *name), repair)
+			if repair {
+				items[*name] = items[key]
+				delete(items, key)
+				return *name
+			}
+		default:
+			report(path, fmt.Sprintf("invalid key doesn't match the embedded name %q", *name), false)
+		}
+	}
+
+	if keyErr != nil && *name == key {
+		report(path, "invalid name: "+keyErr.Error(), false)
+	}
+
+	return key
+}
+
+// checkCreatedAt is used to verify that a creation time is set and not in the future.
+func checkCreatedAt(createdAt *time.Time, path string, report func(path, message string, repaired bool), repair bool) {
+	switch {
+	case createdAt.IsZero():
+		report(path, "created_at is zero", repair)
+	case createdAt.After(now()):
+		report(path, "created_at is in the future", repair)
+	default:
+		return
+	}
+
+	if repair {
+		*createdAt = now()
+	}
+}
+
+// checkShares is used to verify that the folder of username is only shared with the other users in known.
+func checkShares(
+	folder *model.Folder,
+	username string,
+	known map[string]bool,
+	path string,
+	report func(path, message string, repaired bool),
+	repair bool,
+) {
+	for _, grantee := range sortedKeys(folder.Shares) {
+		switch {
+		case grantee == username:
+			report(path, "shared with its owner", repair)
+		case !known[grantee]:
+			report(path, fmt.Sprintf("shared with %s, who doesn't exist", grantee), repair)
+		default:
+			continue
+		}
+
+		if repair {
+			delete(folder.Shares, grantee)
+		}
+	}
+}
+
+// checkGroups is used to verify that the members of the groups at path are known users, and that the folders
+// owned by groups belong to existing ones. With repair, the unknown members are removed, while the folders of
+// a missing group are only reported, as nobody could reach them once removed.
+func checkGroups(path string, known map[string]bool, repair bool) (problems []Problem, err error) {
+	var groups Groups
+	err = ReadGroups(path, &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedKeys(groups.Groups) {
+		group := groups.Groups[name]
+		if group == nil {
+			problems = append(problems, Problem{Path: path + ":" + name, Message: "group is empty", Repaired: repair})
+			if repair {
+				delete(groups.Groups, name)
+			}
+			continue
+		}
+
+		for _, member := range slices.Clone(group.Members) {
+			if known[member] {
+				continue
+			}
+
+			problems = append(problems, Problem{
+				Path:     path + ":" + name,
+				Message:  fmt.Sprintf("member %s doesn't exist", member),
+				Repaired: repair,
+			})
+			if repair {
+				group.Members = slices.DeleteFunc(group.Members, func(m string) bool { return m == member })
+			}
+		}
+	}
+
+	for _, owner := range sortedKeys(groups.Owners) {
+		if groups.Groups[strings.TrimPrefix(owner, model.GroupPrefix)] == nil {
+			problems = append(problems, Problem{Path: path + ":" + owner, Message: "folders of a missing group"})
+		}
+	}
+
+	if repair && slices.ContainsFunc(problems, func(p Problem) bool { return p.Repaired }) {
+		err = WriteGroups(path, &groups)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return problems, nil
+}
+
+// checkIndex is used to verify that the index of a sharded store matches its shards.
+func checkIndex(root string, repair bool) (problems []Problem, err error) {
+	usernames, err := ReadIndex(root)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(ShardPath(root, "*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var shards []string
+	for _, match := range matches {
+		shards = append(shards, strings.TrimSuffix(filepath.Base(match), ".json"))
+	}
+
+	for _, username := range shards {
+		if !slices.Contains(usernames, username) {
+			problems = append(problems, Problem{Path: IndexPath(root), Message: "orphaned shard " + username, Repaired: repair})
+		}
+	}
+
+	for _, username := range usernames {
+		if !slices.Contains(shards, username) {
+			problems = append(problems, Problem{Path: IndexPath(root), Message: "missing shard " + username, Repaired: repair})
+		}
+	}
+
+	if repair && len(problems) > 0 {
+		err = WriteIndex(root, shards)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return problems, nil
+}
+
+// tags is the part of the metadata of an object of a bucket that checkBucket reads.
+type tags struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// objects is what checkBucket finds under a user of a bucket.
+type objects struct {
+	// want and have are the keys of the tag markers the folders and files need, and the ones there are.
+	want, have map[string]bool
+
+	// orphaned are the keys left under folders and files without metadata, like the content of a file.
+	orphaned []string
+}
+
+// checkBucket is used to verify the objects of the bucket at path: the tag markers must match the tags of the
+// folders and files, and every folder and file must have its metadata. The markers are only a hint for the
+// lists by tag, so a missing one hides a tagged item and a stale one is skipped. With repair, the missing
+// markers are put, and the stale ones and the objects left without metadata deleted.
+func checkBucket(path string, repair bool) (problems []Problem, err error) {
+	ctx := context.Background()
+	b, err := bucket.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames, err := b.Dirs(ctx, bucket.UsersPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	report := func(key, message string) {
+		problems = append(problems, Problem{Path: path + ":" + key, Message: message, Repaired: repair})
+	}
+
+	for _, username := range usernames {
+		found, err := userObjects(ctx, b, username)
+		if err != nil {
+			return nil, err
+		}
+
+		var put, deleted []string
+		for _, key := range sortedKeys(found.want) {
+			if !found.have[key] {
+				report(key, "missing tag marker")
+				put = append(put, key)
+			}
+		}
+		for _, key := range sortedKeys(found.have) {
+			if !found.want[key] {
+				report(key, "stale tag marker")
+				deleted = append(deleted, key)
+			}
+		}
+		for _, key := range found.orphaned {
+			report(key, "orphaned object without metadata")
+			deleted = append(deleted, key)
+		}
+
+		if !repair {
+			continue
+		}
+
+		for _, key := range put {
+			err = b.Put(ctx, key, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = b.Delete(ctx, deleted...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return problems, nil
+}
+
+// userObjects is used to walk the folders and files of username in the bucket.
+func userObjects(ctx context.Context, b *bucket.Bucket, username string) (found objects, err error) {
+	found.want, found.have = make(map[string]bool), make(map[string]bool)
+
+	markers, err := b.List(ctx, bucket.TagsPrefix(username))
+	if err != nil {
+		return found, err
+	}
+
+	foldernames, err := b.Dirs(ctx, bucket.FoldersPrefix(username))
+	if err != nil {
+		return found, err
+	}
+
+	for _, foldername := range foldernames {
+		var folder tags
+		err = b.GetJSON(ctx, bucket.FolderKey(username, foldername), &folder)
+		if errors.Is(err, bucket.ErrNotFound) {
+			keys, err := b.List(ctx, bucket.FolderPrefix(username, foldername))
+			if err != nil {
+				return found, err
+			}
+
+			found.orphaned = append(found.orphaned, keys...)
+			continue
+		}
+		if err != nil {
+			return found, err
+		}
+		for _, tag := range folder.Tags {
+			found.want[bucket.TagKey(username, tag, foldername)] = true
+		}
+
+		fileMarkers, err := b.List(ctx, bucket.FileTagsPrefix(username, foldername))
+		if err != nil {
+			return found, err
+		}
+		markers = append(markers, fileMarkers...)
+
+		filenames, err := b.Dirs(ctx, bucket.FilesPrefix(username, foldername))
+		if err != nil {
+			return found, err
+		}
+
+		for _, filename := range filenames {
+			var file tags
+			err = b.GetJSON(ctx, bucket.FileKey(username, foldername, filename), &file)
+			if errors.Is(err, bucket.ErrNotFound) {
+				keys, err := b.List(ctx, bucket.FilePrefix(username, foldername, filename))
+				if err != nil {
+					return found, err
+				}
+
+				found.orphaned = append(found.orphaned, keys...)
+				continue
+			}
+			if err != nil {
+				return found, err
+			}
+
+			for _, tag := range file.Tags {
+				found.want[bucket.FileTagKey(username, foldername, tag, filename)] = true
+			}
+		}
+	}
+
+	for _, key := range markers {
+		found.have[key] = true
+	}
+
+	return found, nil
+}
+
+// sortedKeys is used to iterate a map in a stable order, so reports are reproducible.
+func sortedKeys[T any](items map[string]T) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,271 @@
+package store
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket"
+	"github.com/blackhorseya/iscool-assessment/internal/bucket/buckettest"
+)
+
+func TestCheck(t *testing.T) {
+	created := time.Date(2024, 5, 27, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return created.Add(time.Hour) }
+	defer func() {
+		now = time.Now
+	}()
+
+	newUsers := func() map[string]*model.User {
+		alice := &model.User{Username: "alice", Folders: map[string]*model.Folder{}}
+		photos := &model.Folder{Name: "photos", CreatedAt: created, Files: map[string]*model.File{}}
+		photos.Files["beach"] = &model.File{Name: "beach", CreatedAt: created}
+		alice.Folders["photos"] = photos
+
+		return map[string]*model.User{"alice": alice}
+	}
+
+	tests := []struct {
+		name         string
+		mock         func(users map[string]*model.User)
+		wantProblems int
+		wantRepaired int
+		verify       func(t *testing.T, users map[string]*model.User)
+	}{
+		{
+			name:         "consistent store",
+			mock:         func(users map[string]*model.User) {},
+			wantProblems: 0,
+			wantRepaired: 0,
+		},
+		{
+			name: "embedded name doesn't match the key",
+			mock: func(users map[string]*model.User) {
+				users["alice"].Folders["photos"].Name = "pictures"
+			},
+			wantProblems: 1,
+			wantRepaired: 1,
+			verify: func(t *testing.T, users map[string]*model.User) {
+				if users["alice"].Folders["photos"].Name != "photos" {
+					t.Errorf("Check() didn't restore the folder name")
+				}
+			},
+		},
+		{
+			name: "invalid key with a valid embedded name",
+			mock: func(users map[string]*model.User) {
+				folder := users["alice"].Folders["photos"]
+				users["alice"].Folders["photos!"] = folder
+				delete(users["alice"].Folders, "photos")
+			},
+			wantProblems: 1,
+			wantRepaired: 1,
+			verify: func(t *testing.T, users map[string]*model.User) {
+				if users["alice"].Folders["photos"] == nil {
+					t.Errorf("Check() didn't re-key the folder")
+				}
+			},
+		},
+		{
+			name: "invalid name can't be repaired",
+			mock: func(users map[string]*model.User) {
+				users["alice"].Folders["photos"].Files["beach!"] = &model.File{Name: "beach!", CreatedAt: created}
+			},
+			wantProblems: 1,
+			wantRepaired: 0,
+		},
+		{
+			name: "shared with its owner and a missing user",
+			mock: func(users map[string]*model.User) {
+				users["alice"].Folders["photos"].Shares = map[string]model.Access{
+					"alice": model.AccessRead,
+					"bob":   model.AccessWrite,
+				}
+			},
+			wantProblems: 2,
+			wantRepaired: 2,
+			verify: func(t *testing.T, users map[string]*model.User) {
+				if len(users["alice"].Folders["photos"].Shares) != 0 {
+					t.Errorf("Check() didn't remove the dangling shares")
+				}
+			},
+		},
+		{
+			name: "shared with another user",
+			mock: func(users map[string]*model.User) {
+				users["bob"] = &model.User{Username: "bob", Folders: map[string]*model.Folder{}}
+				users["alice"].Folders["photos"].Shares = map[string]model.Access{"bob": model.AccessRead}
+			},
+			wantProblems: 0,
+			wantRepaired: 0,
+		},
+		{
+			name: "zero and future created_at",
+			mock: func(users map[string]*model.User) {
+				users["alice"].Folders["photos"].CreatedAt = time.Time{}
+				users["alice"].Folders["photos"].Files["beach"].CreatedAt = created.Add(48 * time.Hour)
+			},
+			wantProblems: 2,
+			wantRepaired: 2,
+			verify: func(t *testing.T, users map[string]*model.User) {
+				if !users["alice"].Folders["photos"].CreatedAt.Equal(now()) {
+					t.Errorf("Check() didn't reset created_at")
+				}
+			},
+		},
+		{
+			name: "missing maps",
+			mock: func(users map[string]*model.User) {
+				users["alice"].Folders["photos"].Files = nil
+			},
+			wantProblems: 1,
+			wantRepaired: 1,
+			verify: func(t *testing.T, users map[string]*model.User) {
+				if users["alice"].Folders["photos"].Files == nil {
+					t.Errorf("Check() didn't create the files map")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newUsers()
+			tt.mock(users)
+
+			problems := Check(users, nil, true)
+			if len(problems) != tt.wantProblems {
+				t.Fatalf("Check() problems = %v, want %d", problems, tt.wantProblems)
+			}
+
+			repaired := 0
+			for _, problem := range problems {
+				if problem.Repaired {
+					repaired++
+				}
+			}
+			if repaired != tt.wantRepaired {
+				t.Errorf("Check() repaired = %d, want %d", repaired, tt.wantRepaired)
+			}
+
+			if tt.verify != nil {
+				tt.verify(t, users)
+			}
+
+			if again := Check(users, nil, false); len(again)-(tt.wantProblems-tt.wantRepaired) != 0 {
+				t.Errorf("Check() after repair problems = %v", again)
+			}
+		})
+	}
+}
+
+func TestFsck_Sharded(t *testing.T) {
+	const root = "out/vfs.shards"
+	_ = WriteFile(ShardPath(root, "alice"), map[string]*model.User{
+		"alice": {Username: "alice", Folders: map[string]*model.Folder{}},
+	})
+	_ = WriteIndex(root, []string{"bob"})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	problems, err := Fsck(root, false)
+	if err != nil {
+		t.Fatalf("Fsck() error = %v", err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Fsck() problems = %v, want orphaned alice and missing bob", problems)
+	}
+
+	_, err = Fsck(root, true)
+	if err != nil {
+		t.Fatalf("Fsck() repair error = %v", err)
+	}
+
+	problems, _ = Fsck(root, false)
+	if len(problems) != 0 {
+		t.Errorf("Fsck() after repair problems = %v", problems)
+	}
+}
+
+func TestFsck_Groups(t *testing.T) {
+	const root = "out/vfs.shards"
+	for _, username := range []string{"alice", "bob"} {
+		_ = WriteFile(ShardPath(root, username), map[string]*model.User{
+			username: {Username: username, Folders: map[string]*model.Folder{}},
+		})
+	}
+	_ = WriteIndex(root, []string{"alice", "bob"})
+	_ = WriteGroups(GroupsPath(root), &Groups{
+		Groups: map[string]*model.Group{"team": {Name: "team", Members: []string{"alice", "bob", "carol"}}},
+		Owners: map[string]*model.User{
+			"group:team": {Username: "group:team", Folders: map[string]*model.Folder{}},
+			"group:gone": {Username: "group:gone", Folders: map[string]*model.Folder{}},
+		},
+	})
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	problems, err := Fsck(root, false)
+	if err != nil {
+		t.Fatalf("Fsck() error = %v", err)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("Fsck() problems = %v, want missing member carol and missing group gone", problems)
+	}
+
+	_, err = Fsck(root, true)
+	if err != nil {
+		t.Fatalf("Fsck() repair error = %v", err)
+	}
+
+	// the folders of a missing group can't be repaired
+	problems, _ = Fsck(root, false)
+	if len(problems) != 1 {
+		t.Errorf("Fsck() after repair problems = %v", problems)
+	}
+
+	var groups Groups
+	_ = ReadGroups(GroupsPath(root), &groups)
+	if members := groups.Groups["team"].Members; len(members) != 2 {
+		t.Errorf("Fsck() repair didn't remove the missing member, got %v", members)
+	}
+}
+
+func TestFsck_Bucket(t *testing.T) {
+	path := buckettest.Start(t)
+	ctx := context.Background()
+
+	b, _ := bucket.Open(path)
+	_ = b.PutJSON(ctx, bucket.UserKey("alice"), model.User{Username: "alice"})
+	_ = b.PutJSON(ctx, bucket.FolderKey("alice", "photos"), map[string]any{"name": "photos", "tags": []string{"summer"}})
+	_ = b.PutJSON(ctx, bucket.FileKey("alice", "photos", "beach"), map[string]any{"name": "beach", "tags": []string{"sea"}})
+	_ = b.Put(ctx, bucket.FileTagKey("alice", "photos", "sea", "beach"), nil)
+	_ = b.Put(ctx, bucket.TagKey("alice", "winter", "photos"), nil)
+	_ = b.Put(ctx, bucket.ContentKey("alice", "photos", "lost"), nil)
+
+	problems, err := Fsck(path, false)
+	if err != nil {
+		t.Fatalf("Fsck() error = %v", err)
+	}
+	if len(problems) != 3 {
+		t.Fatalf("Fsck() problems = %v, want missing summer, stale winter and orphaned lost", problems)
+	}
+
+	_, err = Fsck(path, true)
+	if err != nil {
+		t.Fatalf("Fsck() repair error = %v", err)
+	}
+
+	problems, _ = Fsck(path, false)
+	if len(problems) != 0 {
+		t.Errorf("Fsck() after repair problems = %v", problems)
+	}
+
+	keys, _ := b.List(ctx, bucket.TagPrefix("alice", "summer"))
+	if len(keys) != 1 {
+		t.Errorf("Fsck() repair didn't put the missing marker, got %v", keys)
+	}
+}
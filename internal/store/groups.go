@@ -0,0 +1,156 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// groupsFile is the name of the file of the groups in the directory of a sharded store.
+const groupsFile = "groups.json"
+
+// Groups is the document of the groups of a store, with the folders they own by the name of their owner.
+type Groups struct {
+	Groups map[string]*model.Group `json:"groups"`
+	Owners map[string]*model.User  `json:"owners"`
+}
+
+// GroupsPath returns the path of the groups of the store at path: in the directory of a sharded store,
+// next to a document otherwise, compressed like it, as out/vfs.groups.json.gz for out/vfs.yaml.gz.
+func GroupsPath(path string) string {
+	groups := besidePath(path, groupsFile)
+	if compression := compressionOf(path); compression != compressionNone {
+		groups += compression
+	}
+
+	return groups
+}
+
+// besidePath returns the path of the file named file kept with the store at path: in the directory of a sharded
+// store, next to a document otherwise, named after it.
+func besidePath(path, file string) string {
+	if utils.CheckPathType(path) == "sharded" {
+		return filepath.Join(path, file)
+	}
+
+	// a leading dot is part of the name, like the staged copy of a batch
+	base := filepath.Base(path)
+	name, _, _ := strings.Cut(base[1:], ".")
+	return filepath.Join(filepath.Dir(path), base[:1]+name+"."+file)
+}
+
+// ReadGroups is used to read the groups at path into groups, decrypting and decompressing them like a document.
+// A missing file is not an error.
+func ReadGroups(path string, groups *Groups) (err error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	raw, err = open(raw)
+	if err != nil {
+		return fmt.Errorf("failed to read groups: %w", err)
+	}
+
+	r, err := decompressor(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("failed to decompress groups: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress groups: %w", err)
+	}
+
+	err = json.Unmarshal(data, groups)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal groups: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalGroups is used to encode groups in JSON, encrypted when a Secret is set, like Marshal for a document.
+func MarshalGroups(groups *Groups) ([]byte, error) {
+	data, err := json.Marshal(groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal groups: %w", err)
+	}
+
+	return seal(data)
+}
+
+// UnmarshalGroups is used to decode the groups encoded by MarshalGroups.
+func UnmarshalGroups(raw []byte, groups *Groups) error {
+	data, err := open(raw)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(data, groups)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal groups: %w", err)
+	}
+
+	return nil
+}
+
+// WriteGroups is used to write groups to path, replacing the file at once. They are kept in JSON whatever the
+// format of the documents, since the codecs only encode users, but compressed according to the extension of path
+// and encrypted when a Secret is set, like a document.
+func WriteGroups(path string, groups *Groups) (err error) {
+	if err = utils.EnsureDir(path); err != nil {
+		return fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	plaintext, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal groups: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	cw, err := compressor(compressionOf(path), buf)
+	if err != nil {
+		return fmt.Errorf("failed to compress groups: %w", err)
+	}
+
+	_, err = cw.Write(plaintext)
+	if err == nil {
+		err = cw.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to compress groups: %w", err)
+	}
+
+	data, err := seal(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write groups: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write groups: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestGroupsPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "out/vfs.json", want: "out/vfs.groups.json"},
+		{path: "out/vfs.yaml.gz", want: "out/vfs.groups.json.gz"},
+		{path: "out/vfs.shards", want: "out/vfs.shards/groups.json"},
+		{path: "out/.batch-vfs.json", want: "out/.batch-vfs.groups.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := GroupsPath(tt.path); got != tt.want {
+				t.Errorf("GroupsPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteGroupsAndReadGroups(t *testing.T) {
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	group := &model.Group{Name: "team", Members: []string{"user1"}}
+	owner := group.Owner()
+	owner.Folders = map[string]*model.Folder{"folder1": {Name: "folder1", Files: map[string]*model.File{}}}
+	want := &Groups{
+		Groups: map[string]*model.Group{group.Name: group},
+		Owners: map[string]*model.User{owner.Username: owner},
+	}
+
+	err := WriteGroups("out/vfs.groups.json", want)
+	if err != nil {
+		t.Fatalf("WriteGroups() error = %v", err)
+	}
+
+	got := &Groups{}
+	err = ReadGroups("out/vfs.groups.json", got)
+	if err != nil {
+		t.Fatalf("ReadGroups() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Groups, want.Groups) || got.Owners[owner.Username].Folders["folder1"] == nil {
+		t.Errorf("ReadGroups() got = %+v, want %+v", got, want)
+	}
+
+	missing := &Groups{}
+	err = ReadGroups("out/missing.groups.json", missing)
+	if err != nil || missing.Groups != nil {
+		t.Errorf("ReadGroups() of missing file got = %+v, error = %v", missing, err)
+	}
+}
+
+func TestWriteGroups_Sealed(t *testing.T) {
+	secret, err := Passphrase("secret")
+	if err != nil {
+		t.Fatalf("Passphrase() error = %v", err)
+	}
+	SetSecret(secret)
+	defer func() {
+		SetSecret(nil)
+		_ = os.RemoveAll("out")
+	}()
+
+	want := &Groups{Groups: map[string]*model.Group{"team": {Name: "team", Members: []string{"user1"}}}}
+	err = WriteGroups("out/vfs.groups.json.gz", want)
+	if err != nil {
+		t.Fatalf("WriteGroups() error = %v", err)
+	}
+
+	// the names and the members don't leak from an encrypted store
+	data, _ := os.ReadFile("out/vfs.groups.json.gz")
+	if bytes.Contains(data, []byte("team")) || bytes.Contains(data, []byte("user1")) {
+		t.Errorf("WriteGroups() wrote the groups in plaintext")
+	}
+
+	got := &Groups{}
+	err = ReadGroups("out/vfs.groups.json.gz", got)
+	if err != nil || !reflect.DeepEqual(got.Groups, want.Groups) {
+		t.Errorf("ReadGroups() got = %+v, error = %v, want %+v", got, err, want)
+	}
+
+	SetSecret(nil)
+	if err = ReadGroups("out/vfs.groups.json.gz", &Groups{}); !errors.Is(err, ErrKeyRequired) {
+		t.Errorf("ReadGroups() without the secret error = %v, want %v", err, ErrKeyRequired)
+	}
+}
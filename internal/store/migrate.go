@@ -0,0 +1,71 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// Upgrade describes the schema migration of one document.
+type Upgrade struct {
+	Path string
+	From int
+	To   int
+}
+
+// Documents is used to list the paths of the documents making up the store at path.
+func Documents(path string) (paths []string, err error) {
+	switch pathType := utils.CheckPathType(path); {
+	case utils.IsDocument(pathType):
+		return []string{path}, nil
+	case pathType == "sharded":
+		usernames, err := ReadIndex(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, username := range usernames {
+			paths = append(paths, ShardPath(path, username))
+		}
+
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("unsupported path type: %s", pathType)
+	}
+}
+
+// Migrate is used to upgrade every document of the store at path to CurrentVersion.
+// With dryRun nothing is written, the upgrades only describe what would be done.
+func Migrate(path string, dryRun bool) (upgrades []Upgrade, err error) {
+	paths, err := Documents(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range paths {
+		users := make(map[string]*model.User)
+		version, err := readDocument(doc, &users)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("failed to migrate %s: %w", doc, err)
+		}
+
+		upgrades = append(upgrades, Upgrade{Path: doc, From: version, To: CurrentVersion})
+		if dryRun || version == CurrentVersion {
+			continue
+		}
+
+		err = WriteFile(doc, users)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return upgrades, nil
+}
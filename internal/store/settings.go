@@ -0,0 +1,80 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// settingsFile is the name of the file of the settings in the directory of a sharded store.
+const settingsFile = "settings.json"
+
+// Settings are the options of a store, kept along with it.
+type Settings struct {
+	// RequireAuth makes every change to the store demand a valid session.
+	RequireAuth bool `json:"require_auth"`
+}
+
+// SettingsPath returns the path of the settings of the store at path, like GroupsPath, or an empty path for
+// the stores which can't keep any, the ones which aren't a document or sharded.
+func SettingsPath(path string) string {
+	pathType := utils.CheckPathType(path)
+	if pathType != "sharded" && !utils.IsDocument(pathType) {
+		return ""
+	}
+
+	return besidePath(path, settingsFile)
+}
+
+// ReadSettings is used to read the settings at path. A missing file, or an empty path, is the default settings.
+func ReadSettings(path string) (settings *Settings, err error) {
+	settings = &Settings{}
+	if path == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return settings, nil
+		}
+
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	err = json.Unmarshal(data, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// WriteSettings is used to write settings to path, replacing the file at once.
+func WriteSettings(path string, settings *Settings) (err error) {
+	if err = utils.EnsureDir(path); err != nil {
+		return fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSettingsPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "out/vfs.json", want: "out/vfs.settings.json"},
+		{path: "out/vfs.yaml.gz", want: "out/vfs.settings.json"},
+		{path: "out/vfs.shards", want: "out/vfs.shards/settings.json"},
+		{path: "s3://bucket/prefix", want: ""},
+		{path: "redis://localhost:6379/0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := SettingsPath(tt.path); got != tt.want {
+				t.Errorf("SettingsPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSettingsAndReadSettings(t *testing.T) {
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	settings, err := ReadSettings("out/vfs.settings.json")
+	if err != nil || settings.RequireAuth {
+		t.Errorf("ReadSettings() of missing file got = %+v, error = %v", settings, err)
+	}
+
+	err = WriteSettings("out/vfs.settings.json", &Settings{RequireAuth: true})
+	if err != nil {
+		t.Fatalf("WriteSettings() error = %v", err)
+	}
+
+	settings, err = ReadSettings("out/vfs.settings.json")
+	if err != nil || !settings.RequireAuth {
+		t.Errorf("ReadSettings() got = %+v, error = %v", settings, err)
+	}
+
+	settings, err = ReadSettings("")
+	if err != nil || settings.RequireAuth {
+		t.Errorf("ReadSettings() of no path got = %+v, error = %v", settings, err)
+	}
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// A sharded store is a directory with one document per user:
+//
+//	<root>/index.json           sorted list of usernames
+//	<root>/users/<username>.json the same document format as a single-file store, holding one user
+const (
+	indexFile = "index.json"
+	shardDir  = "users"
+)
+
+// IndexPath returns the path of the username index of the sharded store at root.
+func IndexPath(root string) string {
+	return filepath.Join(root, indexFile)
+}
+
+// ShardPath returns the path of the document holding the data of username.
+func ShardPath(root, username string) string {
+	return filepath.Join(root, shardDir, username+".json")
+}
+
+// ReadIndex is used to read the usernames of the sharded store at root.
+func ReadIndex(root string) (usernames []string, err error) {
+	data, err := os.ReadFile(IndexPath(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	err = json.Unmarshal(data, &usernames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+
+	return usernames, nil
+}
+
+// WriteIndex is used to write the usernames of the sharded store at root.
+func WriteIndex(root string, usernames []string) (err error) {
+	path := IndexPath(root)
+	if err = utils.EnsureDir(path); err != nil {
+		return fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	sorted := slices.Clone(usernames)
+	slices.Sort(sorted)
+
+	data, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	// the index decides which shards are part of the store, so it's replaced at once
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+
+	return nil
+}
+
+// Shard is used to split the single-file store at src into the sharded store at dst.
+// It returns the number of users moved and refuses to overwrite a user already in dst.
+// The shards are written to a temporary directory next to dst first: a new store is renamed into place at once,
+// while the shards joining an existing one are moved in before the index listing them, and removed on failure.
+func Shard(src, dst string) (count int, err error) {
+	users := make(map[string]*model.User)
+	err = ReadFile(src, &users)
+	if err != nil {
+		return 0, err
+	}
+
+	// the usernames name the shards, so they must not escape the store directory
+	for username := range users {
+		err = model.ValidateInput(username)
+		if err != nil {
+			return 0, fmt.Errorf("invalid username %q: %w", username, err)
+		}
+	}
+
+	usernames, err := ReadIndex(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	for username := range users {
+		if slices.Contains(usernames, username) {
+			return 0, fmt.Errorf("the %s has already existed", username)
+		}
+	}
+
+	err = utils.EnsureDir(dst)
+	if err != nil {
+		return 0, fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	added := sortedKeys(users)
+	for _, username := range added {
+		err = WriteFile(ShardPath(tmp, username), map[string]*model.User{username: users[username]})
+		if err != nil {
+			return 0, err
+		}
+	}
+	usernames = append(usernames, added...)
+
+	_, err = os.Stat(dst)
+	if errors.Is(err, os.ErrNotExist) {
+		err = WriteIndex(tmp, usernames)
+		if err != nil {
+			return 0, err
+		}
+
+		err = os.Rename(tmp, dst)
+		if err != nil {
+			return 0, fmt.Errorf("failed to move the shards into place: %w", err)
+		}
+
+		return len(users), nil
+	}
+
+	err = moveShards(tmp, dst, added)
+	if err != nil {
+		return 0, err
+	}
+
+	err = WriteIndex(dst, usernames)
+	if err != nil {
+		for _, username := range added {
+			_ = os.Remove(ShardPath(dst, username))
+		}
+
+		return 0, err
+	}
+
+	return len(users), nil
+}
+
+// moveShards is used to move the shards of usernames from the store at src into the one at dst, removing the
+// ones already moved on failure.
+func moveShards(src, dst string, usernames []string) error {
+	err := utils.EnsureDir(ShardPath(dst, "_"))
+	if err != nil {
+		return fmt.Errorf("failed to ensure directory: %w", err)
+	}
+
+	for n, username := range usernames {
+		err = os.Rename(ShardPath(src, username), ShardPath(dst, username))
+		if err != nil {
+			for _, moved := range usernames[:n] {
+				_ = os.Remove(ShardPath(dst, moved))
+			}
+
+			return fmt.Errorf("failed to move the shard of %s: %w", username, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestWriteIndexAndReadIndex(t *testing.T) {
+	err := WriteIndex("out/vfs.shards", []string{"user2", "user1"})
+	if err != nil {
+		t.Fatalf("WriteIndex() error = %v", err)
+	}
+
+	got, err := ReadIndex("out/vfs.shards")
+	if err != nil {
+		t.Fatalf("ReadIndex() error = %v", err)
+	}
+
+	if want := []string{"user1", "user2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadIndex() got = %v, want %v", got, want)
+	}
+
+	got, err = ReadIndex("out/non-existing.shards")
+	if err != nil || got != nil {
+		t.Errorf("ReadIndex() of missing store got = %v, error = %v", got, err)
+	}
+
+	_ = os.RemoveAll("out")
+}
+
+func TestShard(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     map[string]*model.User
+		mock      func()
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			name: "split every user into its own shard",
+			users: map[string]*model.User{
+				"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+				"user2": {Username: "user2", Folders: map[string]*model.Folder{}},
+			},
+			wantCount: 2,
+			wantErr:   false,
+		},
+		{
+			name: "add the users to an existing store",
+			users: map[string]*model.User{
+				"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+			},
+			mock: func() {
+				_ = WriteFile(ShardPath("out/vfs.shards", "user0"), map[string]*model.User{
+					"user0": {Username: "user0", Folders: map[string]*model.Folder{}},
+				})
+				_ = WriteIndex("out/vfs.shards", []string{"user0"})
+			},
+			wantCount: 1,
+			wantErr:   false,
+		},
+		{
+			name: "refuse to overwrite an existing shard",
+			users: map[string]*model.User{
+				"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+			},
+			mock: func() {
+				_ = WriteIndex("out/vfs.shards", []string{"user1"})
+			},
+			wantCount: 0,
+			wantErr:   true,
+		},
+		{
+			name: "refuse a username escaping the store",
+			users: map[string]*model.User{
+				"user1":     {Username: "user1", Folders: map[string]*model.Folder{}},
+				"../escape": {Username: "../escape", Folders: map[string]*model.Folder{}},
+			},
+			wantCount: 0,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mock != nil {
+				tt.mock()
+			}
+
+			_ = WriteFile("out/vfs.json", tt.users)
+
+			count, err := Shard("out/vfs.json", "out/vfs.shards")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Shard() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if count != tt.wantCount {
+				t.Errorf("Shard() count = %v, want %v", count, tt.wantCount)
+			}
+
+			for username := range tt.users {
+				shard := make(map[string]*model.User)
+				_ = ReadFile(ShardPath("out/vfs.shards", username), &shard)
+				if (shard[username] == nil) != tt.wantErr {
+					t.Errorf("Shard() shard of %s = %v", username, shard)
+				}
+			}
+
+			if leftovers, _ := filepath.Glob("out/.vfs.shards-*"); len(leftovers) != 0 {
+				t.Errorf("Shard() left the temporary directories %v", leftovers)
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
+
+func TestShard_MoveFailure(t *testing.T) {
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	_ = WriteFile(ShardPath("out/vfs.shards", "user0"), map[string]*model.User{
+		"user0": {Username: "user0", Folders: map[string]*model.Folder{}},
+	})
+	_ = WriteIndex("out/vfs.shards", []string{"user0"})
+	_ = WriteFile("out/vfs.json", map[string]*model.User{
+		"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+		"user2": {Username: "user2", Folders: map[string]*model.Folder{}},
+	})
+
+	// the shard of user2 can't be moved in once its path is taken by a directory
+	_ = os.MkdirAll(filepath.Join(ShardPath("out/vfs.shards", "user2"), "taken"), 0700)
+
+	_, err := Shard("out/vfs.json", "out/vfs.shards")
+	if err == nil {
+		t.Fatalf("Shard() error = nil, want error")
+	}
+
+	if _, err = os.Stat(ShardPath("out/vfs.shards", "user1")); !os.IsNotExist(err) {
+		t.Errorf("Shard() left the shard of user1 behind, stat error = %v", err)
+	}
+	if usernames, _ := ReadIndex("out/vfs.shards"); !reflect.DeepEqual(usernames, []string{"user0"}) {
+		t.Errorf("Shard() changed the index to %v", usernames)
+	}
+	if leftovers, _ := filepath.Glob("out/.vfs.shards-*"); len(leftovers) != 0 {
+		t.Errorf("Shard() left the temporary directories %v", leftovers)
+	}
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/utils"
+)
+
+// Load is used to read every user of the store at path, whatever its layout.
+func Load(path string) (users map[string]*model.User, err error) {
+	users = make(map[string]*model.User)
+
+	switch pathType := utils.CheckPathType(path); {
+	case utils.IsDocument(pathType):
+		err = ReadFile(path, &users)
+		if err != nil {
+			return nil, err
+		}
+	case pathType == "sharded":
+		usernames, err := ReadIndex(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, username := range usernames {
+			err = ReadFile(ShardPath(path, username), &users)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported path type: %s", pathType)
+	}
+
+	return users, nil
+}
+
+// Save is used to replace the content of the store at path with users, whatever its layout.
+func Save(path string, users map[string]*model.User) (err error) {
+	switch pathType := utils.CheckPathType(path); {
+	case utils.IsDocument(pathType):
+		return WriteFile(path, users)
+	case pathType == "sharded":
+		previous, err := ReadIndex(path)
+		if err != nil {
+			return err
+		}
+
+		var usernames []string
+		for username, user := range users {
+			err = WriteFile(ShardPath(path, username), map[string]*model.User{username: user})
+			if err != nil {
+				return err
+			}
+			usernames = append(usernames, username)
+		}
+
+		err = WriteIndex(path, usernames)
+		if err != nil {
+			return err
+		}
+
+		for _, username := range previous {
+			if slices.Contains(usernames, username) {
+				continue
+			}
+
+			err = os.Remove(ShardPath(path, username))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove shard: %w", err)
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported path type: %s", pathType)
+	}
+}
+
+// Rekey is used to re-encrypt every document of the store at path, and its groups, with next, nil decrypts them.
+// The documents are read with the current Secret, which is replaced by next afterward.
+func Rekey(path string, next *Secret) (err error) {
+	users, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	groups := &Groups{}
+	err = ReadGroups(GroupsPath(path), groups)
+	if err != nil {
+		return err
+	}
+
+	previous := secret
+	SetSecret(next)
+
+	err = Save(path, users)
+	if err == nil && groups.Groups != nil {
+		err = WriteGroups(GroupsPath(path), groups)
+	}
+	if err != nil {
+		SetSecret(previous)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"os"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{
+			name:    "single-file store",
+			path:    "out/vfs.json",
+			wantErr: false,
+		},
+		{
+			name:    "sharded store",
+			path:    "out/vfs.shards",
+			wantErr: false,
+		},
+		{
+			name:    "unsupported store",
+			path:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Save(tt.path, map[string]*model.User{
+				"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+				"user2": {Username: "user2", Folders: map[string]*model.Folder{}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Save() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			// saving fewer users must drop the missing ones
+			err = Save(tt.path, map[string]*model.User{
+				"user1": {Username: "user1", Folders: map[string]*model.Folder{}},
+			})
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			users, err := Load(tt.path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(users) != 1 || users["user1"] == nil {
+				t.Errorf("Load() users = %v", users)
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
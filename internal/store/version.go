@@ -0,0 +1,65 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// CurrentVersion is the schema version of the documents written by this build.
+const CurrentVersion = 1
+
+// Migration upgrades the data of a document by exactly one schema version.
+type Migration func(data json.RawMessage) (json.RawMessage, error)
+
+// migrations holds the upgrade from each historical version to the next one.
+var migrations = map[int]Migration{
+	// version 0 is the raw `map[string]*model.User` written before the envelope existed;
+	// its data is the same as version 1, only the envelope is new.
+	0: func(data json.RawMessage) (json.RawMessage, error) {
+		return data, nil
+	},
+}
+
+// envelope is the versioned wrapper persisted around the data of a document.
+type envelope struct {
+	Version int `json:"version" yaml:"version" toml:"version"`
+	Data    any `json:"data" yaml:"data" toml:"data"`
+}
+
+// decodeEnvelope is used to split a document into its schema version and data.
+// A document without an envelope is considered as version 0.
+func decodeEnvelope(raw []byte) (version int, data json.RawMessage) {
+	var probe struct {
+		Version *int            `json:"version"`
+		Data    json.RawMessage `json:"data"`
+	}
+
+	if json.Unmarshal(raw, &probe) != nil || probe.Version == nil || probe.Data == nil {
+		return 0, bytes.TrimSpace(raw)
+	}
+
+	return *probe.Version, probe.Data
+}
+
+// upgrade is used to apply the migrations bringing data from version to CurrentVersion.
+func upgrade(version int, data json.RawMessage) (json.RawMessage, error) {
+	if version > CurrentVersion {
+		return nil, fmt.Errorf("unsupported schema version %d, the newest known is %d", version, CurrentVersion)
+	}
+
+	for ; version < CurrentVersion; version++ {
+		migrate, exists := migrations[version]
+		if !exists {
+			return nil, fmt.Errorf("no migration from schema version %d", version)
+		}
+
+		var err error
+		data, err = migrate(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate schema version %d: %w", version, err)
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+func TestReadFile_Golden(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "version 0 without envelope",
+			path: "testdata/v0.json",
+		},
+		{
+			name: "version 1",
+			path: "testdata/v1.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := make(map[string]*model.User)
+			err := ReadFile(tt.path, &users)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+
+			folder := users["alice"].Folders["photos"]
+			if folder == nil || folder.Description != "holiday pictures" {
+				t.Fatalf("ReadFile() folder = %v", folder)
+			}
+
+			want := time.Date(2024, 5, 27, 10, 5, 0, 0, time.UTC)
+			file := folder.Files["beach"]
+			if file == nil || file.Description != "sunset at the beach" || !file.CreatedAt.Equal(want) {
+				t.Errorf("ReadFile() file = %v", file)
+			}
+		})
+	}
+}
+
+func TestReadFile_UnsupportedVersion(t *testing.T) {
+	_ = os.MkdirAll("out", 0755)
+	_ = os.WriteFile("out/vfs.json", []byte(`{"version": 99, "data": {}}`), 0600)
+	defer func() {
+		_ = os.RemoveAll("out")
+	}()
+
+	users := make(map[string]*model.User)
+	err := ReadFile("out/vfs.json", &users)
+	if err == nil {
+		t.Errorf("ReadFile() error = nil, want error")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	legacy, _ := os.ReadFile("testdata/v0.json")
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantVersion int
+	}{
+		{
+			name:        "dry run leaves the document untouched",
+			dryRun:      true,
+			wantVersion: 0,
+		},
+		{
+			name:        "migrate rewrites the document",
+			dryRun:      false,
+			wantVersion: CurrentVersion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.MkdirAll("out", 0755)
+			_ = os.WriteFile("out/vfs.json", legacy, 0600)
+
+			upgrades, err := Migrate("out/vfs.json", tt.dryRun)
+			if err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+			if len(upgrades) != 1 || upgrades[0].From != 0 || upgrades[0].To != CurrentVersion {
+				t.Errorf("Migrate() upgrades = %v", upgrades)
+			}
+
+			raw, _ := os.ReadFile("out/vfs.json")
+			if version, _ := decodeEnvelope(raw); version != tt.wantVersion {
+				t.Errorf("Migrate() version = %v, want %v", version, tt.wantVersion)
+			}
+
+			// Clean up
+			_ = os.RemoveAll("out")
+		})
+	}
+}
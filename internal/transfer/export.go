@@ -0,0 +1,276 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+// ExportReport summarizes an export.
+type ExportReport struct {
+	Folders int
+	Files   int
+}
+
+// sink is where the entries of an export are written, one after the other.
+type sink interface {
+	// Dir is used to write the directory name.
+	Dir(name string, modTime time.Time) error
+
+	// File is used to write the file name with the content of r.
+	File(name string, modTime time.Time, size int64, r io.Reader) error
+
+	// Close is used to flush the export, it's called once whatever happened.
+	Close() error
+}
+
+// Export is used to write the folders of username through fs to the path to, a directory, a .tar,
+// .tar.gz (or .tgz) or .zip archive. Only foldername is exported when it isn't empty.
+// Each folder becomes a directory holding its files, and manifest.json records the descriptions and
+// created times so Import restores them exactly. The virtual file system keeps no file content, so the
+// files are empty. Entries are streamed to the output, only the manifest is held in memory.
+func Export(fs vfs.VirtualFileSystem, username, foldername, to string) (report *ExportReport, err error) {
+	manifest, err := manifestOf(fs, username, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := openSink(to)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		closeErr := out.Close()
+		if err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write %s: %w", to, closeErr)
+		}
+	}()
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the %s: %w", ManifestName, err)
+	}
+
+	err = out.File(ManifestName, manifest.ExportedAt, int64(len(data)), bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", to, err)
+	}
+
+	report = &ExportReport{}
+	for _, folder := range manifest.Folders {
+		err = out.Dir(folder.Name, folder.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", to, err)
+		}
+		report.Folders++
+
+		for _, file := range folder.Files {
+			err = out.File(folder.Name+"/"+file.Name, file.CreatedAt, 0, bytes.NewReader(nil))
+			if err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", to, err)
+			}
+			report.Files++
+		}
+	}
+
+	return report, nil
+}
+
+// manifestOf is used to list the folders and files to export.
+func manifestOf(fs vfs.VirtualFileSystem, username, foldername string) (*Manifest, error) {
+	folders, err := fs.ListFolders(username, "name", orderAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:    ManifestVersion,
+		Username:   username,
+		ExportedAt: time.Now(),
+		Folders:    []ManifestFolder{},
+	}
+	for _, folder := range folders {
+		if foldername != "" && folder.Name != foldername {
+			continue
+		}
+
+		files, err := fs.ListFiles(username, folder.Name, "name", orderAsc)
+		if err != nil {
+			return nil, err
+		}
+
+		exported := ManifestFolder{
+			Name:        folder.Name,
+			Description: folder.Description,
+			CreatedAt:   folder.CreatedAt,
+			Tags:        folder.Tags,
+			Files:       make([]ManifestFile, 0, len(files)),
+		}
+		for _, file := range files {
+			exported.Files = append(exported.Files, ManifestFile{
+				Name:        file.Name,
+				Description: file.Description,
+				CreatedAt:   file.CreatedAt,
+				Tags:        file.Tags,
+			})
+		}
+		manifest.Folders = append(manifest.Folders, exported)
+	}
+
+	if foldername != "" && len(manifest.Folders) == 0 {
+		return nil, fmt.Errorf("the %s doesn't exist", foldername)
+	}
+
+	return manifest, nil
+}
+
+func openSink(to string) (sink, error) {
+	if archiveOf(to) == archiveNone {
+		err := os.MkdirAll(to, 0755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", to, err)
+		}
+
+		return &dirSink{root: to}, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(to), 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", to, err)
+	}
+
+	file, err := os.Create(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", to, err)
+	}
+
+	switch archiveOf(to) {
+	case archiveZip:
+		return &zipSink{file: file, zw: zip.NewWriter(file)}, nil
+	case archiveTarGzip:
+		gz := gzip.NewWriter(file)
+		return &tarSink{file: file, gz: gz, tw: tar.NewWriter(gz)}, nil
+	default:
+		return &tarSink{file: file, tw: tar.NewWriter(file)}, nil
+	}
+}
+
+// dirSink writes the entries under a local directory, refusing to replace existing files.
+type dirSink struct {
+	root string
+}
+
+func (s *dirSink) Dir(name string, modTime time.Time) error {
+	path := filepath.Join(s.root, filepath.FromSlash(name))
+	err := os.Mkdir(path, 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, modTime, modTime)
+}
+
+func (s *dirSink) File(name string, modTime time.Time, _ int64, r io.Reader) error {
+	path := filepath.Join(s.root, filepath.FromSlash(name))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, r)
+	err = errors.Join(err, file.Close())
+	if err != nil {
+		return err
+	}
+
+	return os.Chtimes(path, modTime, modTime)
+}
+
+func (s *dirSink) Close() error {
+	return nil
+}
+
+// tarSink writes the entries to a tar archive, gzipped when gz isn't nil.
+type tarSink struct {
+	file *os.File
+	gz   *gzip.Writer
+	tw   *tar.Writer
+}
+
+func (s *tarSink) Dir(name string, modTime time.Time) error {
+	return s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	})
+}
+
+func (s *tarSink) File(name string, modTime time.Time, size int64, r io.Reader) error {
+	err := s.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(s.tw, r)
+	return err
+}
+
+func (s *tarSink) Close() error {
+	err := s.tw.Close()
+	if s.gz != nil {
+		err = errors.Join(err, s.gz.Close())
+	}
+
+	return errors.Join(err, s.file.Close())
+}
+
+// zipSink writes the entries to a zip archive.
+type zipSink struct {
+	file *os.File
+	zw   *zip.Writer
+}
+
+func (s *zipSink) Dir(name string, modTime time.Time) error {
+	_, err := s.zw.CreateHeader(&zip.FileHeader{
+		Name:     name + "/",
+		Modified: modTime,
+	})
+	return err
+}
+
+func (s *zipSink) File(name string, modTime time.Time, _ int64, r io.Reader) error {
+	w, err := s.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
+
+func (s *zipSink) Close() error {
+	return errors.Join(s.zw.Close(), s.file.Close())
+}
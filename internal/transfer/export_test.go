@@ -0,0 +1,116 @@
+package transfer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExport_RoundTrip(t *testing.T) {
+	for _, name := range []string{"dir", "out.tar", "out.tar.gz", "out.tgz", "out.zip"} {
+		t.Run(name, func(t *testing.T) {
+			fs := newVFS(t)
+			_, _ = fs.CreateFolder("alice", "photos", "holidays")
+			_, _ = fs.CreateFile("alice", "photos", "beach", "the beach")
+			_, _ = fs.CreateFile("alice", "photos", "sunset", "")
+			_, _ = fs.CreateFolder("alice", "empty", "")
+			want, _ := fs.ListFolders("alice", "name", "asc")
+			wantFiles, _ := fs.ListFiles("alice", "photos", "name", "asc")
+
+			to := filepath.Join(t.TempDir(), name)
+			report, err := Export(fs, "alice", "", to)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if report.Folders != 2 || report.Files != 2 {
+				t.Errorf("Export() report = %+v", report)
+			}
+
+			_ = fs.DeleteFolder("alice", "photos")
+			_ = fs.DeleteFolder("alice", "empty")
+
+			imported, err := Import(fs, "alice", to, ImportOptions{})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if imported.Folders != 2 || imported.Files != 2 {
+				t.Errorf("Import() report = %+v", imported)
+			}
+
+			got, _ := fs.ListFolders("alice", "name", "asc")
+			if len(got) != len(want) {
+				t.Fatalf("Import() folders = %v, want %v", got, want)
+			}
+			for n := range want {
+				if got[n].Name != want[n].Name ||
+					got[n].Description != want[n].Description ||
+					!got[n].CreatedAt.Equal(want[n].CreatedAt) {
+					t.Errorf("Import() folder = %+v, want %+v", got[n], want[n])
+				}
+			}
+
+			gotFiles, _ := fs.ListFiles("alice", "photos", "name", "asc")
+			if len(gotFiles) != len(wantFiles) {
+				t.Fatalf("Import() files = %v, want %v", gotFiles, wantFiles)
+			}
+			for n := range wantFiles {
+				if gotFiles[n].Name != wantFiles[n].Name ||
+					gotFiles[n].Description != wantFiles[n].Description ||
+					!gotFiles[n].CreatedAt.Equal(wantFiles[n].CreatedAt) {
+					t.Errorf("Import() file = %+v, want %+v", gotFiles[n], wantFiles[n])
+				}
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	fs := newVFS(t)
+	_, _ = fs.CreateFolder("alice", "photos", "")
+	_, _ = fs.CreateFile("alice", "photos", "beach", "")
+	_, _ = fs.CreateFolder("alice", "docs", "")
+
+	dir := filepath.Join(t.TempDir(), "export")
+	report, err := Export(fs, "alice", "photos", dir)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if report.Folders != 1 || report.Files != 1 {
+		t.Errorf("Export() report = %+v", report)
+	}
+	for _, path := range []string{ManifestName, "photos/beach"} {
+		if _, err = os.Stat(filepath.Join(dir, path)); err != nil {
+			t.Errorf("Export() didn't write %s: %v", path, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "docs")); err == nil {
+		t.Errorf("Export() wrote the folder docs")
+	}
+
+	_, err = Export(fs, "alice", "photos", dir)
+	if err == nil {
+		t.Errorf("Export() over an export error = nil")
+	}
+
+	_, err = Export(fs, "alice", "missing", filepath.Join(t.TempDir(), "out.zip"))
+	if err == nil {
+		t.Errorf("Export() of a missing folder error = nil")
+	}
+
+	// the exported files join the existing folder under the conflict policy
+	_, _ = fs.CreateFile("alice", "photos", "sunset", "")
+	_ = fs.DeleteFile("alice", "photos", "beach")
+	_, _ = fs.CreateFile("alice", "photos", "beach", "kept")
+	imported, err := Import(fs, "alice", dir, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if imported.Skipped != 1 || imported.Folders != 0 {
+		t.Errorf("Import() report = %+v", imported)
+	}
+
+	_, err = Import(fs, "alice", filepath.Join(t.TempDir(), "out.zip"), ImportOptions{})
+	if err == nil {
+		t.Errorf("Import() of a missing archive error = nil")
+	}
+}
@@ -0,0 +1,381 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+// Conflict policies of Import, for the folders and files that already exist.
+const (
+	// PolicySkip keeps the existing files, and imports into the existing folders.
+	PolicySkip = "skip"
+
+	// PolicyOverwrite replaces the existing files, and imports into the existing folders.
+	PolicyOverwrite = "overwrite"
+
+	// PolicyRename imports into new folders and files named with a numeric suffix.
+	PolicyRename = "rename"
+)
+
+const orderAsc = "asc"
+
+// Operations of the actions of an import.
+const (
+	OpCreateFolder  = "create-folder"
+	OpRestoreFolder = "restore-folder"
+	OpCreateFile    = "create-file"
+	OpOverwriteFile = "overwrite-file"
+	OpSkipFile      = "skip-file"
+)
+
+// Action describes one change of an import.
+type Action struct {
+	Op          string
+	Folder      string
+	File        string
+	Source      string
+	Description string
+
+	// restored is the exported folder with its files, restored by OpRestoreFolder.
+	restored *model.Folder
+}
+
+func (a Action) String() string {
+	path := a.Folder
+	if a.File != "" {
+		path += "/" + a.File
+	}
+
+	return fmt.Sprintf("%s %s (from %s)", a.Op, path, a.Source)
+}
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// Policy is the conflict policy, PolicySkip by default.
+	Policy string
+
+	// Mapping is used to sanitize the invalid names, DefaultMapping by default.
+	Mapping map[string]string
+
+	// DryRun only plans the actions without applying them.
+	DryRun bool
+}
+
+// Report summarizes an import.
+type Report struct {
+	Actions     []Action
+	Folders     int
+	Files       int
+	Overwritten int
+	Skipped     int
+	Renamed     int
+	Sanitized   int
+}
+
+// group is a local directory imported as one folder.
+type group struct {
+	source  string
+	files   []string
+	subdirs bool
+
+	// exported is the folder of a manifest, restored as it was when it's new.
+	exported *model.Folder
+}
+
+// Import is used to recreate the local directory dir as folders and files of username through fs.
+// Every directory holding files, or empty, becomes a folder named after its path relative to dir,
+// the files directly in dir go to a folder named after dir, and the descriptions record the local paths.
+// An export, a directory holding manifest.json or an archive written by Export, is imported from its
+// manifest instead: the new folders are restored with their files, descriptions and created times.
+func Import(fs vfs.VirtualFileSystem, username, dir string, opts ImportOptions) (report *Report, err error) {
+	if opts.Policy == "" {
+		opts.Policy = PolicySkip
+	}
+	if opts.Policy != PolicySkip && opts.Policy != PolicyOverwrite && opts.Policy != PolicyRename {
+		return nil, fmt.Errorf("unsupported conflict policy: %s, use skip, overwrite or rename", opts.Policy)
+	}
+
+	if opts.Mapping == nil {
+		opts.Mapping = DefaultMapping
+	}
+	sanitizer, err := NewSanitizer(opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*group
+	manifest, err := readManifest(dir)
+	switch {
+	case err == nil:
+		groups = groupsOf(manifest)
+	case errors.Is(err, errNoManifest) && archiveOf(dir) == archiveNone:
+		groups, err = walk(dir)
+		if err != nil {
+			return nil, err
+		}
+	case errors.Is(err, errNoManifest):
+		return nil, fmt.Errorf("the %s has no %s", dir, ManifestName)
+	default:
+		return nil, err
+	}
+
+	report, err = plan(fs, username, groups, sanitizer, opts.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for _, action := range report.Actions {
+		err = apply(fs, username, action)
+		if err != nil {
+			return report, fmt.Errorf("failed to %s: %w", action, err)
+		}
+	}
+
+	return report, nil
+}
+
+// walk is used to list the directories of dir to import with their regular files, in lexical order.
+func walk(dir string) (groups []*group, err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("the %s isn't a directory", dir)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	byDir := make(map[string]*group)
+	err = filepath.WalkDir(abs, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(abs, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case entry.IsDir() && rel != ".":
+			byDir[rel] = &group{source: rel}
+			groups = append(groups, byDir[rel])
+			if parent := byDir[filepath.ToSlash(filepath.Dir(rel))]; parent != nil {
+				parent.subdirs = true
+			}
+		case entry.Type().IsRegular():
+			parent := filepath.ToSlash(filepath.Dir(rel))
+			if byDir[parent] == nil {
+				// the files directly in dir
+				byDir[parent] = &group{source: filepath.Base(abs)}
+				groups = append([]*group{byDir[parent]}, groups...)
+			}
+			byDir[parent].files = append(byDir[parent].files, rel)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+	}
+
+	// directories holding only directories have nothing to import
+	kept := groups[:0]
+	for _, g := range groups {
+		if len(g.files) > 0 || !g.subdirs {
+			kept = append(kept, g)
+		}
+	}
+
+	return kept, nil
+}
+
+// groupsOf is used to list the folders of manifest to import with their files.
+func groupsOf(manifest *Manifest) []*group {
+	groups := make([]*group, 0, len(manifest.Folders))
+	for _, folder := range manifest.Folders {
+		g := &group{source: folder.Name, exported: folder.Folder()}
+		for _, file := range folder.Files {
+			g.files = append(g.files, file.Name)
+		}
+		groups = append(groups, g)
+	}
+
+	return groups
+}
+
+// plan is used to decide the actions importing groups, against the folders and files which already exist.
+func plan(
+	fs vfs.VirtualFileSystem,
+	username string,
+	groups []*group,
+	sanitizer *Sanitizer,
+	policy string,
+) (*Report, error) {
+	folders, err := fs.ListFolders(username, "name", orderAsc)
+	if err != nil {
+		return nil, err
+	}
+
+	// the files of each folder, existing or planned, loaded on first use
+	taken := make(map[string]map[string]bool)
+	for _, folder := range folders {
+		taken[folder.Name] = nil
+	}
+	filesOf := func(foldername string) (map[string]bool, error) {
+		if taken[foldername] != nil {
+			return taken[foldername], nil
+		}
+
+		files, err := fs.ListFiles(username, foldername, "name", orderAsc)
+		if err != nil {
+			return nil, err
+		}
+
+		taken[foldername] = make(map[string]bool, len(files))
+		for _, file := range files {
+			taken[foldername][file.Name] = true
+		}
+
+		return taken[foldername], nil
+	}
+
+	report := &Report{}
+	name := func(source string) string {
+		sanitized, changed := sanitizer.Name(source)
+		if changed {
+			report.Sanitized++
+		}
+		return sanitized
+	}
+
+	for _, g := range groups {
+		foldername := name(g.source)
+		_, exists := taken[foldername]
+		if exists && policy == PolicyRename {
+			foldername = free(foldername, func(candidate string) bool {
+				_, exists := taken[candidate]
+				return exists
+			})
+			exists = false
+			report.Renamed++
+		}
+
+		if !exists && g.exported != nil {
+			restored := *g.exported
+			restored.Name = foldername
+			taken[foldername] = make(map[string]bool, len(g.files))
+			for _, filename := range g.files {
+				taken[foldername][filename] = true
+			}
+			report.Actions = append(report.Actions, Action{
+				Op:       OpRestoreFolder,
+				Folder:   foldername,
+				Source:   g.source,
+				restored: &restored,
+			})
+			report.Folders++
+			report.Files += len(g.files)
+			continue
+		}
+
+		if !exists {
+			taken[foldername] = make(map[string]bool)
+			report.Actions = append(report.Actions, Action{
+				Op:          OpCreateFolder,
+				Folder:      foldername,
+				Source:      g.source,
+				Description: g.source,
+			})
+			report.Folders++
+		}
+
+		files, err := filesOf(foldername)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, source := range g.files {
+			filename := name(filepath.Base(source))
+			action := Action{Op: OpCreateFile, Folder: foldername, File: filename, Source: source, Description: source}
+			if g.exported != nil {
+				action.Source = g.source + "/" + source
+				action.Description = g.exported.Files[source].Description
+			}
+			if files[filename] {
+				switch policy {
+				case PolicySkip:
+					action.Op = OpSkipFile
+					report.Skipped++
+				case PolicyOverwrite:
+					action.Op = OpOverwriteFile
+					report.Overwritten++
+				case PolicyRename:
+					action.File = free(filename, func(candidate string) bool {
+						return files[candidate]
+					})
+					report.Renamed++
+				}
+			}
+
+			if action.Op == OpCreateFile {
+				report.Files++
+			}
+			files[action.File] = true
+			report.Actions = append(report.Actions, action)
+		}
+	}
+
+	return report, nil
+}
+
+func apply(fs vfs.VirtualFileSystem, username string, action Action) (err error) {
+	switch action.Op {
+	case OpCreateFolder:
+		_, err = fs.CreateFolder(username, action.Folder, action.Description)
+	case OpRestoreFolder:
+		_, err = fs.RestoreFolder(username, action.restored)
+	case OpCreateFile:
+		_, err = fs.CreateFile(username, action.Folder, action.File, action.Description)
+	case OpOverwriteFile:
+		err = fs.DeleteFile(username, action.Folder, action.File)
+		if err != nil {
+			return err
+		}
+
+		_, err = fs.CreateFile(username, action.Folder, action.File, action.Description)
+	}
+
+	return err
+}
+
+// free returns the first name-N which isn't taken.
+func free(name string, taken func(string) bool) string {
+	for n := 1; ; n++ {
+		suffix := fmt.Sprintf("-%d", n)
+		candidate := name
+		if len(candidate)+len(suffix) > model.MaxInputLength {
+			candidate = candidate[:model.MaxInputLength-len(suffix)]
+		}
+		candidate += suffix
+
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
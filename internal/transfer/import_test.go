@@ -0,0 +1,178 @@
+package transfer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+	groupR "github.com/blackhorseya/iscool-assessment/internal/repo/group"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/user"
+	vfsI "github.com/blackhorseya/iscool-assessment/internal/vfs"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+const testPath = "out/vfs.json"
+
+func newVFS(t *testing.T) vfs.VirtualFileSystem {
+	t.Helper()
+	t.Cleanup(func() {
+		_ = os.RemoveAll("out")
+	})
+
+	users, err := user.NewJSONFile(testPath)
+	if err != nil {
+		t.Fatalf("user.NewJSONFile() error = %v", err)
+	}
+
+	_, err = users.Register(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	// the folders are loaded after the registration, like in a later command
+	folders, err := folder.NewJSONFile(testPath)
+	if err != nil {
+		t.Fatalf("folder.NewJSONFile() error = %v", err)
+	}
+
+	groups, err := groupR.NewMemory()
+	if err != nil {
+		t.Fatalf("group.NewMemory() error = %v", err)
+	}
+
+	return vfsI.New(users, folders, groups)
+}
+
+// tree is used to create the files at paths under a new directory named root.
+func tree(t *testing.T, root string, paths ...string) string {
+	t.Helper()
+
+	dir := filepath.Join(t.TempDir(), root)
+	for _, path := range paths {
+		path = filepath.Join(dir, path)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte("content"), 0644)
+	}
+	_ = os.MkdirAll(dir, 0755)
+
+	return dir
+}
+
+func TestImport(t *testing.T) {
+	fs := newVFS(t)
+	dir := tree(t, "team", "readme.md", "Photos 2024/beach.jpg", "Photos 2024/sunset.jpg", "docs/specs/api.md")
+
+	report, err := Import(fs, "alice", dir, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Import() dry run error = %v", err)
+	}
+	if report.Folders != 3 || report.Files != 4 || report.Sanitized != 6 {
+		t.Errorf("Import() dry run report = %+v", report)
+	}
+	if folders, _ := fs.ListFolders("alice", "name", "asc"); len(folders) != 0 {
+		t.Errorf("Import() dry run created %v", folders)
+	}
+
+	_, err = Import(fs, "alice", dir, ImportOptions{})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	folders, _ := fs.ListFolders("alice", "name", "asc")
+	var names []string
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+	}
+	if len(names) != 3 || names[0] != "Photos_2024" || names[1] != "docs-specs" || names[2] != "team" {
+		t.Errorf("Import() folders = %v", names)
+	}
+
+	files, _ := fs.ListFiles("alice", "Photos_2024", "name", "asc")
+	if len(files) != 2 || files[0].Name != "beach_jpg" || files[0].Description != "Photos 2024/beach.jpg" {
+		t.Errorf("Import() files = %v", files)
+	}
+
+	_, err = Import(fs, "bob", dir, ImportOptions{})
+	if err == nil {
+		t.Errorf("Import() for a missing user error = nil")
+	}
+
+	_, err = Import(fs, "alice", filepath.Join(dir, "readme.md"), ImportOptions{})
+	if err == nil {
+		t.Errorf("Import() of a file error = nil")
+	}
+}
+
+func TestImport_Policies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   func(t *testing.T, fs vfs.VirtualFileSystem, report *Report)
+	}{
+		{
+			name:   "skip",
+			policy: PolicySkip,
+			want: func(t *testing.T, fs vfs.VirtualFileSystem, report *Report) {
+				if report.Skipped != 1 || report.Files != 1 {
+					t.Errorf("Import() report = %+v", report)
+				}
+
+				files, _ := fs.ListFiles("alice", "photos", "name", "asc")
+				if len(files) != 2 || files[0].Description != "kept" {
+					t.Errorf("Import() files = %v", files)
+				}
+			},
+		},
+		{
+			name:   "overwrite",
+			policy: PolicyOverwrite,
+			want: func(t *testing.T, fs vfs.VirtualFileSystem, report *Report) {
+				if report.Overwritten != 1 || report.Files != 1 {
+					t.Errorf("Import() report = %+v", report)
+				}
+
+				files, _ := fs.ListFiles("alice", "photos", "name", "asc")
+				if len(files) != 2 || files[0].Description != "photos/beach" {
+					t.Errorf("Import() files = %v", files)
+				}
+			},
+		},
+		{
+			name:   "rename",
+			policy: PolicyRename,
+			want: func(t *testing.T, fs vfs.VirtualFileSystem, report *Report) {
+				if report.Renamed != 1 || report.Folders != 1 || report.Files != 2 {
+					t.Errorf("Import() report = %+v", report)
+				}
+
+				files, _ := fs.ListFiles("alice", "photos-1", "name", "asc")
+				if len(files) != 2 {
+					t.Errorf("Import() files = %v", files)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newVFS(t)
+			_, _ = fs.CreateFolder("alice", "photos", "")
+			_, _ = fs.CreateFile("alice", "photos", "beach", "kept")
+
+			report, err := Import(fs, "alice", tree(t, "root", "photos/beach", "photos/sunset"), ImportOptions{
+				Policy: tt.policy,
+			})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+
+			tt.want(t, fs, report)
+		})
+	}
+
+	_, err := Import(newVFS(t), "alice", t.TempDir(), ImportOptions{Policy: "merge"})
+	if err == nil {
+		t.Errorf("Import() with an unsupported policy error = nil")
+	}
+}
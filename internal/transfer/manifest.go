@@ -0,0 +1,180 @@
+package transfer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// ManifestName is the name of the manifest at the root of an export.
+const ManifestName = "manifest.json"
+
+// ManifestVersion is the version of the manifests written by Export.
+const ManifestVersion = 1
+
+// Manifest records the folders and files of an export, so a later import restores them exactly.
+type Manifest struct {
+	Version    int              `json:"version"`
+	Username   string           `json:"username"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Folders    []ManifestFolder `json:"folders"`
+}
+
+// ManifestFolder is an exported folder.
+type ManifestFolder struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Tags        []string       `json:"tags,omitempty"`
+	Files       []ManifestFile `json:"files"`
+}
+
+// ManifestFile is an exported file.
+type ManifestFile struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	Tags        []string  `json:"tags,omitempty"`
+}
+
+// Folder is used to convert f into a folder with its files, without owner.
+func (f ManifestFolder) Folder() *model.Folder {
+	folder := &model.Folder{
+		Name:        f.Name,
+		Description: f.Description,
+		CreatedAt:   f.CreatedAt,
+		Files:       make(map[string]*model.File, len(f.Files)),
+		Folders:     make(map[string]*model.Folder),
+		Tags:        f.Tags,
+	}
+	for _, file := range f.Files {
+		folder.Files[file.Name] = &model.File{
+			Name:        file.Name,
+			Description: file.Description,
+			CreatedAt:   file.CreatedAt,
+			Folder:      folder,
+			Tags:        file.Tags,
+		}
+	}
+
+	return folder
+}
+
+// errNoManifest is returned when a directory has no manifest, so it's imported as a plain directory tree.
+var errNoManifest = errors.New("no manifest")
+
+// readManifest is used to read the manifest of the export at path, a directory, a tar archive
+// optionally gzipped, or a zip archive. The manifest is the first entry of the archives written by
+// Export, so a tar archive is only read up to it.
+func readManifest(path string) (*Manifest, error) {
+	switch archiveOf(path) {
+	case archiveTar, archiveTarGzip:
+		return readTarManifest(path)
+	case archiveZip:
+		return readZipManifest(path)
+	default:
+		file, err := os.Open(filepath.Join(path, ManifestName))
+		if err != nil {
+			return nil, errNoManifest
+		}
+		defer file.Close()
+
+		return decodeManifest(file, path)
+	}
+}
+
+func readTarManifest(path string) (*Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if archiveOf(path) == archiveTarGzip {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errNoManifest
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if header.Name == ManifestName {
+			return decodeManifest(tr, path)
+		}
+	}
+}
+
+func readZipManifest(path string) (*Manifest, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	file, err := zr.Open(ManifestName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errNoManifest
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return decodeManifest(file, path)
+}
+
+func decodeManifest(r io.Reader, path string) (*Manifest, error) {
+	var manifest Manifest
+	err := json.NewDecoder(r).Decode(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the %s of %s: %w", ManifestName, path, err)
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version: %d", manifest.Version)
+	}
+
+	return &manifest, nil
+}
+
+// Archive formats of an export, chosen by the extension of its path.
+const (
+	archiveNone    = ""
+	archiveTar     = "tar"
+	archiveTarGzip = "tar.gz"
+	archiveZip     = "zip"
+)
+
+func archiveOf(path string) string {
+	switch lower := strings.ToLower(path); {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGzip
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	default:
+		return archiveNone
+	}
+}
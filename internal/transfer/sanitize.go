@@ -0,0 +1,74 @@
+package transfer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+)
+
+// DefaultMapping replaces the characters most often found in real names.
+var DefaultMapping = map[string]string{
+	" ": "_",
+	".": "_",
+	"/": "-",
+}
+
+var invalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Sanitizer is used to turn real names into names accepted by model.ValidateInput.
+type Sanitizer struct {
+	replacer *strings.Replacer
+}
+
+// NewSanitizer is used to create a new Sanitizer replacing the keys of mapping by their values.
+// Characters still invalid afterwards are replaced by an underscore.
+func NewSanitizer(mapping map[string]string) (*Sanitizer, error) {
+	olds := make([]string, 0, len(mapping))
+	for old, replacement := range mapping {
+		if old == "" {
+			return nil, fmt.Errorf("invalid mapping: empty text for %q", replacement)
+		}
+		if replacement != "" && model.ValidateInput(replacement) != nil {
+			return nil, fmt.Errorf("invalid mapping: %q contains invalid characters", replacement)
+		}
+
+		olds = append(olds, old)
+	}
+
+	// longer texts first, so they win over the texts they contain
+	sort.Slice(olds, func(i, j int) bool {
+		if len(olds[i]) != len(olds[j]) {
+			return len(olds[i]) > len(olds[j])
+		}
+		return olds[i] < olds[j]
+	})
+
+	pairs := make([]string, 0, 2*len(olds))
+	for _, old := range olds {
+		pairs = append(pairs, old, mapping[old])
+	}
+
+	return &Sanitizer{
+		replacer: strings.NewReplacer(pairs...),
+	}, nil
+}
+
+// Name returns name if it is valid, otherwise its sanitized version and true.
+func (s *Sanitizer) Name(name string) (string, bool) {
+	if model.ValidateInput(name) == nil {
+		return name, false
+	}
+
+	sanitized := invalidChars.ReplaceAllString(s.replacer.Replace(name), "_")
+	if len(sanitized) > model.MaxInputLength {
+		sanitized = sanitized[:model.MaxInputLength]
+	}
+	if sanitized == "" {
+		sanitized = "_"
+	}
+
+	return sanitized, true
+}
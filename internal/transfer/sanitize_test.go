@@ -0,0 +1,42 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizer_Name(t *testing.T) {
+	tests := []struct {
+		name        string
+		mapping     map[string]string
+		input       string
+		want        string
+		wantChanged bool
+	}{
+		{name: "valid name", mapping: DefaultMapping, input: "photos", want: "photos"},
+		{name: "spaces and dots", mapping: DefaultMapping, input: "Photos 2024.jpg", want: "Photos_2024_jpg", wantChanged: true},
+		{name: "nested path", mapping: DefaultMapping, input: "docs/specs", want: "docs-specs", wantChanged: true},
+		{name: "unmapped characters", mapping: DefaultMapping, input: "café (1)", want: "caf___1_", wantChanged: true},
+		{name: "custom mapping", mapping: map[string]string{" ": "-", "&": "and"}, input: "R & D", want: "R-and-D", wantChanged: true},
+		{name: "longer text first", mapping: map[string]string{".": "_", ".tar.gz": "-tgz"}, input: "a.tar.gz", want: "a-tgz", wantChanged: true},
+		{name: "too long", mapping: DefaultMapping, input: strings.Repeat("a", 300), want: strings.Repeat("a", 255), wantChanged: true},
+		{name: "emptied", mapping: map[string]string{"!": ""}, input: "!!", want: "_", wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSanitizer(tt.mapping)
+			if err != nil {
+				t.Fatalf("NewSanitizer() error = %v", err)
+			}
+
+			got, changed := s.Name(tt.input)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf("Name() = %v, %v, want %v, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+
+	if _, err := NewSanitizer(map[string]string{" ": "?"}); err == nil {
+		t.Errorf("NewSanitizer() with an invalid replacement error = nil")
+	}
+}
@@ -0,0 +1,400 @@
+package vfs
+
+import (
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+// Check is used to allow a call to the file system by the name of its command, like create-folder, and
+// whether it changes the store, returning why it's refused otherwise.
+type Check func(command string, write bool) error
+
+type guarded struct {
+	guardedTx
+
+	next vfs.VirtualFileSystem
+}
+
+// NewGuarded is used to wrap next so that every call is allowed by check first. The sessions and the tokens are
+// how a check is passed, so Login, Logout, Authenticate and AuthenticateToken are never checked.
+func NewGuarded(next vfs.VirtualFileSystem, check Check) vfs.VirtualFileSystem {
+	return &guarded{
+		guardedTx: guardedTx{tx: next, check: check},
+		next:      next,
+	}
+}
+
+func (g *guarded) RegisterUser(username string) (item *model.User, err error) {
+	if err = g.check("register", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.RegisterUser(username)
+}
+
+func (g *guarded) DeleteUser(username string, cascade bool) (err error) {
+	if err = g.check("delete-user", true); err != nil {
+		return err
+	}
+
+	return g.next.DeleteUser(username, cascade)
+}
+
+func (g *guarded) RenameUser(username, newUsername string) (item *model.User, err error) {
+	if err = g.check("rename-user", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.RenameUser(username, newUsername)
+}
+
+func (g *guarded) ListUsers(order string, offset, limit int) (items []*model.User, err error) {
+	if err = g.check("list-users", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListUsers(order, offset, limit)
+}
+
+func (g *guarded) As(actor string) vfs.VirtualFileSystem {
+	return NewGuarded(g.next.As(actor), g.check)
+}
+
+func (g *guarded) GrantRole(username string, role model.Role) (item *model.User, err error) {
+	if err = g.check("grant-role", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.GrantRole(username, role)
+}
+
+func (g *guarded) RevokeRole(username string, role model.Role) (item *model.User, err error) {
+	if err = g.check("revoke-role", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.RevokeRole(username, role)
+}
+
+func (g *guarded) SetPassword(username, password string) (item *model.User, err error) {
+	if err = g.check("set-password", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.SetPassword(username, password)
+}
+
+func (g *guarded) Login(username, password string, ttl time.Duration) (token string, err error) {
+	return g.next.Login(username, password, ttl)
+}
+
+func (g *guarded) Logout(username, token string) (err error) {
+	return g.next.Logout(username, token)
+}
+
+func (g *guarded) Authenticate(username, token string) (item *model.User, err error) {
+	return g.next.Authenticate(username, token)
+}
+
+func (g *guarded) CreateToken(
+	username string,
+	scopes []model.Scope,
+	ttl time.Duration,
+) (token string, item *model.Token, err error) {
+	if err = g.check("token create", true); err != nil {
+		return "", nil, err
+	}
+
+	return g.next.CreateToken(username, scopes, ttl)
+}
+
+func (g *guarded) ListTokens(username string) (items []*model.Token, err error) {
+	if err = g.check("token list", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListTokens(username)
+}
+
+func (g *guarded) RevokeToken(username, id string) (err error) {
+	if err = g.check("token revoke", true); err != nil {
+		return err
+	}
+
+	return g.next.RevokeToken(username, id)
+}
+
+func (g *guarded) AuthenticateToken(token string) (user *model.User, item *model.Token, err error) {
+	return g.next.AuthenticateToken(token)
+}
+
+func (g *guarded) SetQuota(username string, quota *model.Quota) (item *model.User, err error) {
+	if err = g.check("set-quota", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.SetQuota(username, quota)
+}
+
+func (g *guarded) Usage(username string) (item *model.Usage, err error) {
+	if err = g.check("usage", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.Usage(username)
+}
+
+func (g *guarded) ShareFolder(username, foldername, grantee string, access model.Access) (err error) {
+	if err = g.check("share-folder", true); err != nil {
+		return err
+	}
+
+	return g.next.ShareFolder(username, foldername, grantee, access)
+}
+
+func (g *guarded) UnshareFolder(username, foldername, grantee string) (err error) {
+	if err = g.check("unshare-folder", true); err != nil {
+		return err
+	}
+
+	return g.next.UnshareFolder(username, foldername, grantee)
+}
+
+func (g *guarded) ListShares(username string) (items []*model.Share, err error) {
+	if err = g.check("list-shares", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListShares(username)
+}
+
+func (g *guarded) TagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	if err = g.check("tag-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.TagFolder(username, foldername, tags)
+}
+
+func (g *guarded) UntagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	if err = g.check("untag-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.UntagFolder(username, foldername, tags)
+}
+
+func (g *guarded) ListTaggedFolders(
+	username, tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	if err = g.check("list-folders", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListTaggedFolders(username, tag, sortBy, order)
+}
+
+func (g *guarded) Tags(username string) (items []*model.TagCount, err error) {
+	if err = g.check("tags", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.Tags(username)
+}
+
+func (g *guarded) TagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	if err = g.check("tag-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.TagFile(username, foldername, filename, tags)
+}
+
+func (g *guarded) UntagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	if err = g.check("untag-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.UntagFile(username, foldername, filename, tags)
+}
+
+func (g *guarded) ListTaggedFiles(
+	username, foldername, tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	if err = g.check("list-files", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListTaggedFiles(username, foldername, tag, sortBy, order)
+}
+
+func (g *guarded) CreateGroup(username, name string) (item *model.Group, err error) {
+	if err = g.check("create-group", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.CreateGroup(username, name)
+}
+
+func (g *guarded) AddMember(username, name, member string) (item *model.Group, err error) {
+	if err = g.check("add-member", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.AddMember(username, name, member)
+}
+
+func (g *guarded) RemoveMember(username, name, member string) (item *model.Group, err error) {
+	if err = g.check("remove-member", true); err != nil {
+		return nil, err
+	}
+
+	return g.next.RemoveMember(username, name, member)
+}
+
+func (g *guarded) ListGroups(username string) (items []*model.Group, err error) {
+	if err = g.check("list-groups", false); err != nil {
+		return nil, err
+	}
+
+	return g.next.ListGroups(username)
+}
+
+func (g *guarded) Tx(fn func(tx vfs.Tx) error) (err error) {
+	return g.next.Tx(func(tx vfs.Tx) error {
+		return fn(&guardedTx{tx: tx, check: g.check})
+	})
+}
+
+// guardedTx checks the calls to the folders and files of tx, within a transaction or not.
+type guardedTx struct {
+	tx    vfs.Tx
+	check Check
+}
+
+func (g *guardedTx) CreateFolder(username, foldername, description string) (item *model.Folder, err error) {
+	if err = g.check("create-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.CreateFolder(username, foldername, description)
+}
+
+func (g *guardedTx) DeleteFolder(username, foldername string) (err error) {
+	if err = g.check("delete-folder", true); err != nil {
+		return err
+	}
+
+	return g.tx.DeleteFolder(username, foldername)
+}
+
+func (g *guardedTx) ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error) {
+	if err = g.check("list-folders", false); err != nil {
+		return nil, err
+	}
+
+	return g.tx.ListFolders(username, sortBy, order)
+}
+
+func (g *guardedTx) RenameFolder(username, foldername, newFoldername string) (item *model.Folder, err error) {
+	if err = g.check("rename-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.RenameFolder(username, foldername, newFoldername)
+}
+
+func (g *guardedTx) RestoreFolder(username string, folder *model.Folder) (item *model.Folder, err error) {
+	if err = g.check("restore-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.RestoreFolder(username, folder)
+}
+
+func (g *guardedTx) CopyFolder(
+	username, foldername, newUsername, newFoldername string,
+) (item *model.Folder, err error) {
+	if err = g.check("copy-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.CopyFolder(username, foldername, newUsername, newFoldername)
+}
+
+func (g *guardedTx) MoveFolder(
+	username, foldername, newUsername, newFoldername string,
+) (item *model.Folder, err error) {
+	if err = g.check("move-folder", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.MoveFolder(username, foldername, newUsername, newFoldername)
+}
+
+func (g *guardedTx) CreateFile(username, foldername, filename, description string) (item *model.File, err error) {
+	if err = g.check("create-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.CreateFile(username, foldername, filename, description)
+}
+
+func (g *guardedTx) DeleteFile(username, foldername, filename string) (err error) {
+	if err = g.check("delete-file", true); err != nil {
+		return err
+	}
+
+	return g.tx.DeleteFile(username, foldername, filename)
+}
+
+func (g *guardedTx) ListFiles(
+	username, foldername string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	if err = g.check("list-files", false); err != nil {
+		return nil, err
+	}
+
+	return g.tx.ListFiles(username, foldername, sortBy, order)
+}
+
+func (g *guardedTx) RenameFile(
+	username, foldername, filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	if err = g.check("rename-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.RenameFile(username, foldername, filename, newFilename, conflict)
+}
+
+func (g *guardedTx) MoveFile(
+	username, foldername, filename, newFoldername string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	if err = g.check("move-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.MoveFile(username, foldername, filename, newFoldername, conflict)
+}
+
+func (g *guardedTx) CopyFile(
+	username, foldername, filename, newFoldername, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	if err = g.check("copy-file", true); err != nil {
+		return nil, err
+	}
+
+	return g.tx.CopyFile(username, foldername, filename, newFoldername, newFilename, conflict)
+}
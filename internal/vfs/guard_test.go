@@ -0,0 +1,72 @@
+package vfs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewGuarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := vfs.NewMockVirtualFileSystem(ctrl)
+	tx := vfs.NewMockTx(ctrl)
+
+	refused := errors.New("refused")
+	var checked []string
+	g := NewGuarded(next, func(command string, write bool) error {
+		checked = append(checked, command)
+		if write {
+			return refused
+		}
+
+		return nil
+	})
+
+	next.EXPECT().ListFolders("alice", "name", "asc").Return(nil, nil)
+	next.EXPECT().Login("alice", "secret", gomock.Any()).Return("token", nil)
+	next.EXPECT().As("bob").Return(next)
+	next.EXPECT().Tx(gomock.Any()).DoAndReturn(func(fn func(tx vfs.Tx) error) error {
+		return fn(tx)
+	})
+	tx.EXPECT().ListFiles("alice", "photos", "name", "asc").Return(nil, nil)
+
+	_, err := g.ListFolders("alice", "name", "asc")
+	if err != nil {
+		t.Errorf("ListFolders() error = %v, want nil", err)
+	}
+	_, err = g.CreateFolder("alice", "photos", "")
+	if !errors.Is(err, refused) {
+		t.Errorf("CreateFolder() error = %v, want %v", err, refused)
+	}
+	_, err = g.As("bob").GrantRole("alice", model.RoleAdmin)
+	if !errors.Is(err, refused) {
+		t.Errorf("As().GrantRole() error = %v, want %v", err, refused)
+	}
+
+	// the sessions aren't checked
+	_, err = g.Login("alice", "secret", 0)
+	if err != nil {
+		t.Errorf("Login() error = %v, want nil", err)
+	}
+
+	err = g.Tx(func(tx vfs.Tx) error {
+		_, err := tx.ListFiles("alice", "photos", "name", "asc")
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteFile("alice", "photos", "file1")
+	})
+	if !errors.Is(err, refused) {
+		t.Errorf("Tx() error = %v, want %v", err, refused)
+	}
+
+	want := []string{"list-folders", "create-folder", "grant-role", "list-files", "delete-file"}
+	if !reflect.DeepEqual(checked, want) {
+		t.Errorf("checked = %v, want %v", checked, want)
+	}
+}
@@ -0,0 +1,447 @@
+package vfs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/internal/history"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+type withHistory struct {
+	recorder
+
+	next       vfs.VirtualFileSystem
+	repository *history.Repository
+}
+
+// NewWithHistory is used to wrap next so that every successful mutation is committed to repository,
+// with a message like "alice: create-folder photos".
+func NewWithHistory(next vfs.VirtualFileSystem, repository *history.Repository) vfs.VirtualFileSystem {
+	h := &withHistory{
+		next:       next,
+		repository: repository,
+	}
+	h.recorder = recorder{tx: next, record: h.commit}
+
+	return h
+}
+
+func (h *withHistory) RegisterUser(username string) (item *model.User, err error) {
+	item, err = h.next.RegisterUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: register", username))
+}
+
+func (h *withHistory) DeleteUser(username string, cascade bool) (err error) {
+	err = h.next.DeleteUser(username, cascade)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("%s: delete-user", username)
+	if cascade {
+		message += " --cascade"
+	}
+
+	return h.commit(message)
+}
+
+func (h *withHistory) RenameUser(username, newUsername string) (item *model.User, err error) {
+	item, err = h.next.RenameUser(username, newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: rename-user %s", username, newUsername))
+}
+
+func (h *withHistory) ListUsers(order string, offset, limit int) (items []*model.User, err error) {
+	return h.next.ListUsers(order, offset, limit)
+}
+
+func (h *withHistory) As(actor string) vfs.VirtualFileSystem {
+	return NewWithHistory(h.next.As(actor), h.repository)
+}
+
+func (h *withHistory) GrantRole(username string, role model.Role) (item *model.User, err error) {
+	item, err = h.next.GrantRole(username, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: grant-role %s", username, role))
+}
+
+func (h *withHistory) RevokeRole(username string, role model.Role) (item *model.User, err error) {
+	item, err = h.next.RevokeRole(username, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: revoke-role %s", username, role))
+}
+
+func (h *withHistory) SetPassword(username, password string) (item *model.User, err error) {
+	item, err = h.next.SetPassword(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// the password itself is never written down
+	return item, h.commit(fmt.Sprintf("%s: set-password", username))
+}
+
+func (h *withHistory) Login(username, password string, ttl time.Duration) (token string, err error) {
+	token, err = h.next.Login(username, password, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return token, h.commit(fmt.Sprintf("%s: login", username))
+}
+
+func (h *withHistory) Logout(username, token string) (err error) {
+	err = h.next.Logout(username, token)
+	if err != nil {
+		return err
+	}
+
+	return h.commit(fmt.Sprintf("%s: logout", username))
+}
+
+func (h *withHistory) Authenticate(username, token string) (item *model.User, err error) {
+	return h.next.Authenticate(username, token)
+}
+
+func (h *withHistory) CreateToken(
+	username string,
+	scopes []model.Scope,
+	ttl time.Duration,
+) (token string, item *model.Token, err error) {
+	token, item, err = h.next.CreateToken(username, scopes, ttl)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, item, h.commit(fmt.Sprintf("%s: token create %s", username, item.ID))
+}
+
+func (h *withHistory) ListTokens(username string) (items []*model.Token, err error) {
+	return h.next.ListTokens(username)
+}
+
+func (h *withHistory) RevokeToken(username, id string) (err error) {
+	err = h.next.RevokeToken(username, id)
+	if err != nil {
+		return err
+	}
+
+	return h.commit(fmt.Sprintf("%s: token revoke %s", username, id))
+}
+
+func (h *withHistory) AuthenticateToken(token string) (user *model.User, item *model.Token, err error) {
+	return h.next.AuthenticateToken(token)
+}
+
+func (h *withHistory) SetQuota(username string, quota *model.Quota) (item *model.User, err error) {
+	item, err = h.next.SetQuota(username, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := model.Quota{}
+	if item.Quota != nil {
+		limits = *item.Quota
+	}
+
+	return item, h.commit(fmt.Sprintf(
+		"%s: set-quota --max-folders %d --max-folder-files %d --max-files %d --max-bytes %d",
+		username,
+		limits.MaxFolders,
+		limits.MaxFolderFiles,
+		limits.MaxFiles,
+		limits.MaxBytes,
+	))
+}
+
+func (h *withHistory) Usage(username string) (item *model.Usage, err error) {
+	return h.next.Usage(username)
+}
+
+func (h *withHistory) ShareFolder(username, foldername, grantee string, access model.Access) (err error) {
+	err = h.next.ShareFolder(username, foldername, grantee, access)
+	if err != nil {
+		return err
+	}
+
+	return h.commit(fmt.Sprintf("%s: share-folder %s %s %s", username, foldername, grantee, access))
+}
+
+func (h *withHistory) UnshareFolder(username, foldername, grantee string) (err error) {
+	err = h.next.UnshareFolder(username, foldername, grantee)
+	if err != nil {
+		return err
+	}
+
+	return h.commit(fmt.Sprintf("%s: unshare-folder %s %s", username, foldername, grantee))
+}
+
+func (h *withHistory) ListShares(username string) (items []*model.Share, err error) {
+	return h.next.ListShares(username)
+}
+
+func (h *withHistory) TagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	item, err = h.next.TagFolder(username, foldername, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: tag %s %s", username, foldername, strings.Join(tags, ",")))
+}
+
+func (h *withHistory) UntagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	item, err = h.next.UntagFolder(username, foldername, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: untag %s %s", username, foldername, strings.Join(tags, ",")))
+}
+
+func (h *withHistory) ListTaggedFolders(
+	username, tag string,
+	sortBy string,
+	order string,
+) (items []*model.Folder, err error) {
+	return h.next.ListTaggedFolders(username, tag, sortBy, order)
+}
+
+func (h *withHistory) Tags(username string) (items []*model.TagCount, err error) {
+	return h.next.Tags(username)
+}
+
+func (h *withHistory) TagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	item, err = h.next.TagFile(username, foldername, filename, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf(
+		"%s: tag %s %s --file %s",
+		username,
+		foldername,
+		strings.Join(tags, ","),
+		filename,
+	))
+}
+
+func (h *withHistory) UntagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	item, err = h.next.UntagFile(username, foldername, filename, tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf(
+		"%s: untag %s %s --file %s",
+		username,
+		foldername,
+		strings.Join(tags, ","),
+		filename,
+	))
+}
+
+func (h *withHistory) ListTaggedFiles(
+	username, foldername, tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	return h.next.ListTaggedFiles(username, foldername, tag, sortBy, order)
+}
+
+func (h *withHistory) CreateGroup(username, name string) (item *model.Group, err error) {
+	item, err = h.next.CreateGroup(username, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: create-group %s", username, name))
+}
+
+func (h *withHistory) AddMember(username, name, member string) (item *model.Group, err error) {
+	item, err = h.next.AddMember(username, name, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: add-member %s %s", username, name, member))
+}
+
+func (h *withHistory) RemoveMember(username, name, member string) (item *model.Group, err error) {
+	item, err = h.next.RemoveMember(username, name, member)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, h.commit(fmt.Sprintf("%s: remove-member %s %s", username, name, member))
+}
+
+func (h *withHistory) ListGroups(username string) (items []*model.Group, err error) {
+	return h.next.ListGroups(username)
+}
+
+// Tx runs fn in a transaction of next, committed to repository as a single commit listing its mutations.
+func (h *withHistory) Tx(fn func(tx vfs.Tx) error) (err error) {
+	var messages []string
+	err = h.next.Tx(func(tx vfs.Tx) error {
+		return fn(&recorder{tx: tx, record: func(message string) error {
+			messages = append(messages, message)
+			return nil
+		}})
+	})
+	if err != nil || len(messages) == 0 {
+		return err
+	}
+
+	return h.commit(strings.Join(messages, "; "))
+}
+
+func (h *withHistory) commit(message string) error {
+	_, err := h.repository.Commit(message)
+	return err
+}
+
+// recorder wraps the folders and files of tx so that every successful mutation is recorded with a message.
+type recorder struct {
+	tx     vfs.Tx
+	record func(message string) error
+}
+
+func (r *recorder) CreateFolder(username, foldername, description string) (item *model.Folder, err error) {
+	item, err = r.tx.CreateFolder(username, foldername, description)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: create-folder %s", username, foldername)
+}
+
+func (r *recorder) DeleteFolder(username, foldername string) (err error) {
+	err = r.tx.DeleteFolder(username, foldername)
+	if err != nil {
+		return err
+	}
+
+	return r.recordf("%s: delete-folder %s", username, foldername)
+}
+
+func (r *recorder) ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error) {
+	return r.tx.ListFolders(username, sortBy, order)
+}
+
+func (r *recorder) RenameFolder(username, foldername, newFoldername string) (item *model.Folder, err error) {
+	item, err = r.tx.RenameFolder(username, foldername, newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: rename-folder %s %s", username, foldername, newFoldername)
+}
+
+func (r *recorder) RestoreFolder(username string, folder *model.Folder) (item *model.Folder, err error) {
+	item, err = r.tx.RestoreFolder(username, folder)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: restore-folder %s", username, item.Name)
+}
+
+func (r *recorder) CopyFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error) {
+	item, err = r.tx.CopyFolder(username, foldername, newUsername, newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: copy-folder %s %s %s", username, foldername, newUsername, newFoldername)
+}
+
+func (r *recorder) MoveFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error) {
+	item, err = r.tx.MoveFolder(username, foldername, newUsername, newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: move-folder %s %s %s", username, foldername, newUsername, newFoldername)
+}
+
+func (r *recorder) CreateFile(username, foldername, filename, description string) (item *model.File, err error) {
+	item, err = r.tx.CreateFile(username, foldername, filename, description)
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: create-file %s %s", username, foldername, filename)
+}
+
+func (r *recorder) DeleteFile(username, foldername, filename string) (err error) {
+	err = r.tx.DeleteFile(username, foldername, filename)
+	if err != nil {
+		return err
+	}
+
+	return r.recordf("%s: delete-file %s %s", username, foldername, filename)
+}
+
+func (r *recorder) ListFiles(
+	username, foldername string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	return r.tx.ListFiles(username, foldername, sortBy, order)
+}
+
+func (r *recorder) RenameFile(
+	username, foldername, filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	item, err = r.tx.RenameFile(username, foldername, filename, newFilename, conflict)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: rename-file %s %s %s", username, foldername, filename, newFilename)
+}
+
+func (r *recorder) MoveFile(
+	username, foldername, filename, newFoldername string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	item, err = r.tx.MoveFile(username, foldername, filename, newFoldername, conflict)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: move-file %s %s %s", username, foldername, filename, newFoldername)
+}
+
+func (r *recorder) CopyFile(
+	username, foldername, filename, newFoldername, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	item, err = r.tx.CopyFile(username, foldername, filename, newFoldername, newFilename, conflict)
+	if err != nil || item == nil {
+		return nil, err
+	}
+
+	return item, r.recordf("%s: copy-file %s %s %s %s", username, foldername, filename, newFoldername, newFilename)
+}
+
+func (r *recorder) recordf(format string, args ...any) error {
+	return r.record(fmt.Sprintf(format, args...))
+}
@@ -2,105 +2,1223 @@ package vfs
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
+	"github.com/blackhorseya/iscool-assessment/internal/auth"
 	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
 )
 
+// errGroupsInTx is returned by the transactions, which can't change the groups or their folders.
+var errGroupsInTx = errors.New("the groups can't be changed in a transaction")
+
 type impl struct {
 	users   repo.UserManager
 	folders repo.FolderManager
+	groups  repo.GroupManager
+
+	// actor is the user acting for the usernames given, each username itself when it's empty.
+	actor string
 }
 
 // New is used to create a new VirtualFileSystem.
-func New(users repo.UserManager, folders repo.FolderManager) vfs.VirtualFileSystem {
+func New(users repo.UserManager, folders repo.FolderManager, groups repo.GroupManager) vfs.VirtualFileSystem {
 	return &impl{
 		users:   users,
 		folders: folders,
+		groups:  groups,
 	}
 }
 
 func (i *impl) RegisterUser(username string) (item *model.User, err error) {
+	if i.actor != "" {
+		actor, err := i.getUserByUsername(i.actor)
+		if err != nil {
+			return nil, err
+		}
+
+		if actor.Is(model.RoleReadonly) {
+			return nil, fmt.Errorf("%w: %s is readonly", vfs.ErrPermissionDenied, actor.Username)
+		}
+	}
+
 	return i.users.Register(context.TODO(), username)
 }
 
-func (i *impl) CreateFolder(username, foldername, description string) (item *model.Folder, err error) {
+func (i *impl) DeleteUser(username string, cascade bool) (err error) {
+	user, _, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return err
+	}
+
+	groups, err := i.groups.List(context.TODO(), user)
+	if err != nil {
+		return err
+	}
+
+	// a group without members would keep its folders out of reach
+	for _, group := range groups {
+		if len(group.Members) == 1 {
+			return fmt.Errorf("the %s is the last member of %s", username, group.Name)
+		}
+	}
+
+	// the user leaves its groups before it's deleted, and joins them back when a later step fails, so it's never
+	// deleted while still a member
+	var undo []func()
+	fail := func(err error) error {
+		for n := len(undo) - 1; n >= 0; n-- {
+			undo[n]()
+		}
+
+		return err
+	}
+
+	for _, group := range groups {
+		_, err = i.groups.RemoveMember(context.TODO(), group.Name, user)
+		if err != nil {
+			return fail(err)
+		}
+		undo = append(undo, func() {
+			_, _ = i.groups.AddMember(context.TODO(), group.Name, user)
+		})
+	}
+
+	err = i.users.Delete(context.TODO(), username, cascade)
+	if err != nil {
+		return fail(err)
+	}
+
+	return nil
+}
+
+func (i *impl) RenameUser(username, newUsername string) (item *model.User, err error) {
+	user, _, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := i.groups.List(context.TODO(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	// the new name joins the groups before the rename and the old one leaves them after, each step being undone
+	// when a later one fails, so the user never ends up renamed outside of its groups
+	var undo []func()
+	fail := func(err error) (*model.User, error) {
+		for n := len(undo) - 1; n >= 0; n-- {
+			undo[n]()
+		}
+
+		return nil, err
+	}
+
+	// the stores rename the user they return in place, so the names are kept apart
+	previous, next := &model.User{Username: username}, &model.User{Username: newUsername}
+	for _, group := range groups {
+		_, err = i.groups.AddMember(context.TODO(), group.Name, next)
+		if err != nil {
+			return fail(err)
+		}
+		undo = append(undo, func() {
+			_, _ = i.groups.RemoveMember(context.TODO(), group.Name, next)
+		})
+	}
+
+	item, err = i.users.Rename(context.TODO(), username, newUsername)
+	if err != nil {
+		return fail(err)
+	}
+	undo = append(undo, func() {
+		_, _ = i.users.Rename(context.TODO(), newUsername, username)
+	})
+
+	for _, group := range groups {
+		_, err = i.groups.RemoveMember(context.TODO(), group.Name, previous)
+		if err != nil {
+			return fail(err)
+		}
+		undo = append(undo, func() {
+			_, _ = i.groups.AddMember(context.TODO(), group.Name, previous)
+		})
+	}
+
+	return item, nil
+}
+
+func (i *impl) ListUsers(order string, offset, limit int) (items []*model.User, err error) {
+	if offset < 0 || limit < 0 {
+		return nil, fmt.Errorf("the offset and the limit can't be negative")
+	}
+
+	return i.users.List(context.TODO(), order, offset, limit)
+}
+
+func (i *impl) As(actor string) vfs.VirtualFileSystem {
+	acting := *i
+	acting.actor = actor
+
+	return &acting
+}
+
+func (i *impl) GrantRole(username string, role model.Role) (item *model.User, err error) {
+	err = i.checkAdmin("change the roles", role == model.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.users.SetRole(context.TODO(), username, role)
+}
+
+func (i *impl) RevokeRole(username string, role model.Role) (item *model.User, err error) {
+	err = i.checkAdmin("change the roles", false)
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := i.getUserByUsername(username)
 	if err != nil {
 		return nil, err
 	}
 
-	return i.folders.Create(context.TODO(), user, foldername, description)
+	if role == model.RoleMember {
+		return nil, fmt.Errorf("the %s role can't be revoked", role)
+	}
+	if !user.Is(role) {
+		return nil, fmt.Errorf("the %s isn't %s", username, role)
+	}
+
+	return i.users.SetRole(context.TODO(), username, model.RoleMember)
 }
 
-func (i *impl) DeleteFolder(username, foldername string) (err error) {
+func (i *impl) SetPassword(username, password string) (item *model.User, err error) {
+	_, _, err = i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.users.SetPassword(context.TODO(), username, passwordHash)
+}
+
+func (i *impl) Login(username, password string, ttl time.Duration) (token string, err error) {
+	if ttl <= 0 {
+		return "", errors.New("the ttl of a session must be positive")
+	}
+
 	user, err := i.getUserByUsername(username)
+	if err != nil {
+		return "", err
+	}
+
+	if user.PasswordHash == "" {
+		return "", fmt.Errorf("%w: %s has no password", vfs.ErrUnauthenticated, username)
+	}
+
+	matched, err := auth.VerifyPassword(user.PasswordHash, password)
+	if err != nil {
+		return "", err
+	}
+	if !matched {
+		return "", fmt.Errorf("%w: wrong password for %s", vfs.ErrUnauthenticated, username)
+	}
+
+	token, tokenHash, err := auth.NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = i.users.AddSession(context.TODO(), username, tokenHash, time.Now().Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (i *impl) Logout(username, token string) (err error) {
+	_, err = i.Authenticate(username, token)
 	if err != nil {
 		return err
 	}
 
-	return i.folders.Delete(context.TODO(), user, foldername)
+	_, err = i.users.RemoveSession(context.TODO(), username, auth.HashToken(token))
+
+	return err
 }
 
-func (i *impl) ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error) {
+func (i *impl) Authenticate(username, token string) (item *model.User, err error) {
 	user, err := i.getUserByUsername(username)
 	if err != nil {
 		return nil, err
 	}
 
-	return i.folders.List(context.TODO(), user, sortBy, order)
+	expiresAt, exists := user.Sessions[auth.HashToken(token)]
+	if !exists || !time.Now().Before(expiresAt) {
+		return nil, fmt.Errorf("%w: the session of %s has expired or ended, login again", vfs.ErrUnauthenticated, username)
+	}
+
+	return user, nil
+}
+
+func (i *impl) CreateToken(
+	username string,
+	scopes []model.Scope,
+	ttl time.Duration,
+) (token string, item *model.Token, err error) {
+	if ttl <= 0 {
+		return "", nil, errors.New("the ttl of a token must be positive")
+	}
+	if len(scopes) == 0 {
+		return "", nil, errors.New("a token needs a scope at least")
+	}
+
+	_, _, err = i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret, secretHash, err := auth.NewToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the token names its user and its ID, which can't hold a dot
+	now := time.Now()
+	item = &model.Token{ID: secretHash[:8], Scopes: scopes, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	token = username + "." + item.ID + "." + secret
+	item.Hash = auth.HashToken(token)
+
+	_, err = i.users.AddToken(context.TODO(), username, item)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, item, nil
+}
+
+func (i *impl) ListTokens(username string) (items []*model.Token, err error) {
+	user, _, err := i.userOf(username, model.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range user.Tokens {
+		items = append(items, token)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		if items[a].CreatedAt.Equal(items[b].CreatedAt) {
+			return items[a].ID < items[b].ID
+		}
+
+		return items[a].CreatedAt.Before(items[b].CreatedAt)
+	})
+
+	return items, nil
+}
+
+func (i *impl) RevokeToken(username, id string) (err error) {
+	_, _, err = i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.users.RemoveToken(context.TODO(), username, id)
+
+	return err
+}
+
+func (i *impl) AuthenticateToken(token string) (user *model.User, item *model.Token, err error) {
+	username, rest, _ := strings.Cut(token, ".")
+	id, _, found := strings.Cut(rest, ".")
+	if !found || model.ValidateInput(username) != nil {
+		return nil, nil, fmt.Errorf("%w: the token is malformed", vfs.ErrUnauthenticated)
+	}
+
+	user, err = i.getUserByUsername(username)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: the token isn't valid", vfs.ErrUnauthenticated)
+	}
+
+	item, exists := user.Tokens[id]
+	if !exists || item.Hash != auth.HashToken(token) {
+		return nil, nil, fmt.Errorf("%w: the token isn't valid", vfs.ErrUnauthenticated)
+	}
+	if item.Expired(time.Now()) {
+		return nil, nil, fmt.Errorf("%w: the token %s of %s has expired", vfs.ErrUnauthenticated, id, username)
+	}
+
+	return user, item, nil
+}
+
+func (i *impl) SetQuota(username string, quota *model.Quota) (item *model.User, err error) {
+	err = i.checkAdmin("change the quotas", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if quota.Unlimited() {
+		quota = nil
+	} else if err = quota.Validate(); err != nil {
+		return nil, err
+	}
+
+	if name, found := strings.CutPrefix(username, model.GroupPrefix); found {
+		group, err := i.groups.SetQuota(context.TODO(), name, quota)
+		if err != nil {
+			return nil, err
+		}
+
+		return group.Owner(), nil
+	}
+
+	return i.users.SetQuota(context.TODO(), username, quota)
+}
+
+func (i *impl) Usage(username string) (item *model.Usage, err error) {
+	folders, owner, err := i.listedOf(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.usageOf(folders, owner)
+}
+
+func (i *impl) CreateFolder(username, foldername, description string) (item *model.Folder, err error) {
+	folders, owner, foldername, _, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.checkQuota(folders, owner, 1, 0, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.Create(context.TODO(), owner, foldername, description)
+}
+
+func (i *impl) DeleteFolder(username, foldername string) (err error) {
+	folders, owner, foldername, _, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return err
+	}
+
+	return folders.Delete(context.TODO(), owner, foldername)
+}
+
+func (i *impl) ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error) {
+	folders, owner, err := i.listedOf(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.List(context.TODO(), owner, sortBy, order)
 }
 
 func (i *impl) RenameFolder(username, foldername, newFoldername string) (item *model.Folder, err error) {
-	user, err := i.getUserByUsername(username)
+	folders, owner, foldername, _, err := i.ownerOf(username, foldername)
 	if err != nil {
 		return nil, err
 	}
 
-	return i.folders.Rename(context.TODO(), user, foldername, newFoldername)
+	return folders.Rename(context.TODO(), owner, foldername, newFoldername)
 }
 
-func (i *impl) CreateFile(username, foldername, filename, description string) (item *model.File, err error) {
-	user, err := i.getUserByUsername(username)
+func (i *impl) RestoreFolder(username string, folder *model.Folder) (item *model.Folder, err error) {
+	user, _, err := i.userOf(username, model.AccessWrite)
 	if err != nil {
 		return nil, err
 	}
 
-	folder, err := i.folders.GetByName(context.TODO(), user, foldername)
+	err = i.checkQuota(i.folders, user, 1, len(folder.Files), "", len(folder.Files))
 	if err != nil {
 		return nil, err
 	}
 
-	return i.folders.CreateFile(context.TODO(), user, folder, filename, description)
+	return i.folders.Restore(context.TODO(), user, folder)
 }
 
-func (i *impl) DeleteFile(username, foldername, filename string) (err error) {
-	user, err := i.getUserByUsername(username)
+func (i *impl) CopyFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error) {
+	folders, owner, foldername, admin, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := i.targetOwnerOf(username, admin, folders, newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.checkFolderQuota(folders, owner, foldername, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.CopyFolder(context.TODO(), owner, foldername, target, newFoldername)
+}
+
+func (i *impl) MoveFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error) {
+	folders, owner, foldername, admin, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := i.targetOwnerOf(username, admin, folders, newUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	// a folder moved between the folders of the same owner doesn't add anything
+	if target.Username != owner.Username {
+		err = i.checkFolderQuota(folders, owner, foldername, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return folders.MoveFolder(context.TODO(), owner, foldername, target, newFoldername)
+}
+
+func (i *impl) ShareFolder(username, foldername, grantee string, access model.Access) (err error) {
+	user, foldername, err := i.sharedOf(username, foldername)
 	if err != nil {
 		return err
 	}
 
-	folder, err := i.folders.GetByName(context.TODO(), user, foldername)
+	if grantee == user.Username {
+		return fmt.Errorf("the %s can't be shared with its owner", foldername)
+	}
+
+	to, err := i.getUserByUsername(grantee)
 	if err != nil {
 		return err
 	}
 
-	return i.folders.DeleteFile(context.TODO(), user, folder, filename)
+	return i.folders.Share(context.TODO(), user, foldername, to, access)
 }
 
-func (i *impl) ListFiles(username, foldername string, sortBy string, order string) (items []*model.File, err error) {
-	user, err := i.getUserByUsername(username)
+func (i *impl) UnshareFolder(username, foldername, grantee string) (err error) {
+	user, foldername, err := i.sharedOf(username, foldername)
+	if err != nil {
+		return err
+	}
+
+	to, err := i.getUserByUsername(grantee)
+	if err != nil {
+		return err
+	}
+
+	return i.folders.Unshare(context.TODO(), user, foldername, to)
+}
+
+func (i *impl) ListShares(username string) (items []*model.Share, err error) {
+	user, _, err := i.userOf(username, model.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := i.folders.List(context.TODO(), user, "name", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range folders {
+		grantees := make([]string, 0, len(folder.Shares))
+		for grantee := range folder.Shares {
+			grantees = append(grantees, grantee)
+		}
+		sort.Strings(grantees)
+
+		for _, grantee := range grantees {
+			items = append(items, &model.Share{
+				Owner:   username,
+				Folder:  folder.Name,
+				Grantee: grantee,
+				Access:  folder.Shares[grantee],
+			})
+		}
+	}
+
+	shared, err := i.folders.ListShared(context.TODO(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(items, shared...), nil
+}
+
+func (i *impl) TagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	folders, owner, foldername, _, err := i.ownerOf(username, foldername)
 	if err != nil {
 		return nil, err
 	}
 
-	folder, err := i.folders.GetByName(context.TODO(), user, foldername)
+	return folders.TagFolder(context.TODO(), owner, foldername, tags)
+}
+
+func (i *impl) UntagFolder(username, foldername string, tags []string) (item *model.Folder, err error) {
+	folders, owner, foldername, _, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.UntagFolder(context.TODO(), owner, foldername, tags)
+}
+
+func (i *impl) ListTaggedFolders(username, tag string, sortBy string, order string) (items []*model.Folder, err error) {
+	folders, owner, err := i.listedOf(username)
 	if err != nil {
 		return nil, err
 	}
 
-	return i.folders.ListFiles(context.TODO(), user, folder, sortBy, order)
+	return folders.ListTagged(context.TODO(), owner, tag, sortBy, order)
+}
+
+func (i *impl) Tags(username string) (items []*model.TagCount, err error) {
+	folders, owner, err := i.listedOf(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return tagsOf(folders, owner)
+}
+
+func (i *impl) CreateFile(username, foldername, filename, description string) (item *model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.checkQuota(folders, owner, 0, 1, folder.Name, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.CreateFile(context.TODO(), owner, folder, filename, description)
+}
+
+func (i *impl) DeleteFile(username, foldername, filename string) (err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return err
+	}
+
+	return folders.DeleteFile(context.TODO(), owner, folder, filename)
+}
+
+func (i *impl) ListFiles(username, foldername string, sortBy string, order string) (items []*model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.ListFiles(context.TODO(), owner, folder, sortBy, order)
+}
+
+func (i *impl) TagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.TagFile(context.TODO(), owner, folder, filename, tags)
+}
+
+func (i *impl) UntagFile(username, foldername, filename string, tags []string) (item *model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.UntagFile(context.TODO(), owner, folder, filename, tags)
+}
+
+func (i *impl) ListTaggedFiles(
+	username, foldername, tag string,
+	sortBy string,
+	order string,
+) (items []*model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.ListTaggedFiles(context.TODO(), owner, folder, tag, sortBy, order)
+}
+
+func (i *impl) RenameFile(
+	username, foldername, filename, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	folders, owner, folder, _, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.RenameFile(context.TODO(), owner, folder, filename, newFilename, conflict)
+}
+
+func (i *impl) MoveFile(
+	username, foldername, filename, newFoldername string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	folders, owner, folder, admin, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := i.targetOf(username, admin, folders, owner, newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Name != folder.Name {
+		err = i.checkQuota(folders, owner, 0, 0, target.Name, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return folders.MoveFile(context.TODO(), owner, folder, filename, target, conflict)
+}
+
+func (i *impl) CopyFile(
+	username, foldername, filename, newFoldername, newFilename string,
+	conflict model.Conflict,
+) (item *model.File, err error) {
+	folders, owner, folder, admin, err := i.folderOf(username, foldername, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := i.targetOf(username, admin, folders, owner, newFoldername)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.checkQuota(folders, owner, 0, 1, target.Name, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return folders.CopyFile(context.TODO(), owner, folder, filename, target, newFilename, conflict)
+}
+
+func (i *impl) CreateGroup(username, name string) (item *model.Group, err error) {
+	user, _, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.groups == nil {
+		return nil, errGroupsInTx
+	}
+
+	return i.groups.Create(context.TODO(), name, user)
+}
+
+func (i *impl) AddMember(username, name, member string) (item *model.Group, err error) {
+	_, admin, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.groupOf(username, admin, name)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := i.getUserByUsername(member)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.groups.AddMember(context.TODO(), name, user)
+}
+
+func (i *impl) RemoveMember(username, name, member string) (item *model.Group, err error) {
+	_, admin, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = i.groupOf(username, admin, name)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := i.getUserByUsername(member)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.groups.RemoveMember(context.TODO(), name, user)
+}
+
+func (i *impl) ListGroups(username string) (items []*model.Group, err error) {
+	user, _, err := i.userOf(username, model.AccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.groups == nil {
+		return nil, errGroupsInTx
+	}
+
+	return i.groups.List(context.TODO(), user)
+}
+
+func (i *impl) Tx(fn func(tx vfs.Tx) error) (err error) {
+	ctx := context.TODO()
+	tx, err := i.folders.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	err = fn(&impl{users: i.users, folders: tx, actor: i.actor})
+	if err != nil {
+		return err
+	}
+
+	committed = true
+	return tx.Commit(ctx)
+}
+
+// listedOf is used to get the user or group username whose folders are listed, with the manager of its folders.
+func (i *impl) listedOf(username string) (folders repo.FolderManager, owner *model.User, err error) {
+	if name, found := strings.CutPrefix(username, model.GroupPrefix); found {
+		// the actor must be a member of the group or an admin, while nothing is checked without an actor, as
+		// for the folders of the users
+		admin := i.actor == ""
+		if !admin {
+			_, admin, err = i.userOf(i.actor, model.AccessRead)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		group, err := i.groupOf(i.actor, admin, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return i.groups.Folders(), group.Owner(), nil
+	}
+
+	user, _, err := i.userOf(username, model.AccessRead)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.folders, user, nil
+}
+
+// ownerOf is used to get the owner of the folder foldername which username changes, with the manager of its
+// folders and its name: username itself, or a group username belongs to addressed as group:<name>/foldername.
+// Only their owners and the admins change the folders of the other users.
+func (i *impl) ownerOf(
+	username, foldername string,
+) (folders repo.FolderManager, owner *model.User, name string, admin bool, err error) {
+	user, admin, err := i.userOf(username, model.AccessWrite)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+
+	ownername, name := splitFoldername(username, foldername)
+	if ownername != username && !strings.HasPrefix(ownername, model.GroupPrefix) && !admin {
+		return nil, nil, "", false, fmt.Errorf(
+			"%w: only %s can change %s",
+			vfs.ErrPermissionDenied,
+			ownername,
+			foldername,
+		)
+	}
+
+	folders, owner, err = i.resolve(user, admin, ownername)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
+
+	return folders, owner, name, admin, nil
+}
+
+// sharedOf is used to get the folder foldername of username which username shares, which the groups can't.
+func (i *impl) sharedOf(username, foldername string) (owner *model.User, name string, err error) {
+	folders, owner, name, _, err := i.ownerOf(username, foldername)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if folders != i.folders {
+		return nil, "", fmt.Errorf("the folders of %s can't be shared", owner.Username)
+	}
+
+	return owner, name, nil
+}
+
+// targetOwnerOf is used to get the user or group newUsername which username copies or moves a folder of folders
+// to, which must be kept by the same manager.
+func (i *impl) targetOwnerOf(
+	username string,
+	admin bool,
+	folders repo.FolderManager,
+	newUsername string,
+) (target *model.User, err error) {
+	targetFolders := i.folders
+	if name, found := strings.CutPrefix(newUsername, model.GroupPrefix); found {
+		group, err := i.groupOf(username, admin, name)
+		if err != nil {
+			return nil, err
+		}
+
+		targetFolders, target = i.groups.Folders(), group.Owner()
+	} else {
+		target, err = i.getUserByUsername(newUsername)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if targetFolders != folders {
+		return nil, fmt.Errorf("the folders can't be copied or moved between users and groups")
+	}
+
+	return target, nil
+}
+
+// folderOf is used to get the folder foldername which username works on with the access need, along with its
+// owner and the manager of its folders: a folder of username, of a group username belongs to addressed as
+// group:<name>/foldername, or of another user addressed as owner/foldername when it's shared with username.
+func (i *impl) folderOf(
+	username, foldername string,
+	need model.Access,
+) (folders repo.FolderManager, owner *model.User, folder *model.Folder, admin bool, err error) {
+	user, admin, err := i.userOf(username, need)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	ownername, name := splitFoldername(username, foldername)
+	folders, owner, err = i.resolve(user, admin, ownername)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	folder, err = folders.GetByName(context.TODO(), owner, name)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	if !admin && !allowed(username, ownername, folder, need) {
+		return nil, nil, nil, false, fmt.Errorf(
+			"%w: %s can't %s %s",
+			vfs.ErrPermissionDenied,
+			username,
+			need,
+			foldername,
+		)
+	}
+
+	return folders, owner, folder, admin, nil
+}
+
+// targetOf is used to get the folder foldername which username moves or copies files of owner to,
+// which must belong to owner too.
+func (i *impl) targetOf(
+	username string,
+	admin bool,
+	folders repo.FolderManager,
+	owner *model.User,
+	foldername string,
+) (folder *model.Folder, err error) {
+	ownername, name := splitFoldername(username, foldername)
+	if ownername != owner.Username {
+		return nil, fmt.Errorf("the files of %s can't be put in %s", owner.Username, foldername)
+	}
+
+	folder, err = folders.GetByName(context.TODO(), owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !admin && !allowed(username, ownername, folder, model.AccessWrite) {
+		return nil, fmt.Errorf("%w: %s can't write %s", vfs.ErrPermissionDenied, username, foldername)
+	}
+
+	return folder, nil
+}
+
+// resolve is used to get the owner ownername of the folders user works on, with the manager of its folders.
+func (i *impl) resolve(
+	user *model.User,
+	admin bool,
+	ownername string,
+) (folders repo.FolderManager, owner *model.User, err error) {
+	if ownername == user.Username {
+		return i.folders, user, nil
+	}
+
+	if name, found := strings.CutPrefix(ownername, model.GroupPrefix); found {
+		group, err := i.groupOf(user.Username, admin, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return i.groups.Folders(), group.Owner(), nil
+	}
+
+	owner, err = i.getUserByUsername(ownername)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.folders, owner, nil
+}
+
+// groupOf is used to get the group name which username must be a member of, unless acting for an admin.
+func (i *impl) groupOf(username string, admin bool, name string) (item *model.Group, err error) {
+	group, err := i.getGroup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !admin && !group.HasMember(username) {
+		return nil, fmt.Errorf("%w: %s isn't a member of %s", vfs.ErrPermissionDenied, username, name)
+	}
+
+	return group, nil
+}
+
+func (i *impl) getGroup(name string) (item *model.Group, err error) {
+	if i.groups == nil {
+		return nil, errGroupsInTx
+	}
+
+	return i.groups.GetByName(context.TODO(), name)
+}
+
+// allowed reports whether username has the access need to folder of ownername: its own folders, the ones of
+// its groups, and the ones shared with it.
+func allowed(username, ownername string, folder *model.Folder, need model.Access) bool {
+	return ownername == username ||
+		strings.HasPrefix(ownername, model.GroupPrefix) ||
+		folder.Shares[username].Allows(need)
+}
+
+// checkQuota is used to check that owner stays within its quota once it has newFolders folders and newFiles files
+// more in folders, folderFiles of them going into the folder foldername, or into a new folder when it's empty.
+// A file replacing another one counts as a new one.
+func (i *impl) checkQuota(
+	folders repo.FolderManager,
+	owner *model.User,
+	newFolders, newFiles int,
+	foldername string,
+	folderFiles int,
+) (err error) {
+	quota := owner.Quota
+	if quota.Unlimited() {
+		return nil
+	}
+
+	usage, err := i.usageOf(folders, owner)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case quota.MaxFolders > 0 && usage.Folders+newFolders > quota.MaxFolders:
+		return fmt.Errorf("%w: %s can't have more than %d folders", vfs.ErrQuotaExceeded, owner.Username, quota.MaxFolders)
+	case quota.MaxFiles > 0 && usage.Files+newFiles > quota.MaxFiles:
+		return fmt.Errorf("%w: %s can't have more than %d files", vfs.ErrQuotaExceeded, owner.Username, quota.MaxFiles)
+	case quota.MaxFolderFiles > 0 && usage.FilesByFolder[foldername]+folderFiles > quota.MaxFolderFiles:
+		return fmt.Errorf(
+			"%w: %s can't have more than %d files in a folder",
+			vfs.ErrQuotaExceeded,
+			owner.Username,
+			quota.MaxFolderFiles,
+		)
+	}
+
+	return nil
+}
+
+// checkFolderQuota is used to check that target stays within its quota once it has the folder foldername of owner
+// with its files.
+func (i *impl) checkFolderQuota(
+	folders repo.FolderManager,
+	owner *model.User,
+	foldername string,
+	target *model.User,
+) (err error) {
+	if target.Quota.Unlimited() {
+		return nil
+	}
+
+	folder, err := folders.GetByName(context.TODO(), owner, foldername)
+	if err != nil {
+		return err
+	}
+
+	files, err := folders.ListFiles(context.TODO(), owner, folder, "name", "asc")
+	if err != nil {
+		return err
+	}
+
+	return i.checkQuota(folders, target, 1, len(files), "", len(files))
+}
+
+// usageOf is used to count the folders of owner kept by folders and their files. The files don't have any
+// content yet, so they take no bytes.
+func (i *impl) usageOf(folders repo.FolderManager, owner *model.User) (item *model.Usage, err error) {
+	items, err := folders.List(context.TODO(), owner, "name", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &model.Usage{
+		Username:      owner.Username,
+		Quota:         owner.Quota,
+		Folders:       len(items),
+		FilesByFolder: make(map[string]int, len(items)),
+	}
+	for _, folder := range items {
+		files, err := folders.ListFiles(context.TODO(), owner, folder, "name", "asc")
+		if err != nil {
+			return nil, err
+		}
+
+		usage.Files += len(files)
+		usage.FilesByFolder[folder.Name] = len(files)
+	}
+
+	return usage, nil
+}
+
+// tagsOf is used to count the folders of owner kept by folders and their files by tag, sorted by tag.
+func tagsOf(folders repo.FolderManager, owner *model.User) (items []*model.TagCount, err error) {
+	list, err := folders.List(context.TODO(), owner, "name", "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]*model.TagCount)
+	countOf := func(tag string) *model.TagCount {
+		if counts[tag] == nil {
+			counts[tag] = &model.TagCount{Tag: tag}
+			items = append(items, counts[tag])
+		}
+
+		return counts[tag]
+	}
+	for _, folder := range list {
+		for _, tag := range folder.Tags {
+			countOf(tag).Folders++
+		}
+
+		files, err := folders.ListFiles(context.TODO(), owner, folder, "name", "asc")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			for _, tag := range file.Tags {
+				countOf(tag).Files++
+			}
+		}
+	}
+
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].Tag < items[b].Tag
+	})
+
+	return items, nil
+}
+
+// splitFoldername is used to split foldername addressed as owner/foldername, which names are never valid with,
+// or the folder of username otherwise.
+func splitFoldername(username, foldername string) (ownername, name string) {
+	if ownername, name, found := strings.Cut(foldername, "/"); found {
+		return ownername, name
+	}
+
+	return username, foldername
+}
+
+// userOf is used to get the user username which the actor works as with the access need, and whether the actor
+// is an admin, who passes the permission checks. Only the admins act as the other users, and readonly actors
+// can't write.
+func (i *impl) userOf(username string, need model.Access) (user *model.User, admin bool, err error) {
+	user, err = i.getUserByUsername(username)
+	if err != nil {
+		return nil, false, err
+	}
+
+	actor := user
+	if i.actor != "" && i.actor != username {
+		actor, err = i.getUserByUsername(i.actor)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if !actor.Is(model.RoleAdmin) {
+			return nil, false, fmt.Errorf("%w: %s can't act as %s", vfs.ErrPermissionDenied, i.actor, username)
+		}
+	}
+
+	if need == model.AccessWrite && actor.Is(model.RoleReadonly) {
+		return nil, false, fmt.Errorf("%w: %s is readonly", vfs.ErrPermissionDenied, actor.Username)
+	}
+
+	return user, actor.Is(model.RoleAdmin), nil
+}
+
+// checkAdmin is used to check that the actor is an admin before it does what. Without an actor, it's only allowed
+// to bootstrap the first admin of a store which doesn't have any yet.
+func (i *impl) checkAdmin(what string, bootstrap bool) (err error) {
+	if i.actor == "" {
+		if bootstrap {
+			users, err := i.users.List(context.TODO(), "", 0, 0)
+			if err != nil {
+				return err
+			}
+
+			if !slices.ContainsFunc(users, func(user *model.User) bool { return user.Is(model.RoleAdmin) }) {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%w: only the admins %s, and no admin is acting", vfs.ErrPermissionDenied, what)
+	}
+
+	actor, err := i.getUserByUsername(i.actor)
+	if err != nil {
+		return err
+	}
+
+	if !actor.Is(model.RoleAdmin) {
+		return fmt.Errorf("%w: only the admins %s, %s isn't one", vfs.ErrPermissionDenied, what, i.actor)
+	}
+
+	return nil
 }
 
 func (i *impl) getUserByUsername(username string) (item *model.User, err error) {
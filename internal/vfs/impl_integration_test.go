@@ -1,14 +1,19 @@
 package vfs
 
 import (
+	"errors"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 	"github.com/blackhorseya/iscool-assessment/entity/repo"
 	"github.com/blackhorseya/iscool-assessment/internal/repo/folder"
+	"github.com/blackhorseya/iscool-assessment/internal/repo/group"
 	"github.com/blackhorseya/iscool-assessment/internal/repo/user"
+	"github.com/blackhorseya/iscool-assessment/internal/store"
 	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
 	"github.com/stretchr/testify/suite"
 )
@@ -20,6 +25,7 @@ type suiteIntegration struct {
 
 	users   repo.UserManager
 	folders repo.FolderManager
+	groups  repo.GroupManager
 	vfs     vfs.VirtualFileSystem
 }
 
@@ -32,11 +38,16 @@ func (s *suiteIntegration) SetupTest() {
 	s.Require().NoError(err)
 	s.folders = folders
 
-	s.vfs = New(s.users, s.folders)
+	groups, err := group.NewJSONFile(defaultPath)
+	s.Require().NoError(err)
+	s.groups = groups
+
+	s.vfs = New(s.users, s.folders, s.groups)
 }
 
 func (s *suiteIntegration) TearDownTest() {
 	_ = os.Remove(defaultPath)
+	_ = os.Remove(store.GroupsPath(defaultPath))
 }
 
 func TestIntegration(t *testing.T) {
@@ -75,3 +86,550 @@ func (s *suiteIntegration) Test_impl_RegisterUser() {
 		})
 	}
 }
+
+func (s *suiteIntegration) Test_impl_Tx() {
+	_, err := s.vfs.RegisterUser("user1")
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name        string
+		fn          func(tx vfs.Tx) error
+		wantErr     bool
+		wantFolders int
+	}{
+		{
+			name: "roll back when fn fails",
+			fn: func(tx vfs.Tx) error {
+				_, _ = tx.CreateFolder("user1", "folder1", "")
+				return errors.New("error")
+			},
+			wantErr:     true,
+			wantFolders: 0,
+		},
+		{
+			name: "roll back when an operation fails",
+			fn: func(tx vfs.Tx) error {
+				_, _ = tx.CreateFolder("user1", "folder1", "")
+				_, err := tx.CreateFile("user1", "missing", "file1", "")
+				return err
+			},
+			wantErr:     true,
+			wantFolders: 0,
+		},
+		{
+			name: "commit when fn succeeds",
+			fn: func(tx vfs.Tx) error {
+				_, err := tx.CreateFolder("user1", "folder1", "")
+				if err != nil {
+					return err
+				}
+				_, err = tx.CreateFile("user1", "folder1", "file1", "")
+				if err != nil {
+					return err
+				}
+				_, err = tx.RenameFolder("user1", "folder1", "folder2")
+				return err
+			},
+			wantErr:     false,
+			wantFolders: 1,
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			err := s.vfs.Tx(tt.fn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Tx() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			folders, _ := s.vfs.ListFolders("user1", "name", "asc")
+			if len(folders) != tt.wantFolders {
+				t.Errorf("ListFolders() got = %v, want %d folders", folders, tt.wantFolders)
+			}
+		})
+	}
+
+	files, err := s.vfs.ListFiles("user1", "folder2", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+}
+
+func (s *suiteIntegration) Test_impl_ShareFolder() {
+	for _, username := range []string{"alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	_, err := s.vfs.CreateFolder("alice", "photos", "")
+	s.Require().NoError(err)
+
+	_, err = s.vfs.ListFiles("bob", "alice/photos", "name", "asc")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	s.Require().Error(s.vfs.ShareFolder("alice", "photos", "alice", model.AccessRead))
+
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessRead))
+	_, err = s.vfs.ListFiles("bob", "alice/photos", "name", "asc")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFile("bob", "alice/photos", "file1", "")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessWrite))
+	item, err := s.vfs.CreateFile("bob", "alice/photos", "file1", "")
+	s.Require().NoError(err)
+	s.Require().Equal("alice", item.Owner.Username)
+	_, err = s.vfs.CopyFile("bob", "alice/photos", "file1", "alice/photos", "file2", model.ConflictFail)
+	s.Require().NoError(err)
+
+	s.Require().ErrorIs(s.vfs.DeleteFolder("bob", "alice/photos"), vfs.ErrPermissionDenied)
+	s.Require().ErrorIs(s.vfs.ShareFolder("bob", "alice/photos", "bob", model.AccessWrite), vfs.ErrPermissionDenied)
+
+	want := []*model.Share{{Owner: "alice", Folder: "photos", Grantee: "bob", Access: model.AccessWrite}}
+	for _, username := range []string{"alice", "bob"} {
+		shares, err := s.vfs.ListShares(username)
+		s.Require().NoError(err)
+		s.Require().Equal(want, shares)
+	}
+
+	s.Require().NoError(s.vfs.UnshareFolder("alice", "photos", "bob"))
+	_, err = s.vfs.ListFiles("bob", "alice/photos", "name", "asc")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+}
+
+func (s *suiteIntegration) Test_impl_Groups() {
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	_, err := s.vfs.CreateGroup("alice", "team")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateGroup("bob", "team")
+	s.Require().Error(err)
+
+	_, err = s.vfs.AddMember("bob", "team", "bob")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	group, err := s.vfs.AddMember("alice", "team", "bob")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"alice", "bob"}, group.Members)
+
+	_, err = s.vfs.CreateFolder("bob", "group:team/docs", "")
+	s.Require().NoError(err)
+	item, err := s.vfs.CreateFile("alice", "group:team/docs", "file1", "")
+	s.Require().NoError(err)
+	s.Require().Equal("group:team", item.Owner.Username)
+
+	_, err = s.vfs.ListFiles("carol", "group:team/docs", "name", "asc")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	s.Require().Error(s.vfs.ShareFolder("alice", "group:team/docs", "carol", model.AccessRead))
+
+	folders, err := s.vfs.ListFolders("group:team", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(folders, 1)
+	s.Require().Equal("docs", folders[0].Name)
+
+	// an actor lists the folders of its groups only
+	_, err = s.vfs.As("carol").ListFolders("group:team", "name", "asc")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.As("carol").Usage("group:team")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	folders, err = s.vfs.As("bob").ListFolders("group:team", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(folders, 1)
+
+	groups, err := s.vfs.ListGroups("bob")
+	s.Require().NoError(err)
+	s.Require().Len(groups, 1)
+
+	_, err = s.vfs.RemoveMember("bob", "team", "alice")
+	s.Require().NoError(err)
+	_, err = s.vfs.RemoveMember("bob", "team", "bob")
+	s.Require().Error(err)
+	s.Require().ErrorIs(s.vfs.DeleteFolder("alice", "group:team/docs"), vfs.ErrPermissionDenied)
+
+	// the groups and their folders are still there after a reload
+	s.SetupTest()
+	files, err := s.vfs.ListFiles("bob", "group:team/docs", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+}
+
+func (s *suiteIntegration) Test_impl_Roles() {
+	for _, username := range []string{"root", "alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	_, err := s.vfs.CreateFolder("alice", "photos", "")
+	s.Require().NoError(err)
+
+	// without an actor, only the first admin is granted
+	_, err = s.vfs.GrantRole("root", model.RoleAdmin)
+	s.Require().NoError(err)
+	_, err = s.vfs.As("alice").GrantRole("alice", model.RoleAdmin)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.GrantRole("alice", model.RoleAdmin)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+
+	admin := s.vfs.As("root")
+	_, err = admin.GrantRole("bob", model.RoleReadonly)
+	s.Require().NoError(err)
+
+	// a readonly user can't promote itself, with or without an actor
+	_, err = s.vfs.GrantRole("bob", model.RoleMember)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.As("bob").GrantRole("bob", model.RoleMember)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.RevokeRole("bob", model.RoleReadonly)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.CreateFolder("bob", "docs", "")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = admin.CreateFile("bob", "alice/photos", "file1", "")
+	s.Require().NoError(err)
+	_, err = admin.CreateFolder("root", "alice/docs", "")
+	s.Require().NoError(err)
+
+	_, err = s.vfs.As("alice").ListFiles("bob", "alice/photos", "name", "asc")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessWrite))
+	files, err := s.vfs.ListFiles("bob", "alice/photos", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+	_, err = s.vfs.CreateFile("bob", "alice/photos", "file2", "")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.As("bob").RegisterUser("carol")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+
+	_, err = admin.RevokeRole("bob", model.RoleAdmin)
+	s.Require().Error(err)
+	user, err := admin.RevokeRole("bob", model.RoleReadonly)
+	s.Require().NoError(err)
+	s.Require().True(user.Is(model.RoleMember))
+	_, err = s.vfs.CreateFile("bob", "alice/photos", "file2", "")
+	s.Require().NoError(err)
+}
+
+func (s *suiteIntegration) Test_impl_Sessions() {
+	for _, username := range []string{"alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+
+	_, err := s.vfs.Login("alice", "secret", time.Hour)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+
+	_, err = s.vfs.As("bob").SetPassword("alice", "secret")
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.As("alice").SetPassword("alice", "")
+	s.Require().Error(err)
+	user, err := s.vfs.As("alice").SetPassword("alice", "secret")
+	s.Require().NoError(err)
+	s.Require().NotContains(user.PasswordHash, "secret")
+
+	_, err = s.vfs.Login("alice", "wrong", time.Hour)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+	_, err = s.vfs.Login("alice", "secret", 0)
+	s.Require().Error(err)
+	token, err := s.vfs.Login("alice", "secret", time.Hour)
+	s.Require().NoError(err)
+	expiring, err := s.vfs.Login("alice", "secret", time.Millisecond)
+	s.Require().NoError(err)
+
+	// the sessions outlive a reload of the store
+	s.SetupTest()
+	user, err = s.vfs.Authenticate("alice", token)
+	s.Require().NoError(err)
+	s.Require().Equal("alice", user.Username)
+	_, err = s.vfs.Authenticate("bob", token)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+
+	time.Sleep(2 * time.Millisecond)
+	_, err = s.vfs.Authenticate("alice", expiring)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+
+	// a login drops the expired sessions
+	other, err := s.vfs.Login("alice", "secret", time.Hour)
+	s.Require().NoError(err)
+	user, err = s.vfs.Authenticate("alice", other)
+	s.Require().NoError(err)
+	s.Require().Len(user.Sessions, 2)
+
+	s.Require().NoError(s.vfs.Logout("alice", token))
+	_, err = s.vfs.Authenticate("alice", token)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+	s.Require().ErrorIs(s.vfs.Logout("alice", token), vfs.ErrUnauthenticated)
+	_, err = s.vfs.Authenticate("alice", other)
+	s.Require().NoError(err)
+}
+
+func (s *suiteIntegration) Test_impl_Tokens() {
+	for _, username := range []string{"alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	scopes := []model.Scope{model.ScopeFoldersRead}
+
+	_, _, err := s.vfs.CreateToken("alice", nil, time.Hour)
+	s.Require().Error(err)
+	_, _, err = s.vfs.CreateToken("alice", scopes, 0)
+	s.Require().Error(err)
+	_, _, err = s.vfs.As("bob").CreateToken("alice", scopes, time.Hour)
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+
+	token, item, err := s.vfs.CreateToken("alice", scopes, time.Hour)
+	s.Require().NoError(err)
+	s.Require().True(strings.HasPrefix(token, "alice."+item.ID+"."))
+	s.Require().NotContains(item.Hash, token)
+	expiring, _, err := s.vfs.CreateToken("alice", scopes, time.Millisecond)
+	s.Require().NoError(err)
+
+	// the tokens outlive a reload of the store
+	s.SetupTest()
+	user, got, err := s.vfs.AuthenticateToken(token)
+	s.Require().NoError(err)
+	s.Require().Equal("alice", user.Username)
+	s.Require().Equal(scopes, got.Scopes)
+
+	for _, invalid := range []string{"", "alice", "bob." + item.ID + ".secret", token + "x"} {
+		_, _, err = s.vfs.AuthenticateToken(invalid)
+		s.Require().ErrorIs(err, vfs.ErrUnauthenticated, invalid)
+	}
+	time.Sleep(2 * time.Millisecond)
+	_, _, err = s.vfs.AuthenticateToken(expiring)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+
+	tokens, err := s.vfs.ListTokens("alice")
+	s.Require().NoError(err)
+	s.Require().Len(tokens, 2)
+	s.Require().Equal(item.ID, tokens[0].ID)
+
+	s.Require().Error(s.vfs.RevokeToken("alice", "missing"))
+	s.Require().NoError(s.vfs.RevokeToken("alice", item.ID))
+	_, _, err = s.vfs.AuthenticateToken(token)
+	s.Require().ErrorIs(err, vfs.ErrUnauthenticated)
+}
+
+func (s *suiteIntegration) Test_impl_Quotas() {
+	for _, username := range []string{"root", "alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	_, err := s.vfs.GrantRole("root", model.RoleAdmin)
+	s.Require().NoError(err)
+	admin := s.vfs.As("root")
+	_, err = s.vfs.CreateFolder("bob", "docs", "")
+	s.Require().NoError(err)
+	for _, filename := range []string{"file1", "file2"} {
+		_, err = s.vfs.CreateFile("bob", "docs", filename, "")
+		s.Require().NoError(err)
+	}
+
+	_, err = s.vfs.As("alice").SetQuota("alice", &model.Quota{MaxFolders: 10})
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = s.vfs.SetQuota("alice", &model.Quota{MaxFolders: 10})
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	_, err = admin.SetQuota("alice", &model.Quota{MaxFolders: -1})
+	s.Require().Error(err)
+
+	user, err := admin.SetQuota("alice", &model.Quota{MaxFolders: 2, MaxFolderFiles: 2, MaxFiles: 3})
+	s.Require().NoError(err)
+	s.Require().Equal(2, user.Quota.MaxFolders)
+
+	_, err = s.vfs.CreateFolder("alice", "photos", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFolder("alice", "music", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFolder("alice", "videos", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+	_, err = s.vfs.CopyFolder("bob", "docs", "alice", "docs")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+
+	for _, filename := range []string{"file1", "file2"} {
+		_, err = s.vfs.CreateFile("alice", "photos", filename, "")
+		s.Require().NoError(err)
+	}
+	_, err = s.vfs.CreateFile("alice", "photos", "file3", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+	_, err = s.vfs.MoveFile("alice", "photos", "file1", "music", model.ConflictFail)
+	s.Require().NoError(err)
+	_, err = s.vfs.CopyFile("alice", "photos", "file2", "music", "file3", model.ConflictFail)
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFile("alice", "photos", "file4", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+
+	usage, err := s.vfs.As("alice").Usage("alice")
+	s.Require().NoError(err)
+	s.Require().Equal(2, usage.Folders)
+	s.Require().Equal(3, usage.Files)
+	s.Require().Equal(map[string]int{"music": 2, "photos": 1}, usage.FilesByFolder)
+	s.Require().Equal(3, usage.Quota.MaxFiles)
+
+	// the quota counts for the owner, whoever adds the files
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessWrite))
+	_, err = s.vfs.CreateFile("bob", "alice/photos", "file4", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+
+	user, err = admin.SetQuota("alice", &model.Quota{})
+	s.Require().NoError(err)
+	s.Require().Nil(user.Quota)
+	_, err = s.vfs.CreateFolder("alice", "videos", "")
+	s.Require().NoError(err)
+
+	// the folders of a group count for the group, whichever member adds them
+	_, err = s.vfs.CreateGroup("alice", "team")
+	s.Require().NoError(err)
+	_, err = s.vfs.AddMember("alice", "team", "bob")
+	s.Require().NoError(err)
+	_, err = s.vfs.SetQuota("group:team", &model.Quota{MaxFolders: 1, MaxFiles: 1})
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	user, err = admin.SetQuota("group:team", &model.Quota{MaxFolders: 1, MaxFiles: 1})
+	s.Require().NoError(err)
+	s.Require().Equal("group:team", user.Username)
+	_, err = s.vfs.CreateFolder("alice", "group:team/docs", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFolder("bob", "group:team/music", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+	_, err = s.vfs.CreateFile("bob", "group:team/docs", "file1", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateFile("alice", "group:team/docs", "file2", "")
+	s.Require().ErrorIs(err, vfs.ErrQuotaExceeded)
+
+	usage, err = s.vfs.Usage("group:team")
+	s.Require().NoError(err)
+	s.Require().Equal(1, usage.Folders)
+	s.Require().Equal(1, usage.Files)
+	s.Require().Equal(1, usage.Quota.MaxFiles)
+}
+
+func (s *suiteIntegration) Test_impl_Tags() {
+	for _, username := range []string{"alice", "bob"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	for _, foldername := range []string{"photos", "music", "docs"} {
+		_, err := s.vfs.CreateFolder("alice", foldername, "")
+		s.Require().NoError(err)
+	}
+	for _, filename := range []string{"beach", "forest"} {
+		_, err := s.vfs.CreateFile("alice", "photos", filename, "")
+		s.Require().NoError(err)
+	}
+
+	folder, err := s.vfs.TagFolder("alice", "photos", []string{"travel", "family"})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"family", "travel"}, folder.Tags)
+	_, err = s.vfs.TagFolder("alice", "music", []string{"family"})
+	s.Require().NoError(err)
+	_, err = s.vfs.TagFile("alice", "photos", "beach", []string{"travel"})
+	s.Require().NoError(err)
+	_, err = s.vfs.UntagFolder("alice", "docs", []string{"travel"})
+	s.Require().Error(err)
+
+	folders, err := s.vfs.ListTaggedFolders("alice", "family", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(folders, 2)
+	s.Require().Equal("music", folders[0].Name)
+	files, err := s.vfs.ListTaggedFiles("alice", "photos", "travel", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+	s.Require().Equal("beach", files[0].Name)
+
+	// the folders are tagged by their owners, the files by the users who can write them
+	_, err = s.vfs.As("bob").TagFolder("bob", "alice/photos", []string{"shared"})
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessRead))
+	_, err = s.vfs.As("bob").TagFile("bob", "alice/photos", "forest", []string{"travel"})
+	s.Require().ErrorIs(err, vfs.ErrPermissionDenied)
+	files, err = s.vfs.As("bob").ListTaggedFiles("bob", "alice/photos", "travel", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(files, 1)
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessWrite))
+	_, err = s.vfs.As("bob").TagFile("bob", "alice/photos", "forest", []string{"travel"})
+	s.Require().NoError(err)
+
+	tags, err := s.vfs.Tags("alice")
+	s.Require().NoError(err)
+	s.Require().Equal([]*model.TagCount{
+		{Tag: "family", Folders: 2, Files: 0},
+		{Tag: "travel", Folders: 1, Files: 2},
+	}, tags)
+
+	_, err = s.vfs.UntagFile("alice", "photos", "beach", []string{"travel"})
+	s.Require().NoError(err)
+	_, err = s.vfs.UntagFolder("alice", "photos", []string{"family", "travel"})
+	s.Require().NoError(err)
+	tags, err = s.vfs.Tags("alice")
+	s.Require().NoError(err)
+	s.Require().Equal([]*model.TagCount{
+		{Tag: "family", Folders: 1, Files: 0},
+		{Tag: "travel", Folders: 0, Files: 1},
+	}, tags)
+
+	// the tags are kept by the store
+	s.SetupTest()
+	folders, err = s.vfs.ListTaggedFolders("alice", "family", "name", "asc")
+	s.Require().NoError(err)
+	s.Require().Len(folders, 1)
+	s.Require().Equal("music", folders[0].Name)
+}
+
+func (s *suiteIntegration) Test_impl_Users() {
+	for _, username := range []string{"alice", "bob", "carol"} {
+		_, err := s.vfs.RegisterUser(username)
+		s.Require().NoError(err)
+	}
+	_, err := s.vfs.CreateFolder("alice", "photos", "")
+	s.Require().NoError(err)
+	s.Require().NoError(s.vfs.ShareFolder("alice", "photos", "bob", model.AccessWrite))
+	_, err = s.vfs.CreateFolder("bob", "docs", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.CreateGroup("alice", "team")
+	s.Require().NoError(err)
+	_, err = s.vfs.AddMember("alice", "team", "bob")
+	s.Require().NoError(err)
+
+	s.Require().ErrorIs(s.vfs.As("carol").DeleteUser("bob", true), vfs.ErrPermissionDenied)
+
+	// a failed rename leaves the memberships as they were
+	_, err = s.vfs.RenameUser("bob", "carol")
+	s.Require().Error(err)
+	groups, err := s.vfs.ListGroups("bob")
+	s.Require().NoError(err)
+	s.Require().Len(groups, 1)
+	s.Require().Equal([]string{"alice", "bob"}, groups[0].Members)
+
+	user, err := s.vfs.RenameUser("bob", "robert")
+	s.Require().NoError(err)
+	s.Require().Equal("robert", user.Username)
+	_, err = s.vfs.CreateFile("robert", "alice/photos", "file1", "")
+	s.Require().NoError(err)
+	_, err = s.vfs.ListFiles("robert", "docs", "name", "asc")
+	s.Require().NoError(err)
+	groups, err = s.vfs.ListGroups("robert")
+	s.Require().NoError(err)
+	s.Require().Len(groups, 1)
+	s.Require().Equal([]string{"alice", "robert"}, groups[0].Members)
+
+	users, err := s.vfs.ListUsers("desc", 0, 2)
+	s.Require().NoError(err)
+	s.Require().Len(users, 2)
+	s.Require().Equal("robert", users[0].Username)
+	_, err = s.vfs.ListUsers("asc", -1, 0)
+	s.Require().Error(err)
+
+	// a failed deletion leaves the memberships as they were
+	s.Require().Error(s.vfs.DeleteUser("robert", false))
+	groups, err = s.vfs.ListGroups("robert")
+	s.Require().NoError(err)
+	s.Require().Len(groups, 1)
+	s.Require().Equal([]string{"alice", "robert"}, groups[0].Members)
+
+	s.Require().NoError(s.vfs.DeleteUser("robert", true))
+	shares, err := s.vfs.ListShares("alice")
+	s.Require().NoError(err)
+	s.Require().Empty(shares)
+	groups, err = s.vfs.ListGroups("alice")
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"alice"}, groups[0].Members)
+
+	// the last member of a group stays
+	s.Require().Error(s.vfs.DeleteUser("alice", true))
+	s.Require().NoError(s.vfs.DeleteUser("carol", false))
+}
@@ -19,6 +19,7 @@ type suiteTester struct {
 	ctrl    *gomock.Controller
 	users   *repo.MockUserManager
 	folders *repo.MockFolderManager
+	groups  *repo.MockGroupManager
 	vfs     vfs.VirtualFileSystem
 }
 
@@ -26,7 +27,8 @@ func (s *suiteTester) SetupTest() {
 	s.ctrl = gomock.NewController(s.T())
 	s.users = repo.NewMockUserManager(s.ctrl)
 	s.folders = repo.NewMockFolderManager(s.ctrl)
-	s.vfs = New(s.users, s.folders)
+	s.groups = repo.NewMockGroupManager(s.ctrl)
+	s.vfs = New(s.users, s.folders, s.groups)
 }
 
 func (s *suiteTester) TearDownTest() {
@@ -761,3 +763,303 @@ func (s *suiteTester) Test_impl_ListFiles() {
 		})
 	}
 }
+
+func (s *suiteTester) Test_impl_MoveFile() {
+	user1, _ := model.NewUser("validUsername")
+	folder1, _ := model.NewFolder(user1, "validFoldername", "validDescription")
+	folder2, _ := model.NewFolder(user1, "newFoldername", "validDescription")
+	file1, _ := model.NewFile(user1, folder2, "validFilename", "validDescription")
+
+	type args struct {
+		username      string
+		foldername    string
+		filename      string
+		newFoldername string
+		conflict      model.Conflict
+		mock          func()
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantItem *model.File
+		wantErr  bool
+	}{
+		{
+			name: "move file to an existing folder",
+			args: args{
+				username:      "validUsername",
+				foldername:    "validFoldername",
+				filename:      "validFilename",
+				newFoldername: "newFoldername",
+				conflict:      model.ConflictOverwrite,
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), user1.Username).Return(user1, nil).Times(1)
+					s.folders.EXPECT().GetByName(gomock.Any(), user1, folder1.Name).Return(folder1, nil).Times(1)
+					s.folders.EXPECT().GetByName(gomock.Any(), user1, folder2.Name).Return(folder2, nil).Times(1)
+					s.folders.EXPECT().MoveFile(
+						gomock.Any(),
+						user1,
+						folder1,
+						file1.Name,
+						folder2,
+						model.ConflictOverwrite,
+					).Return(file1, nil).Times(1)
+				},
+			},
+			wantItem: file1,
+			wantErr:  false,
+		},
+		{
+			name: "move file to a missing folder",
+			args: args{
+				username:      "validUsername",
+				foldername:    "validFoldername",
+				filename:      "validFilename",
+				newFoldername: "missing",
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), user1.Username).Return(user1, nil).Times(1)
+					s.folders.EXPECT().GetByName(gomock.Any(), user1, folder1.Name).Return(folder1, nil).Times(1)
+					s.folders.EXPECT().GetByName(
+						gomock.Any(),
+						user1,
+						"missing",
+					).Return(nil, errors.New("the missing doesn't exist")).Times(1)
+				},
+			},
+			wantItem: nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			if tt.args.mock != nil {
+				tt.args.mock()
+			}
+
+			gotItem, err := s.vfs.MoveFile(
+				tt.args.username,
+				tt.args.foldername,
+				tt.args.filename,
+				tt.args.newFoldername,
+				tt.args.conflict,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MoveFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotItem, tt.wantItem) {
+				t.Errorf("MoveFile() gotItem = %v, want %v", gotItem, tt.wantItem)
+			}
+		})
+	}
+}
+
+func (s *suiteTester) Test_impl_DeleteUser() {
+	team := &model.Group{Name: "team", Members: []string{"alice", "bob"}}
+	bob := &model.User{Username: "bob"}
+
+	type args struct {
+		mock func()
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "leave the groups before the deletion",
+			args: args{
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().List(gomock.Any(), bob).Return([]*model.Group{team}, nil).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", bob).Return(team, nil).Times(1)
+					s.users.EXPECT().Delete(gomock.Any(), "bob", true).Return(nil).Times(1)
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "join the groups back when the deletion fails",
+			args: args{
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().List(gomock.Any(), bob).Return([]*model.Group{team}, nil).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", bob).Return(team, nil).Times(1)
+					s.users.EXPECT().Delete(gomock.Any(), "bob", true).Return(errors.New("error")).Times(1)
+					s.groups.EXPECT().AddMember(gomock.Any(), "team", bob).Return(team, nil).Times(1)
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			if tt.args.mock != nil {
+				tt.args.mock()
+			}
+
+			err := s.vfs.DeleteUser("bob", true)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func (s *suiteTester) Test_impl_RenameUser() {
+	team := &model.Group{Name: "team", Members: []string{"alice", "bob"}}
+	bob := &model.User{Username: "bob"}
+	robert := &model.User{Username: "robert"}
+
+	type args struct {
+		mock func()
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "move the memberships with the user",
+			args: args{
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().List(gomock.Any(), bob).Return([]*model.Group{team}, nil).Times(1)
+					s.groups.EXPECT().AddMember(gomock.Any(), "team", robert).Return(team, nil).Times(1)
+					s.users.EXPECT().Rename(gomock.Any(), "bob", "robert").Return(robert, nil).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", bob).Return(team, nil).Times(1)
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "leave the memberships when the rename fails",
+			args: args{
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().List(gomock.Any(), bob).Return([]*model.Group{team}, nil).Times(1)
+					s.groups.EXPECT().AddMember(gomock.Any(), "team", robert).Return(team, nil).Times(1)
+					s.users.EXPECT().Rename(gomock.Any(), "bob", "robert").Return(nil, errors.New("error")).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", robert).Return(team, nil).Times(1)
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "undo the rename when the old membership can't be removed",
+			args: args{
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().List(gomock.Any(), bob).Return([]*model.Group{team}, nil).Times(1)
+					s.groups.EXPECT().AddMember(gomock.Any(), "team", robert).Return(team, nil).Times(1)
+					s.users.EXPECT().Rename(gomock.Any(), "bob", "robert").Return(robert, nil).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", bob).Return(nil, errors.New("error")).Times(1)
+					s.users.EXPECT().Rename(gomock.Any(), "robert", "bob").Return(bob, nil).Times(1)
+					s.groups.EXPECT().RemoveMember(gomock.Any(), "team", robert).Return(team, nil).Times(1)
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			if tt.args.mock != nil {
+				tt.args.mock()
+			}
+
+			_, err := s.vfs.RenameUser("bob", "robert")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RenameUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
+
+func (s *suiteTester) Test_impl_GrantRole() {
+	admin := &model.User{Username: "admin", Role: model.RoleAdmin}
+	member, _ := model.NewUser("member")
+
+	type args struct {
+		actor    string
+		username string
+		role     model.Role
+		mock     func()
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "grant the first admin without an actor",
+			args: args{
+				username: "member",
+				role:     model.RoleAdmin,
+				mock: func() {
+					s.users.EXPECT().List(gomock.Any(), "", 0, 0).Return([]*model.User{member}, nil).Times(1)
+					s.users.EXPECT().SetRole(gomock.Any(), "member", model.RoleAdmin).Return(member, nil).Times(1)
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "grant admin without an actor once there is an admin",
+			args: args{
+				username: "member",
+				role:     model.RoleAdmin,
+				mock: func() {
+					s.users.EXPECT().List(gomock.Any(), "", 0, 0).Return([]*model.User{admin, member}, nil).Times(1)
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "grant another role without an actor",
+			args: args{
+				username: "member",
+				role:     model.RoleMember,
+			},
+			wantErr: true,
+		},
+		{
+			name: "grant role as an admin",
+			args: args{
+				actor:    "admin",
+				username: "member",
+				role:     model.RoleReadonly,
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "admin").Return(admin, nil).Times(1)
+					s.users.EXPECT().SetRole(gomock.Any(), "member", model.RoleReadonly).Return(member, nil).Times(1)
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "grant role as a member",
+			args: args{
+				actor:    "member",
+				username: "member",
+				role:     model.RoleAdmin,
+				mock: func() {
+					s.users.EXPECT().GetByUsername(gomock.Any(), "member").Return(member, nil).Times(1)
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			if tt.args.mock != nil {
+				tt.args.mock()
+			}
+
+			_, err := s.vfs.As(tt.args.actor).GrantRole(tt.args.username, tt.args.role)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GrantRole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package vfs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+)
+
+// commandScopes are the scopes a token needs for each command. The ones missing, like managing the tokens or
+// the passwords, can't be run with a token at all.
+var commandScopes = map[string][]model.Scope{
+	"register":       {model.ScopeUsersWrite},
+	"delete-user":    {model.ScopeUsersWrite},
+	"rename-user":    {model.ScopeUsersWrite},
+	"list-users":     {model.ScopeUsersRead},
+	"grant-role":     {model.ScopeUsersWrite},
+	"revoke-role":    {model.ScopeUsersWrite},
+	"set-quota":      {model.ScopeUsersWrite},
+	"usage":          {model.ScopeUsersRead},
+	"create-folder":  {model.ScopeFoldersWrite},
+	"delete-folder":  {model.ScopeFoldersWrite},
+	"list-folders":   {model.ScopeFoldersRead},
+	"rename-folder":  {model.ScopeFoldersWrite},
+	"restore-folder": {model.ScopeFoldersWrite, model.ScopeFilesWrite},
+	"copy-folder":    {model.ScopeFoldersWrite},
+	"move-folder":    {model.ScopeFoldersWrite},
+	"share-folder":   {model.ScopeSharesWrite},
+	"unshare-folder": {model.ScopeSharesWrite},
+	"list-shares":    {model.ScopeSharesRead},
+	"tag-folder":     {model.ScopeFoldersWrite},
+	"untag-folder":   {model.ScopeFoldersWrite},
+	"tags":           {model.ScopeFoldersRead, model.ScopeFilesRead},
+	"create-group":   {model.ScopeGroupsWrite},
+	"add-member":     {model.ScopeGroupsWrite},
+	"remove-member":  {model.ScopeGroupsWrite},
+	"list-groups":    {model.ScopeGroupsRead},
+	"create-file":    {model.ScopeFilesWrite},
+	"delete-file":    {model.ScopeFilesWrite},
+	"list-files":     {model.ScopeFilesRead},
+	"rename-file":    {model.ScopeFilesWrite},
+	"move-file":      {model.ScopeFilesWrite},
+	"copy-file":      {model.ScopeFilesWrite},
+	"tag-file":       {model.ScopeFilesWrite},
+	"untag-file":     {model.ScopeFilesWrite},
+}
+
+// NewWithScopes is used to wrap next so that every call needs the scopes of a token, failing with
+// ErrPermissionDenied otherwise.
+func NewWithScopes(next vfs.VirtualFileSystem, scopes []model.Scope) vfs.VirtualFileSystem {
+	return NewGuarded(next, func(command string, _ bool) error {
+		needs, exists := commandScopes[command]
+		if !exists {
+			return fmt.Errorf("%w: %s can't be run with a token", vfs.ErrPermissionDenied, command)
+		}
+
+		var missing []string
+		for _, need := range needs {
+			if !model.Grants(scopes, need) {
+				missing = append(missing, string(need))
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf(
+				"%w: %s needs the %s scope",
+				vfs.ErrPermissionDenied,
+				command,
+				strings.Join(missing, ","),
+			)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package vfs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/blackhorseya/iscool-assessment/entity/model"
+	"github.com/blackhorseya/iscool-assessment/pkg/vfs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewWithScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	next := vfs.NewMockVirtualFileSystem(ctrl)
+	g := NewWithScopes(next, []model.Scope{model.ScopeFoldersRead, model.ScopeFilesWrite})
+
+	next.EXPECT().ListFolders("alice", "name", "asc").Return(nil, nil)
+	next.EXPECT().ListFiles("alice", "photos", "name", "asc").Return(nil, nil)
+	next.EXPECT().CreateFile("alice", "photos", "file1", "").Return(&model.File{Name: "file1"}, nil)
+
+	_, err := g.ListFolders("alice", "name", "asc")
+	if err != nil {
+		t.Errorf("ListFolders() with folders:read error = %v", err)
+	}
+	_, err = g.ListFiles("alice", "photos", "name", "asc")
+	if err != nil {
+		t.Errorf("ListFiles() with files:write error = %v", err)
+	}
+	_, err = g.CreateFile("alice", "photos", "file1", "")
+	if err != nil {
+		t.Errorf("CreateFile() with files:write error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "create folder with folders:read", call: func() error {
+			_, err := g.CreateFolder("alice", "docs", "")
+			return err
+		}},
+		{name: "import without folders:write", call: func() error {
+			_, err := g.RestoreFolder("alice", &model.Folder{Name: "docs"})
+			return err
+		}},
+		{name: "list shares without a shares scope", call: func() error {
+			_, err := g.ListShares("alice")
+			return err
+		}},
+		{name: "create token", call: func() error {
+			_, _, err := g.CreateToken("alice", []model.Scope{model.ScopeUsersWrite}, 0)
+			return err
+		}},
+		{name: "set password", call: func() error {
+			_, err := g.SetPassword("alice", "secret")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, vfs.ErrPermissionDenied) {
+				t.Errorf("error = %v, want %v", err, vfs.ErrPermissionDenied)
+			}
+		})
+	}
+}
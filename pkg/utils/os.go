@@ -13,11 +13,27 @@ func CheckPathType(path string) string {
 		return "error"
 	}
 
+	if strings.HasPrefix(path, "s3://") {
+		return "s3"
+	}
+
+	if strings.HasPrefix(path, "redis://") || strings.HasPrefix(path, "rediss://") {
+		return "redis"
+	}
+
+	if strings.HasSuffix(strings.TrimRight(path, "/"), ".shards") {
+		return "sharded"
+	}
+
+	if strings.HasSuffix(strings.TrimRight(path, "/"), ".git") {
+		return "git"
+	}
+
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			if strings.HasSuffix(path, ".json") {
-				return "json"
+			if pathType := documentType(path); pathType != "" {
+				return pathType
 			}
 
 			return "folder"
@@ -26,16 +42,54 @@ func CheckPathType(path string) string {
 	}
 
 	if fileInfo.IsDir() {
+		// a directory holding a git repository is a git-backed store
+		if _, err = os.Stat(filepath.Join(path, ".git")); err == nil {
+			return "git"
+		}
+
 		return "folder"
 	}
 
-	if strings.HasSuffix(path, ".json") {
-		return "json"
+	if pathType := documentType(path); pathType != "" {
+		return pathType
 	}
 
 	return "file"
 }
 
+// documentTypes maps the extensions of single-document stores to their path type
+var documentTypes = map[string]string{
+	".json":    "json",
+	".gob":     "gob",
+	".msgpack": "msgpack",
+	".mpk":     "msgpack",
+	".pb":      "protobuf",
+	".yaml":    "yaml",
+	".yml":     "yaml",
+	".toml":    "toml",
+}
+
+// documentType returns the path type of a single-document store, optionally compressed with gzip or zstd,
+// or an empty string if the path isn't one
+func documentType(path string) string {
+	for _, ext := range []string{".gz", ".zst"} {
+		path = strings.TrimSuffix(path, ext)
+	}
+
+	return documentTypes[filepath.Ext(path)]
+}
+
+// IsDocument checks if the path type returned by CheckPathType is a single-document store
+func IsDocument(pathType string) bool {
+	for _, documentType := range documentTypes {
+		if pathType == documentType {
+			return true
+		}
+	}
+
+	return false
+}
+
 // EnsureDir checks if the directory for the file exists and creates it if not
 func EnsureDir(fileName string) error {
 	if fileName == "" {
@@ -44,6 +44,98 @@ func TestCheckPathType(t *testing.T) {
 				_ = os.MkdirAll("out/existing_folder", 0755)
 			},
 		},
+		{
+			name: "Non-existing gzip json file",
+			path: "non_existing_file.json.gz",
+			want: "json",
+		},
+		{
+			name: "Existing zstd json file",
+			path: "out/existing_file.json.zst",
+			want: "json",
+			mock: func() {
+				_ = os.MkdirAll("out", 0755)
+				_, _ = os.Create("out/existing_file.json.zst")
+			},
+		},
+		{
+			name: "Existing gzip non-json file",
+			path: "out/existing_file.txt.gz",
+			want: "file",
+			mock: func() {
+				_ = os.MkdirAll("out", 0755)
+				_, _ = os.Create("out/existing_file.txt.gz")
+			},
+		},
+		{
+			name: "Non-existing gob file",
+			path: "non_existing_file.gob",
+			want: "gob",
+		},
+		{
+			name: "Non-existing compressed msgpack file",
+			path: "non_existing_file.msgpack.zst",
+			want: "msgpack",
+		},
+		{
+			name: "Existing protobuf file",
+			path: "out/existing_file.pb",
+			want: "protobuf",
+			mock: func() {
+				_ = os.MkdirAll("out", 0755)
+				_, _ = os.Create("out/existing_file.pb")
+			},
+		},
+		{
+			name: "Non-existing yaml file",
+			path: "non_existing_file.yml",
+			want: "yaml",
+		},
+		{
+			name: "Existing toml file",
+			path: "out/existing_file.toml",
+			want: "toml",
+			mock: func() {
+				_ = os.MkdirAll("out", 0755)
+				_, _ = os.Create("out/existing_file.toml")
+			},
+		},
+		{
+			name: "Non-existing sharded store",
+			path: "non_existing.shards",
+			want: "sharded",
+		},
+		{
+			name: "Existing sharded store",
+			path: "out/existing.shards/",
+			want: "sharded",
+			mock: func() {
+				_ = os.MkdirAll("out/existing.shards", 0755)
+			},
+		},
+		{
+			name: "S3 bucket",
+			path: "s3://bucket/prefix",
+			want: "s3",
+		},
+		{
+			name: "Redis server",
+			path: "redis://localhost:6379/0",
+			want: "redis",
+		},
+		{
+			name: "Non-existing git-backed store",
+			path: "non_existing.git",
+			want: "git",
+		},
+		{
+			name: "Existing git repository",
+			path: "out/repository",
+			want: "git",
+			mock: func() {
+				_ = os.MkdirAll("out/repository/.git", 0755)
+			},
+		},
 		{
 			name: "Non-existing non-json file",
 			path: "non_existing_file.txt",
@@ -65,6 +157,30 @@ func TestCheckPathType(t *testing.T) {
 	_ = os.RemoveAll("out")
 }
 
+func TestIsDocument(t *testing.T) {
+	tests := []struct {
+		pathType string
+		want     bool
+	}{
+		{pathType: "json", want: true},
+		{pathType: "gob", want: true},
+		{pathType: "msgpack", want: true},
+		{pathType: "protobuf", want: true},
+		{pathType: "yaml", want: true},
+		{pathType: "toml", want: true},
+		{pathType: "sharded", want: false},
+		{pathType: "folder", want: false},
+		{pathType: "error", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pathType, func(t *testing.T) {
+			if got := IsDocument(tt.pathType); got != tt.want {
+				t.Errorf("IsDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestEnsureDir(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,14 @@
+package vfs
+
+import (
+	"errors"
+)
+
+// ErrPermissionDenied is returned when a user works on a folder of another user without the access it needs.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ErrQuotaExceeded is returned when a change adds more folders or files than the quota of their owner allows.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// ErrUnauthenticated is returned when a user can't log in, or when its session isn't valid.
+var ErrUnauthenticated = errors.New("unauthenticated")
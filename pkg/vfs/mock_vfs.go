@@ -6,6 +6,7 @@ package vfs
 
 import (
 	reflect "reflect"
+	time "time"
 
 	model "github.com/blackhorseya/iscool-assessment/entity/model"
 	gomock "go.uber.org/mock/gomock"
@@ -34,6 +35,96 @@ func (m *MockVirtualFileSystem) EXPECT() *MockVirtualFileSystemMockRecorder {
 	return m.recorder
 }
 
+// AddMember mocks base method.
+func (m *MockVirtualFileSystem) AddMember(username, name, member string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMember", username, name, member)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMember indicates an expected call of AddMember.
+func (mr *MockVirtualFileSystemMockRecorder) AddMember(username, name, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMember", reflect.TypeOf((*MockVirtualFileSystem)(nil).AddMember), username, name, member)
+}
+
+// As mocks base method.
+func (m *MockVirtualFileSystem) As(actor string) VirtualFileSystem {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "As", actor)
+	ret0, _ := ret[0].(VirtualFileSystem)
+	return ret0
+}
+
+// As indicates an expected call of As.
+func (mr *MockVirtualFileSystemMockRecorder) As(actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "As", reflect.TypeOf((*MockVirtualFileSystem)(nil).As), actor)
+}
+
+// Authenticate mocks base method.
+func (m *MockVirtualFileSystem) Authenticate(username, token string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", username, token)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockVirtualFileSystemMockRecorder) Authenticate(username, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockVirtualFileSystem)(nil).Authenticate), username, token)
+}
+
+// AuthenticateToken mocks base method.
+func (m *MockVirtualFileSystem) AuthenticateToken(token string) (*model.User, *model.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateToken", token)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(*model.Token)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthenticateToken indicates an expected call of AuthenticateToken.
+func (mr *MockVirtualFileSystemMockRecorder) AuthenticateToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateToken", reflect.TypeOf((*MockVirtualFileSystem)(nil).AuthenticateToken), token)
+}
+
+// CopyFile mocks base method.
+func (m *MockVirtualFileSystem) CopyFile(username, foldername, filename, newFoldername, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFile", username, foldername, filename, newFoldername, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFile indicates an expected call of CopyFile.
+func (mr *MockVirtualFileSystemMockRecorder) CopyFile(username, foldername, filename, newFoldername, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockVirtualFileSystem)(nil).CopyFile), username, foldername, filename, newFoldername, newFilename, conflict)
+}
+
+// CopyFolder mocks base method.
+func (m *MockVirtualFileSystem) CopyFolder(username, foldername, newUsername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFolder", username, foldername, newUsername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFolder indicates an expected call of CopyFolder.
+func (mr *MockVirtualFileSystemMockRecorder) CopyFolder(username, foldername, newUsername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).CopyFolder), username, foldername, newUsername, newFoldername)
+}
+
 // CreateFile mocks base method.
 func (m *MockVirtualFileSystem) CreateFile(username, foldername, filename, description string) (*model.File, error) {
 	m.ctrl.T.Helper()
@@ -64,6 +155,37 @@ func (mr *MockVirtualFileSystemMockRecorder) CreateFolder(username, foldername,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).CreateFolder), username, foldername, description)
 }
 
+// CreateGroup mocks base method.
+func (m *MockVirtualFileSystem) CreateGroup(username, name string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGroup", username, name)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGroup indicates an expected call of CreateGroup.
+func (mr *MockVirtualFileSystemMockRecorder) CreateGroup(username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGroup", reflect.TypeOf((*MockVirtualFileSystem)(nil).CreateGroup), username, name)
+}
+
+// CreateToken mocks base method.
+func (m *MockVirtualFileSystem) CreateToken(username string, scopes []model.Scope, ttl time.Duration) (string, *model.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", username, scopes, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(*model.Token)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateToken indicates an expected call of CreateToken.
+func (mr *MockVirtualFileSystemMockRecorder) CreateToken(username, scopes, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockVirtualFileSystem)(nil).CreateToken), username, scopes, ttl)
+}
+
 // DeleteFile mocks base method.
 func (m *MockVirtualFileSystem) DeleteFile(username, foldername, filename string) error {
 	m.ctrl.T.Helper()
@@ -92,6 +214,35 @@ func (mr *MockVirtualFileSystemMockRecorder) DeleteFolder(username, foldername i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).DeleteFolder), username, foldername)
 }
 
+// DeleteUser mocks base method.
+func (m *MockVirtualFileSystem) DeleteUser(username string, cascade bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", username, cascade)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockVirtualFileSystemMockRecorder) DeleteUser(username, cascade interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockVirtualFileSystem)(nil).DeleteUser), username, cascade)
+}
+
+// GrantRole mocks base method.
+func (m *MockVirtualFileSystem) GrantRole(username string, role model.Role) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", username, role)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockVirtualFileSystemMockRecorder) GrantRole(username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockVirtualFileSystem)(nil).GrantRole), username, role)
+}
+
 // ListFiles mocks base method.
 func (m *MockVirtualFileSystem) ListFiles(username, foldername, sortBy, order string) ([]*model.File, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +273,155 @@ func (mr *MockVirtualFileSystemMockRecorder) ListFolders(username, sortBy, order
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolders", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListFolders), username, sortBy, order)
 }
 
+// ListGroups mocks base method.
+func (m *MockVirtualFileSystem) ListGroups(username string) ([]*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroups", username)
+	ret0, _ := ret[0].([]*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListGroups indicates an expected call of ListGroups.
+func (mr *MockVirtualFileSystemMockRecorder) ListGroups(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListGroups), username)
+}
+
+// ListShares mocks base method.
+func (m *MockVirtualFileSystem) ListShares(username string) ([]*model.Share, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListShares", username)
+	ret0, _ := ret[0].([]*model.Share)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListShares indicates an expected call of ListShares.
+func (mr *MockVirtualFileSystemMockRecorder) ListShares(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListShares", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListShares), username)
+}
+
+// ListTaggedFiles mocks base method.
+func (m *MockVirtualFileSystem) ListTaggedFiles(username, foldername, tag, sortBy, order string) ([]*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaggedFiles", username, foldername, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaggedFiles indicates an expected call of ListTaggedFiles.
+func (mr *MockVirtualFileSystemMockRecorder) ListTaggedFiles(username, foldername, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaggedFiles", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListTaggedFiles), username, foldername, tag, sortBy, order)
+}
+
+// ListTaggedFolders mocks base method.
+func (m *MockVirtualFileSystem) ListTaggedFolders(username, tag, sortBy, order string) ([]*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTaggedFolders", username, tag, sortBy, order)
+	ret0, _ := ret[0].([]*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTaggedFolders indicates an expected call of ListTaggedFolders.
+func (mr *MockVirtualFileSystemMockRecorder) ListTaggedFolders(username, tag, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTaggedFolders", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListTaggedFolders), username, tag, sortBy, order)
+}
+
+// ListTokens mocks base method.
+func (m *MockVirtualFileSystem) ListTokens(username string) ([]*model.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTokens", username)
+	ret0, _ := ret[0].([]*model.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTokens indicates an expected call of ListTokens.
+func (mr *MockVirtualFileSystemMockRecorder) ListTokens(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTokens", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListTokens), username)
+}
+
+// ListUsers mocks base method.
+func (m *MockVirtualFileSystem) ListUsers(order string, offset, limit int) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", order, offset, limit)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockVirtualFileSystemMockRecorder) ListUsers(order, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockVirtualFileSystem)(nil).ListUsers), order, offset, limit)
+}
+
+// Login mocks base method.
+func (m *MockVirtualFileSystem) Login(username, password string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", username, password, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockVirtualFileSystemMockRecorder) Login(username, password, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockVirtualFileSystem)(nil).Login), username, password, ttl)
+}
+
+// Logout mocks base method.
+func (m *MockVirtualFileSystem) Logout(username, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", username, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockVirtualFileSystemMockRecorder) Logout(username, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockVirtualFileSystem)(nil).Logout), username, token)
+}
+
+// MoveFile mocks base method.
+func (m *MockVirtualFileSystem) MoveFile(username, foldername, filename, newFoldername string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", username, foldername, filename, newFoldername, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockVirtualFileSystemMockRecorder) MoveFile(username, foldername, filename, newFoldername, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockVirtualFileSystem)(nil).MoveFile), username, foldername, filename, newFoldername, conflict)
+}
+
+// MoveFolder mocks base method.
+func (m *MockVirtualFileSystem) MoveFolder(username, foldername, newUsername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", username, foldername, newUsername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFolder indicates an expected call of MoveFolder.
+func (mr *MockVirtualFileSystemMockRecorder) MoveFolder(username, foldername, newUsername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).MoveFolder), username, foldername, newUsername, newFoldername)
+}
+
 // RegisterUser mocks base method.
 func (m *MockVirtualFileSystem) RegisterUser(username string) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -137,6 +437,36 @@ func (mr *MockVirtualFileSystemMockRecorder) RegisterUser(username interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockVirtualFileSystem)(nil).RegisterUser), username)
 }
 
+// RemoveMember mocks base method.
+func (m *MockVirtualFileSystem) RemoveMember(username, name, member string) (*model.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", username, name, member)
+	ret0, _ := ret[0].(*model.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockVirtualFileSystemMockRecorder) RemoveMember(username, name, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockVirtualFileSystem)(nil).RemoveMember), username, name, member)
+}
+
+// RenameFile mocks base method.
+func (m *MockVirtualFileSystem) RenameFile(username, foldername, filename, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFile", username, foldername, filename, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFile indicates an expected call of RenameFile.
+func (mr *MockVirtualFileSystemMockRecorder) RenameFile(username, foldername, filename, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFile", reflect.TypeOf((*MockVirtualFileSystem)(nil).RenameFile), username, foldername, filename, newFilename, conflict)
+}
+
 // RenameFolder mocks base method.
 func (m *MockVirtualFileSystem) RenameFolder(username, foldername, newFoldername string) (*model.Folder, error) {
 	m.ctrl.T.Helper()
@@ -151,3 +481,440 @@ func (mr *MockVirtualFileSystemMockRecorder) RenameFolder(username, foldername,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).RenameFolder), username, foldername, newFoldername)
 }
+
+// RenameUser mocks base method.
+func (m *MockVirtualFileSystem) RenameUser(username, newUsername string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", username, newUsername)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockVirtualFileSystemMockRecorder) RenameUser(username, newUsername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockVirtualFileSystem)(nil).RenameUser), username, newUsername)
+}
+
+// RestoreFolder mocks base method.
+func (m *MockVirtualFileSystem) RestoreFolder(username string, folder *model.Folder) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFolder", username, folder)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFolder indicates an expected call of RestoreFolder.
+func (mr *MockVirtualFileSystemMockRecorder) RestoreFolder(username, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).RestoreFolder), username, folder)
+}
+
+// RevokeRole mocks base method.
+func (m *MockVirtualFileSystem) RevokeRole(username string, role model.Role) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", username, role)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockVirtualFileSystemMockRecorder) RevokeRole(username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockVirtualFileSystem)(nil).RevokeRole), username, role)
+}
+
+// RevokeToken mocks base method.
+func (m *MockVirtualFileSystem) RevokeToken(username, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", username, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockVirtualFileSystemMockRecorder) RevokeToken(username, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockVirtualFileSystem)(nil).RevokeToken), username, id)
+}
+
+// SetPassword mocks base method.
+func (m *MockVirtualFileSystem) SetPassword(username, password string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", username, password)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockVirtualFileSystemMockRecorder) SetPassword(username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockVirtualFileSystem)(nil).SetPassword), username, password)
+}
+
+// SetQuota mocks base method.
+func (m *MockVirtualFileSystem) SetQuota(username string, quota *model.Quota) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuota", username, quota)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQuota indicates an expected call of SetQuota.
+func (mr *MockVirtualFileSystemMockRecorder) SetQuota(username, quota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuota", reflect.TypeOf((*MockVirtualFileSystem)(nil).SetQuota), username, quota)
+}
+
+// ShareFolder mocks base method.
+func (m *MockVirtualFileSystem) ShareFolder(username, foldername, grantee string, access model.Access) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShareFolder", username, foldername, grantee, access)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ShareFolder indicates an expected call of ShareFolder.
+func (mr *MockVirtualFileSystemMockRecorder) ShareFolder(username, foldername, grantee, access interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShareFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).ShareFolder), username, foldername, grantee, access)
+}
+
+// TagFile mocks base method.
+func (m *MockVirtualFileSystem) TagFile(username, foldername, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFile", username, foldername, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFile indicates an expected call of TagFile.
+func (mr *MockVirtualFileSystemMockRecorder) TagFile(username, foldername, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFile", reflect.TypeOf((*MockVirtualFileSystem)(nil).TagFile), username, foldername, filename, tags)
+}
+
+// TagFolder mocks base method.
+func (m *MockVirtualFileSystem) TagFolder(username, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagFolder", username, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TagFolder indicates an expected call of TagFolder.
+func (mr *MockVirtualFileSystemMockRecorder) TagFolder(username, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).TagFolder), username, foldername, tags)
+}
+
+// Tags mocks base method.
+func (m *MockVirtualFileSystem) Tags(username string) ([]*model.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tags", username)
+	ret0, _ := ret[0].([]*model.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tags indicates an expected call of Tags.
+func (mr *MockVirtualFileSystemMockRecorder) Tags(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tags", reflect.TypeOf((*MockVirtualFileSystem)(nil).Tags), username)
+}
+
+// Tx mocks base method.
+func (m *MockVirtualFileSystem) Tx(fn func(Tx) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tx", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Tx indicates an expected call of Tx.
+func (mr *MockVirtualFileSystemMockRecorder) Tx(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tx", reflect.TypeOf((*MockVirtualFileSystem)(nil).Tx), fn)
+}
+
+// UnshareFolder mocks base method.
+func (m *MockVirtualFileSystem) UnshareFolder(username, foldername, grantee string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnshareFolder", username, foldername, grantee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnshareFolder indicates an expected call of UnshareFolder.
+func (mr *MockVirtualFileSystemMockRecorder) UnshareFolder(username, foldername, grantee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnshareFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).UnshareFolder), username, foldername, grantee)
+}
+
+// UntagFile mocks base method.
+func (m *MockVirtualFileSystem) UntagFile(username, foldername, filename string, tags []string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFile", username, foldername, filename, tags)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFile indicates an expected call of UntagFile.
+func (mr *MockVirtualFileSystemMockRecorder) UntagFile(username, foldername, filename, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFile", reflect.TypeOf((*MockVirtualFileSystem)(nil).UntagFile), username, foldername, filename, tags)
+}
+
+// UntagFolder mocks base method.
+func (m *MockVirtualFileSystem) UntagFolder(username, foldername string, tags []string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UntagFolder", username, foldername, tags)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UntagFolder indicates an expected call of UntagFolder.
+func (mr *MockVirtualFileSystemMockRecorder) UntagFolder(username, foldername, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UntagFolder", reflect.TypeOf((*MockVirtualFileSystem)(nil).UntagFolder), username, foldername, tags)
+}
+
+// Usage mocks base method.
+func (m *MockVirtualFileSystem) Usage(username string) (*model.Usage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage", username)
+	ret0, _ := ret[0].(*model.Usage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockVirtualFileSystemMockRecorder) Usage(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockVirtualFileSystem)(nil).Usage), username)
+}
+
+// MockTx is a mock of Tx interface.
+type MockTx struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxMockRecorder
+}
+
+// MockTxMockRecorder is the mock recorder for MockTx.
+type MockTxMockRecorder struct {
+	mock *MockTx
+}
+
+// NewMockTx creates a new mock instance.
+func NewMockTx(ctrl *gomock.Controller) *MockTx {
+	mock := &MockTx{ctrl: ctrl}
+	mock.recorder = &MockTxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTx) EXPECT() *MockTxMockRecorder {
+	return m.recorder
+}
+
+// CopyFile mocks base method.
+func (m *MockTx) CopyFile(username, foldername, filename, newFoldername, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFile", username, foldername, filename, newFoldername, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFile indicates an expected call of CopyFile.
+func (mr *MockTxMockRecorder) CopyFile(username, foldername, filename, newFoldername, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFile", reflect.TypeOf((*MockTx)(nil).CopyFile), username, foldername, filename, newFoldername, newFilename, conflict)
+}
+
+// CopyFolder mocks base method.
+func (m *MockTx) CopyFolder(username, foldername, newUsername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyFolder", username, foldername, newUsername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CopyFolder indicates an expected call of CopyFolder.
+func (mr *MockTxMockRecorder) CopyFolder(username, foldername, newUsername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyFolder", reflect.TypeOf((*MockTx)(nil).CopyFolder), username, foldername, newUsername, newFoldername)
+}
+
+// CreateFile mocks base method.
+func (m *MockTx) CreateFile(username, foldername, filename, description string) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFile", username, foldername, filename, description)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFile indicates an expected call of CreateFile.
+func (mr *MockTxMockRecorder) CreateFile(username, foldername, filename, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFile", reflect.TypeOf((*MockTx)(nil).CreateFile), username, foldername, filename, description)
+}
+
+// CreateFolder mocks base method.
+func (m *MockTx) CreateFolder(username, foldername, description string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFolder", username, foldername, description)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFolder indicates an expected call of CreateFolder.
+func (mr *MockTxMockRecorder) CreateFolder(username, foldername, description interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFolder", reflect.TypeOf((*MockTx)(nil).CreateFolder), username, foldername, description)
+}
+
+// DeleteFile mocks base method.
+func (m *MockTx) DeleteFile(username, foldername, filename string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFile", username, foldername, filename)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFile indicates an expected call of DeleteFile.
+func (mr *MockTxMockRecorder) DeleteFile(username, foldername, filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockTx)(nil).DeleteFile), username, foldername, filename)
+}
+
+// DeleteFolder mocks base method.
+func (m *MockTx) DeleteFolder(username, foldername string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFolder", username, foldername)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFolder indicates an expected call of DeleteFolder.
+func (mr *MockTxMockRecorder) DeleteFolder(username, foldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFolder", reflect.TypeOf((*MockTx)(nil).DeleteFolder), username, foldername)
+}
+
+// ListFiles mocks base method.
+func (m *MockTx) ListFiles(username, foldername, sortBy, order string) ([]*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", username, foldername, sortBy, order)
+	ret0, _ := ret[0].([]*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFiles indicates an expected call of ListFiles.
+func (mr *MockTxMockRecorder) ListFiles(username, foldername, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockTx)(nil).ListFiles), username, foldername, sortBy, order)
+}
+
+// ListFolders mocks base method.
+func (m *MockTx) ListFolders(username, sortBy, order string) ([]*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFolders", username, sortBy, order)
+	ret0, _ := ret[0].([]*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFolders indicates an expected call of ListFolders.
+func (mr *MockTxMockRecorder) ListFolders(username, sortBy, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFolders", reflect.TypeOf((*MockTx)(nil).ListFolders), username, sortBy, order)
+}
+
+// MoveFile mocks base method.
+func (m *MockTx) MoveFile(username, foldername, filename, newFoldername string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", username, foldername, filename, newFoldername, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockTxMockRecorder) MoveFile(username, foldername, filename, newFoldername, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockTx)(nil).MoveFile), username, foldername, filename, newFoldername, conflict)
+}
+
+// MoveFolder mocks base method.
+func (m *MockTx) MoveFolder(username, foldername, newUsername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFolder", username, foldername, newUsername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveFolder indicates an expected call of MoveFolder.
+func (mr *MockTxMockRecorder) MoveFolder(username, foldername, newUsername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFolder", reflect.TypeOf((*MockTx)(nil).MoveFolder), username, foldername, newUsername, newFoldername)
+}
+
+// RenameFile mocks base method.
+func (m *MockTx) RenameFile(username, foldername, filename, newFilename string, conflict model.Conflict) (*model.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFile", username, foldername, filename, newFilename, conflict)
+	ret0, _ := ret[0].(*model.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFile indicates an expected call of RenameFile.
+func (mr *MockTxMockRecorder) RenameFile(username, foldername, filename, newFilename, conflict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFile", reflect.TypeOf((*MockTx)(nil).RenameFile), username, foldername, filename, newFilename, conflict)
+}
+
+// RenameFolder mocks base method.
+func (m *MockTx) RenameFolder(username, foldername, newFoldername string) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameFolder", username, foldername, newFoldername)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenameFolder indicates an expected call of RenameFolder.
+func (mr *MockTxMockRecorder) RenameFolder(username, foldername, newFoldername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameFolder", reflect.TypeOf((*MockTx)(nil).RenameFolder), username, foldername, newFoldername)
+}
+
+// RestoreFolder mocks base method.
+func (m *MockTx) RestoreFolder(username string, folder *model.Folder) (*model.Folder, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreFolder", username, folder)
+	ret0, _ := ret[0].(*model.Folder)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreFolder indicates an expected call of RestoreFolder.
+func (mr *MockTxMockRecorder) RestoreFolder(username, folder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreFolder", reflect.TypeOf((*MockTx)(nil).RestoreFolder), username, folder)
+}
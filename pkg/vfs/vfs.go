@@ -3,6 +3,8 @@
 package vfs
 
 import (
+	"time"
+
 	"github.com/blackhorseya/iscool-assessment/entity/model"
 )
 
@@ -11,12 +13,134 @@ type VirtualFileSystem interface {
 	// RegisterUser registers a new user.
 	RegisterUser(username string) (item *model.User, err error)
 
+	// DeleteUser removes username with its folders when cascade, failing when it has any otherwise, and
+	// RenameUser renames it with its folders. Both update the shares granted to username and its groups,
+	// where it can't be the last member.
+	DeleteUser(username string, cascade bool) (err error)
+	RenameUser(username, newUsername string) (item *model.User, err error)
+
+	// ListUsers lists the users by username, ascending unless order is desc, keeping the limit users after
+	// offset, all of them without a limit.
+	ListUsers(order string, offset, limit int) (items []*model.User, err error)
+
+	// As returns the file system acting as the user actor for the usernames given: an admin works on behalf of
+	// any user and passes the permission checks, the others only act as themselves. A readonly actor, like a
+	// readonly username without one, fails to change anything with ErrPermissionDenied.
+	As(actor string) VirtualFileSystem
+
+	// GrantRole gives username the role, and RevokeRole takes it back, leaving username a member. Only an
+	// admin actor changes the roles. Without an actor, only the first admin of a store without any is granted.
+	GrantRole(username string, role model.Role) (item *model.User, err error)
+	RevokeRole(username string, role model.Role) (item *model.User, err error)
+
+	// SetPassword sets the password username logs in with. Login starts a session of username lasting ttl
+	// when the password matches, returning its token, and Logout ends it. Authenticate returns username when
+	// the token is the one of its sessions not expired yet. They fail with ErrUnauthenticated otherwise.
+	SetPassword(username, password string) (item *model.User, err error)
+	Login(username, password string, ttl time.Duration) (token string, err error)
+	Logout(username, token string) (err error)
+	Authenticate(username, token string) (item *model.User, err error)
+
+	// CreateToken creates an API token of username granting the scopes for ttl, returned only then along with
+	// the token kept. ListTokens lists the tokens of username, expired or not, and RevokeToken removes one by ID.
+	// AuthenticateToken returns the user of a token not expired yet, failing with ErrUnauthenticated otherwise.
+	CreateToken(username string, scopes []model.Scope, ttl time.Duration) (token string, item *model.Token, err error)
+	ListTokens(username string) (items []*model.Token, err error)
+	RevokeToken(username, id string) (err error)
+	AuthenticateToken(token string) (user *model.User, item *model.Token, err error)
+
+	// SetQuota replaces the quota of username, or of the group named group:<name>, an unlimited one removing it,
+	// which only an admin actor changes like the roles. Usage reports the folders and files of username against its
+	// quota. The changes adding folders or files beyond the quota of their owner fail with ErrQuotaExceeded.
+	SetQuota(username string, quota *model.Quota) (item *model.User, err error)
+	Usage(username string) (item *model.Usage, err error)
+
+	// The files of a folder shared by another user are addressed by the foldername owner/foldername,
+	// failing with ErrPermissionDenied when the access granted isn't enough. Only the owner changes folders,
+	// along with the members of a group for its folders addressed as group:<name>/foldername.
+
+	CreateFolder(username, foldername, description string) (item *model.Folder, err error)
+	DeleteFolder(username, foldername string) (err error)
+	// ListFolders lists the folders of username, or of a group named group:<name>.
+	ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error)
+	RenameFolder(username, foldername, newFoldername string) (item *model.Folder, err error)
+
+	// RestoreFolder adds a folder with its files as they are, keeping their descriptions and created times.
+	RestoreFolder(username string, folder *model.Folder) (item *model.Folder, err error)
+
+	// CopyFolder and MoveFolder put a folder with its files at newFoldername of newUsername,
+	// the same or another user, or between the groups of username.
+	CopyFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error)
+	MoveFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error)
+
+	// ShareFolder grants grantee access to a folder of username, replacing a previous grant,
+	// and UnshareFolder revokes it.
+	ShareFolder(username, foldername, grantee string, access model.Access) (err error)
+	UnshareFolder(username, foldername, grantee string) (err error)
+
+	// ListShares lists the folders username has shared with the others, then the ones shared with username.
+	ListShares(username string) (items []*model.Share, err error)
+
+	// TagFolder adds tags to a folder, which only its owners change, and UntagFolder removes them.
+	TagFolder(username, foldername string, tags []string) (item *model.Folder, err error)
+	UntagFolder(username, foldername string, tags []string) (item *model.Folder, err error)
+
+	// ListTaggedFolders lists the folders of username, or of a group as group:<name>, tagged tag.
+	ListTaggedFolders(username, tag string, sortBy string, order string) (items []*model.Folder, err error)
+
+	// Tags counts the folders and files of username, or of a group as group:<name>, by tag.
+	Tags(username string) (items []*model.TagCount, err error)
+
+	// CreateGroup creates a group with username as its first member, then AddMember and RemoveMember
+	// change its members, which only the members can do.
+	CreateGroup(username, name string) (item *model.Group, err error)
+	AddMember(username, name, member string) (item *model.Group, err error)
+	RemoveMember(username, name, member string) (item *model.Group, err error)
+
+	// ListGroups lists the groups username belongs to.
+	ListGroups(username string) (items []*model.Group, err error)
+
+	CreateFile(username, foldername, filename, description string) (item *model.File, err error)
+	DeleteFile(username, foldername, filename string) (err error)
+	ListFiles(username, foldername string, sortBy string, order string) (items []*model.File, err error)
+
+	// TagFile and UntagFile change the tags of a file, which the users allowed to write its folder do,
+	// and ListTaggedFiles lists the files of a folder tagged tag.
+	TagFile(username, foldername, filename string, tags []string) (item *model.File, err error)
+	UntagFile(username, foldername, filename string, tags []string) (item *model.File, err error)
+	ListTaggedFiles(username, foldername, tag string, sortBy string, order string) (items []*model.File, err error)
+
+	// RenameFile, MoveFile and CopyFile resolve an existing destination file with conflict, returning
+	// a nil item when it's skipped. The files stay with the owner of the folder.
+	RenameFile(username, foldername, filename, newFilename string, conflict model.Conflict) (item *model.File, err error)
+	MoveFile(username, foldername, filename, newFoldername string, conflict model.Conflict) (item *model.File, err error)
+	CopyFile(
+		username, foldername, filename, newFoldername, newFilename string,
+		conflict model.Conflict,
+	) (item *model.File, err error)
+
+	// Tx runs fn in a transaction: the changes made through tx are committed all at once when fn succeeds,
+	// and rolled back when it returns an error. The folders of the groups can't be changed through tx.
+	Tx(fn func(tx Tx) error) (err error)
+}
+
+// Tx represents the folders and files of the virtual file system within a transaction.
+type Tx interface {
 	CreateFolder(username, foldername, description string) (item *model.Folder, err error)
 	DeleteFolder(username, foldername string) (err error)
 	ListFolders(username string, sortBy string, order string) (items []*model.Folder, err error)
 	RenameFolder(username, foldername, newFoldername string) (item *model.Folder, err error)
+	RestoreFolder(username string, folder *model.Folder) (item *model.Folder, err error)
+	CopyFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error)
+	MoveFolder(username, foldername, newUsername, newFoldername string) (item *model.Folder, err error)
 
 	CreateFile(username, foldername, filename, description string) (item *model.File, err error)
 	DeleteFile(username, foldername, filename string) (err error)
 	ListFiles(username, foldername string, sortBy string, order string) (items []*model.File, err error)
+	RenameFile(username, foldername, filename, newFilename string, conflict model.Conflict) (item *model.File, err error)
+	MoveFile(username, foldername, filename, newFoldername string, conflict model.Conflict) (item *model.File, err error)
+	CopyFile(
+		username, foldername, filename, newFoldername, newFilename string,
+		conflict model.Conflict,
+	) (item *model.File, err error)
 }